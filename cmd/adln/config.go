@@ -175,6 +175,13 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, cfg.Ethstats.URL)
 	}
+	// Add the GraphQL endpoint if requested, served by the HTTP-RPC server.
+	if ctx.GlobalBool(utils.GraphQLEnabledFlag.Name) {
+		if cfg.Node.HTTPHost == "" {
+			utils.Fatalf("GraphQL requires the HTTP-RPC server to be enabled (--rpc)")
+		}
+		utils.RegisterGraphQLService(stack, ctx.GlobalBool(utils.GraphQLUIFlag.Name))
+	}
 	return stack
 }
 
@@ -71,6 +71,8 @@ var (
 		utils.EthashDatasetDirFlag,
 		utils.EthashDatasetsInMemoryFlag,
 		utils.EthashDatasetsOnDiskFlag,
+		utils.EthashSealProofsFlag,
+		utils.EthashDagRootsFlag,
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
@@ -87,6 +89,7 @@ var (
 		utils.GCModeFlag,
 		utils.LightServFlag,
 		utils.LightPeersFlag,
+		utils.LightServSealProofsFlag,
 		utils.LightKDFFlag,
 		utils.CacheFlag,
 		utils.CacheDatabaseFlag,
@@ -103,11 +106,13 @@ var (
 		utils.NATFlag,
 		utils.NoDiscoverFlag,
 		utils.DiscoveryV5Flag,
+		utils.DNSDiscoveryFlag,
 		utils.NetrestrictFlag,
 		utils.NodeKeyFileFlag,
 		utils.NodeKeyHexFlag,
 		utils.DeveloperFlag,
 		utils.DeveloperPeriodFlag,
+		utils.DeveloperEthashFlag,
 		utils.TestnetFlag,
 		utils.RinkebyFlag,
 		utils.VMEnableDebugFlag,
@@ -121,6 +126,10 @@ var (
 		utils.GpoBlocksFlag,
 		utils.GpoPercentileFlag,
 		utils.ExtraDataFlag,
+		utils.MinerRecommitIntervalFlag,
+		utils.MinerNotifyFlag,
+		utils.StratumAddrFlag,
+		utils.StratumDifficultyFlag,
 		configFileFlag,
 	}
 
@@ -134,6 +143,16 @@ var (
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.AuthRPCJWTSecretFlag,
+		utils.AuthRPCModulesFlag,
+		utils.RPCSlowLogFlag,
+		utils.RPCBatchLimitFlag,
+		utils.RPCResponseLimitFlag,
+		utils.HealthMinPeersFlag,
+		utils.HealthMaxHeadAgeFlag,
+		utils.HealthSyncedFlag,
+		utils.GraphQLEnabledFlag,
+		utils.GraphQLUIFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 	}
@@ -170,6 +189,7 @@ func init() {
 		// See misccmd.go:
 		makecacheCommand,
 		makedagCommand,
+		makedagrootCommand,
 		versionCommand,
 		bugCommand,
 		licenseCommand,
@@ -55,6 +55,18 @@ The makedag command generates an ethash DAG in <outputDir>.
 
 This command exists to support the system testing project.
 Regular users do not need to execute it.
+`,
+	}
+	makedagrootCommand = cli.Command{
+		Action:    utils.MigrateFlags(makedagroot),
+		Name:      "makedagroot",
+		Usage:     "Generate ethash mining DAG and print its Merkle root",
+		ArgsUsage: "<blockNum> <outputDir>",
+		Category:  "MISCELLANEOUS COMMANDS",
+		Description: `
+The makedagroot command generates an ethash DAG in <outputDir> and prints the
+root hash of its Merkle tree. The roots of past epochs may be passed to light
+clients via --ethash.dagroots to verify seals via proofs served by their peers.
 `,
 	}
 	versionCommand = cli.Command{
@@ -106,6 +118,22 @@ func makedag(ctx *cli.Context) error {
 	return nil
 }
 
+// makedagroot generates an ethash mining DAG into the provided folder and prints
+// the root hash of its Merkle tree.
+func makedagroot(ctx *cli.Context) error {
+	args := ctx.Args()
+	if len(args) != 2 {
+		utils.Fatalf(`Usage: geth makedagroot <block number> <outputdir>`)
+	}
+	block, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		utils.Fatalf("Invalid block number: %v", err)
+	}
+	fmt.Println(ethash.MakeDatasetRoot(block, args[1]).Hex())
+
+	return nil
+}
+
 func version(ctx *cli.Context) error {
 	fmt.Println(strings.Title(clientIdentifier))
 	fmt.Println("Version:", params.Version)
@@ -80,6 +80,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.IdentityFlag,
 			utils.LightServFlag,
 			utils.LightPeersFlag,
+			utils.LightServSealProofsFlag,
 			utils.LightKDFFlag,
 		},
 	},
@@ -87,6 +88,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.DeveloperFlag,
 			utils.DeveloperPeriodFlag,
+			utils.DeveloperEthashFlag,
 		},
 	},
 	{
@@ -98,6 +100,8 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.EthashDatasetDirFlag,
 			utils.EthashDatasetsInMemoryFlag,
 			utils.EthashDatasetsOnDiskFlag,
+			utils.EthashSealProofsFlag,
+			utils.EthashDagRootsFlag,
 		},
 	},
 	//{
@@ -153,6 +157,16 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.WSPortFlag,
 			utils.WSApiFlag,
 			utils.WSAllowedOriginsFlag,
+			utils.AuthRPCJWTSecretFlag,
+			utils.AuthRPCModulesFlag,
+			utils.RPCSlowLogFlag,
+			utils.RPCBatchLimitFlag,
+			utils.RPCResponseLimitFlag,
+			utils.HealthMinPeersFlag,
+			utils.HealthMaxHeadAgeFlag,
+			utils.HealthSyncedFlag,
+			utils.GraphQLEnabledFlag,
+			utils.GraphQLUIFlag,
 			utils.IPCDisabledFlag,
 			utils.IPCPathFlag,
 			utils.RPCCORSDomainFlag,
@@ -174,6 +188,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.NATFlag,
 			utils.NoDiscoverFlag,
 			utils.DiscoveryV5Flag,
+			utils.DNSDiscoveryFlag,
 			utils.NetrestrictFlag,
 			utils.NodeKeyFileFlag,
 			utils.NodeKeyHexFlag,
@@ -188,6 +203,10 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TargetGasLimitFlag,
 			utils.GasPriceFlag,
 			utils.ExtraDataFlag,
+			utils.MinerRecommitIntervalFlag,
+			utils.MinerNotifyFlag,
+			utils.StratumAddrFlag,
+			utils.StratumDifficultyFlag,
 		},
 	},
 	{
@@ -0,0 +1,244 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/cmd/utils"
+	"github.com/AdelineCoin/go-adln/core/forkid"
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/p2p/discover"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
+	"github.com/AdelineCoin/go-adln/params"
+	"github.com/AdelineCoin/go-adln/rlp"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandDiscv4 = cli.Command{
+	Name:  "discv4",
+	Usage: "Node Discovery v4 commands",
+	Subcommands: []cli.Command{
+		commandDiscv4Crawl,
+	},
+}
+
+var commandDiscv4Crawl = cli.Command{
+	Name:      "crawl",
+	Usage:     "collect the node records of the network into a nodes.json file",
+	ArgsUsage: "<nodes.json>",
+	Description: `
+Crawl the network using random lookups and request the signed node record
+(EIP-868) of every node found. The records are merged into the given nodes.json
+file, keeping the latest record of each node, so that crawls can be resumed.
+The crawl runs until the timeout expires or it is interrupted.
+
+As the discovery network is shared with other chains, only records announcing
+an IP address, a TCP port and a fork ID (EIP-2124) compatible with the chain of
+the genesis file given by --genesis are kept. The main network is assumed by
+default.
+
+A tree directory for the dns commands is created by crawling into its
+nodes.json file.`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "bootnodes",
+			Usage: "comma separated enode URLs to start the crawl from (defaults to the mainnet bootnodes)",
+		},
+		cli.StringFlag{
+			Name:  "genesis",
+			Usage: "genesis file of the chain to collect the nodes of (defaults to the main network)",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "duration of the crawl",
+			Value: 30 * time.Minute,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() < 1 {
+			utils.Fatalf("Need the nodes.json file as argument")
+		}
+		file := ctx.Args().Get(0)
+		ns := make(nodeSet)
+		if err := readJSON(file, &ns); err != nil && !os.IsNotExist(err) {
+			utils.Fatalf("Failed to read %s: %v", file, err)
+		}
+		if ns == nil {
+			ns = make(nodeSet)
+		}
+		tab := startDiscv4(ctx)
+		defer tab.Close()
+
+		genesis := loadGenesis(ctx.String("genesis"))
+		c := &crawler{
+			tab:       tab,
+			filter:    forkid.NewStaticFilter(genesis.Config, genesis.ToBlock(nil).Hash()),
+			nodes:     ns,
+			requested: make(map[discover.NodeID]bool),
+		}
+		c.run(ctx.Duration("timeout"))
+		writeJSON(file, ns)
+		fmt.Printf("Crawled %d nodes, %d records added or updated, %d rejected, %d nodes in %s\n", len(c.requested), c.updated, c.rejected, len(ns), file)
+		return nil
+	},
+}
+
+// startDiscv4 starts a discovery table under a throwaway identity, bootstrapping
+// from the nodes given on the command line.
+func startDiscv4(ctx *cli.Context) *discover.Table {
+	urls := params.MainnetBootnodes
+	if ctx.IsSet("bootnodes") {
+		urls = strings.Split(ctx.String("bootnodes"), ",")
+	}
+	bootnodes := make([]*discover.Node, 0, len(urls))
+	for _, url := range urls {
+		n, err := discover.ParseNode(url)
+		if err != nil {
+			utils.Fatalf("Invalid bootnode %q: %v", url, err)
+		}
+		bootnodes = append(bootnodes, n)
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		utils.Fatalf("Failed to generate node key: %v", err)
+	}
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{})
+	if err != nil {
+		utils.Fatalf("Failed to listen: %v", err)
+	}
+	tab, err := discover.ListenUDP(conn, discover.Config{PrivateKey: key, Bootnodes: bootnodes})
+	if err != nil {
+		utils.Fatalf("Failed to start discovery: %v", err)
+	}
+	return tab
+}
+
+// ethEntry is the "eth" ENR entry announcing the fork ID of a node's chain.
+type ethEntry struct {
+	ForkID forkid.ID
+	Rest   []rlp.RawValue `rlp:"tail"`
+}
+
+// ENRKey implements enr.Entry.
+func (e ethEntry) ENRKey() string {
+	return "eth"
+}
+
+// crawler collects node records through random lookups.
+type crawler struct {
+	tab       *discover.Table
+	nodes     nodeSet
+	filter    forkid.Filter            // Fork ID filter of the crawled chain
+	requested map[discover.NodeID]bool // Nodes whose record was requested in this crawl
+	updated   int                      // Number of records added or updated
+	rejected  int                      // Number of records of other chains or without endpoint
+
+	lock sync.Mutex // Protects nodes, updated and rejected
+}
+
+// run performs lookups until the timeout expires or the process is interrupted.
+func (c *crawler) run(timeout time.Duration) {
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, os.Interrupt)
+	defer signal.Stop(sigc)
+
+	deadline := time.After(timeout)
+	for {
+		select {
+		case <-deadline:
+			return
+		case <-sigc:
+			return
+		default:
+		}
+		var target discover.NodeID
+		rand.Read(target[:])
+		c.requestRecords(c.tab.Lookup(target))
+	}
+}
+
+// requestRecords requests the records of the given nodes not asked yet.
+func (c *crawler) requestRecords(nodes []*discover.Node) {
+	var wg sync.WaitGroup
+	for _, n := range nodes {
+		if c.requested[n.ID] {
+			continue
+		}
+		c.requested[n.ID] = true
+
+		wg.Add(1)
+		go func(n *discover.Node) {
+			defer wg.Done()
+			if r, err := c.tab.RequestENR(n); err == nil {
+				c.add(n.ID, r)
+			}
+		}(n)
+	}
+	wg.Wait()
+}
+
+// add stores a node record unless a newer one is known, or the record doesn't
+// belong to a reachable node of the crawled chain.
+func (c *crawler) add(id discover.NodeID, r *enr.Record) {
+	text, err := discover.RecordString(r)
+	if err != nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.check(r); err != nil {
+		c.rejected++
+		return
+	}
+	key := id.String()
+	if old, ok := c.nodes[key]; ok && old.Seq >= r.Seq() {
+		return
+	}
+	c.nodes[key] = nodeJSON{Seq: r.Seq(), Record: text}
+	c.updated++
+}
+
+// check verifies that a record announces a TCP endpoint and a fork ID accepted
+// by the filter of the crawled chain.
+func (c *crawler) check(r *enr.Record) error {
+	var (
+		ip4 enr.IP4
+		ip6 enr.IP6
+		tcp enr.TCP
+		eth ethEntry
+	)
+	if r.Load(&ip4) != nil && r.Load(&ip6) != nil {
+		return errors.New("no IP address")
+	}
+	if err := r.Load(&tcp); err != nil {
+		return err
+	}
+	if err := r.Load(&eth); err != nil {
+		return err
+	}
+	return c.filter(eth.ForkID)
+}
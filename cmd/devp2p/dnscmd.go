@@ -0,0 +1,296 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/AdelineCoin/go-adln/cmd/utils"
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/p2p/discover"
+	"github.com/AdelineCoin/go-adln/p2p/dnsdisc"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandDNS = cli.Command{
+	Name:  "dns",
+	Usage: "DNS node list (EIP-1459) commands",
+	Description: `
+Node lists are kept in a tree directory containing the files
+
+  nodes.json          the node records of the list, keyed by node ID
+  enrtree-info.json   the sequence number, signature, URL and links of the list
+
+The nodes.json file can be created using 'devp2p discv4 crawl' or downloaded
+using the sync command. Once signed, the TXT records to deploy can be created
+using to-txt.`,
+	Subcommands: []cli.Command{
+		commandDNSSync,
+		commandDNSSign,
+		commandDNSToTXT,
+	},
+}
+
+var commandDNSSync = cli.Command{
+	Name:      "sync",
+	Usage:     "download a DNS node list into a tree directory",
+	ArgsUsage: "<url> [<tree-directory>]",
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() < 1 {
+			utils.Fatalf("Need the enrtree:// URL of the list as argument")
+		}
+		url := ctx.Args().Get(0)
+		domain, _, err := dnsdisc.ParseURL(url)
+		if err != nil {
+			utils.Fatalf("Invalid list URL: %v", err)
+		}
+		dir := domain
+		if ctx.NArg() > 1 {
+			dir = ctx.Args().Get(1)
+		}
+		client, err := dnsdisc.NewClient(dnsdisc.Config{})
+		if err != nil {
+			utils.Fatalf("Failed to create DNS client: %v", err)
+		}
+		defer client.Close()
+
+		t, err := client.SyncTree(url)
+		if err != nil {
+			utils.Fatalf("Failed to sync list: %v", err)
+		}
+		def := &treeDefinition{
+			meta: treeMeta{
+				URL:   url,
+				Seq:   t.Seq(),
+				Sig:   t.Signature(),
+				Links: t.Links(),
+			},
+			nodes: makeNodeSet(t.Records()),
+		}
+		writeTreeDefinition(dir, def)
+		fmt.Printf("Downloaded %d nodes and %d links into %s\n", len(def.nodes), len(def.meta.Links), dir)
+		return nil
+	},
+}
+
+var commandDNSSign = cli.Command{
+	Name:      "sign",
+	Usage:     "sign the node list in a tree directory",
+	ArgsUsage: "<tree-directory> <key-file>",
+	Description: `
+Sign the node list in the tree directory using the hex encoded private key in
+the key file, as created by 'bootnode -genkey'. The URL of the signed list is
+printed and stored in enrtree-info.json.
+
+The sequence number of the list is increased unless set using --seq.`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "domain",
+			Usage: "domain name of the list (defaults to the domain of the current URL, or the directory name)",
+		},
+		cli.UintFlag{
+			Name:  "seq",
+			Usage: "sequence number of the list",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() < 2 {
+			utils.Fatalf("Need the tree directory and the key file as arguments")
+		}
+		var (
+			dir     = ctx.Args().Get(0)
+			keyfile = ctx.Args().Get(1)
+			def     = loadTreeDefinition(dir)
+			domain  = filepath.Base(dir)
+		)
+		if def.meta.URL != "" {
+			d, _, err := dnsdisc.ParseURL(def.meta.URL)
+			if err != nil {
+				utils.Fatalf("Invalid URL in enrtree-info.json: %v", err)
+			}
+			domain = d
+		}
+		if ctx.IsSet("domain") {
+			domain = ctx.String("domain")
+		}
+		if ctx.IsSet("seq") {
+			def.meta.Seq = ctx.Uint("seq")
+		} else {
+			def.meta.Seq++
+		}
+		key, err := crypto.LoadECDSA(keyfile)
+		if err != nil {
+			utils.Fatalf("Failed to load key: %v", err)
+		}
+		t, err := dnsdisc.MakeTree(def.meta.Seq, def.nodes.records(), def.meta.Links)
+		if err != nil {
+			utils.Fatalf("Failed to create tree: %v", err)
+		}
+		url, err := t.Sign(key, domain)
+		if err != nil {
+			utils.Fatalf("Failed to sign tree: %v", err)
+		}
+		def.meta.URL, def.meta.Sig = url, t.Signature()
+		writeJSON(filepath.Join(dir, treeMetaFile), def.meta)
+		fmt.Println(url)
+		return nil
+	},
+}
+
+var commandDNSToTXT = cli.Command{
+	Name:      "to-txt",
+	Usage:     "create the DNS TXT records of a signed node list",
+	ArgsUsage: "<tree-directory> <output-file>",
+	Description: `
+Write the TXT records of the signed node list in the tree directory to the
+output file, as a JSON object mapping record names to values. Use - as output
+file to print the records.`,
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() < 2 {
+			utils.Fatalf("Need the tree directory and the output file as arguments")
+		}
+		var (
+			dir    = ctx.Args().Get(0)
+			output = ctx.Args().Get(1)
+			def    = loadTreeDefinition(dir)
+		)
+		if def.meta.URL == "" {
+			utils.Fatalf("Node list is not signed, run 'devp2p dns sign' first")
+		}
+		domain, pubkey, err := dnsdisc.ParseURL(def.meta.URL)
+		if err != nil {
+			utils.Fatalf("Invalid URL in enrtree-info.json: %v", err)
+		}
+		t, err := dnsdisc.MakeTree(def.meta.Seq, def.nodes.records(), def.meta.Links)
+		if err != nil {
+			utils.Fatalf("Failed to create tree: %v", err)
+		}
+		if err := t.SetSignature(pubkey, def.meta.Sig); err != nil {
+			utils.Fatalf("Invalid signature, the list changed since signing: %v", err)
+		}
+		writeJSON(output, t.ToTXT(domain))
+		return nil
+	},
+}
+
+const (
+	treeNodesFile = "nodes.json"
+	treeMetaFile  = "enrtree-info.json"
+)
+
+// treeDefinition is the content of a tree directory.
+type treeDefinition struct {
+	meta  treeMeta
+	nodes nodeSet
+}
+
+// treeMeta is the content of enrtree-info.json.
+type treeMeta struct {
+	URL   string   `json:"url,omitempty"`
+	Seq   uint     `json:"seq"`
+	Sig   string   `json:"signature,omitempty"`
+	Links []string `json:"links"`
+}
+
+// nodeSet is the content of nodes.json.
+type nodeSet map[string]nodeJSON
+
+type nodeJSON struct {
+	Seq    uint64 `json:"seq"`
+	Record string `json:"record"`
+}
+
+func makeNodeSet(records []*enr.Record) nodeSet {
+	ns := make(nodeSet, len(records))
+	for _, r := range records {
+		n, err := discover.NodeFromRecord(r)
+		if err != nil {
+			utils.Fatalf("Invalid node record: %v", err)
+		}
+		text, err := discover.RecordString(r)
+		if err != nil {
+			utils.Fatalf("Invalid node record: %v", err)
+		}
+		ns[n.ID.String()] = nodeJSON{Seq: r.Seq(), Record: text}
+	}
+	return ns
+}
+
+// records parses the node records of the set.
+func (ns nodeSet) records() []*enr.Record {
+	records := make([]*enr.Record, 0, len(ns))
+	for id, n := range ns {
+		r, err := discover.ParseRecord(n.Record)
+		if err != nil {
+			utils.Fatalf("Invalid record of node %s: %v", id, err)
+		}
+		records = append(records, r)
+	}
+	return records
+}
+
+// loadTreeDefinition reads a tree directory. A missing enrtree-info.json is
+// treated as an unsigned list without links.
+func loadTreeDefinition(dir string) *treeDefinition {
+	def := new(treeDefinition)
+	if err := readJSON(filepath.Join(dir, treeMetaFile), &def.meta); err != nil && !os.IsNotExist(err) {
+		utils.Fatalf("Failed to read %s: %v", treeMetaFile, err)
+	}
+	if err := readJSON(filepath.Join(dir, treeNodesFile), &def.nodes); err != nil {
+		utils.Fatalf("Failed to read %s: %v", treeNodesFile, err)
+	}
+	return def
+}
+
+// writeTreeDefinition writes a tree directory, creating it if necessary.
+func writeTreeDefinition(dir string, def *treeDefinition) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		utils.Fatalf("Failed to create directory: %v", err)
+	}
+	writeJSON(filepath.Join(dir, treeMetaFile), def.meta)
+	writeJSON(filepath.Join(dir, treeNodesFile), def.nodes)
+}
+
+func readJSON(file string, v interface{}) error {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
+
+// writeJSON writes the indented JSON encoding of v to the given file, or to
+// stdout if the file name is "-".
+func writeJSON(file string, v interface{}) {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to encode JSON: %v", err)
+	}
+	content = append(content, '\n')
+	if file == "-" {
+		os.Stdout.Write(content)
+		return
+	}
+	if err := ioutil.WriteFile(file, content, 0644); err != nil {
+		utils.Fatalf("Failed to write %s: %v", file, err)
+	}
+}
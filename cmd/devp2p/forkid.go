@@ -0,0 +1,76 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/AdelineCoin/go-adln/cmd/utils"
+	"github.com/AdelineCoin/go-adln/core"
+	"github.com/AdelineCoin/go-adln/core/forkid"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandForkID = cli.Command{
+	Name:      "forkid",
+	Usage:     "print the fork identifier of a chain",
+	ArgsUsage: "[<genesis.json>]",
+	Description: `
+Print the EIP-2124 fork identifier announced by nodes of the chain defined by
+the given genesis file, or of the main network if no file is given.
+
+The head block of the announcing node can be set using the --block flag.`,
+	Flags: []cli.Flag{
+		cli.Uint64Flag{
+			Name:  "block",
+			Usage: "head block number of the announcing node",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		genesis := loadGenesis(ctx.Args().First())
+		hash := genesis.ToBlock(nil).Hash()
+		id := forkid.NewIDWithGenesis(genesis.Config, hash, ctx.Uint64("block"))
+
+		fmt.Printf("Genesis: %x\n", hash)
+		fmt.Printf("Fork ID: %#x (next: %d)\n", id.Hash, id.Next)
+		return nil
+	},
+}
+
+// loadGenesis reads the genesis file at the given path, or returns the genesis
+// of the main network if the path is empty.
+func loadGenesis(path string) *core.Genesis {
+	genesis := core.DefaultGenesisBlock()
+	if path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			utils.Fatalf("Failed to read genesis file: %v", err)
+		}
+		defer file.Close()
+
+		genesis = new(core.Genesis)
+		if err := json.NewDecoder(file).Decode(genesis); err != nil {
+			utils.Fatalf("Invalid genesis file: %v", err)
+		}
+	}
+	if genesis.Config == nil {
+		utils.Fatalf("Genesis has no chain configuration")
+	}
+	return genesis
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -32,7 +33,9 @@ import (
 	"github.com/AdelineCoin/go-adln/accounts/keystore"
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/common/fdlimit"
+	"github.com/AdelineCoin/go-adln/common/hexutil"
 	"github.com/AdelineCoin/go-adln/consensus"
+	"github.com/AdelineCoin/go-adln/consensus/authority"
 	"github.com/AdelineCoin/go-adln/consensus/clique"
 	"github.com/AdelineCoin/go-adln/consensus/ethash"
 	"github.com/AdelineCoin/go-adln/core"
@@ -45,6 +48,7 @@ import (
 	"github.com/AdelineCoin/go-adln/eth/gasprice"
 	"github.com/AdelineCoin/go-adln/ethdb"
 	"github.com/AdelineCoin/go-adln/ethstats"
+	"github.com/AdelineCoin/go-adln/graphql"
 	"github.com/AdelineCoin/go-adln/les"
 	"github.com/AdelineCoin/go-adln/log"
 	"github.com/AdelineCoin/go-adln/metrics"
@@ -52,6 +56,7 @@ import (
 	"github.com/AdelineCoin/go-adln/p2p"
 	"github.com/AdelineCoin/go-adln/p2p/discover"
 	"github.com/AdelineCoin/go-adln/p2p/discv5"
+	"github.com/AdelineCoin/go-adln/p2p/dnsdisc"
 	"github.com/AdelineCoin/go-adln/p2p/nat"
 	"github.com/AdelineCoin/go-adln/p2p/netutil"
 	"github.com/AdelineCoin/go-adln/params"
@@ -147,6 +152,10 @@ var (
 		Name:  "dev.period",
 		Usage: "Block period to use in developer mode (0 = mine only if transaction pending)",
 	}
+	DeveloperEthashFlag = cli.BoolFlag{
+		Name:  "dev.ethash",
+		Usage: "Use the ethash rules of the main network in developer mode, sealing with a fake proof-of-work",
+	}
 	IdentityFlag = cli.StringFlag{
 		Name:  "identity",
 		Usage: "Custom node name",
@@ -185,6 +194,10 @@ var (
 		Usage: "Maximum number of LES client peers",
 		Value: eth.DefaultConfig.LightPeers,
 	}
+	LightServSealProofsFlag = cli.BoolFlag{
+		Name:  "lightserv.sealproofs",
+		Usage: "Serve ethash seal proofs to light clients (builds the full DAG of the current epoch)",
+	}
 	LightKDFFlag = cli.BoolFlag{
 		Name:  "lightkdf",
 		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
@@ -239,6 +252,14 @@ var (
 		Usage: "Number of recent ethash mining DAGs to keep on disk (1+GB each)",
 		Value: eth.DefaultConfig.Ethash.DatasetsOnDisk,
 	}
+	EthashSealProofsFlag = cli.BoolFlag{
+		Name:  "ethash.sealproofs",
+		Usage: "Verify ethash seals via DAG proofs from light servers instead of the verification cache",
+	}
+	EthashDagRootsFlag = cli.StringFlag{
+		Name:  "ethash.dagroots",
+		Usage: "Comma separated trusted ethash DAG Merkle roots, indexed by epoch (for --ethash.sealproofs)",
+	}
 	// Transaction pool settings
 	TxPoolNoLocalsFlag = cli.BoolFlag{
 		Name:  "txpool.nolocals",
@@ -339,6 +360,23 @@ var (
 		Name:  "extradata",
 		Usage: "Block extra data set by the miner (default = client version)",
 	}
+	MinerRecommitIntervalFlag = cli.DurationFlag{
+		Name:  "miner.recommit",
+		Usage: "Time interval to recreate the block being mined with new transactions (0 = disabled)",
+		Value: eth.DefaultConfig.MinerRecommit,
+	}
+	MinerNotifyFlag = cli.StringFlag{
+		Name:  "miner.notify",
+		Usage: "Comma separated HTTP URL list to notify of new work packages",
+	}
+	StratumAddrFlag = cli.StringFlag{
+		Name:  "stratum.addr",
+		Usage: "Listen address of the built-in stratum mining server (empty = disabled)",
+	}
+	StratumDifficultyFlag = cli.Uint64Flag{
+		Name:  "stratum.difficulty",
+		Usage: "Default share difficulty of stratum workers (0 = block difficulty)",
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -434,6 +472,51 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	AuthRPCJWTSecretFlag = cli.StringFlag{
+		Name:  "authrpc.jwtsecret",
+		Usage: "Path to a hex encoded 32 byte secret authenticating HTTP and WS-RPC requests (HS256 JWT)",
+		Value: "",
+	}
+	AuthRPCModulesFlag = cli.StringFlag{
+		Name:  "authrpc.modules",
+		Usage: "Per-token API allowlists keyed by the token subject (e.g. 'alice=eth,net;bob=web3')",
+		Value: "",
+	}
+	RPCSlowLogFlag = cli.DurationFlag{
+		Name:  "rpc.slowlog",
+		Usage: "Log parameters and timing of RPC calls slower than this (0 = disabled)",
+		Value: 0,
+	}
+	RPCBatchLimitFlag = cli.IntFlag{
+		Name:  "rpc.batchlimit",
+		Usage: "Maximum number of requests in an HTTP or WS-RPC batch (0 = unlimited)",
+		Value: node.DefaultConfig.RPCBatchLimit,
+	}
+	RPCResponseLimitFlag = cli.IntFlag{
+		Name:  "rpc.responselimit",
+		Usage: "Maximum size in bytes of an HTTP or WS-RPC (batch) response (0 = unlimited)",
+		Value: node.DefaultConfig.RPCResponseLimit,
+	}
+	HealthMinPeersFlag = cli.IntFlag{
+		Name:  "health.minpeers",
+		Usage: "Minimum number of connected peers for the HTTP /ready endpoint to succeed (0 = unchecked)",
+	}
+	HealthMaxHeadAgeFlag = cli.DurationFlag{
+		Name:  "health.maxheadage",
+		Usage: "Maximum age of the chain head for the HTTP /ready endpoint to succeed (0 = unchecked)",
+	}
+	HealthSyncedFlag = cli.BoolFlag{
+		Name:  "health.synced",
+		Usage: "Require chain synchronisation to be complete for the HTTP /ready endpoint to succeed",
+	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL query endpoint on the HTTP-RPC server (/graphql)",
+	}
+	GraphQLUIFlag = cli.BoolFlag{
+		Name:  "graphql.ui",
+		Usage: "Serve a query explorer alongside the GraphQL endpoint (/graphql/ui)",
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -495,6 +578,11 @@ var (
 		Name:  "v5disc",
 		Usage: "Enables the experimental RLPx V5 (Topic Discovery) mechanism",
 	}
+	DNSDiscoveryFlag = cli.StringFlag{
+		Name:  "discovery.dns",
+		Usage: "Comma separated enrtree:// URLs of DNS node lists to dial peers from",
+		Value: "",
+	}
 	NetrestrictFlag = cli.StringFlag{
 		Name:  "netrestrict",
 		Usage: "Restricts network communication to the given IP networks (CIDR masks)",
@@ -642,6 +730,48 @@ func setBootstrapNodesV5(ctx *cli.Context, cfg *p2p.Config) {
 	}
 }
 
+// setDNSDiscovery configures the DNS node lists used as additional dial
+// candidates from the command line flags.
+func setDNSDiscovery(ctx *cli.Context, cfg *p2p.Config) {
+	if !ctx.GlobalIsSet(DNSDiscoveryFlag.Name) {
+		return
+	}
+	cfg.DNSDiscovery = nil
+	for _, url := range strings.Split(ctx.GlobalString(DNSDiscoveryFlag.Name), ",") {
+		if url = strings.TrimSpace(url); url == "" {
+			continue
+		}
+		if _, _, err := dnsdisc.ParseURL(url); err != nil {
+			log.Error("DNS node list URL invalid", "url", url, "err", err)
+			continue
+		}
+		cfg.DNSDiscovery = append(cfg.DNSDiscovery, url)
+	}
+}
+
+// setMinerNotify creates the list of URLs to notify of new mining work from the
+// command line flags, dropping empty and invalid entries.
+func setMinerNotify(ctx *cli.Context, cfg *eth.Config) {
+	if !ctx.GlobalIsSet(MinerNotifyFlag.Name) {
+		return
+	}
+	cfg.MinerNotify = nil
+	for _, target := range strings.Split(ctx.GlobalString(MinerNotifyFlag.Name), ",") {
+		if target = strings.TrimSpace(target); target == "" {
+			continue
+		}
+		u, err := url.Parse(target)
+		if err == nil && ((u.Scheme != "http" && u.Scheme != "https") || u.Host == "") {
+			err = fmt.Errorf("not an absolute http(s) URL")
+		}
+		if err != nil {
+			log.Error("Mining notification URL invalid", "url", target, "err", err)
+			continue
+		}
+		cfg.MinerNotify = append(cfg.MinerNotify, target)
+	}
+}
+
 // setListenAddress creates a TCP listening address string from set command
 // line flags.
 func setListenAddress(ctx *cli.Context, cfg *p2p.Config) {
@@ -693,6 +823,12 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(RPCVirtualHostsFlag.Name) {
 		cfg.HTTPVirtualHosts = splitAndTrim(ctx.GlobalString(RPCVirtualHostsFlag.Name))
 	}
+	if ctx.GlobalIsSet(AuthRPCJWTSecretFlag.Name) {
+		cfg.HTTPJWTSecret = ctx.GlobalString(AuthRPCJWTSecretFlag.Name)
+	}
+	if ctx.GlobalIsSet(AuthRPCModulesFlag.Name) {
+		cfg.HTTPJWTModules = parseModuleAllowlists(ctx.GlobalString(AuthRPCModulesFlag.Name))
+	}
 }
 
 // setWS creates the WebSocket RPC listener interface string from the set
@@ -714,6 +850,29 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(WSApiFlag.Name) {
 		cfg.WSModules = splitAndTrim(ctx.GlobalString(WSApiFlag.Name))
 	}
+	if ctx.GlobalIsSet(AuthRPCJWTSecretFlag.Name) {
+		cfg.WSJWTSecret = ctx.GlobalString(AuthRPCJWTSecretFlag.Name)
+	}
+	if ctx.GlobalIsSet(AuthRPCModulesFlag.Name) {
+		cfg.WSJWTModules = parseModuleAllowlists(ctx.GlobalString(AuthRPCModulesFlag.Name))
+	}
+}
+
+// parseModuleAllowlists converts a list of semicolon separated subject=modules
+// entries into per-token API module allowlists.
+func parseModuleAllowlists(input string) map[string][]string {
+	allowlists := make(map[string][]string)
+	for _, entry := range strings.Split(input, ";") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			Fatalf("Invalid token module allowlist %q, want subject=module,...", entry)
+		}
+		allowlists[strings.TrimSpace(parts[0])] = splitAndTrim(parts[1])
+	}
+	return allowlists
 }
 
 // setIPC creates an IPC path configuration from the set command line flags,
@@ -807,6 +966,7 @@ func SetP2PConfig(ctx *cli.Context, cfg *p2p.Config) {
 	setListenAddress(ctx, cfg)
 	setBootstrapNodes(ctx, cfg)
 	setBootstrapNodesV5(ctx, cfg)
+	setDNSDiscovery(ctx, cfg)
 
 	lightClient := ctx.GlobalBool(LightModeFlag.Name) || ctx.GlobalString(SyncModeFlag.Name) == "light"
 	lightServer := ctx.GlobalInt(LightServFlag.Name) != 0
@@ -876,6 +1036,25 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setWS(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
+	if ctx.GlobalIsSet(RPCSlowLogFlag.Name) {
+		cfg.RPCSlowCallThreshold = ctx.GlobalDuration(RPCSlowLogFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCBatchLimitFlag.Name) {
+		cfg.RPCBatchLimit = ctx.GlobalInt(RPCBatchLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCResponseLimitFlag.Name) {
+		cfg.RPCResponseLimit = ctx.GlobalInt(RPCResponseLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(HealthMinPeersFlag.Name) {
+		cfg.Health.MinPeers = ctx.GlobalInt(HealthMinPeersFlag.Name)
+	}
+	if ctx.GlobalIsSet(HealthMaxHeadAgeFlag.Name) {
+		cfg.Health.MaxHeadAge = ctx.GlobalDuration(HealthMaxHeadAgeFlag.Name)
+	}
+	if ctx.GlobalIsSet(HealthSyncedFlag.Name) {
+		cfg.Health.NotSyncing = ctx.GlobalBool(HealthSyncedFlag.Name)
+	}
+
 	switch {
 	case ctx.GlobalIsSet(DataDirFlag.Name):
 		cfg.DataDir = ctx.GlobalString(DataDirFlag.Name)
@@ -959,6 +1138,19 @@ func setEthash(ctx *cli.Context, cfg *eth.Config) {
 	if ctx.GlobalIsSet(EthashDatasetsOnDiskFlag.Name) {
 		cfg.Ethash.DatasetsOnDisk = ctx.GlobalInt(EthashDatasetsOnDiskFlag.Name)
 	}
+	if ctx.GlobalIsSet(EthashSealProofsFlag.Name) {
+		cfg.Ethash.SealProofs = ctx.GlobalBool(EthashSealProofsFlag.Name)
+	}
+	if ctx.GlobalIsSet(EthashDagRootsFlag.Name) {
+		cfg.Ethash.DagRoots = nil
+		for _, root := range strings.Split(ctx.GlobalString(EthashDagRootsFlag.Name), ",") {
+			hash, err := hexutil.Decode(strings.TrimSpace(root))
+			if err != nil || len(hash) != common.HashLength {
+				Fatalf("Invalid ethash DAG root %q", root)
+			}
+			cfg.Ethash.DagRoots = append(cfg.Ethash.DagRoots, common.BytesToHash(hash))
+		}
+	}
 }
 
 // checkExclusive verifies that only a single isntance of the provided flags was
@@ -1037,6 +1229,9 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(LightPeersFlag.Name) {
 		cfg.LightPeers = ctx.GlobalInt(LightPeersFlag.Name)
 	}
+	if ctx.GlobalIsSet(LightServSealProofsFlag.Name) {
+		cfg.LightServSealProofs = ctx.GlobalBool(LightServSealProofsFlag.Name)
+	}
 	if ctx.GlobalIsSet(NetworkIdFlag.Name) {
 		cfg.NetworkId = ctx.GlobalUint64(NetworkIdFlag.Name)
 	}
@@ -1066,6 +1261,16 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(GasPriceFlag.Name) {
 		cfg.GasPrice = GlobalBig(ctx, GasPriceFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerRecommitIntervalFlag.Name) {
+		cfg.MinerRecommit = ctx.GlobalDuration(MinerRecommitIntervalFlag.Name)
+	}
+	setMinerNotify(ctx, cfg)
+	if ctx.GlobalIsSet(StratumAddrFlag.Name) {
+		cfg.StratumAddr = ctx.GlobalString(StratumAddrFlag.Name)
+	}
+	if ctx.GlobalIsSet(StratumDifficultyFlag.Name) {
+		cfg.StratumDifficulty = ctx.GlobalUint64(StratumDifficultyFlag.Name)
+	}
 	if ctx.GlobalIsSet(VMEnableDebugFlag.Name) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
@@ -1102,7 +1307,14 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		}
 		log.Info("Using developer account", "address", developer.Address)
 
-		cfg.Genesis = core.DeveloperGenesisBlock(uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name)), developer.Address)
+		period := uint64(ctx.GlobalInt(DeveloperPeriodFlag.Name))
+		if ctx.GlobalBool(DeveloperEthashFlag.Name) {
+			cfg.Genesis = core.DeveloperEthashGenesisBlock(params.MainnetChainConfig, developer.Address)
+			cfg.Ethash.PowMode = ethash.ModeDev
+			cfg.Ethash.DevPeriod = period
+		} else {
+			cfg.Genesis = core.DeveloperGenesisBlock(period, developer.Address)
+		}
 		if !ctx.GlobalIsSet(GasPriceFlag.Name) {
 			cfg.GasPrice = big.NewInt(1)
 		}
@@ -1175,6 +1387,25 @@ func RegisterEthStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService configures the GraphQL query endpoint and adds it to
+// the given node, serving it on the node's HTTP-RPC server.
+func RegisterGraphQLService(stack *node.Node, ui bool) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Resolve queries against whichever of the eth or les services is running
+		var ethServ *eth.Ethereum
+		if err := ctx.Service(&ethServ); err == nil {
+			return graphql.New(ethServ.ApiBackend, ui)
+		}
+		var lesServ *les.LightEthereum
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend, ui)
+		}
+		return nil, fmt.Errorf("no Ethereum service available for GraphQL")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 // SetupNetwork configures the system for either the main net or some test network.
 func SetupNetwork(ctx *cli.Context) {
 	// TODO(fjl): move target gas limit into config
@@ -1223,6 +1454,8 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 	var engine consensus.Engine
 	if config.Clique != nil {
 		engine = clique.New(config.Clique, chainDb)
+	} else if config.Authority != nil {
+		engine = authority.New(config.Authority, chainDb)
 	} else {
 		engine = ethash.NewFaker()
 		if !ctx.GlobalBool(FakePoWFlag.Name) {
@@ -0,0 +1,67 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authority
+
+import (
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/rpc"
+)
+
+// API is a user facing RPC API to allow inspecting the validator set and the
+// finality of the proof-of-authority scheme.
+type API struct {
+	chain     consensus.ChainReader
+	authority *Authority
+}
+
+// header retrieves the requested header, defaulting to the current head.
+func (api *API) header(number *rpc.BlockNumber) *types.Header {
+	switch {
+	case number == nil || *number == rpc.LatestBlockNumber:
+		return api.chain.CurrentHeader()
+	case *number == rpc.FinalizedBlockNumber:
+		return api.authority.FinalizedHeader(api.chain)
+	default:
+		return api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+}
+
+// GetSnapshot retrieves the state snapshot at a given block.
+func (api *API) GetSnapshot(number *rpc.BlockNumber) (*Snapshot, error) {
+	header := api.header(number)
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return api.authority.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+}
+
+// GetValidators retrieves the list of validators sealing the block following
+// the specified one.
+func (api *API) GetValidators(number *rpc.BlockNumber) ([]common.Address, error) {
+	snap, err := api.GetSnapshot(number)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Validators, nil
+}
+
+// GetFinalizedHeader retrieves the highest header considered final.
+func (api *API) GetFinalizedHeader() *types.Header {
+	return api.authority.FinalizedHeader(api.chain)
+}
@@ -0,0 +1,816 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package authority implements a proof-of-authority consensus engine with the
+// validator set maintained by a system contract and deterministic finality.
+//
+// Validators seal blocks round-robin, the in-turn validator of block N being the
+// one at index N modulo the number of validators. At every epoch boundary, the
+// checkpoint block embeds the validator set read from the system contract at the
+// end of the block, which takes effect from the next block on. Blocks are final
+// once more than 2/3 of the validators have sealed blocks on top of them.
+package authority
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/accounts"
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus"
+	"github.com/AdelineCoin/go-adln/consensus/misc"
+	"github.com/AdelineCoin/go-adln/core/state"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/crypto/sha3"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/params"
+	"github.com/AdelineCoin/go-adln/rlp"
+	"github.com/AdelineCoin/go-adln/rpc"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	checkpointInterval = 1024 // Number of blocks after which to save the validator snapshot to the database
+	inmemorySnapshots  = 128  // Number of recent validator snapshots to keep in memory
+	inmemorySignatures = 4096 // Number of recent block signatures to keep in memory
+
+	wiggleTime = 500 * time.Millisecond // Random delay (per validator) to allow concurrent validators
+)
+
+// Authority proof-of-authority protocol constants.
+var (
+	epochLength = uint64(30000) // Default number of blocks after which to refresh the validator set
+	blockPeriod = uint64(15)    // Default minimum difference between two consecutive block's timestamps
+
+	extraVanity = 32 // Fixed number of extra-data prefix bytes reserved for validator vanity
+	extraSeal   = 65 // Fixed number of extra-data suffix bytes reserved for validator seal
+
+	uncleHash = types.CalcUncleHash(nil) // Always Keccak256(RLP([])) as uncles are meaningless outside of PoW.
+
+	diffInTurn = big.NewInt(2) // Block difficulty for in-turn signatures
+	diffNoTurn = big.NewInt(1) // Block difficulty for out-of-turn signatures
+)
+
+// Various error messages to mark blocks invalid. These should be private to
+// prevent engine specific errors from being referenced in the remainder of the
+// codebase, inherently breaking if the engine is swapped out. Please put common
+// error types into the consensus package.
+var (
+	// errUnknownBlock is returned when the list of validators is requested for a
+	// block that is not part of the local blockchain.
+	errUnknownBlock = errors.New("unknown block")
+
+	// errInvalidNonce is returned if a block's nonce is non-zero.
+	errInvalidNonce = errors.New("non-zero nonce")
+
+	// errMissingVanity is returned if a block's extra-data section is shorter than
+	// 32 bytes, which is required to store the validator vanity.
+	errMissingVanity = errors.New("extra-data 32 byte vanity prefix missing")
+
+	// errMissingSignature is returned if a block's extra-data section doesn't seem
+	// to contain a 65 byte secp256k1 signature.
+	errMissingSignature = errors.New("extra-data 65 byte suffix signature missing")
+
+	// errExtraValidators is returned if non-checkpoint block contain validator
+	// data in their extra-data fields.
+	errExtraValidators = errors.New("non-checkpoint block contains extra validator list")
+
+	// errInvalidCheckpointValidators is returned if a checkpoint block contains an
+	// invalid list of validators (i.e. non divisible by 20 bytes, empty, contains
+	// duplicates or doesn't match the validator set contract).
+	errInvalidCheckpointValidators = errors.New("invalid validator list on checkpoint block")
+
+	// errEmptyValidatorSet is returned if the validator set contract returns no
+	// validators at all.
+	errEmptyValidatorSet = errors.New("empty validator set")
+
+	// errInvalidMixDigest is returned if a block's mix digest is non-zero.
+	errInvalidMixDigest = errors.New("non-zero mix digest")
+
+	// errInvalidUncleHash is returned if a block contains an non-empty uncle list.
+	errInvalidUncleHash = errors.New("non empty uncle hash")
+
+	// errInvalidDifficulty is returned if the difficulty of a block is not either
+	// of 1 or 2, or if the value does not match the turn of the validator.
+	errInvalidDifficulty = errors.New("invalid difficulty")
+
+	// errInvalidTimestamp is returned if the timestamp of a block is lower than
+	// the previous block's timestamp + the minimum block period.
+	errInvalidTimestamp = errors.New("invalid timestamp")
+
+	// errInvalidCoinbase is returned if the beneficiary of a block is not the
+	// validator sealing it.
+	errInvalidCoinbase = errors.New("coinbase not the sealing validator")
+
+	// errInvalidValidatorChain is returned if a validator snapshot is attempted to
+	// be extended via out-of-range or non-contiguous headers.
+	errInvalidValidatorChain = errors.New("invalid validator chain")
+
+	// errFinalityConflict is returned if a block conflicts with a block already
+	// considered final by the local node.
+	errFinalityConflict = errors.New("block conflicts with finalized chain")
+
+	// errUnauthorized is returned if a header is signed by a non-authorized entity.
+	errUnauthorized = errors.New("unauthorized")
+
+	// errWaitTransactions is returned if an empty block is attempted to be sealed
+	// on an instant chain (0 second period).
+	errWaitTransactions = errors.New("waiting for transactions")
+)
+
+// SignerFn is a signer callback function to request a hash to be signed by a
+// backing account.
+type SignerFn func(accounts.Account, []byte) ([]byte, error)
+
+// sigHash returns the hash which is used as input for the proof-of-authority
+// signing. It is the hash of the entire header apart from the 65 byte signature
+// contained at the end of the extra data.
+//
+// Note, the method requires the extra data to be at least 65 bytes, otherwise it
+// panics. This is done to avoid accidentally using both forms (signature present
+// or not), which could be abused to produce different hashes for the same header.
+func sigHash(header *types.Header) (hash common.Hash) {
+	hasher := sha3.NewKeccak256()
+
+	enc := []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra[:len(header.Extra)-65], // Yes, this will panic if extra is too short
+		header.MixDigest,
+		header.Nonce,
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	rlp.Encode(hasher, enc)
+	hasher.Sum(hash[:0])
+	return hash
+}
+
+// ecrecover extracts the Ethereum account address from a signed header.
+func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+	// If the signature's already cached, return that
+	hash := header.Hash()
+	if address, known := sigcache.Get(hash); known {
+		return address.(common.Address), nil
+	}
+	// Retrieve the signature from the header extra-data
+	if len(header.Extra) < extraSeal {
+		return common.Address{}, errMissingSignature
+	}
+	signature := header.Extra[len(header.Extra)-extraSeal:]
+
+	// Recover the public key and the Ethereum address
+	pubkey, err := crypto.Ecrecover(sigHash(header).Bytes(), signature)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var signer common.Address
+	copy(signer[:], crypto.Keccak256(pubkey[1:])[12:])
+
+	sigcache.Add(hash, signer)
+	return signer, nil
+}
+
+// checkpointValidators extracts the validator list embedded in the extra-data of
+// a checkpoint header, ensuring it is non-empty and free of duplicates.
+func checkpointValidators(header *types.Header) ([]common.Address, error) {
+	if len(header.Extra) < extraVanity+extraSeal {
+		return nil, errMissingSignature
+	}
+	blob := header.Extra[extraVanity : len(header.Extra)-extraSeal]
+	if len(blob) == 0 || len(blob)%common.AddressLength != 0 {
+		return nil, errInvalidCheckpointValidators
+	}
+	validators := make([]common.Address, len(blob)/common.AddressLength)
+	seen := make(map[common.Address]struct{})
+	for i := range validators {
+		copy(validators[i][:], blob[i*common.AddressLength:])
+		if _, ok := seen[validators[i]]; ok {
+			return nil, errInvalidCheckpointValidators
+		}
+		seen[validators[i]] = struct{}{}
+	}
+	return validators, nil
+}
+
+// Authority is the proof-of-authority consensus engine with the validator set
+// maintained by a system contract.
+type Authority struct {
+	config *params.AuthorityConfig // Consensus engine configuration parameters
+	db     ethdb.Database          // Database to store and retrieve snapshot checkpoints
+
+	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
+	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
+
+	finalized    *types.Header             // Highest header known to be final
+	finalityHead *types.Header             // Chain head the finalized header was last evaluated at
+	sealed       map[common.Address]uint64 // Last block sealed by each validator above the finalized header
+	finalityLock sync.Mutex                // Protects the finality fields
+
+	signer common.Address // Ethereum address of the signing key
+	signFn SignerFn       // Signer function to authorize hashes with
+	lock   sync.RWMutex   // Protects the signer fields
+}
+
+// New creates an Authority proof-of-authority consensus engine with the initial
+// validators set to the ones in the genesis block.
+func New(config *params.AuthorityConfig, db ethdb.Database) *Authority {
+	// Set any missing consensus parameters to their defaults
+	conf := *config
+	if conf.Epoch == 0 {
+		conf.Epoch = epochLength
+	}
+	// Allocate the snapshot caches and create the engine
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	signatures, _ := lru.NewARC(inmemorySignatures)
+
+	return &Authority{
+		config:     &conf,
+		db:         db,
+		recents:    recents,
+		signatures: signatures,
+	}
+}
+
+// Author implements consensus.Engine, returning the Ethereum address recovered
+// from the signature in the header's extra-data section.
+func (a *Authority) Author(header *types.Header) (common.Address, error) {
+	return ecrecover(header, a.signatures)
+}
+
+// VerifyHeader checks whether a header conforms to the consensus rules.
+func (a *Authority) VerifyHeader(chain consensus.ChainReader, header *types.Header, seal bool) error {
+	return a.verifyHeader(chain, header, nil)
+}
+
+// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
+// method returns a quit channel to abort the operations and a results channel to
+// retrieve the async verifications (the order is that of the input slice).
+func (a *Authority) VerifyHeaders(chain consensus.ChainReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			err := a.verifyHeader(chain, header, headers[:i])
+
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
+}
+
+// verifyHeader checks whether a header conforms to the consensus rules. The
+// caller may optionally pass in a batch of parents (ascending order) to avoid
+// looking those up from the database. This is useful for concurrently verifying
+// a batch of new headers.
+func (a *Authority) verifyHeader(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	if header.Number == nil {
+		return errUnknownBlock
+	}
+	number := header.Number.Uint64()
+
+	// Don't waste time checking blocks from the future
+	if header.Time.Cmp(big.NewInt(time.Now().Unix())) > 0 {
+		return consensus.ErrFutureBlock
+	}
+	// Nonces are unused, enforce them to be zero
+	if header.Nonce != (types.BlockNonce{}) {
+		return errInvalidNonce
+	}
+	// Check that the extra-data contains both the vanity and signature
+	if len(header.Extra) < extraVanity {
+		return errMissingVanity
+	}
+	if len(header.Extra) < extraVanity+extraSeal {
+		return errMissingSignature
+	}
+	// Ensure that the extra-data contains a validator list on checkpoint, but none otherwise
+	checkpoint := number%a.config.Epoch == 0
+	if !checkpoint && len(header.Extra) != extraVanity+extraSeal {
+		return errExtraValidators
+	}
+	if checkpoint {
+		if _, err := checkpointValidators(header); err != nil {
+			return err
+		}
+	}
+	// Ensure that the mix digest is zero as we don't have fork protection currently
+	if header.MixDigest != (common.Hash{}) {
+		return errInvalidMixDigest
+	}
+	// Ensure that the block doesn't contain any uncles which are meaningless in PoA
+	if header.UncleHash != uncleHash {
+		return errInvalidUncleHash
+	}
+	// Ensure that the block's difficulty is meaningful (may not be correct at this point)
+	if number > 0 {
+		if header.Difficulty == nil || (header.Difficulty.Cmp(diffInTurn) != 0 && header.Difficulty.Cmp(diffNoTurn) != 0) {
+			return errInvalidDifficulty
+		}
+	}
+	// If all checks passed, validate any special fields for hard forks
+	if err := misc.VerifyForkHashes(chain.Config(), header, false); err != nil {
+		return err
+	}
+	// All basic checks passed, verify cascading fields
+	return a.verifyCascadingFields(chain, header, parents)
+}
+
+// verifyCascadingFields verifies all the header fields that are not standalone,
+// rather depend on a batch of previous headers. The caller may optionally pass
+// in a batch of parents (ascending order) to avoid looking those up from the
+// database. This is useful for concurrently verifying a batch of new headers.
+func (a *Authority) verifyCascadingFields(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	// The genesis block is the always valid dead-end
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil
+	}
+	// Ensure that the block's timestamp isn't too close to it's parent
+	var parent *types.Header
+	if len(parents) > 0 {
+		parent = parents[len(parents)-1]
+	} else {
+		parent = chain.GetHeader(header.ParentHash, number-1)
+	}
+	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
+		return consensus.ErrUnknownAncestor
+	}
+	if parent.Time.Uint64()+a.config.Period > header.Time.Uint64() {
+		return errInvalidTimestamp
+	}
+	if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		return err
+	}
+	// Blocks competing with the finalized chain must never be accepted
+	if final := a.FinalizedHeader(chain); final != nil && number <= final.Number.Uint64() {
+		if canon := chain.GetHeaderByNumber(number); canon == nil || canon.Hash() != header.Hash() {
+			return errFinalityConflict
+		}
+	}
+	// All basic checks passed, verify the seal and return
+	return a.verifySeal(chain, header, parents)
+}
+
+// snapshot retrieves the validator snapshot at a given point in time.
+func (a *Authority) snapshot(chain consensus.ChainReader, number uint64, hash common.Hash, parents []*types.Header) (*Snapshot, error) {
+	// Search for a snapshot in memory or on disk for checkpoints
+	var (
+		headers []*types.Header
+		snap    *Snapshot
+	)
+	for snap == nil {
+		// If an in-memory snapshot was found, use that
+		if s, ok := a.recents.Get(hash); ok {
+			snap = s.(*Snapshot)
+			break
+		}
+		// If an on-disk checkpoint snapshot can be found, use that
+		if number%checkpointInterval == 0 {
+			if s, err := loadSnapshot(a.config, a.signatures, a.db, hash); err == nil {
+				log.Trace("Loaded validator snapshot form disk", "number", number, "hash", hash)
+				snap = s
+				break
+			}
+		}
+		// If we're at block zero, make a snapshot
+		if number == 0 {
+			genesis := chain.GetHeaderByNumber(0)
+			if err := a.VerifyHeader(chain, genesis, false); err != nil {
+				return nil, err
+			}
+			validators, err := checkpointValidators(genesis)
+			if err != nil {
+				return nil, err
+			}
+			snap = newSnapshot(a.config, a.signatures, 0, genesis.Hash(), validators)
+			if err := snap.store(a.db); err != nil {
+				return nil, err
+			}
+			log.Trace("Stored genesis validator snapshot to disk")
+			break
+		}
+		// No snapshot for this header, gather the header and move backward
+		var header *types.Header
+		if len(parents) > 0 {
+			// If we have explicit parents, pick from there (enforced)
+			header = parents[len(parents)-1]
+			if header.Hash() != hash || header.Number.Uint64() != number {
+				return nil, consensus.ErrUnknownAncestor
+			}
+			parents = parents[:len(parents)-1]
+		} else {
+			// No explicit parents (or no more left), reach out to the database
+			header = chain.GetHeader(hash, number)
+			if header == nil {
+				return nil, consensus.ErrUnknownAncestor
+			}
+		}
+		headers = append(headers, header)
+		number, hash = number-1, header.ParentHash
+	}
+	// Previous snapshot found, apply any pending headers on top of it
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	snap, err := snap.apply(headers)
+	if err != nil {
+		return nil, err
+	}
+	a.recents.Add(snap.Hash, snap)
+
+	// If we've generated a new checkpoint snapshot, save to disk
+	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
+		if err = snap.store(a.db); err != nil {
+			return nil, err
+		}
+		log.Trace("Stored validator snapshot to disk", "number", snap.Number, "hash", snap.Hash)
+	}
+	return snap, err
+}
+
+// VerifyUncles implements consensus.Engine, always returning an error for any
+// uncles as this consensus mechanism doesn't permit uncles.
+func (a *Authority) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	if len(block.Uncles()) > 0 {
+		return errors.New("uncles not allowed")
+	}
+	return nil
+}
+
+// VerifySeal implements consensus.Engine, checking whether the signature contained
+// in the header satisfies the consensus protocol requirements.
+func (a *Authority) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
+	return a.verifySeal(chain, header, nil)
+}
+
+// verifySeal checks whether the signature contained in the header satisfies the
+// consensus protocol requirements. The method accepts an optional list of parent
+// headers that aren't yet part of the local blockchain to generate the snapshots
+// from.
+func (a *Authority) verifySeal(chain consensus.ChainReader, header *types.Header, parents []*types.Header) error {
+	// Verifying the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return errUnknownBlock
+	}
+	// Retrieve the snapshot needed to verify this header and cache it
+	snap, err := a.snapshot(chain, number-1, header.ParentHash, parents)
+	if err != nil {
+		return err
+	}
+	// Resolve the authorization key and check against validators
+	signer, err := ecrecover(header, a.signatures)
+	if err != nil {
+		return err
+	}
+	if !snap.isValidator(signer) {
+		return errUnauthorized
+	}
+	if header.Coinbase != signer {
+		return errInvalidCoinbase
+	}
+	for seen, recent := range snap.Recents {
+		if recent == signer {
+			// Validator is among recents, only fail if the current block doesn't shift it out
+			if limit := uint64(len(snap.Validators)/2 + 1); seen > number-limit {
+				return errUnauthorized
+			}
+		}
+	}
+	// Ensure that the difficulty corresponds to the turn-ness of the validator
+	inturn := snap.inturn(number, signer)
+	if inturn && header.Difficulty.Cmp(diffInTurn) != 0 {
+		return errInvalidDifficulty
+	}
+	if !inturn && header.Difficulty.Cmp(diffNoTurn) != 0 {
+		return errInvalidDifficulty
+	}
+	return nil
+}
+
+// Prepare implements consensus.Engine, preparing all the consensus fields of the
+// header for running the transactions on top.
+func (a *Authority) Prepare(chain consensus.ChainReader, header *types.Header) error {
+	a.lock.RLock()
+	signer := a.signer
+	a.lock.RUnlock()
+
+	// Transaction fees are paid to the sealing validator
+	header.Coinbase = signer
+	header.Nonce = types.BlockNonce{}
+
+	number := header.Number.Uint64()
+	snap, err := a.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return err
+	}
+	// Set the correct difficulty
+	header.Difficulty = CalcDifficulty(snap, signer)
+
+	// Ensure the extra data has all it's components, the validator list of
+	// checkpoint blocks is only known after running the transactions
+	if len(header.Extra) < extraVanity {
+		header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, extraVanity-len(header.Extra))...)
+	}
+	header.Extra = append(header.Extra[:extraVanity], make([]byte, extraSeal)...)
+
+	// Mix digest is reserved for now, set to empty
+	header.MixDigest = common.Hash{}
+
+	// Ensure the timestamp has the correct delay
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(a.config.Period))
+	if header.Time.Int64() < time.Now().Unix() {
+		header.Time = big.NewInt(time.Now().Unix())
+	}
+	return nil
+}
+
+// Finalize implements consensus.Engine, ensuring no uncles are set, nor block
+// rewards given, and returns the final block. On checkpoint blocks the validator
+// set is read from the system contract and embedded into freshly prepared
+// headers, or checked against the embedded one when importing blocks.
+func (a *Authority) Finalize(chain consensus.ChainReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt) (*types.Block, error) {
+	if number := header.Number.Uint64(); number > 0 && number%a.config.Epoch == 0 {
+		validators, err := a.contractValidators(chain, header, state)
+		if err != nil {
+			return nil, err
+		}
+		list := make([]byte, 0, len(validators)*common.AddressLength)
+		for _, validator := range validators {
+			list = append(list, validator[:]...)
+		}
+		embedded := header.Extra[extraVanity : len(header.Extra)-extraSeal]
+		switch {
+		case len(embedded) == 0:
+			extra := append(append([]byte{}, header.Extra[:extraVanity]...), list...)
+			header.Extra = append(extra, header.Extra[len(header.Extra)-extraSeal:]...)
+		case !bytes.Equal(embedded, list):
+			return nil, errInvalidCheckpointValidators
+		}
+	}
+	// No block rewards in PoA, so the state remains as is and uncles are dropped
+	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	header.UncleHash = types.CalcUncleHash(nil)
+
+	// Assemble and return the final block for sealing
+	return types.NewBlock(header, txs, nil, receipts), nil
+}
+
+// Authorize injects a private key into the consensus engine to mint new blocks
+// with.
+func (a *Authority) Authorize(signer common.Address, signFn SignerFn) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	a.signer = signer
+	a.signFn = signFn
+}
+
+// Seal implements consensus.Engine, attempting to create a sealed block using
+// the local signing credentials.
+func (a *Authority) Seal(chain consensus.ChainReader, block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	header := block.Header()
+
+	// Sealing the genesis block is not supported
+	number := header.Number.Uint64()
+	if number == 0 {
+		return nil, errUnknownBlock
+	}
+	// For 0-period chains, refuse to seal empty blocks (no reward but would spin sealing)
+	if a.config.Period == 0 && len(block.Transactions()) == 0 {
+		return nil, errWaitTransactions
+	}
+	// Don't hold the signer fields for the entire sealing procedure
+	a.lock.RLock()
+	signer, signFn := a.signer, a.signFn
+	a.lock.RUnlock()
+
+	// Bail out if we're unauthorized to sign a block
+	snap, err := a.snapshot(chain, number-1, header.ParentHash, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !snap.isValidator(signer) {
+		return nil, errUnauthorized
+	}
+	// If we're amongst the recent validators, wait for the next block
+	for seen, recent := range snap.Recents {
+		if recent == signer {
+			// Validator is among recents, only wait if the current block doesn't shift it out
+			if limit := uint64(len(snap.Validators)/2 + 1); number < limit || seen > number-limit {
+				log.Info("Sealed recently, must wait for others")
+				<-stop
+				return nil, nil
+			}
+		}
+	}
+	// Sweet, the protocol permits us to sign the block, wait for our time
+	delay := time.Unix(header.Time.Int64(), 0).Sub(time.Now()) // nolint: gosimple
+	if header.Difficulty.Cmp(diffNoTurn) == 0 {
+		// It's not our turn explicitly to sign, delay it a bit
+		wiggle := time.Duration(len(snap.Validators)/2+1) * wiggleTime
+		delay += time.Duration(rand.Int63n(int64(wiggle)))
+
+		log.Trace("Out-of-turn sealing requested", "wiggle", common.PrettyDuration(wiggle))
+	}
+	log.Trace("Waiting for slot to seal and propagate", "delay", common.PrettyDuration(delay))
+
+	select {
+	case <-stop:
+		return nil, nil
+	case <-time.After(delay):
+	}
+	// Sign all the things!
+	sighash, err := signFn(accounts.Account{Address: signer}, sigHash(header).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	copy(header.Extra[len(header.Extra)-extraSeal:], sighash)
+
+	return block.WithSeal(header), nil
+}
+
+// CalcDifficulty is the difficulty adjustment algorithm. It returns the difficulty
+// that a new block should have based on the previous blocks in the chain and the
+// current validator.
+func (a *Authority) CalcDifficulty(chain consensus.ChainReader, time uint64, parent *types.Header) *big.Int {
+	snap, err := a.snapshot(chain, parent.Number.Uint64(), parent.Hash(), nil)
+	if err != nil {
+		return nil
+	}
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return CalcDifficulty(snap, a.signer)
+}
+
+// CalcDifficulty is the difficulty adjustment algorithm. It returns the difficulty
+// that a new block should have based on the previous blocks in the chain and the
+// current validator.
+func CalcDifficulty(snap *Snapshot, validator common.Address) *big.Int {
+	if snap.inturn(snap.Number+1, validator) {
+		return new(big.Int).Set(diffInTurn)
+	}
+	return new(big.Int).Set(diffNoTurn)
+}
+
+// FinalizedHeader implements consensus.FinalityEngine, returning the highest
+// header of the local chain on top of which more than 2/3 of the validators have
+// already sealed blocks. The finalized header never moves backwards, unless the
+// local chain was explicitly rewound below it.
+//
+// The last block sealed by each validator is tracked across calls, so extending
+// the chain only needs the new headers to be inspected, even if finality stalls.
+func (a *Authority) FinalizedHeader(chain consensus.ChainReader) *types.Header {
+	a.finalityLock.Lock()
+	defer a.finalityLock.Unlock()
+
+	// Drop the cached finality if the chain was rewound beneath it
+	if a.finalized != nil {
+		if canon := chain.GetHeaderByNumber(a.finalized.Number.Uint64()); canon == nil || canon.Hash() != a.finalized.Hash() {
+			a.finalized, a.finalityHead, a.sealed = nil, nil, nil
+		}
+	}
+	head := chain.CurrentHeader()
+	if head == nil {
+		return a.finalized
+	}
+	if a.finalityHead != nil && a.finalityHead.Hash() == head.Hash() {
+		return a.finalized
+	}
+	// The genesis block is final by definition
+	if a.finalized == nil {
+		a.finalized = chain.GetHeaderByNumber(0)
+	}
+	snap, err := a.snapshot(chain, head.Number.Uint64(), head.Hash(), nil)
+	if err != nil {
+		log.Warn("Failed to retrieve validator snapshot", "number", head.Number, "hash", head.Hash(), "err", err)
+		return a.finalized
+	}
+	// Collect the seals of the headers added since the last evaluation, starting
+	// over if the previously evaluated head is not an ancestor any more
+	var (
+		prev    = a.finalityHead
+		sealed  = make(map[common.Address]uint64)
+		limit   = a.finalized.Number.Uint64()
+		resumed = false
+	)
+	for header := head; header != nil && header.Number.Uint64() > limit; header = chain.GetHeader(header.ParentHash, header.Number.Uint64()-1) {
+		if prev != nil && header.Number.Uint64() <= prev.Number.Uint64() {
+			if header.Hash() == prev.Hash() {
+				resumed = true
+				break
+			}
+			prev = nil
+		}
+		// Without previous seals to build on, stop as soon as finality is reached
+		if prev == nil && countValidators(snap, sealed)*3 > 2*len(snap.Validators) {
+			break
+		}
+		signer, err := ecrecover(header, a.signatures)
+		if err != nil {
+			log.Warn("Failed to recover block sealer", "number", header.Number, "hash", header.Hash(), "err", err)
+			return a.finalized
+		}
+		if _, ok := sealed[signer]; !ok {
+			sealed[signer] = header.Number.Uint64()
+		}
+	}
+	if resumed {
+		for signer, number := range a.sealed {
+			if _, ok := sealed[signer]; !ok {
+				sealed[signer] = number
+			}
+		}
+	}
+	a.finalityHead, a.sealed = head, sealed
+
+	// The highest final block is right below the oldest of the most recent seals
+	// of the required number of distinct validators
+	var numbers []uint64
+	for signer, number := range sealed {
+		if snap.isValidator(signer) {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Slice(numbers, func(i, j int) bool { return numbers[i] > numbers[j] })
+
+	if required := 2*len(snap.Validators)/3 + 1; len(numbers) >= required {
+		if number := numbers[required-1] - 1; number > limit {
+			if header := chain.GetHeaderByNumber(number); header != nil {
+				a.finalized = header
+			}
+		}
+	}
+	// Seals at or below the finalized header are irrelevant for future progress
+	for signer, number := range sealed {
+		if number <= a.finalized.Number.Uint64() {
+			delete(sealed, signer)
+		}
+	}
+	return a.finalized
+}
+
+// countValidators returns the number of current validators in the given set of
+// sealers.
+func countValidators(snap *Snapshot, sealed map[common.Address]uint64) int {
+	count := 0
+	for signer := range sealed {
+		if snap.isValidator(signer) {
+			count++
+		}
+	}
+	return count
+}
+
+// APIs implements consensus.Engine, returning the user facing RPC API to allow
+// inspecting the validator set and finality.
+func (a *Authority) APIs(chain consensus.ChainReader) []rpc.API {
+	return []rpc.API{{
+		Namespace: "authority",
+		Version:   "1.0",
+		Service:   &API{chain: chain, authority: a},
+		Public:    false,
+	}}
+}
@@ -0,0 +1,236 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authority
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/state"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+// testerChain implements consensus.ChainReader on top of a list of headers,
+// starting with the genesis.
+type testerChain struct {
+	config  *params.ChainConfig
+	headers []*types.Header
+	lookups int // Number of headers retrieved by hash
+}
+
+func (c *testerChain) Config() *params.ChainConfig  { return c.config }
+func (c *testerChain) CurrentHeader() *types.Header { return c.headers[len(c.headers)-1] }
+func (c *testerChain) GetBlock(common.Hash, uint64) *types.Block {
+	panic("not supported")
+}
+
+func (c *testerChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	c.lookups++
+	if header := c.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+
+func (c *testerChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number >= uint64(len(c.headers)) {
+		return nil
+	}
+	return c.headers[number]
+}
+
+func (c *testerChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	for _, header := range c.headers {
+		if header.Hash() == hash {
+			return header
+		}
+	}
+	return nil
+}
+
+// newTesterChain creates a chain of the given length, sealed round-robin by the
+// in-turn validator out of the given keys.
+func newTesterChain(keys []*ecdsa.PrivateKey, length int) (*testerChain, []common.Address) {
+	validators := make([]common.Address, len(keys))
+	genesis := &types.Header{
+		Number:     new(big.Int),
+		Time:       new(big.Int),
+		Difficulty: big.NewInt(1),
+		UncleHash:  uncleHash,
+		Extra:      make([]byte, extraVanity),
+	}
+	for i, key := range keys {
+		validators[i] = crypto.PubkeyToAddress(key.PublicKey)
+		genesis.Extra = append(genesis.Extra, validators[i][:]...)
+	}
+	genesis.Extra = append(genesis.Extra, make([]byte, extraSeal)...)
+
+	config := *params.TestChainConfig
+	config.Ethash, config.Authority = nil, &params.AuthorityConfig{Epoch: 30000}
+	chain := &testerChain{config: &config, headers: []*types.Header{genesis}}
+	for i := 1; i <= length; i++ {
+		chain.headers = append(chain.headers, newTesterHeader(chain.headers[i-1], keys[i%len(keys)], diffInTurn))
+	}
+	return chain, validators
+}
+
+// newTesterHeader creates a header on top of parent, sealed by the given key.
+func newTesterHeader(parent *types.Header, key *ecdsa.PrivateKey, difficulty *big.Int) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Coinbase:   crypto.PubkeyToAddress(key.PublicKey),
+		UncleHash:  uncleHash,
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Time:       new(big.Int).Add(parent.Time, common.Big1),
+		Difficulty: difficulty,
+		Extra:      make([]byte, extraVanity+extraSeal),
+	}
+	sig, _ := crypto.Sign(sigHash(header).Bytes(), key)
+	copy(header.Extra[extraVanity:], sig)
+	return header
+}
+
+// Tests that blocks become final once more than 2/3 of the validators have sealed
+// on top of them, and that conflicting blocks are rejected afterwards.
+func TestFinality(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	chain, validators := newTesterChain(keys, 5)
+
+	db, _ := ethdb.NewMemDatabase()
+	engine := New(chain.config.Authority, db)
+
+	// Validate the chain and ensure the tail gets finalized
+	for _, header := range chain.headers[1:] {
+		if err := engine.VerifyHeader(chain, header, true); err != nil {
+			t.Fatalf("header %d: failed to verify: %v", header.Number, err)
+		}
+	}
+	if final := engine.FinalizedHeader(chain); final.Hash() != chain.headers[2].Hash() {
+		t.Errorf("finalized header mismatch: have %d, want %d", final.Number, 2)
+	}
+	if snap, err := (&API{chain: chain, authority: engine}).GetSnapshot(nil); err != nil || !reflect.DeepEqual(snap.Validators, validators) {
+		t.Errorf("validator set mismatch: have %v, want %v (err %v)", snap, validators, err)
+	}
+	// Ensure a fork conflicting with the finalized chain is rejected
+	fork := newTesterHeader(chain.headers[1], keys[0], diffNoTurn)
+	if err := engine.VerifyHeader(chain, fork, true); err != errFinalityConflict {
+		t.Errorf("conflicting header error mismatch: have %v, want %v", err, errFinalityConflict)
+	}
+	// Ensure a fork above the finalized chain is still accepted
+	fork = newTesterHeader(chain.headers[3], keys[2], diffNoTurn)
+	if err := engine.VerifyHeader(chain, fork, true); err != nil {
+		t.Errorf("non-final fork rejected: %v", err)
+	}
+}
+
+// Tests that a stalled finality is tracked incrementally instead of walking back
+// to the finalized header for every new head, and that it resumes afterwards.
+func TestFinalityStall(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	// Three validators suffice for sealing, but not for finalizing anything
+	chain, _ := newTesterChain(keys[:3], 0)
+	genesis := chain.headers[0]
+	genesis.Extra = genesis.Extra[:extraVanity]
+	for _, key := range keys {
+		address := crypto.PubkeyToAddress(key.PublicKey)
+		genesis.Extra = append(genesis.Extra, address[:]...)
+	}
+	genesis.Extra = append(genesis.Extra, make([]byte, extraSeal)...)
+	for i := 1; i <= 300; i++ {
+		chain.headers = append(chain.headers, newTesterHeader(chain.headers[i-1], keys[i%3], diffNoTurn))
+	}
+	db, _ := ethdb.NewMemDatabase()
+	engine := New(chain.config.Authority, db)
+
+	if final := engine.FinalizedHeader(chain); final.Hash() != genesis.Hash() {
+		t.Fatalf("finalized header mismatch: have %d, want %d", final.Number, 0)
+	}
+	// Extend the stalled chain and ensure only the new header is inspected
+	chain.headers = append(chain.headers, newTesterHeader(chain.headers[300], keys[1], diffNoTurn))
+	chain.lookups = 0
+	if final := engine.FinalizedHeader(chain); final.Hash() != genesis.Hash() {
+		t.Fatalf("finalized header mismatch: have %d, want %d", final.Number, 0)
+	}
+	if chain.lookups > 4 {
+		t.Errorf("too many header lookups for a stalled chain: have %d, want at most %d", chain.lookups, 4)
+	}
+	// A fourth validator sealing should finalize everything below the oldest seal
+	chain.headers = append(chain.headers, newTesterHeader(chain.headers[301], keys[3], diffNoTurn))
+	if final := engine.FinalizedHeader(chain); final.Hash() != chain.headers[298].Hash() {
+		t.Errorf("finalized header mismatch: have %d, want %d", final.Number, 298)
+	}
+}
+
+// Tests that checkpoint blocks get the validator set of the system contract
+// embedded and that mismatching embedded sets are rejected.
+func TestCheckpointValidators(t *testing.T) {
+	validators := []common.Address{common.HexToAddress("0x01"), common.HexToAddress("0x02")}
+
+	// Assemble a contract returning the ABI encoded validator list, with a
+	// duplicate that needs to be dropped
+	listed := append(validators, validators[0])
+	ret := append(common.LeftPadBytes([]byte{0x20}, 32), common.LeftPadBytes([]byte{byte(len(listed))}, 32)...)
+	for _, validator := range listed {
+		ret = append(ret, common.LeftPadBytes(validator[:], 32)...)
+	}
+	code := []byte{
+		0x61, 0x00, byte(len(ret)), // PUSH2 len
+		0x61, 0x00, 0x0f, // PUSH2 offset
+		0x60, 0x00, // PUSH1 0
+		0x39,                       // CODECOPY
+		0x61, 0x00, byte(len(ret)), // PUSH2 len
+		0x60, 0x00, // PUSH1 0
+		0xf3, // RETURN
+	}
+	code = append(code, ret...)
+
+	contract := common.HexToAddress("0x1000")
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	statedb.SetCode(contract, code)
+
+	key, _ := crypto.GenerateKey()
+	chain, _ := newTesterChain([]*ecdsa.PrivateKey{key}, 0)
+	engine := New(&params.AuthorityConfig{Epoch: 1, Contract: contract}, db)
+
+	// Ensure a freshly prepared checkpoint gets the validator list embedded
+	header := newTesterHeader(chain.headers[0], key, diffInTurn)
+	if _, err := engine.Finalize(chain, header, statedb, nil, nil, nil); err != nil {
+		t.Fatalf("failed to finalize checkpoint: %v", err)
+	}
+	if have, err := checkpointValidators(header); err != nil || !reflect.DeepEqual(have, validators) {
+		t.Errorf("embedded validators mismatch: have %v, want %v (err %v)", have, validators, err)
+	}
+	// Ensure a checkpoint with a different embedded list is rejected
+	header = newTesterHeader(chain.headers[0], key, diffInTurn)
+	header.Extra = append(append(header.Extra[:extraVanity], validators[1][:]...), make([]byte, extraSeal)...)
+	if _, err := engine.Finalize(chain, header, statedb, nil, nil, nil); err != errInvalidCheckpointValidators {
+		t.Errorf("mismatching checkpoint error mismatch: have %v, want %v", err, errInvalidCheckpointValidators)
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authority
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/AdelineCoin/go-adln/accounts/abi"
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus"
+	"github.com/AdelineCoin/go-adln/core"
+	"github.com/AdelineCoin/go-adln/core/state"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/core/vm"
+)
+
+// ValidatorSetABI is the interface the system contract maintaining the validator
+// set needs to implement. The order of the returned validators determines their
+// round-robin sealing turns.
+const ValidatorSetABI = `[{"constant":true,"inputs":[],"name":"getValidators","outputs":[{"name":"","type":"address[]"}],"payable":false,"stateMutability":"view","type":"function"}]`
+
+// validatorCallGas is the gas allowance for retrieving the validator set.
+const validatorCallGas = 50000000
+
+// validatorSetABI is the parsed interface of the validator set contract.
+var validatorSetABI, _ = abi.JSON(strings.NewReader(ValidatorSetABI))
+
+// contractValidators retrieves the validator set from the system contract, as
+// seen by the given state on top of the given header.
+func (a *Authority) contractValidators(chain consensus.ChainReader, header *types.Header, statedb *state.StateDB) ([]common.Address, error) {
+	input, err := validatorSetABI.Pack("getValidators")
+	if err != nil {
+		return nil, err
+	}
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash: func(n uint64) common.Hash {
+			if header := chain.GetHeaderByNumber(n); header != nil {
+				return header.Hash()
+			}
+			return common.Hash{}
+		},
+		GasPrice:    new(big.Int),
+		Coinbase:    header.Coinbase,
+		GasLimit:    validatorCallGas,
+		BlockNumber: new(big.Int).Set(header.Number),
+		Time:        new(big.Int).Set(header.Time),
+		Difficulty:  new(big.Int).Set(header.Difficulty),
+	}
+	evm := vm.NewEVM(context, statedb, chain.Config(), vm.Config{})
+
+	output, _, err := evm.StaticCall(vm.AccountRef(common.Address{}), a.config.Contract, input, validatorCallGas)
+	if err != nil {
+		return nil, err
+	}
+	var validators []common.Address
+	if err := validatorSetABI.Unpack(&validators, "getValidators", output); err != nil {
+		return nil, err
+	}
+	// Drop any duplicates, keeping the first occurrence to retain the sealing order,
+	// as checkpoints with repeated validators would be rejected by everyone
+	seen := make(map[common.Address]struct{})
+	unique := validators[:0]
+	for _, validator := range validators {
+		if _, ok := seen[validator]; !ok {
+			seen[validator] = struct{}{}
+			unique = append(unique, validator)
+		}
+	}
+	if len(unique) == 0 {
+		return nil, errEmptyValidatorSet
+	}
+	return unique, nil
+}
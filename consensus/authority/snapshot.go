@@ -0,0 +1,175 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package authority
+
+import (
+	"encoding/json"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/params"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Snapshot is the state of the validator set at a given point in time.
+type Snapshot struct {
+	config   *params.AuthorityConfig // Consensus engine parameters to fine tune behavior
+	sigcache *lru.ARCCache           // Cache of recent block signatures to speed up ecrecover
+
+	Number     uint64                    `json:"number"`     // Block number where the snapshot was created
+	Hash       common.Hash               `json:"hash"`       // Block hash where the snapshot was created
+	Validators []common.Address          `json:"validators"` // Validators in round-robin order at this moment
+	Recents    map[uint64]common.Address `json:"recents"`    // Set of recent validators for spam protections
+}
+
+// newSnapshot creates a new snapshot with the specified startup parameters. This
+// method does not initialize the set of recent validators, so only ever use if
+// for the genesis block.
+func newSnapshot(config *params.AuthorityConfig, sigcache *lru.ARCCache, number uint64, hash common.Hash, validators []common.Address) *Snapshot {
+	return &Snapshot{
+		config:     config,
+		sigcache:   sigcache,
+		Number:     number,
+		Hash:       hash,
+		Validators: validators,
+		Recents:    make(map[uint64]common.Address),
+	}
+}
+
+// loadSnapshot loads an existing snapshot from the database.
+func loadSnapshot(config *params.AuthorityConfig, sigcache *lru.ARCCache, db ethdb.Database, hash common.Hash) (*Snapshot, error) {
+	blob, err := db.Get(append([]byte("authority-"), hash[:]...))
+	if err != nil {
+		return nil, err
+	}
+	snap := new(Snapshot)
+	if err := json.Unmarshal(blob, snap); err != nil {
+		return nil, err
+	}
+	snap.config = config
+	snap.sigcache = sigcache
+
+	return snap, nil
+}
+
+// store inserts the snapshot into the database.
+func (s *Snapshot) store(db ethdb.Database) error {
+	blob, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return db.Put(append([]byte("authority-"), s.Hash[:]...), blob)
+}
+
+// copy creates a deep copy of the snapshot.
+func (s *Snapshot) copy() *Snapshot {
+	cpy := &Snapshot{
+		config:     s.config,
+		sigcache:   s.sigcache,
+		Number:     s.Number,
+		Hash:       s.Hash,
+		Validators: make([]common.Address, len(s.Validators)),
+		Recents:    make(map[uint64]common.Address),
+	}
+	copy(cpy.Validators, s.Validators)
+	for block, validator := range s.Recents {
+		cpy.Recents[block] = validator
+	}
+	return cpy
+}
+
+// apply creates a new validator snapshot by applying the given headers to the
+// original one.
+func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
+	// Allow passing in no headers for cleaner code
+	if len(headers) == 0 {
+		return s, nil
+	}
+	// Sanity check that the headers can be applied
+	for i := 0; i < len(headers)-1; i++ {
+		if headers[i+1].Number.Uint64() != headers[i].Number.Uint64()+1 {
+			return nil, errInvalidValidatorChain
+		}
+	}
+	if headers[0].Number.Uint64() != s.Number+1 {
+		return nil, errInvalidValidatorChain
+	}
+	// Iterate through the headers and create a new snapshot
+	snap := s.copy()
+
+	for _, header := range headers {
+		// Delete the oldest validator from the recent list to allow it sealing again
+		number := header.Number.Uint64()
+		if limit := uint64(len(snap.Validators)/2 + 1); number >= limit {
+			delete(snap.Recents, number-limit)
+		}
+		// Resolve the authorization key and check against validators
+		validator, err := ecrecover(header, s.sigcache)
+		if err != nil {
+			return nil, err
+		}
+		if !snap.isValidator(validator) {
+			return nil, errUnauthorized
+		}
+		for _, recent := range snap.Recents {
+			if recent == validator {
+				return nil, errUnauthorized
+			}
+		}
+		snap.Recents[number] = validator
+
+		// Checkpoint blocks carry the validator set of the next epoch
+		if number%s.config.Epoch == 0 {
+			validators, err := checkpointValidators(header)
+			if err != nil {
+				return nil, err
+			}
+			snap.Validators = validators
+
+			// Validator set might have shrunk, delete any leftover recent caches
+			limit := uint64(len(snap.Validators)/2 + 1)
+			for block := range snap.Recents {
+				if block+limit <= number {
+					delete(snap.Recents, block)
+				}
+			}
+		}
+	}
+	snap.Number += uint64(len(headers))
+	snap.Hash = headers[len(headers)-1].Hash()
+
+	return snap, nil
+}
+
+// isValidator returns whether the given address is part of the validator set.
+func (s *Snapshot) isValidator(address common.Address) bool {
+	for _, validator := range s.Validators {
+		if validator == address {
+			return true
+		}
+	}
+	return false
+}
+
+// inturn returns if a validator at a given block height is in-turn or not.
+func (s *Snapshot) inturn(number uint64, validator common.Address) bool {
+	if len(s.Validators) == 0 {
+		return false
+	}
+	return s.Validators[number%uint64(len(s.Validators))] == validator
+}
@@ -17,12 +17,59 @@
 package clique
 
 import (
+	"bytes"
+	"errors"
+
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/consensus"
 	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/rpc"
 )
 
+const (
+	defaultStatusBlocks = 64    // Number of recent blocks to report signer activity for by default
+	maxVoteRange        = 10000 // Maximum number of blocks to replay for retrieving votes
+)
+
+var (
+	// errInvalidRange is returned if a block range is requested whose end
+	// precedes its start.
+	errInvalidRange = errors.New("invalid block range")
+
+	// errRangeTooLarge is returned if votes are requested for too many blocks.
+	errRangeTooLarge = errors.New("block range too large")
+)
+
+// Status is the signing activity of the authorized signers over a number of
+// recent blocks.
+type Status struct {
+	NumBlocks      uint64                 `json:"numBlocks"`      // Number of blocks the activity was collected over
+	InturnPercent  float64                `json:"inturnPercent"`  // Percentage of blocks signed in-turn
+	SignerActivity map[common.Address]int `json:"signerActivity"` // Number of blocks signed by each current signer
+}
+
+// VoteRecord is a vote cast in a block header, along with its effect on the tally.
+type VoteRecord struct {
+	Block     uint64         `json:"block"`     // Block number the vote was cast in
+	Hash      common.Hash    `json:"hash"`      // Block hash the vote was cast in
+	Signer    common.Address `json:"signer"`    // Authorized signer that cast the vote
+	Address   common.Address `json:"address"`   // Account being voted on to change its authorization
+	Authorize bool           `json:"authorize"` // Whether to authorize or deauthorize the voted account
+	Counted   bool           `json:"counted"`   // Whether the vote was counted (meaningful for the signer list)
+	Tally     int            `json:"tally"`     // Number of votes for the proposal after this one
+	Passed    bool           `json:"passed"`    // Whether the vote passed the proposal
+}
+
+// SignerChange is an entry in the audit trail of the signer list, recording when
+// an account was authorized or deauthorized and which signers voted for it.
+type SignerChange struct {
+	Block     uint64           `json:"block"`     // Block number in which the change took effect
+	Hash      common.Hash      `json:"hash"`      // Block hash in which the change took effect
+	Address   common.Address   `json:"address"`   // Account whose authorization changed
+	Authorize bool             `json:"authorize"` // Whether the account was authorized or deauthorized
+	Voters    []common.Address `json:"voters"`    // Signers whose votes passed the change (none for genesis signers)
+}
+
 // API is a user facing RPC API to allow controlling the signer and voting
 // mechanisms of the proof-of-authority scheme.
 type API struct {
@@ -117,3 +164,187 @@ func (api *API) Discard(address common.Address) {
 
 	delete(api.clique.proposals, address)
 }
+
+// header retrieves the header of a block number, defaulting to the current one
+// if none or a non-concrete block number was requested.
+func (api *API) header(number *rpc.BlockNumber) *types.Header {
+	if number == nil || *number < 0 {
+		return api.chain.CurrentHeader()
+	}
+	return api.chain.GetHeaderByNumber(uint64(number.Int64()))
+}
+
+// Status retrieves the signing activity of the current signers over the given
+// number of recent blocks (64 by default) and the ratio of in-turn blocks.
+func (api *API) Status(blocks *uint64) (*Status, error) {
+	head := api.chain.CurrentHeader()
+	snap, err := api.clique.snapshot(api.chain, head.Number.Uint64(), head.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// Gather the signers of the requested range, down to the first block
+	numBlocks := uint64(defaultStatusBlocks)
+	if blocks != nil {
+		numBlocks = *blocks
+	}
+	if number := head.Number.Uint64(); numBlocks > number {
+		numBlocks = number
+	}
+	status := &Status{
+		NumBlocks:      numBlocks,
+		SignerActivity: make(map[common.Address]int),
+	}
+	for signer := range snap.Signers {
+		status.SignerActivity[signer] = 0
+	}
+	var inturn uint64
+	for header := head; header != nil && head.Number.Uint64()-header.Number.Uint64() < numBlocks; {
+		signer, err := ecrecover(header, api.clique.signatures)
+		if err != nil {
+			return nil, err
+		}
+		status.SignerActivity[signer]++
+		if header.Difficulty.Cmp(diffInTurn) == 0 {
+			inturn++
+		}
+		header = api.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	if numBlocks > 0 {
+		status.InturnPercent = float64(inturn) * 100 / float64(numBlocks)
+	}
+	return status, nil
+}
+
+// GetVotes retrieves the votes cast in the given range of blocks (inclusive),
+// along with their effect on the tally as evaluated by the voting snapshots.
+func (api *API) GetVotes(fromBlock, toBlock *rpc.BlockNumber) ([]*VoteRecord, error) {
+	from, to := api.header(fromBlock), api.header(toBlock)
+	if from == nil || to == nil {
+		return nil, errUnknownBlock
+	}
+	first, last := from.Number.Uint64(), to.Number.Uint64()
+	if first == 0 {
+		first = 1 // the genesis block can't contain votes
+	}
+	if first > last {
+		if last == 0 {
+			return []*VoteRecord{}, nil
+		}
+		return nil, errInvalidRange
+	}
+	if last-first >= maxVoteRange {
+		return nil, errRangeTooLarge
+	}
+	// Collect the headers of the range and the snapshot preceding them
+	headers := make([]*types.Header, 0, last-first+1)
+	for header := to; header.Number.Uint64() >= first; {
+		headers = append(headers, header)
+		if header = api.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1); header == nil {
+			return nil, errUnknownBlock
+		}
+	}
+	parent := api.chain.GetHeader(headers[len(headers)-1].ParentHash, first-1)
+	snap, err := api.clique.snapshot(api.chain, first-1, parent.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// Replay the headers one by one, recording the votes and their outcomes
+	votes := []*VoteRecord{}
+	for i := len(headers) - 1; i >= 0; i-- {
+		header := headers[i]
+
+		next, err := snap.apply([]*types.Header{header})
+		if err != nil {
+			return nil, err
+		}
+		if header.Coinbase != (common.Address{}) {
+			signer, err := ecrecover(header, api.clique.signatures)
+			if err != nil {
+				return nil, err
+			}
+			authorize := bytes.Equal(header.Nonce[:], nonceAuthVote)
+			votes = append(votes, &VoteRecord{
+				Block:     header.Number.Uint64(),
+				Hash:      header.Hash(),
+				Signer:    signer,
+				Address:   header.Coinbase,
+				Authorize: authorize,
+				Counted:   snap.validVote(header.Coinbase, authorize),
+				Tally:     next.Tally[header.Coinbase].Votes,
+				Passed:    signerChanged(snap, next, header.Coinbase),
+			})
+		}
+		snap = next
+	}
+	return votes, nil
+}
+
+// GetSignerHistory retrieves the changes to the authorization of an account,
+// derived by replaying the voting of the canonical chain from the genesis block.
+//
+// Note, the replay covers the entire chain, so this call gets more expensive as
+// the chain grows.
+func (api *API) GetSignerHistory(address common.Address) ([]*SignerChange, error) {
+	genesis := api.chain.GetHeaderByNumber(0)
+	if genesis == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := api.clique.snapshot(api.chain, 0, genesis.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	history := []*SignerChange{}
+	if _, ok := snap.Signers[address]; ok {
+		history = append(history, &SignerChange{Block: 0, Hash: genesis.Hash(), Address: address, Authorize: true})
+	}
+	// Replay the canonical headers one by one, recording the passed votes
+	parent, head := genesis, api.chain.CurrentHeader().Number.Uint64()
+	for number := uint64(1); number <= head; number++ {
+		header := api.chain.GetHeaderByNumber(number)
+		if header == nil || header.ParentHash != parent.Hash() {
+			return nil, errUnknownBlock
+		}
+		next, err := snap.apply([]*types.Header{header})
+		if err != nil {
+			return nil, err
+		}
+		if header.Coinbase == address && signerChanged(snap, next, address) {
+			signer, err := ecrecover(header, api.clique.signatures)
+			if err != nil {
+				return nil, err
+			}
+			history = append(history, &SignerChange{
+				Block:     number,
+				Hash:      header.Hash(),
+				Address:   address,
+				Authorize: bytes.Equal(header.Nonce[:], nonceAuthVote),
+				Voters:    passingVoters(snap, address, signer),
+			})
+		}
+		snap, parent = next, header
+	}
+	return history, nil
+}
+
+// signerChanged reports whether the authorization of an account differs between
+// two voting snapshots, i.e. whether a vote on it passed in between.
+func signerChanged(prev, next *Snapshot, address common.Address) bool {
+	_, before := prev.Signers[address]
+	_, after := next.Signers[address]
+	return before != after
+}
+
+// passingVoters returns the signers whose votes passed a change to the authorization
+// of an account, given the snapshot before the passing vote and its signer.
+func passingVoters(snap *Snapshot, address common.Address, signer common.Address) []common.Address {
+	_, authorized := snap.Signers[address]
+
+	var voters []common.Address
+	for _, vote := range snap.Votes {
+		// A signer's earlier vote is superseded by the passing one
+		if vote.Address == address && vote.Authorize != authorized && vote.Signer != signer {
+			voters = append(voters, vote.Signer)
+		}
+	}
+	return append(voters, signer)
+}
@@ -0,0 +1,164 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package clique
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/params"
+	"github.com/AdelineCoin/go-adln/rpc"
+)
+
+// testerHeaderChain implements consensus.ChainReader on top of a genesis block
+// stored in the database and a list of headers following it.
+type testerHeaderChain struct {
+	testerChainReader
+	headers []*types.Header
+}
+
+func (r *testerHeaderChain) CurrentHeader() *types.Header {
+	return r.headers[len(r.headers)-1]
+}
+
+func (r *testerHeaderChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := r.GetHeaderByNumber(number); header != nil && header.Hash() == hash {
+		return header
+	}
+	return nil
+}
+
+func (r *testerHeaderChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number == 0 {
+		return r.testerChainReader.GetHeaderByNumber(0)
+	}
+	if number > uint64(len(r.headers)) {
+		return nil
+	}
+	return r.headers[number-1]
+}
+
+// Tests that the votes, signer activity and signer history reported by the API
+// match the evaluated voting.
+func TestVotingAudit(t *testing.T) {
+	accounts := newTesterAccountPool()
+
+	// Create the genesis block with two initial signers
+	genesis := &core.Genesis{
+		ExtraData: make([]byte, extraVanity+2*common.AddressLength+extraSeal),
+	}
+	copy(genesis.ExtraData[extraVanity:], accounts.address("A").Bytes())
+	copy(genesis.ExtraData[extraVanity+common.AddressLength:], accounts.address("B").Bytes())
+
+	db, _ := ethdb.NewMemDatabase()
+	parent := genesis.MustCommit(db).Header()
+
+	// Authorize C by A and B, then drop B by A and C
+	votes := []testerVote{
+		{signer: "A", voted: "C", auth: true},
+		{signer: "B", voted: "C", auth: true},
+		{signer: "C"},
+		{signer: "A", voted: "B"},
+		{signer: "C", voted: "B"},
+	}
+	headers := make([]*types.Header, len(votes))
+	for i, vote := range votes {
+		headers[i] = &types.Header{
+			ParentHash: parent.Hash(),
+			Number:     big.NewInt(int64(i) + 1),
+			Time:       big.NewInt(int64(i) * int64(blockPeriod)),
+			Difficulty: diffNoTurn,
+			Extra:      make([]byte, extraVanity+extraSeal),
+		}
+		if i%2 == 1 {
+			headers[i].Difficulty = diffInTurn
+		}
+		if vote.voted != "" {
+			headers[i].Coinbase = accounts.address(vote.voted)
+		}
+		if vote.auth {
+			copy(headers[i].Nonce[:], nonceAuthVote)
+		}
+		accounts.sign(headers[i], vote.signer)
+		parent = headers[i]
+	}
+	chain := &testerHeaderChain{testerChainReader: testerChainReader{db: db}, headers: headers}
+	api := &API{chain: chain, clique: New(&params.CliqueConfig{}, db)}
+
+	// Ensure the votes and their outcomes are reported correctly
+	from, to := rpc.BlockNumber(0), rpc.LatestBlockNumber
+	records, err := api.GetVotes(&from, &to)
+	if err != nil {
+		t.Fatalf("failed to retrieve votes: %v", err)
+	}
+	want := []*VoteRecord{
+		{Block: 1, Hash: headers[0].Hash(), Signer: accounts.address("A"), Address: accounts.address("C"), Authorize: true, Counted: true, Tally: 1},
+		{Block: 2, Hash: headers[1].Hash(), Signer: accounts.address("B"), Address: accounts.address("C"), Authorize: true, Counted: true, Passed: true},
+		{Block: 4, Hash: headers[3].Hash(), Signer: accounts.address("A"), Address: accounts.address("B"), Counted: true, Tally: 1},
+		{Block: 5, Hash: headers[4].Hash(), Signer: accounts.address("C"), Address: accounts.address("B"), Counted: true, Passed: true},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("votes mismatch:\nhave %+v\nwant %+v", records, want)
+	}
+	from, to = rpc.BlockNumber(3), rpc.BlockNumber(2)
+	if _, err := api.GetVotes(&from, &to); err != errInvalidRange {
+		t.Errorf("inverted range error mismatch: have %v, want %v", err, errInvalidRange)
+	}
+	// Ensure the signer activity is reported correctly
+	status, err := api.Status(nil)
+	if err != nil {
+		t.Fatalf("failed to retrieve status: %v", err)
+	}
+	activity := map[common.Address]int{accounts.address("A"): 2, accounts.address("B"): 1, accounts.address("C"): 2}
+	if status.NumBlocks != 5 || status.InturnPercent != 40 || !reflect.DeepEqual(status.SignerActivity, activity) {
+		t.Errorf("status mismatch: have %+v", status)
+	}
+	// Ensure the signer history contains the genesis and voted authorizations
+	history, err := api.GetSignerHistory(accounts.address("C"))
+	if err != nil {
+		t.Fatalf("failed to retrieve signer history: %v", err)
+	}
+	changes := []*SignerChange{{
+		Block:     2,
+		Hash:      headers[1].Hash(),
+		Address:   accounts.address("C"),
+		Authorize: true,
+		Voters:    []common.Address{accounts.address("A"), accounts.address("B")},
+	}}
+	if !reflect.DeepEqual(history, changes) {
+		t.Errorf("signer history mismatch: have %+v, want %+v", history, changes)
+	}
+	if history, _ := api.GetSignerHistory(accounts.address("A")); len(history) != 1 || history[0].Block != 0 || history[0].Voters != nil {
+		t.Errorf("genesis signer history mismatch: have %+v", history)
+	}
+	history, err = api.GetSignerHistory(accounts.address("B"))
+	if err != nil {
+		t.Fatalf("failed to retrieve signer history: %v", err)
+	}
+	changes = []*SignerChange{
+		{Block: 0, Hash: chain.GetHeaderByNumber(0).Hash(), Address: accounts.address("B"), Authorize: true},
+		{Block: 5, Hash: headers[4].Hash(), Address: accounts.address("B"), Voters: []common.Address{accounts.address("A"), accounts.address("C")}},
+	}
+	if !reflect.DeepEqual(history, changes) {
+		t.Errorf("dropped signer history mismatch: have %+v, want %+v", history, changes)
+	}
+}
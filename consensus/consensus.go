@@ -24,6 +24,7 @@ import (
 	"github.com/AdelineCoin/go-adln/params"
 	"github.com/AdelineCoin/go-adln/rpc"
 	"math/big"
+	"time"
 )
 
 // ChainReader defines a small collection of methods needed to access the local
@@ -104,3 +105,28 @@ type PoW interface {
 	// Hashrate returns the current mining hashrate of a PoW consensus engine.
 	Hashrate() float64
 }
+
+// FinalityEngine is a consensus engine providing deterministic finality, i.e.
+// blocks that can't be reorganised out of the chain any more. The chain's fork
+// choice rejects any reorg that would drop the finalized header.
+type FinalityEngine interface {
+	Engine
+
+	// FinalizedHeader retrieves the highest header of the local chain that is
+	// considered final by the consensus rules.
+	FinalizedHeader(chain ChainReader) *types.Header
+}
+
+// DevEngine is a consensus engine able to run developer chains, sealing blocks
+// on demand and keeping its own clock.
+type DevEngine interface {
+	Engine
+
+	// InstantSeal returns whether blocks are sealed as soon as transactions
+	// arrive, instead of continuously.
+	InstantSeal() bool
+
+	// Now returns the current time as seen by the engine, which may be shifted
+	// from the local time.
+	Now() time.Time
+}
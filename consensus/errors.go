@@ -34,4 +34,8 @@ var (
 	// ErrInvalidNumber is returned if a block's number doesn't equal it's parent's
 	// plus one.
 	ErrInvalidNumber = errors.New("invalid block number")
+
+	// ErrNoFinality is returned if the finalized block is requested from a
+	// consensus engine that has no notion of finality.
+	ErrNoFinality = errors.New("consensus engine has no finality")
 )
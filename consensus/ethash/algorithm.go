@@ -261,8 +261,9 @@ func generateDatasetItem(cache []uint32, index uint32, keccak512 hasher) []byte
 }
 
 // generateDataset generates the entire ethash dataset for mining.
-// This method places the result into dest in machine byte order.
-func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
+// This method places the result into dest in machine byte order. The number of
+// items generated so far is tracked in progress, if not nil.
+func generateDataset(dest []uint32, epoch uint64, cache []uint32, progress *uint32) {
 	// Print some debug logs to allow analysis on low end devices
 	logger := log.New("epoch", epoch)
 
@@ -293,7 +294,9 @@ func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
 	var pend sync.WaitGroup
 	pend.Add(threads)
 
-	var progress uint32
+	if progress == nil {
+		progress = new(uint32)
+	}
 	for i := 0; i < threads; i++ {
 		go func(id int) {
 			defer pend.Done()
@@ -317,7 +320,7 @@ func generateDataset(dest []uint32, epoch uint64, cache []uint32) {
 				}
 				copy(dataset[index*hashBytes:], item)
 
-				if status := atomic.AddUint32(&progress, 1); status%percent == 0 {
+				if status := atomic.AddUint32(progress, 1); status%percent == 0 {
 					logger.Info("Generating DAG in progress", "percentage", uint64(status*100)/(size/hashBytes), "elapsed", common.PrettyDuration(time.Since(start)))
 				}
 			}
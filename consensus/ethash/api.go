@@ -0,0 +1,258 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethash
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+
+	"github.com/AdelineCoin/go-adln/common/hexutil"
+	"github.com/AdelineCoin/go-adln/consensus"
+	"github.com/AdelineCoin/go-adln/crypto/sha3"
+	"github.com/AdelineCoin/go-adln/log"
+)
+
+var (
+	errFakeMode        = errors.New("ethash running in fake mode")
+	errNoDatasetDir    = errors.New("ethash DAG storage on disk disabled")
+	errEpochOutOfRange = errors.New("epoch out of range")
+	errDagGenerating   = errors.New("DAG generation already in progress")
+)
+
+// API exposes ethash related methods for the RPC interface, allowing miners to
+// inspect and manage the verification caches and mining DAGs.
+type API struct {
+	chain  consensus.ChainReader
+	ethash *Ethash
+}
+
+// DagProgress is the generation progress of a single mining DAG.
+type DagProgress struct {
+	Epoch      uint64  `json:"epoch"`
+	Percentage float64 `json:"percentage"`
+}
+
+// DagStatus reports the verification caches and mining DAGs currently held in
+// memory and stored on disk, along with the progress of any DAG generation.
+type DagStatus struct {
+	Epoch            uint64        `json:"epoch"`
+	CachesInMemory   []uint64      `json:"cachesInMemory"`
+	CachesOnDisk     []uint64      `json:"cachesOnDisk"`
+	DatasetsInMemory []uint64      `json:"datasetsInMemory"`
+	DatasetsOnDisk   []uint64      `json:"datasetsOnDisk"`
+	Generating       []DagProgress `json:"generating"`
+}
+
+// engine returns the ethash instance actually owning the caches and datasets.
+func (api *API) engine() (*Ethash, error) {
+	ethash := api.ethash
+	if ethash.shared != nil {
+		ethash = ethash.shared
+	}
+	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake || ethash.config.PowMode == ModeDev {
+		return nil, errFakeMode
+	}
+	return ethash, nil
+}
+
+// currentEpoch returns the epoch of the current chain head.
+func (api *API) currentEpoch() uint64 {
+	return api.chain.CurrentHeader().Number.Uint64() / epochLength
+}
+
+// DagStatus retrieves the epochs of the verification caches and mining DAGs held
+// in memory and on disk, and the progress of the DAGs currently being generated.
+func (api *API) DagStatus() (*DagStatus, error) {
+	ethash, err := api.engine()
+	if err != nil {
+		return nil, err
+	}
+	status := &DagStatus{
+		Epoch:            api.currentEpoch(),
+		CachesInMemory:   []uint64{},
+		DatasetsInMemory: []uint64{},
+		Generating:       []DagProgress{},
+	}
+	for epoch := range ethash.caches.items() {
+		status.CachesInMemory = append(status.CachesInMemory, epoch)
+	}
+	datasets := ethash.datasets.items()
+	for epoch := range datasets {
+		status.DatasetsInMemory = append(status.DatasetsInMemory, epoch)
+	}
+	status.CachesOnDisk = sortedEpochs(diskEpochs(ethash.config.CacheDir, "cache"))
+	status.DatasetsOnDisk = sortedEpochs(diskEpochs(ethash.config.DatasetDir, "full"))
+
+	// Gather the progress of both the datasets requested by the miner and the
+	// ones generated on explicit request
+	ethash.lock.Lock()
+	for epoch, d := range ethash.generating {
+		datasets[epoch] = d
+	}
+	ethash.lock.Unlock()
+
+	for epoch, item := range datasets {
+		d := item.(*dataset)
+		items, generated := atomic.LoadUint32(&d.items), atomic.LoadUint32(&d.generated)
+		if items > 0 && generated < items {
+			status.Generating = append(status.Generating, DagProgress{
+				Epoch:      epoch,
+				Percentage: float64(generated) * 100 / float64(items),
+			})
+		}
+	}
+	sortEpochs(status.CachesInMemory)
+	sortEpochs(status.DatasetsInMemory)
+	sort.Slice(status.Generating, func(i, j int) bool { return status.Generating[i].Epoch < status.Generating[j].Epoch })
+
+	return status, nil
+}
+
+// GenerateDag starts generating the mining DAG of an epoch on disk in the background,
+// defaulting to the epoch following the current one. This allows the miner to
+// avoid stalling on DAG generation when crossing the epoch boundary. Only the
+// current and the next epochs are accepted: generating a DAG prunes the ones
+// of older epochs from disk, which must never include the DAG in use.
+func (api *API) GenerateDag(epoch *hexutil.Uint64) (hexutil.Uint64, error) {
+	ethash, err := api.engine()
+	if err != nil {
+		return 0, err
+	}
+	if ethash.config.DatasetDir == "" || ethash.config.DatasetsOnDisk <= 0 {
+		return 0, errNoDatasetDir
+	}
+	current := api.currentEpoch()
+	target := current + 1
+	if epoch != nil {
+		target = uint64(*epoch)
+	}
+	if (target != current && target != current+1) || target >= maxEpoch {
+		return 0, errEpochOutOfRange
+	}
+	// Nothing to do if the DAG is already available or being generated
+	if _, ok := diskEpochs(ethash.config.DatasetDir, "full")[target]; ok {
+		return hexutil.Uint64(target), nil
+	}
+	if _, ok := ethash.datasets.items()[target]; ok {
+		return hexutil.Uint64(target), nil
+	}
+	ethash.lock.Lock()
+	defer ethash.lock.Unlock()
+
+	if _, ok := ethash.generating[target]; ok {
+		return 0, errDagGenerating
+	}
+	if ethash.generating == nil {
+		ethash.generating = make(map[uint64]*dataset)
+	}
+	d := &dataset{epoch: target}
+	ethash.generating[target] = d
+
+	go func() {
+		log.Info("Generating ethash DAG on request", "epoch", target)
+		d.generate(ethash.config.DatasetDir, ethash.config.DatasetsOnDisk, ethash.config.PowMode == ModeTest)
+
+		// The DAG is only needed on disk, release its memory map right away
+		d.finalizer()
+
+		ethash.lock.Lock()
+		delete(ethash.generating, target)
+		ethash.lock.Unlock()
+	}()
+	return hexutil.Uint64(target), nil
+}
+
+// PruneDags deletes all the verification caches and mining DAGs stored on disk
+// for epochs preceding the current one, returning the paths of the removed files.
+func (api *API) PruneDags() ([]string, error) {
+	ethash, err := api.engine()
+	if err != nil {
+		return nil, err
+	}
+	current := api.currentEpoch()
+
+	removed := []string{}
+	for _, files := range []map[uint64]string{
+		diskEpochs(ethash.config.CacheDir, "cache"),
+		diskEpochs(ethash.config.DatasetDir, "full"),
+	} {
+		for epoch, path := range files {
+			if epoch >= current {
+				continue
+			}
+			if err := os.Remove(path); err != nil {
+				log.Warn("Failed to remove stale ethash file", "path", path, "err", err)
+				continue
+			}
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// diskEpochs scans a directory for the ethash files of the given kind ("cache"
+// or "full"), returning their paths indexed by epoch.
+func diskEpochs(dir string, kind string) map[uint64]string {
+	files := make(map[uint64]string)
+	if dir == "" {
+		return files
+	}
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return files
+	}
+	names := make(map[string]struct{})
+	for _, entry := range entries {
+		names[entry.Name()] = struct{}{}
+	}
+	var endian string
+	if !isLittleEndian() {
+		endian = ".be"
+	}
+	// Iterate over the seeds of all the epochs, checking for their files
+	seed := make([]byte, 32)
+	keccak256 := makeHasher(sha3.NewKeccak256())
+	for epoch := uint64(0); epoch < maxEpoch; epoch++ {
+		name := fmt.Sprintf("%s-R%d-%x%s", kind, algorithmRevision, seed[:8], endian)
+		if _, ok := names[name]; ok {
+			files[epoch] = filepath.Join(dir, name)
+		}
+		keccak256(seed, seed)
+	}
+	return files
+}
+
+// sortedEpochs returns the epochs of a file set in ascending order.
+func sortedEpochs(files map[uint64]string) []uint64 {
+	epochs := make([]uint64, 0, len(files))
+	for epoch := range files {
+		epochs = append(epochs, epoch)
+	}
+	sortEpochs(epochs)
+	return epochs
+}
+
+// sortEpochs sorts a list of epochs in ascending order.
+func sortEpochs(epochs []uint64) {
+	sort.Slice(epochs, func(i, j int) bool { return epochs[i] < epochs[j] })
+}
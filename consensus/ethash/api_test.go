@@ -0,0 +1,106 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethash
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/common/hexutil"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+// testHeadChain is a chain reader only aware of its current head.
+type testHeadChain struct {
+	head *types.Header
+}
+
+func (c *testHeadChain) Config() *params.ChainConfig                 { return params.TestChainConfig }
+func (c *testHeadChain) CurrentHeader() *types.Header                { return c.head }
+func (c *testHeadChain) GetHeader(common.Hash, uint64) *types.Header { return nil }
+func (c *testHeadChain) GetHeaderByNumber(uint64) *types.Header      { return nil }
+func (c *testHeadChain) GetHeaderByHash(common.Hash) *types.Header   { return nil }
+func (c *testHeadChain) GetBlock(common.Hash, uint64) *types.Block   { return nil }
+
+// Tests that DAGs can be generated on request, are reported by the status and
+// that stale ones can be pruned from disk.
+func TestDagManagement(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ethash-api-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ethash := New(Config{CacheDir: dir, CachesInMem: 1, CachesOnDisk: 5, DatasetDir: dir, DatasetsOnDisk: 5, PowMode: ModeTest})
+	chain := &testHeadChain{head: &types.Header{Number: big.NewInt(epochLength)}}
+	api := &API{chain: chain, ethash: ethash}
+
+	// Only the DAGs of the current and next epochs can be requested, place the
+	// one of the past epoch on disk directly
+	for _, epoch := range []hexutil.Uint64{0, 3} {
+		if _, err := api.GenerateDag(&epoch); err != errEpochOutOfRange {
+			t.Fatalf("DAG generation of epoch %d error mismatch: have %v, want %v", epoch, err, errEpochOutOfRange)
+		}
+	}
+	past := &dataset{epoch: 0}
+	past.generate(dir, 5, true)
+	past.finalizer()
+
+	current := hexutil.Uint64(1)
+	if _, err := api.GenerateDag(&current); err != nil {
+		t.Fatalf("failed to generate DAG of epoch %d: %v", current, err)
+	}
+	if epoch, err := api.GenerateDag(nil); err != nil || epoch != 2 {
+		t.Fatalf("default DAG generation mismatch: have %d/%v, want %d/nil", epoch, err, 2)
+	}
+	for i := 0; i < 100; i++ {
+		if status, _ := api.DagStatus(); len(status.Generating) == 0 && len(status.DatasetsOnDisk) == 3 {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	status, err := api.DagStatus()
+	if err != nil {
+		t.Fatalf("failed to retrieve DAG status: %v", err)
+	}
+	if status.Epoch != 1 {
+		t.Errorf("current epoch mismatch: have %d, want %d", status.Epoch, 1)
+	}
+	if want := []uint64{0, 1, 2}; !reflect.DeepEqual(status.DatasetsOnDisk, want) {
+		t.Errorf("DAGs on disk mismatch: have %v, want %v", status.DatasetsOnDisk, want)
+	}
+	if len(status.Generating) != 0 {
+		t.Errorf("generating DAGs mismatch: have %v, want none", status.Generating)
+	}
+	// Prune the stale DAGs and ensure only the past one was removed
+	removed, err := api.PruneDags()
+	if err != nil {
+		t.Fatalf("failed to prune DAGs: %v", err)
+	}
+	if len(removed) != 1 {
+		t.Errorf("removed file count mismatch: have %v, want 1", removed)
+	}
+	if status, _ := api.DagStatus(); !reflect.DeepEqual(status.DatasetsOnDisk, []uint64{1, 2}) {
+		t.Errorf("DAGs on disk after pruning mismatch: have %v, want %v", status.DatasetsOnDisk, []uint64{1, 2})
+	}
+}
@@ -236,7 +236,7 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainReader, header, parent *
 			return errLargeBlockTime
 		}
 	} else {
-		if header.Time.Cmp(big.NewInt(time.Now().Add(allowedFutureBlockTime).Unix())) > 0 {
+		if header.Time.Cmp(big.NewInt(ethash.Now().Add(allowedFutureBlockTime).Unix())) > 0 {
 			return consensus.ErrFutureBlock
 		}
 	}
@@ -286,6 +286,9 @@ func (ethash *Ethash) verifyHeader(chain consensus.ChainReader, header, parent *
 	if err := misc.VerifyForkHashes(chain.Config(), header, uncle); err != nil {
 		return err
 	}
+	if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -491,7 +494,7 @@ func calcDifficultyByzantium(chain consensus.ChainReader, time uint64, parent *t
 // the PoW difficulty requirements.
 func (ethash *Ethash) VerifySeal(chain consensus.ChainReader, header *types.Header) error {
 	// If we're running a fake PoW, accept any seal as valid
-	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake {
+	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake || ethash.config.PowMode == ModeDev {
 		time.Sleep(ethash.fakeDelay)
 		if ethash.fakeFail == header.Number.Uint64() {
 			return errInvalidPoW
@@ -512,6 +515,10 @@ func (ethash *Ethash) VerifySeal(chain consensus.ChainReader, header *types.Head
 	if header.Difficulty.Sign() <= 0 {
 		return errInvalidDifficulty
 	}
+	// If light verification is requested, check the seal via a remote proof
+	if ethash.config.SealProofs {
+		return ethash.verifySealProof(header)
+	}
 	// Recompute the digest and PoW value and verify against the header
 	cache := ethash.cache(number)
 	size := datasetSize(number)
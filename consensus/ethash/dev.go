@@ -0,0 +1,117 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethash
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/types"
+)
+
+var (
+	// errNotDeveloper is returned if a developer mode operation is requested
+	// from an ethash engine not running in developer mode.
+	errNotDeveloper = errors.New("not in developer mode")
+
+	// errNegativeTime is returned if the developer clock is attempted to be
+	// moved backwards.
+	errNegativeTime = errors.New("time can't be decreased")
+
+	// errNoPendingSeal is returned if sealing the next block is requested, but no
+	// block was waiting to be sealed within the allowed time.
+	errNoPendingSeal = errors.New("no block waiting to be sealed")
+)
+
+// Developer returns whether the engine runs in developer mode.
+func (ethash *Ethash) Developer() bool {
+	return ethash.config.PowMode == ModeDev
+}
+
+// InstantSeal implements consensus.DevEngine, returning whether the engine seals
+// blocks as soon as transactions arrive, instead of continuously mining.
+func (ethash *Ethash) InstantSeal() bool {
+	return ethash.config.PowMode == ModeDev && ethash.config.DevPeriod == 0
+}
+
+// Now implements consensus.DevEngine, returning the current time as seen by the
+// engine. It is the local time, shifted forward by IncreaseTime in developer mode.
+func (ethash *Ethash) Now() time.Time {
+	return time.Now().Add(time.Duration(atomic.LoadInt64(&ethash.devOffset)) * time.Second)
+}
+
+// IncreaseTime shifts the clock of a developer mode engine forward, affecting
+// the timestamps of all subsequently sealed blocks. The total shift in seconds
+// is returned.
+func (ethash *Ethash) IncreaseTime(seconds int64) (int64, error) {
+	if ethash.config.PowMode != ModeDev {
+		return 0, errNotDeveloper
+	}
+	if seconds < 0 {
+		return 0, errNegativeTime
+	}
+	return atomic.AddInt64(&ethash.devOffset, seconds), nil
+}
+
+// MineNext requests a developer mode engine to seal the block currently being
+// worked on right away, even if it contains no transactions. The request is
+// handed over to the pending seal directly, waiting at most timeout for one, so
+// it can never linger around and trigger an unrelated seal later on.
+func (ethash *Ethash) MineNext(timeout time.Duration) error {
+	if ethash.config.PowMode != ModeDev {
+		return errNotDeveloper
+	}
+	select {
+	case ethash.devMine <- struct{}{}:
+		return nil
+	case <-time.After(timeout):
+		return errNoPendingSeal
+	}
+}
+
+// devSeal seals a block with a fake PoW as soon as it is due: immediately if it
+// contains transactions on an instant chain, once the configured period passed
+// since the last sealed block on a periodic one, or when requested via MineNext.
+func (ethash *Ethash) devSeal(block *types.Block, stop <-chan struct{}) (*types.Block, error) {
+	header := block.Header()
+	header.Nonce, header.MixDigest = types.BlockNonce{}, common.Hash{}
+
+	var timeout <-chan time.Time
+	switch {
+	case ethash.config.DevPeriod > 0:
+		ethash.lock.Lock()
+		deadline := ethash.devSealed.Add(time.Duration(ethash.config.DevPeriod) * time.Second)
+		ethash.lock.Unlock()
+
+		timeout = time.After(time.Until(deadline))
+	case len(block.Transactions()) > 0:
+		return block.WithSeal(header), nil
+	}
+	select {
+	case <-stop:
+		return nil, nil
+	case <-timeout:
+	case <-ethash.devMine:
+	}
+	ethash.lock.Lock()
+	ethash.devSealed = time.Now()
+	ethash.lock.Unlock()
+
+	return block.WithSeal(header), nil
+}
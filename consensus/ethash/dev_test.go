@@ -0,0 +1,85 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethash
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/types"
+)
+
+// Tests that developer mode seals blocks with transactions instantly, and empty
+// ones only on explicit request.
+func TestDeveloperSeal(t *testing.T) {
+	ethash := NewDeveloper(0)
+	if !ethash.InstantSeal() {
+		t.Fatalf("instant sealing not reported")
+	}
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+
+	// Blocks with transactions must be sealed right away
+	tx := types.NewTransaction(0, common.Address{}, new(big.Int), 21000, new(big.Int), nil)
+	block := types.NewBlock(header, []*types.Transaction{tx}, nil, nil)
+	if sealed, err := ethash.Seal(nil, block, nil); err != nil || sealed == nil {
+		t.Fatalf("failed to seal block with transactions: %v", err)
+	}
+	// Requests without a pending seal must fail instead of lingering around
+	if err := ethash.MineNext(10 * time.Millisecond); err != errNoPendingSeal {
+		t.Fatalf("unpending request error mismatch: have %v, want %v", err, errNoPendingSeal)
+	}
+	// Empty blocks must wait until requested or aborted
+	block = types.NewBlock(header, nil, nil, nil)
+
+	stop := make(chan struct{})
+	results := make(chan *types.Block)
+	go func() {
+		sealed, _ := ethash.Seal(nil, block, stop)
+		results <- sealed
+	}()
+	select {
+	case <-results:
+		t.Fatalf("empty block sealed without request")
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := ethash.MineNext(time.Second); err != nil {
+		t.Fatalf("failed to request block: %v", err)
+	}
+	select {
+	case sealed := <-results:
+		if sealed == nil {
+			t.Fatalf("requested block not sealed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("requested block sealing timeout")
+	}
+	// Ensure the clock can be shifted forward only
+	if total, err := ethash.IncreaseTime(3600); err != nil || total != 3600 {
+		t.Errorf("time increase mismatch: have %d (err %v), want %d", total, err, 3600)
+	}
+	if _, err := ethash.IncreaseTime(-1); err != errNegativeTime {
+		t.Errorf("negative time increase error mismatch: have %v, want %v", err, errNegativeTime)
+	}
+	if shift := ethash.Now().Sub(time.Now()); shift < 3599*time.Second {
+		t.Errorf("clock shift mismatch: have %v, want %v", shift, time.Hour)
+	}
+	if _, err := NewFaker().IncreaseTime(1); err != errNotDeveloper {
+		t.Errorf("non-developer error mismatch: have %v, want %v", err, errNotDeveloper)
+	}
+}
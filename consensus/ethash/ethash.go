@@ -29,14 +29,16 @@ import (
 	"runtime"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
-	mmap "github.com/edsrzf/mmap-go"
+	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/consensus"
 	"github.com/AdelineCoin/go-adln/log"
 	"github.com/AdelineCoin/go-adln/metrics"
 	"github.com/AdelineCoin/go-adln/rpc"
+	mmap "github.com/edsrzf/mmap-go"
 	"github.com/hashicorp/golang-lru/simplelru"
 )
 
@@ -47,7 +49,7 @@ var (
 	maxUint256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
 
 	// sharedEthash is a full instance that can be shared between multiple users.
-	sharedEthash = New(Config{"", 3, 0, "", 1, 0, ModeNormal})
+	sharedEthash = New(Config{"", 3, 0, "", 1, 0, ModeNormal, false, nil, 0})
 
 	// algorithmRevision is the data structure version used for file naming.
 	algorithmRevision = 23
@@ -196,6 +198,23 @@ func (lru *lru) get(epoch uint64) (item, future interface{}) {
 	return item, future
 }
 
+// items returns all the items currently held in memory, indexed by epoch.
+func (lru *lru) items() map[uint64]interface{} {
+	lru.mu.Lock()
+	defer lru.mu.Unlock()
+
+	items := make(map[uint64]interface{})
+	for _, key := range lru.cache.Keys() {
+		if item, ok := lru.cache.Peek(key); ok {
+			items[key.(uint64)] = item
+		}
+	}
+	if lru.futureItem != nil {
+		items[lru.future] = lru.futureItem
+	}
+	return items
+}
+
 // cache wraps an ethash cache with some metadata to allow easier concurrent use.
 type cache struct {
 	epoch uint64    // Epoch for which this cache is relevant
@@ -279,6 +298,9 @@ type dataset struct {
 	mmap    mmap.MMap // Memory map itself to unmap before releasing
 	dataset []uint32  // The actual cache data content
 	once    sync.Once // Ensures the cache is generated only once
+
+	items     uint32 // Number of items in the dataset, set when generation starts (atomic)
+	generated uint32 // Number of items generated so far (atomic)
 }
 
 // newDataset creates a new ethash mining dataset and returns it as a plain Go
@@ -302,8 +324,10 @@ func (d *dataset) generate(dir string, limit int, test bool) {
 			cache := make([]uint32, csize/4)
 			generateCache(cache, d.epoch, seed)
 
+			atomic.StoreUint32(&d.items, uint32(dsize/hashBytes))
 			d.dataset = make([]uint32, dsize/4)
-			generateDataset(d.dataset, d.epoch, cache)
+			generateDataset(d.dataset, d.epoch, cache, &d.generated)
+			return
 		}
 		// Disk storage is needed, this will get fancy
 		var endian string
@@ -330,12 +354,14 @@ func (d *dataset) generate(dir string, limit int, test bool) {
 		cache := make([]uint32, csize/4)
 		generateCache(cache, d.epoch, seed)
 
-		d.dump, d.mmap, d.dataset, err = memoryMapAndGenerate(path, dsize, func(buffer []uint32) { generateDataset(buffer, d.epoch, cache) })
+		atomic.StoreUint32(&d.items, uint32(dsize/hashBytes))
+		d.dump, d.mmap, d.dataset, err = memoryMapAndGenerate(path, dsize, func(buffer []uint32) { generateDataset(buffer, d.epoch, cache, &d.generated) })
 		if err != nil {
 			logger.Error("Failed to generate mapped ethash dataset", "err", err)
 
+			atomic.StoreUint32(&d.generated, 0)
 			d.dataset = make([]uint32, dsize/2)
-			generateDataset(d.dataset, d.epoch, cache)
+			generateDataset(d.dataset, d.epoch, cache, &d.generated)
 		}
 		// Iterate over all previous instances and delete old ones
 		for ep := int(d.epoch) - limit; ep >= 0; ep-- {
@@ -376,6 +402,7 @@ const (
 	ModeTest
 	ModeFake
 	ModeFullFake
+	ModeDev
 )
 
 // Config are the configuration parameters of the ethash.
@@ -387,6 +414,13 @@ type Config struct {
 	DatasetsInMem  int
 	DatasetsOnDisk int
 	PowMode        Mode
+
+	// Light verification via seal proofs served by remote peers
+	SealProofs bool          `toml:",omitempty"` // Verify seals via proofs instead of the verification cache
+	DagRoots   []common.Hash `toml:",omitempty"` // Trusted dataset Merkle roots, indexed by epoch
+
+	// Developer mode sealing
+	DevPeriod uint64 `toml:",omitempty"` // Seconds between sealed blocks (0 = seal on transaction arrival)
 }
 
 // Ethash is a consensus engine based on proot-of-work implementing the ethash
@@ -403,6 +437,17 @@ type Ethash struct {
 	update   chan struct{} // Notification channel to update mining parameters
 	hashrate metrics.Meter // Meter tracking the average hashrate
 
+	generating map[uint64]*dataset // DAGs being generated in the background on request
+
+	proofs     SealProofRetriever  // Callback to retrieve seal proofs from remote peers
+	trees      map[uint64]*dagTree // Merkle trees of the datasets for serving seal proofs
+	treeBuilds map[uint64]bool     // Epochs whose dataset trees are being built in the background
+	treeLock   sync.Mutex          // Protects the dataset trees and their builds
+
+	devOffset int64         // Seconds the developer mode clock is shifted by (atomic access)
+	devMine   chan struct{} // Notification channel to seal the next block in developer mode
+	devSealed time.Time     // Time the last block was sealed at in developer mode
+
 	// The fields below are hooks for testing
 	shared    *Ethash       // Shared PoW verifier to avoid cache regeneration
 	fakeFail  uint64        // Block number which fails PoW check even in fake mode
@@ -473,6 +518,20 @@ func NewFakeDelayer(delay time.Duration) *Ethash {
 	}
 }
 
+// NewDeveloper creates an ethash consensus engine with a fake PoW scheme that
+// seals blocks instantly, either on transaction arrival or every period seconds,
+// though they still have to conform to the Ethereum consensus rules.
+func NewDeveloper(period uint64) *Ethash {
+	return &Ethash{
+		config: Config{
+			PowMode:   ModeDev,
+			DevPeriod: period,
+		},
+		devMine:   make(chan struct{}),
+		devSealed: time.Now(),
+	}
+}
+
 // NewFullFaker creates an ethash consensus engine with a full fake scheme that
 // accepts all blocks as valid, without checking any consensus rules whatsoever.
 func NewFullFaker() *Ethash {
@@ -565,10 +624,37 @@ func (ethash *Ethash) Hashrate() float64 {
 	return ethash.hashrate.Rate1()
 }
 
-// APIs implements consensus.Engine, returning the user facing RPC APIs. Currently
-// that is empty.
+// Hashimoto recomputes the mix digest and the PoW result of a nonce for a sealing
+// hash, using the verification cache of the given block's epoch. It allows seals
+// to be reconstructed for external miners that only report the found nonce.
+func (ethash *Ethash) Hashimoto(number uint64, hash common.Hash, nonce uint64) (common.Hash, common.Hash) {
+	// If we're running a fake PoW, there is no meaningful digest to compute
+	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake || ethash.config.PowMode == ModeDev {
+		return common.Hash{}, common.Hash{}
+	}
+	// If we're running a shared PoW, delegate the computation to it
+	if ethash.shared != nil {
+		return ethash.shared.Hashimoto(number, hash, nonce)
+	}
+	cache := ethash.cache(number)
+	size := datasetSize(number)
+	if ethash.config.PowMode == ModeTest {
+		size = 32 * 1024
+	}
+	digest, result := hashimotoLight(size, cache.cache, hash.Bytes(), nonce)
+	runtime.KeepAlive(cache)
+
+	return common.BytesToHash(digest), common.BytesToHash(result)
+}
+
+// APIs implements consensus.Engine, returning the user facing RPC APIs.
 func (ethash *Ethash) APIs(chain consensus.ChainReader) []rpc.API {
-	return nil
+	return []rpc.API{{
+		Namespace: "ethash",
+		Version:   "1.0",
+		Service:   &API{chain: chain, ethash: ethash},
+		Public:    false,
+	}}
 }
 
 // SeedHash is the seed to use for generating a verification cache and the mining
@@ -0,0 +1,430 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/log"
+)
+
+const (
+	// dagTreeCutoff is the height of the lowest Merkle tree level kept in memory.
+	// The subtrees below it are rehashed from the dataset whenever a branch is
+	// needed, reducing the memory use of a tree by a factor of 2^dagTreeCutoff.
+	dagTreeCutoff = 6
+
+	// dagTreesInMem is the number of dataset Merkle trees kept in memory.
+	dagTreesInMem = 2
+)
+
+var (
+	// errNoSealProofs is returned if seal proofs are to be used for verification,
+	// but no means of retrieving them has been configured.
+	errNoSealProofs = errors.New("seal proof retrieval not configured")
+
+	// errUnknownDagRoot is returned if seal proofs are to be used for verification,
+	// but no trusted dataset root is known for the epoch of the block.
+	errUnknownDagRoot = errors.New("unknown dataset root")
+
+	// errInvalidSealProof is returned if a seal proof is malformed or any of the
+	// dataset pages in it do not belong to the trusted dataset.
+	errInvalidSealProof = errors.New("invalid seal proof")
+
+	// errDagTreeUnavailable is returned if a seal proof is requested for an epoch
+	// whose dataset Merkle tree has not been built in advance.
+	errDagTreeUnavailable = errors.New("dataset tree not available")
+)
+
+// SealProof contains the dataset pages accessed by hashimoto while computing the
+// seal of a block, along with their Merkle branches in the tree of the dataset.
+// It allows verifying a seal without the verification cache of its epoch.
+type SealProof struct {
+	Pages    [][]byte        // Dataset pages (mixBytes each) in the order of access
+	Branches [][]common.Hash // Merkle branches of the pages, from the leaf upwards
+}
+
+// SealProofRetriever is a callback to retrieve the seal proof of a header from a
+// remote source. The proof must be verified against the given dataset root and
+// size, as returned by VerifySealProof.
+type SealProofRetriever func(header *types.Header, root common.Hash, size uint64) (*SealProof, error)
+
+// dagTree is the Merkle tree over the pages of a mining dataset. Its leaves are
+// the keccak256 hashes of the dataset pages in little endian byte order, padded
+// with zero hashes to a power of two; its nodes are the hashes of their children.
+type dagTree struct {
+	dataset *dataset        // Dataset the tree was built from, kept alive for rehashing
+	pages   uint64          // Number of pages in the dataset
+	depth   int             // Number of levels above the leaves
+	cutoff  int             // Height of the lowest level kept in memory
+	levels  [][]common.Hash // Nodes of the levels from the cutoff up to the root
+}
+
+// treeDepth returns the depth of the Merkle tree over the given number of pages.
+func treeDepth(pages uint64) int {
+	depth := 0
+	for uint64(1)<<uint(depth) < pages {
+		depth++
+	}
+	return depth
+}
+
+// hashPair returns the Merkle tree node above two children.
+func hashPair(left, right common.Hash) common.Hash {
+	return crypto.Keccak256Hash(left[:], right[:])
+}
+
+// pageBytes returns a dataset page in little endian byte order.
+func pageBytes(dataset []uint32, page uint64) []byte {
+	blob := make([]byte, mixBytes)
+	for i, word := range dataset[page*mixBytes/4 : (page+1)*mixBytes/4] {
+		binary.LittleEndian.PutUint32(blob[i*4:], word)
+	}
+	return blob
+}
+
+// newDagTree builds the Merkle tree over the pages of a generated dataset.
+func newDagTree(d *dataset) *dagTree {
+	pages := uint64(len(d.dataset)) / (mixBytes / 4)
+	tree := &dagTree{
+		dataset: d,
+		pages:   pages,
+		depth:   treeDepth(pages),
+		cutoff:  dagTreeCutoff,
+	}
+	if tree.cutoff > tree.depth {
+		tree.cutoff = tree.depth
+	}
+	// Hash the subtrees below the cutoff on all available cores
+	level := make([]common.Hash, (uint64(1)<<uint(tree.depth))>>uint(tree.cutoff))
+
+	var pend sync.WaitGroup
+	threads := runtime.NumCPU()
+	for i := 0; i < threads; i++ {
+		pend.Add(1)
+		go func(id int) {
+			defer pend.Done()
+			for chunk := id; chunk < len(level); chunk += threads {
+				level[chunk], _ = tree.subtree(uint64(chunk), 0)
+			}
+		}(i)
+	}
+	pend.Wait()
+
+	// Hash the upper levels up to the root
+	tree.levels = append(tree.levels, level)
+	for len(level) > 1 {
+		next := make([]common.Hash, len(level)/2)
+		for i := range next {
+			next[i] = hashPair(level[2*i], level[2*i+1])
+		}
+		tree.levels = append(tree.levels, next)
+		level = next
+	}
+	return tree
+}
+
+// subtree hashes the subtree of the given chunk of pages below the cutoff level,
+// returning its root and the branch of a page within the chunk.
+func (t *dagTree) subtree(chunk uint64, page uint64) (common.Hash, []common.Hash) {
+	first := chunk << uint(t.cutoff)
+
+	nodes := make([]common.Hash, 1<<uint(t.cutoff))
+	for i := range nodes {
+		if p := first + uint64(i); p < t.pages {
+			nodes[i] = crypto.Keccak256Hash(pageBytes(t.dataset.dataset, p))
+		}
+	}
+	var (
+		branch []common.Hash
+		index  = (page - first) & uint64(len(nodes)-1)
+	)
+	for len(nodes) > 1 {
+		branch = append(branch, nodes[index^1])
+		for i := 0; i < len(nodes)/2; i++ {
+			nodes[i] = hashPair(nodes[2*i], nodes[2*i+1])
+		}
+		nodes, index = nodes[:len(nodes)/2], index/2
+	}
+	return nodes[0], branch
+}
+
+// root returns the root hash of the tree.
+func (t *dagTree) root() common.Hash {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// branch returns the Merkle branch of a page, from the leaf upwards.
+func (t *dagTree) branch(page uint64) []common.Hash {
+	_, branch := t.subtree(page>>uint(t.cutoff), page)
+	for height, level := range t.levels[:len(t.levels)-1] {
+		branch = append(branch, level[(page>>uint(t.cutoff+height))^1])
+	}
+	return branch
+}
+
+// verifyBranch checks whether a Merkle branch proves a leaf at the given page
+// index to be part of the tree with the given root.
+func verifyBranch(root common.Hash, page uint64, leaf common.Hash, branch []common.Hash) bool {
+	hash := leaf
+	for height, sibling := range branch {
+		if (page>>uint(height))&1 == 0 {
+			hash = hashPair(hash, sibling)
+		} else {
+			hash = hashPair(sibling, hash)
+		}
+	}
+	return hash == root && page>>uint(len(branch)) == 0
+}
+
+// dagTree retrieves the Merkle tree of the dataset of the given block number,
+// building it synchronously if it's not yet available. Building a tree requires
+// the full dataset, so this must only be used on behalf of the local user.
+func (ethash *Ethash) dagTree(block uint64) *dagTree {
+	if tree := ethash.builtDagTree(block); tree != nil {
+		return tree
+	}
+	return ethash.buildDagTree(block)
+}
+
+// builtDagTree retrieves the Merkle tree of the dataset of the given block number
+// if it has already been built, or nil otherwise.
+func (ethash *Ethash) builtDagTree(block uint64) *dagTree {
+	ethash.treeLock.Lock()
+	defer ethash.treeLock.Unlock()
+
+	return ethash.trees[block/epochLength]
+}
+
+// buildDagTree builds the Merkle tree of the dataset of the given block number
+// and stores it, dropping the oldest tree if too many are held in memory.
+func (ethash *Ethash) buildDagTree(block uint64) *dagTree {
+	epoch := block / epochLength
+
+	log.Info("Building ethash dataset Merkle tree", "epoch", epoch)
+	tree := newDagTree(ethash.dataset(block))
+
+	ethash.treeLock.Lock()
+	defer ethash.treeLock.Unlock()
+
+	if known, ok := ethash.trees[epoch]; ok {
+		return known
+	}
+	if ethash.trees == nil {
+		ethash.trees = make(map[uint64]*dagTree)
+	}
+	if len(ethash.trees) >= dagTreesInMem {
+		oldest := epoch
+		for known := range ethash.trees {
+			if known < oldest {
+				oldest = known
+			}
+		}
+		delete(ethash.trees, oldest)
+	}
+	ethash.trees[epoch] = tree
+	return tree
+}
+
+// PrepareSealProofs starts building the Merkle tree of the dataset of the given
+// block number in the background, if not yet available, so that proofs for the
+// seals of its epoch can be served once done. Serving nodes call this for the
+// epoch of their chain head, which is the dataset the miner uses too.
+func (ethash *Ethash) PrepareSealProofs(block uint64) {
+	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake || ethash.config.PowMode == ModeDev {
+		return
+	}
+	if ethash.shared != nil {
+		ethash.shared.PrepareSealProofs(block)
+		return
+	}
+	epoch := block / epochLength
+	if epoch >= maxEpoch {
+		return
+	}
+	ethash.treeLock.Lock()
+	defer ethash.treeLock.Unlock()
+
+	if _, ok := ethash.trees[epoch]; ok || ethash.treeBuilds[epoch] {
+		return
+	}
+	if ethash.treeBuilds == nil {
+		ethash.treeBuilds = make(map[uint64]bool)
+	}
+	ethash.treeBuilds[epoch] = true
+
+	go func() {
+		ethash.buildDagTree(block)
+
+		ethash.treeLock.Lock()
+		delete(ethash.treeBuilds, epoch)
+		ethash.treeLock.Unlock()
+	}()
+}
+
+// DatasetRoot returns the root hash of the Merkle tree over the mining dataset of
+// the given block number, generating the dataset if needed. The roots of the
+// epochs are the trusted checkpoints required to verify seals via proofs.
+func (ethash *Ethash) DatasetRoot(block uint64) (common.Hash, error) {
+	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake || ethash.config.PowMode == ModeDev {
+		return common.Hash{}, errFakeMode
+	}
+	if ethash.shared != nil {
+		return ethash.shared.DatasetRoot(block)
+	}
+	return ethash.dagTree(block).root(), nil
+}
+
+// MakeDatasetRoot generates a new ethash dataset, optionally storing it to disk,
+// and returns the root hash of its Merkle tree.
+func MakeDatasetRoot(block uint64, dir string) common.Hash {
+	d := &dataset{epoch: block / epochLength}
+	d.generate(dir, math.MaxInt32, false)
+	return newDagTree(d).root()
+}
+
+// SealProof creates the proof of the dataset pages accessed while computing the
+// seal of the given header, allowing remote peers to verify it without the
+// verification cache of its epoch. Proofs are only created for epochs whose
+// dataset Merkle tree is already built, see PrepareSealProofs and DatasetRoot.
+func (ethash *Ethash) SealProof(header *types.Header) (*SealProof, error) {
+	if ethash.config.PowMode == ModeFake || ethash.config.PowMode == ModeFullFake || ethash.config.PowMode == ModeDev {
+		return nil, errFakeMode
+	}
+	if ethash.shared != nil {
+		return ethash.shared.SealProof(header)
+	}
+	number := header.Number.Uint64()
+	if number/epochLength >= maxEpoch {
+		return nil, errNonceOutOfRange
+	}
+	tree := ethash.builtDagTree(number)
+	if tree == nil {
+		return nil, errDagTreeUnavailable
+	}
+	dataset := tree.dataset.dataset
+
+	proof := new(SealProof)
+	hashimoto(header.HashNoNonce().Bytes(), header.Nonce.Uint64(), uint64(len(dataset))*4, func(index uint32) []uint32 {
+		// Hashimoto always accesses both halves of a page, prove it on the first
+		if index%2 == 0 {
+			proof.Pages = append(proof.Pages, pageBytes(dataset, uint64(index/2)))
+			proof.Branches = append(proof.Branches, tree.branch(uint64(index/2)))
+		}
+		offset := index * hashWords
+		return dataset[offset : offset+hashWords]
+	})
+	return proof, nil
+}
+
+// SetSealProofRetriever sets the callback used to retrieve seal proofs if the
+// engine is configured to verify seals via proofs instead of the cache.
+func (ethash *Ethash) SetSealProofRetriever(retriever SealProofRetriever) {
+	if ethash.shared != nil {
+		ethash.shared.SetSealProofRetriever(retriever)
+		return
+	}
+	ethash.lock.Lock()
+	defer ethash.lock.Unlock()
+
+	ethash.proofs = retriever
+}
+
+// verifySealProof checks whether the given header satisfies the PoW difficulty
+// requirements, using a seal proof retrieved from a remote peer.
+func (ethash *Ethash) verifySealProof(header *types.Header) error {
+	number := header.Number.Uint64()
+
+	epoch := number / epochLength
+	if epoch >= uint64(len(ethash.config.DagRoots)) {
+		return errUnknownDagRoot
+	}
+	root := ethash.config.DagRoots[epoch]
+
+	ethash.lock.Lock()
+	retriever := ethash.proofs
+	ethash.lock.Unlock()
+
+	if retriever == nil {
+		return errNoSealProofs
+	}
+	size := datasetSize(number)
+	if ethash.config.PowMode == ModeTest {
+		size = 32 * 1024
+	}
+	proof, err := retriever(header, root, size)
+	if err != nil {
+		return err
+	}
+	return VerifySealProof(header, proof, root, size)
+}
+
+// VerifySealProof checks whether the given header satisfies the PoW difficulty
+// requirements, using the proof of the dataset pages accessed by its seal. The
+// pages are checked against the trusted root of the epoch's dataset of size bytes.
+func VerifySealProof(header *types.Header, proof *SealProof, root common.Hash, size uint64) error {
+	if header.Difficulty.Sign() <= 0 {
+		return errInvalidDifficulty
+	}
+	if proof == nil || len(proof.Pages) != loopAccesses || len(proof.Branches) != loopAccesses {
+		return errInvalidSealProof
+	}
+	depth := treeDepth(size / mixBytes)
+
+	var (
+		access int
+		failed bool
+		page   = make([]uint32, mixBytes/4)
+	)
+	digest, result := hashimoto(header.HashNoNonce().Bytes(), header.Nonce.Uint64(), size, func(index uint32) []uint32 {
+		// Verify each page on its first access, both halves are always accessed
+		if index%2 == 0 && !failed {
+			blob, branch := proof.Pages[access], proof.Branches[access]
+			access++
+
+			if len(blob) != mixBytes || len(branch) != depth || !verifyBranch(root, uint64(index/2), crypto.Keccak256Hash(blob), branch) {
+				failed = true
+			} else {
+				for i := range page {
+					page[i] = binary.LittleEndian.Uint32(blob[i*4:])
+				}
+			}
+		}
+		offset := (index % 2) * hashWords
+		return page[offset : offset+hashWords]
+	})
+	if failed {
+		return errInvalidSealProof
+	}
+	if !bytes.Equal(header.MixDigest[:], digest) {
+		return errInvalidMixDigest
+	}
+	target := new(big.Int).Div(maxUint256, header.Difficulty)
+	if new(big.Int).SetBytes(result).Cmp(target) > 0 {
+		return errInvalidPoW
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethash
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/crypto"
+)
+
+// Tests that the Merkle branches of all the pages of datasets of various sizes
+// verify against the tree root, and only at their own positions.
+func TestDagTreeBranches(t *testing.T) {
+	for _, pages := range []int{1, 2, 3, 64, 65, 200} {
+		d := &dataset{dataset: make([]uint32, pages*mixBytes/4)}
+		for i := range d.dataset {
+			d.dataset[i] = uint32(i)
+		}
+		tree := newDagTree(d)
+		for page := 0; page < pages; page++ {
+			leaf := crypto.Keccak256Hash(pageBytes(d.dataset, uint64(page)))
+			branch := tree.branch(uint64(page))
+
+			if len(branch) != treeDepth(uint64(pages)) {
+				t.Fatalf("pages %d, page %d: branch length mismatch: have %d, want %d", pages, page, len(branch), treeDepth(uint64(pages)))
+			}
+			if !verifyBranch(tree.root(), uint64(page), leaf, branch) {
+				t.Fatalf("pages %d, page %d: valid branch rejected", pages, page)
+			}
+			if pages > 1 && verifyBranch(tree.root(), uint64((page+1)%pages), leaf, branch) {
+				t.Fatalf("pages %d, page %d: branch accepted at wrong position", pages, page)
+			}
+		}
+	}
+}
+
+// newTestProver creates a tester ethash engine storing its datasets in a temporary
+// directory, returning it along with a cleanup function removing the directory.
+func newTestProver(t *testing.T) (*Ethash, func()) {
+	dir, err := ioutil.TempDir("", "ethash-proof-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prover := New(Config{CachesInMem: 1, DatasetDir: dir, DatasetsOnDisk: 1, PowMode: ModeTest})
+	return prover, func() { os.RemoveAll(dir) }
+}
+
+// Tests that seals can be verified via proofs created by a full node, and that
+// tampered proofs or proofs against the wrong root are rejected.
+func TestSealProofVerification(t *testing.T) {
+	// Seal a block with a full tester engine and create the proof of it
+	prover, cleanup := newTestProver(t)
+	defer cleanup()
+
+	head := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	block, err := prover.Seal(nil, types.NewBlockWithHeader(head), nil)
+	if err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	head = block.Header()
+
+	root, err := prover.DatasetRoot(head.Number.Uint64())
+	if err != nil {
+		t.Fatalf("failed to compute dataset root: %v", err)
+	}
+	proof, err := prover.SealProof(head)
+	if err != nil {
+		t.Fatalf("failed to create seal proof: %v", err)
+	}
+	// Verify the seal with a light engine only having access to the proof
+	verifier := New(Config{PowMode: ModeTest, SealProofs: true, DagRoots: []common.Hash{root}})
+	if err := verifier.VerifySeal(nil, head); err != errNoSealProofs {
+		t.Fatalf("verification error mismatch without retriever: have %v, want %v", err, errNoSealProofs)
+	}
+	verifier.SetSealProofRetriever(func(header *types.Header, root common.Hash, size uint64) (*SealProof, error) {
+		return proof, nil
+	})
+	if err := verifier.VerifySeal(nil, head); err != nil {
+		t.Fatalf("valid seal rejected: %v", err)
+	}
+	// Ensure that proofs against unknown or wrong roots are rejected
+	if err := VerifySealProof(head, proof, common.Hash{}, 32*1024); err != errInvalidSealProof {
+		t.Errorf("wrong root error mismatch: have %v, want %v", err, errInvalidSealProof)
+	}
+	future := types.CopyHeader(head)
+	future.Number = big.NewInt(epochLength)
+	if err := verifier.VerifySeal(nil, future); err != errUnknownDagRoot {
+		t.Errorf("unknown root error mismatch: have %v, want %v", err, errUnknownDagRoot)
+	}
+	// Ensure that tampered pages and seals are rejected
+	tampered := &SealProof{Pages: make([][]byte, len(proof.Pages)), Branches: proof.Branches}
+	copy(tampered.Pages, proof.Pages)
+	tampered.Pages[10] = common.CopyBytes(tampered.Pages[10])
+	tampered.Pages[10][0]++
+	if err := VerifySealProof(head, tampered, root, 32*1024); err != errInvalidSealProof {
+		t.Errorf("tampered page error mismatch: have %v, want %v", err, errInvalidSealProof)
+	}
+	invalid := types.CopyHeader(head)
+	invalid.Nonce = types.EncodeNonce(head.Nonce.Uint64() + 1)
+	if err := VerifySealProof(invalid, proof, root, 32*1024); err == nil {
+		t.Errorf("proof accepted for a different nonce")
+	}
+}
+
+// Tests that seal proofs are only created for epochs prepared in advance, and
+// that preparing an epoch builds its dataset tree in the background.
+func TestSealProofPreparation(t *testing.T) {
+	prover, cleanup := newTestProver(t)
+	defer cleanup()
+
+	head := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(100)}
+	block, err := prover.Seal(nil, types.NewBlockWithHeader(head), nil)
+	if err != nil {
+		t.Fatalf("failed to seal block: %v", err)
+	}
+	head = block.Header()
+
+	if _, err := prover.SealProof(head); err != errDagTreeUnavailable {
+		t.Fatalf("unprepared epoch error mismatch: have %v, want %v", err, errDagTreeUnavailable)
+	}
+	prover.PrepareSealProofs(head.Number.Uint64())
+
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		proof, err := prover.SealProof(head)
+		if err == nil {
+			root, _ := prover.DatasetRoot(head.Number.Uint64())
+			if err := VerifySealProof(head, proof, root, 32*1024); err != nil {
+				t.Fatalf("prepared proof invalid: %v", err)
+			}
+			break
+		}
+		if err != errDagTreeUnavailable {
+			t.Fatalf("failed to create seal proof: %v", err)
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("epoch not prepared in time")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
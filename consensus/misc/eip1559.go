@@ -0,0 +1,91 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/common/math"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+var (
+	// ErrMissingBaseFee is returned if a header after the EIP1559 fork doesn't
+	// contain a base fee.
+	ErrMissingBaseFee = errors.New("header is missing base fee")
+
+	// ErrUnexpectedBaseFee is returned if a header before the EIP1559 fork
+	// contains a base fee.
+	ErrUnexpectedBaseFee = errors.New("header has unexpected base fee")
+)
+
+// VerifyEip1559Header verifies that the base fee of a header is present exactly
+// after the EIP1559 fork, and that it was correctly derived from the parent.
+func VerifyEip1559Header(config *params.ChainConfig, parent, header *types.Header) error {
+	if !config.IsEIP1559(header.Number) {
+		if header.BaseFee != nil {
+			return ErrUnexpectedBaseFee
+		}
+		return nil
+	}
+	if header.BaseFee == nil {
+		return ErrMissingBaseFee
+	}
+	if expected := CalcBaseFee(config, parent); header.BaseFee.Cmp(expected) != 0 {
+		return fmt.Errorf("invalid base fee: have %s, want %s (parent base fee %v, parent gas used %d)", header.BaseFee, expected, parent.BaseFee, parent.GasUsed)
+	}
+	return nil
+}
+
+// CalcBaseFee calculates the base fee of the header following the parent. The
+// base fee moves by at most 1/8th per block, towards keeping the gas used at
+// half of the gas limit.
+func CalcBaseFee(config *params.ChainConfig, parent *types.Header) *big.Int {
+	// The fork block starts off with the initial base fee
+	if !config.IsEIP1559(parent.Number) {
+		return new(big.Int).SetUint64(params.InitialBaseFee)
+	}
+	target := parent.GasLimit / params.ElasticityMultiplier
+	if parent.GasUsed == target || target == 0 {
+		return new(big.Int).Set(parent.BaseFee)
+	}
+	var (
+		denominator = new(big.Int).SetUint64(params.BaseFeeChangeDenominator)
+		targetGas   = new(big.Int).SetUint64(target)
+	)
+	if parent.GasUsed > target {
+		// Usage above the target, increase the base fee by at least 1 wei
+		delta := new(big.Int).SetUint64(parent.GasUsed - target)
+		delta.Mul(delta, parent.BaseFee)
+		delta.Div(delta, targetGas)
+		delta.Div(delta, denominator)
+
+		return delta.Add(parent.BaseFee, math.BigMax(delta, common.Big1))
+	}
+	// Usage below the target, decrease the base fee (never below zero as the
+	// delta is at most 1/8th of it)
+	delta := new(big.Int).SetUint64(target - parent.GasUsed)
+	delta.Mul(delta, parent.BaseFee)
+	delta.Div(delta, targetGas)
+	delta.Div(delta, denominator)
+
+	return delta.Sub(parent.BaseFee, delta)
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+// eip1559Config returns a chain config with the fee market activated at block 5.
+func eip1559Config() *params.ChainConfig {
+	config := *params.TestChainConfig
+	config.EIP1559Block = big.NewInt(5)
+	return &config
+}
+
+// Tests that the base fee moves towards the gas target of the parent block.
+func TestCalcBaseFee(t *testing.T) {
+	tests := []struct {
+		parentBaseFee uint64
+		parentGasUsed uint64
+		expected      uint64
+	}{
+		{params.InitialBaseFee, 10000000, params.InitialBaseFee}, // usage == target
+		{params.InitialBaseFee, 9000000, 987500000},              // usage below target
+		{params.InitialBaseFee, 11000000, 1012500000},            // usage above target
+		{params.InitialBaseFee, 0, 875000000},                    // empty block
+		{params.InitialBaseFee, 20000000, 1125000000},            // full block
+		{7, 10000001, 8}, // minimum increase of 1 wei
+	}
+	config := eip1559Config()
+	for i, test := range tests {
+		parent := &types.Header{
+			Number:   big.NewInt(10),
+			GasLimit: 20000000,
+			GasUsed:  test.parentGasUsed,
+			BaseFee:  new(big.Int).SetUint64(test.parentBaseFee),
+		}
+		if have := CalcBaseFee(config, parent); have.Uint64() != test.expected {
+			t.Errorf("test %d: base fee mismatch: have %v, want %d", i, have, test.expected)
+		}
+	}
+}
+
+// Tests that headers carry a correctly derived base fee exactly after the fork.
+func TestVerifyEip1559Header(t *testing.T) {
+	config := eip1559Config()
+
+	// Headers before the fork must not have a base fee
+	parent := &types.Header{Number: big.NewInt(3), GasLimit: 20000000}
+	header := &types.Header{Number: big.NewInt(4), GasLimit: 20000000}
+	if err := VerifyEip1559Header(config, parent, header); err != nil {
+		t.Errorf("pre-fork header rejected: %v", err)
+	}
+	header.BaseFee = new(big.Int).SetUint64(params.InitialBaseFee)
+	if err := VerifyEip1559Header(config, parent, header); err != ErrUnexpectedBaseFee {
+		t.Errorf("pre-fork base fee error mismatch: have %v, want %v", err, ErrUnexpectedBaseFee)
+	}
+	// The fork block must start with the initial base fee
+	parent, header = header, &types.Header{Number: big.NewInt(5), GasLimit: 20000000}
+	parent.BaseFee = nil
+
+	if err := VerifyEip1559Header(config, parent, header); err != ErrMissingBaseFee {
+		t.Errorf("missing base fee error mismatch: have %v, want %v", err, ErrMissingBaseFee)
+	}
+	header.BaseFee = new(big.Int).SetUint64(params.InitialBaseFee)
+	if err := VerifyEip1559Header(config, parent, header); err != nil {
+		t.Errorf("fork header rejected: %v", err)
+	}
+	header.BaseFee = new(big.Int).SetUint64(params.InitialBaseFee + 1)
+	if err := VerifyEip1559Header(config, parent, header); err == nil {
+		t.Errorf("invalid fork base fee accepted")
+	}
+}
@@ -965,6 +965,11 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 		// Split same-difficulty blocks by number, then at random
 		reorg = block.NumberU64() < currentBlock.NumberU64() || (block.NumberU64() == currentBlock.NumberU64() && mrand.Float64() < 0.5)
 	}
+	// Never reorganise finalized blocks out of the chain, whatever the difficulty
+	if reorg && block.ParentHash() != currentBlock.Hash() && !bc.hc.extendsFinalized(bc, block.Header()) {
+		log.Warn("Rejecting reorg below the finalized block", "number", block.Number(), "hash", block.Hash())
+		reorg = false
+	}
 	if reorg {
 		// Reorganise the chain if the parent is not the head block
 		if block.ParentHash() != currentBlock.Hash() {
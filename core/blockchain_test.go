@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus"
 	"github.com/AdelineCoin/go-adln/consensus/ethash"
 	"github.com/AdelineCoin/go-adln/core/state"
 	"github.com/AdelineCoin/go-adln/core/types"
@@ -419,6 +420,71 @@ func testReorg(t *testing.T, first, second []int64, td int64, full bool) {
 }
 
 // Tests that the insertion functions detect banned hashes.
+// finalityFaker is a fake ethash engine considering the canonical block of a given
+// number final, to test fork choice in the presence of deterministic finality.
+type finalityFaker struct {
+	*ethash.Ethash
+	number uint64
+}
+
+func (f *finalityFaker) FinalizedHeader(chain consensus.ChainReader) *types.Header {
+	return chain.GetHeaderByNumber(f.number)
+}
+
+// Tests that heavier forks only take canonical ownership if they don't revert the
+// finalized header of the consensus engine.
+func TestReorgFinalizedHeaders(t *testing.T) { testReorgFinalized(t, false) }
+func TestReorgFinalizedBlocks(t *testing.T)  { testReorgFinalized(t, true) }
+
+func testReorgFinalized(t *testing.T, full bool) {
+	engine := &finalityFaker{Ethash: ethash.NewFaker(), number: 5}
+
+	db, blockchain, err := newCanonical(engine, 10, full)
+	if err != nil {
+		t.Fatalf("failed to create pristine chain: %v", err)
+	}
+	defer blockchain.Stop()
+
+	head := blockchain.CurrentHeader().Hash()
+	if full {
+		head = blockchain.CurrentBlock().Hash()
+	}
+	// Insert a much heavier fork below the finalized header and ensure it's rejected
+	if full {
+		if _, err := blockchain.InsertChain(makeBlockChain(blockchain.GetBlockByNumber(3), 20, engine, db, forkSeed)); err != nil {
+			t.Fatalf("failed to insert fork below finality: %v", err)
+		}
+		if hash := blockchain.CurrentBlock().Hash(); hash != head {
+			t.Fatalf("finalized block reorged: head %x, want %x", hash, head)
+		}
+	} else {
+		if _, err := blockchain.InsertHeaderChain(makeHeaderChain(blockchain.GetHeaderByNumber(3), 20, engine, db, forkSeed), 1); err != nil {
+			t.Fatalf("failed to insert fork below finality: %v", err)
+		}
+		if hash := blockchain.CurrentHeader().Hash(); hash != head {
+			t.Fatalf("finalized header reorged: head %x, want %x", hash, head)
+		}
+	}
+	// Insert a heavier fork above the finalized header and ensure it's accepted
+	if full {
+		blocks := makeBlockChain(blockchain.GetBlockByNumber(6), 10, engine, db, forkSeed+1)
+		if _, err := blockchain.InsertChain(blocks); err != nil {
+			t.Fatalf("failed to insert fork above finality: %v", err)
+		}
+		if hash := blockchain.CurrentBlock().Hash(); hash != blocks[len(blocks)-1].Hash() {
+			t.Fatalf("fork above finality not accepted: head %x, want %x", hash, blocks[len(blocks)-1].Hash())
+		}
+	} else {
+		headers := makeHeaderChain(blockchain.GetHeaderByNumber(6), 10, engine, db, forkSeed+1)
+		if _, err := blockchain.InsertHeaderChain(headers, 1); err != nil {
+			t.Fatalf("failed to insert fork above finality: %v", err)
+		}
+		if hash := blockchain.CurrentHeader().Hash(); hash != headers[len(headers)-1].Hash() {
+			t.Fatalf("fork above finality not accepted: head %x, want %x", hash, headers[len(headers)-1].Hash())
+		}
+	}
+}
+
 func TestBadHeaderHashes(t *testing.T) { testBadHashes(t, false) }
 func TestBadBlockHashes(t *testing.T)  { testBadHashes(t, true) }
 
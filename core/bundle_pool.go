@@ -0,0 +1,360 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus/misc"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/crypto/sha3"
+	"github.com/AdelineCoin/go-adln/event"
+	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+const (
+	// maxBundleTxs is the maximum number of transactions permitted in a bundle.
+	maxBundleTxs = 16
+
+	// maxBundlesPerBlock is the maximum number of bundles tracked for a single
+	// target block.
+	maxBundlesPerBlock = 64
+
+	// maxBundleFuture is the maximum number of blocks ahead of the current head
+	// a bundle may be targeted at.
+	maxBundleFuture = 64
+
+	// maxBundles is the maximum number of bundles tracked across all the target
+	// blocks.
+	maxBundles = 1024
+
+	// maxBundleBytes is the maximum total size of the transactions of all the
+	// bundles tracked.
+	maxBundleBytes = 16 * 1024 * 1024
+)
+
+var (
+	// ErrEmptyBundle is returned if a bundle without any transactions is submitted.
+	ErrEmptyBundle = errors.New("empty bundle")
+
+	// ErrOversizedBundle is returned if a bundle contains more transactions than
+	// the pool is willing to simulate.
+	ErrOversizedBundle = errors.New("oversized bundle")
+
+	// ErrBundleTargetPassed is returned if a bundle targets a block that is not
+	// above the current chain head.
+	ErrBundleTargetPassed = errors.New("bundle target block already passed")
+
+	// ErrBundleTargetTooFar is returned if a bundle targets a block too far in
+	// the future.
+	ErrBundleTargetTooFar = errors.New("bundle target block too far in the future")
+
+	// ErrBundlePoolFull is returned if the maximum number of bundles is already
+	// tracked for the target block, or if the pool is full and holds no bundles
+	// targeting later blocks to make room for a new one.
+	ErrBundlePoolFull = errors.New("bundle pool full")
+
+	// ErrKnownBundle is returned if the exact same bundle is already tracked.
+	ErrKnownBundle = errors.New("known bundle")
+)
+
+// Bundle is an ordered group of transactions which must be included contiguously
+// in the target block, or not at all.
+type Bundle struct {
+	Txs         types.Transactions // Transactions to include, in this order
+	TargetBlock uint64             // Number of the only block to include the bundle in
+
+	hash common.Hash        // Cached hash of the bundle
+	size common.StorageSize // Cached total size of the bundle's transactions
+}
+
+// NewBundle creates a bundle of the given transactions targeting a block.
+func NewBundle(txs types.Transactions, target uint64) *Bundle {
+	bundle := &Bundle{
+		Txs:         make(types.Transactions, len(txs)),
+		TargetBlock: target,
+	}
+	copy(bundle.Txs, txs)
+
+	hw := sha3.NewKeccak256()
+	for _, tx := range bundle.Txs {
+		hw.Write(tx.Hash().Bytes())
+		bundle.size += tx.Size()
+	}
+	hw.Sum(bundle.hash[:0])
+
+	return bundle
+}
+
+// Hash returns the keccak256 hash of the concatenated transaction hashes of the
+// bundle, uniquely identifying it.
+func (b *Bundle) Hash() common.Hash {
+	return b.hash
+}
+
+// Size returns the total encoded size of the transactions in the bundle.
+func (b *Bundle) Size() common.StorageSize {
+	return b.size
+}
+
+// BundlePool keeps track of the transaction bundles submitted to the local node
+// until their target block passes. Bundles are never propagated to the network,
+// they are only ever offered to the local miner.
+type BundlePool struct {
+	config *params.ChainConfig
+	chain  blockChain
+	signer types.Signer
+
+	bundles map[uint64][]*Bundle // Bundles grouped by their target block number
+	count   int                  // Number of bundles tracked across all targets
+	size    common.StorageSize   // Total size of the bundles tracked
+	mu      sync.RWMutex
+
+	chainHeadCh  chan ChainHeadEvent
+	chainHeadSub event.Subscription
+	wg           sync.WaitGroup
+}
+
+// NewBundlePool creates a new bundle pool to track the bundles targeting the
+// upcoming blocks of the given chain.
+func NewBundlePool(chainconfig *params.ChainConfig, chain blockChain) *BundlePool {
+	pool := &BundlePool{
+		config:      chainconfig,
+		chain:       chain,
+		signer:      types.LatestSigner(chainconfig),
+		bundles:     make(map[uint64][]*Bundle),
+		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
+	}
+	pool.chainHeadSub = chain.SubscribeChainHeadEvent(pool.chainHeadCh)
+
+	pool.wg.Add(1)
+	go pool.loop()
+
+	return pool
+}
+
+// loop is the bundle pool's main event loop, dropping all the bundles that can
+// no longer be included once the chain progresses past their target block.
+func (pool *BundlePool) loop() {
+	defer pool.wg.Done()
+
+	for {
+		select {
+		case ev := <-pool.chainHeadCh:
+			if ev.Block != nil {
+				pool.mu.Lock()
+				pool.prune(ev.Block.NumberU64())
+				pool.mu.Unlock()
+			}
+		case <-pool.chainHeadSub.Err():
+			return
+		}
+	}
+}
+
+// prune drops all the bundles targeting blocks up to and including the given one.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *BundlePool) prune(number uint64) {
+	for target, bundles := range pool.bundles {
+		if target <= number {
+			log.Trace("Dropping passed bundles", "target", target, "count", len(bundles))
+			for _, bundle := range bundles {
+				pool.count--
+				pool.size -= bundle.Size()
+			}
+			delete(pool.bundles, target)
+		}
+	}
+}
+
+// Stop terminates the bundle pool.
+func (pool *BundlePool) Stop() {
+	pool.chainHeadSub.Unsubscribe()
+	pool.wg.Wait()
+
+	log.Info("Bundle pool stopped")
+}
+
+// Add validates a bundle and starts tracking it until its target block passes.
+// If the pool is full, bundles targeting later blocks are evicted to make room.
+func (pool *BundlePool) Add(bundle *Bundle) error {
+	// Run all the stateless sanity checks on the bundle
+	if len(bundle.Txs) == 0 {
+		return ErrEmptyBundle
+	}
+	if len(bundle.Txs) > maxBundleTxs {
+		return ErrOversizedBundle
+	}
+	for _, tx := range bundle.Txs {
+		if tx.Size() > 32*1024 {
+			return ErrOversizedData
+		}
+		if tx.Value().Sign() < 0 {
+			return ErrNegativeValue
+		}
+		if tx.GasTipCap().Cmp(tx.GasFeeCap()) > 0 {
+			return ErrTipAboveFeeCap
+		}
+		if _, err := types.Sender(pool.signer, tx); err != nil {
+			return ErrInvalidSender
+		}
+	}
+	head := pool.chain.CurrentBlock()
+	if bundle.TargetBlock <= head.NumberU64() {
+		return ErrBundleTargetPassed
+	}
+	if bundle.TargetBlock > head.NumberU64()+maxBundleFuture {
+		return ErrBundleTargetTooFar
+	}
+	if err := pool.validateState(bundle, head); err != nil {
+		return err
+	}
+	// Bundle seems valid, insert it unless already known or the target is full
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	bundles := pool.bundles[bundle.TargetBlock]
+	for _, known := range bundles {
+		if known.Hash() == bundle.Hash() {
+			return ErrKnownBundle
+		}
+	}
+	if len(bundles) >= maxBundlesPerBlock {
+		return ErrBundlePoolFull
+	}
+	if !pool.evict(bundle) {
+		return ErrBundlePoolFull
+	}
+	pool.bundles[bundle.TargetBlock] = append(pool.bundles[bundle.TargetBlock], bundle)
+	pool.count++
+	pool.size += bundle.Size()
+
+	log.Trace("Pooled new transaction bundle", "hash", bundle.Hash(), "target", bundle.TargetBlock, "txs", len(bundle.Txs))
+	return nil
+}
+
+// validateState checks the bundle against the state of the current head: the
+// senders must not reuse nonces already included, must be able to pay for all
+// their transactions, and the fee caps must be able to cover the base fee of
+// the target block.
+func (pool *BundlePool) validateState(bundle *Bundle, head *types.Block) error {
+	statedb, err := pool.chain.StateAt(head.Root())
+	if err != nil {
+		return err
+	}
+	// The base fee can drop by at most 1/8th per block, reject fee caps which
+	// can't possibly reach the target's base fee
+	var minBaseFee *big.Int
+	if next := new(big.Int).Add(head.Number(), common.Big1); pool.config.IsEIP1559(next) {
+		minBaseFee = misc.CalcBaseFee(pool.config, head.Header())
+		for number := head.NumberU64() + 1; number < bundle.TargetBlock; number++ {
+			minBaseFee.Sub(minBaseFee, new(big.Int).Div(minBaseFee, new(big.Int).SetUint64(params.BaseFeeChangeDenominator)))
+		}
+	}
+	costs := make(map[common.Address]*big.Int)
+	for _, tx := range bundle.Txs {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		if statedb.GetNonce(from) > tx.Nonce() {
+			return ErrNonceTooLow
+		}
+		if minBaseFee != nil && tx.GasFeeCap().Cmp(minBaseFee) < 0 {
+			return ErrFeeCapTooLow
+		}
+		if costs[from] == nil {
+			costs[from] = new(big.Int)
+		}
+		costs[from].Add(costs[from], tx.Cost())
+		if statedb.GetBalance(from).Cmp(costs[from]) < 0 {
+			return ErrInsufficientFunds
+		}
+	}
+	return nil
+}
+
+// evict makes room for a new bundle if the pool is full, dropping the most
+// recently added bundles targeting the furthest blocks, as long as they target
+// blocks after the new bundle. It returns false, without dropping anything, if
+// not enough room can be made.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *BundlePool) evict(bundle *Bundle) bool {
+	count, size := pool.count+1, pool.size+bundle.Size()
+	if count <= maxBundles && size <= maxBundleBytes {
+		return true
+	}
+	// Pool is full, gather the targets eligible for eviction, furthest first
+	var targets []uint64
+	for target := range pool.bundles {
+		if target > bundle.TargetBlock {
+			targets = append(targets, target)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i] > targets[j] })
+
+	// Find out how many bundles need dropping before actually dropping any
+	drops := make(map[uint64]int)
+	for _, target := range targets {
+		bundles := pool.bundles[target]
+		for i := len(bundles) - 1; i >= 0 && (count > maxBundles || size > maxBundleBytes); i-- {
+			count--
+			size -= bundles[i].Size()
+			drops[target]++
+		}
+	}
+	if count > maxBundles || size > maxBundleBytes {
+		return false
+	}
+	for target, n := range drops {
+		bundles := pool.bundles[target]
+		for _, dropped := range bundles[len(bundles)-n:] {
+			log.Trace("Evicting transaction bundle", "hash", dropped.Hash(), "target", target)
+			pool.count--
+			pool.size -= dropped.Size()
+		}
+		if n == len(bundles) {
+			delete(pool.bundles, target)
+		} else {
+			pool.bundles[target] = bundles[:len(bundles)-n]
+		}
+	}
+	return true
+}
+
+// Bundles retrieves all the bundles targeting the given block number. The returned
+// slice is a copy and can be freely modified by calling code.
+func (pool *BundlePool) Bundles(number uint64) []*Bundle {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	bundles := make([]*Bundle, len(pool.bundles[number]))
+	copy(bundles, pool.bundles[number])
+	return bundles
+}
+
+// Stats retrieves the number of bundles currently tracked by the pool.
+func (pool *BundlePool) Stats() int {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.count
+}
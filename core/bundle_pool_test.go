@@ -0,0 +1,212 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/state"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/event"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+// Tests that bundles are validated before being accepted into the pool.
+func TestBundlePoolValidation(t *testing.T) {
+	t.Parallel()
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewBundlePool(params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+	txs := types.Transactions{transaction(0, 100000, key), transaction(1, 100000, key)}
+
+	// Create an account with an already used nonce and an unfunded one
+	used, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(used.PublicKey), big.NewInt(1000000))
+	statedb.SetNonce(crypto.PubkeyToAddress(used.PublicKey), 1)
+
+	poor, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(poor.PublicKey), big.NewInt(150000))
+
+	oversized := make(types.Transactions, maxBundleTxs+1)
+	for i := range oversized {
+		oversized[i] = transaction(uint64(i), 100000, key)
+	}
+	unsigned := types.NewTransaction(0, common.Address{}, big.NewInt(0), 100000, big.NewInt(1), nil)
+
+	tests := []struct {
+		bundle *Bundle
+		err    error
+	}{
+		{NewBundle(nil, 1), ErrEmptyBundle},
+		{NewBundle(oversized, 1), ErrOversizedBundle},
+		{NewBundle(types.Transactions{unsigned}, 1), ErrInvalidSender},
+		{NewBundle(types.Transactions{transaction(0, 100000, used)}, 1), ErrNonceTooLow},
+		{NewBundle(types.Transactions{transaction(0, 100000, poor), transaction(1, 100000, poor)}, 1), ErrInsufficientFunds},
+		{NewBundle(txs, 0), ErrBundleTargetPassed},
+		{NewBundle(txs, maxBundleFuture+1), ErrBundleTargetTooFar},
+		{NewBundle(txs, 1), nil},
+		{NewBundle(txs, 1), ErrKnownBundle},
+		{NewBundle(txs[:1], 1), nil},
+		{NewBundle(txs, 2), nil},
+	}
+	for i, tt := range tests {
+		if err := pool.Add(tt.bundle); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	if count := pool.Stats(); count != 3 {
+		t.Fatalf("bundle count mismatch: have %d, want %d", count, 3)
+	}
+	if bundles := pool.Bundles(1); len(bundles) != 2 {
+		t.Fatalf("target bundle count mismatch: have %d, want %d", len(bundles), 2)
+	}
+}
+
+// Tests that bundles whose fee caps can't cover the base fee of the target block
+// are rejected, accounting for the base fee dropping until the target.
+func TestBundlePoolFeeCap(t *testing.T) {
+	t.Parallel()
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := *params.TestChainConfig
+	config.EIP1559Block = big.NewInt(1)
+
+	pool := NewBundlePool(&config, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), new(big.Int).Lsh(common.Big1, 64))
+
+	// The first EIP1559 block starts at the initial base fee, dropping by at
+	// most 1/8th per block afterwards
+	var (
+		initial = new(big.Int).SetUint64(params.InitialBaseFee)
+		later   = new(big.Int).Sub(initial, new(big.Int).Div(initial, new(big.Int).SetUint64(params.BaseFeeChangeDenominator)))
+	)
+	tests := []struct {
+		price  *big.Int
+		target uint64
+		err    error
+	}{
+		{new(big.Int).Sub(initial, common.Big1), 1, ErrFeeCapTooLow},
+		{initial, 1, nil},
+		{new(big.Int).Sub(later, common.Big1), 2, ErrFeeCapTooLow},
+		{later, 2, nil},
+	}
+	for i, tt := range tests {
+		bundle := NewBundle(types.Transactions{pricedTransaction(0, 100000, tt.price, key)}, tt.target)
+		if err := pool.Add(bundle); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that a full pool makes room for bundles by evicting the ones targeting
+// later blocks, but never the ones targeting the same or earlier blocks.
+func TestBundlePoolEviction(t *testing.T) {
+	t.Parallel()
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewBundlePool(params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), new(big.Int).Lsh(common.Big1, 64))
+
+	// Fill the pool up, spreading the bundles over consecutive target blocks
+	var last *Bundle
+	for i := 0; i < maxBundles; i++ {
+		target := uint64(2 + i/maxBundlesPerBlock)
+		last = NewBundle(types.Transactions{transaction(uint64(i), 100000, key)}, target)
+		if err := pool.Add(last); err != nil {
+			t.Fatalf("failed to add bundle %d: %v", i, err)
+		}
+	}
+	if count := pool.Stats(); count != maxBundles {
+		t.Fatalf("bundle count mismatch: have %d, want %d", count, maxBundles)
+	}
+	// Bundles targeting even further blocks can't evict anything
+	if err := pool.Add(NewBundle(types.Transactions{transaction(maxBundles, 100000, key)}, last.TargetBlock+1)); err != ErrBundlePoolFull {
+		t.Fatalf("furthest bundle error mismatch: have %v, want %v", err, ErrBundlePoolFull)
+	}
+	// Bundles targeting earlier blocks evict the furthest ones
+	if err := pool.Add(NewBundle(types.Transactions{transaction(maxBundles, 100000, key)}, 1)); err != nil {
+		t.Fatalf("failed to add evicting bundle: %v", err)
+	}
+	if count := pool.Stats(); count != maxBundles {
+		t.Fatalf("bundle count mismatch: have %d, want %d", count, maxBundles)
+	}
+	bundles := pool.Bundles(last.TargetBlock)
+	if len(bundles) != maxBundlesPerBlock-1 {
+		t.Fatalf("evicted target bundle count mismatch: have %d, want %d", len(bundles), maxBundlesPerBlock-1)
+	}
+	for _, bundle := range bundles {
+		if bundle.Hash() == last.Hash() {
+			t.Fatalf("most recent furthest bundle not evicted")
+		}
+	}
+}
+
+// Tests that bundles are dropped from the pool once their target block passes.
+func TestBundlePoolPruning(t *testing.T) {
+	t.Parallel()
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewBundlePool(params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+	for target := uint64(1); target <= 3; target++ {
+		if err := pool.Add(NewBundle(types.Transactions{transaction(0, 100000, key)}, target)); err != nil {
+			t.Fatalf("failed to add bundle for block %d: %v", target, err)
+		}
+	}
+	head := types.NewBlock(&types.Header{Number: big.NewInt(2)}, nil, nil, nil)
+	blockchain.chainHeadFeed.Send(ChainHeadEvent{Block: head})
+
+	for i := 0; i < 100 && pool.Stats() != 1; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if count := pool.Stats(); count != 1 {
+		t.Fatalf("bundle count mismatch: have %d, want %d", count, 1)
+	}
+	if bundles := pool.Bundles(3); len(bundles) != 1 {
+		t.Fatalf("surviving bundle count mismatch: have %d, want %d", len(bundles), 1)
+	}
+}
@@ -32,4 +32,12 @@ var (
 	// ErrNonceTooHigh is returned if the nonce of a transaction is higher than the
 	// next one expected based on the local chain.
 	ErrNonceTooHigh = errors.New("nonce too high")
+
+	// ErrFeeCapTooLow is returned if the fee cap of a transaction is below the
+	// base fee of the block it's included in.
+	ErrFeeCapTooLow = errors.New("max fee per gas less than block base fee")
+
+	// ErrTipAboveFeeCap is returned if the tip cap of a transaction is higher
+	// than its total fee cap.
+	ErrTipAboveFeeCap = errors.New("max priority fee per gas higher than max fee per gas")
 )
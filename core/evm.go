@@ -44,6 +44,10 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 	} else {
 		beneficiary = *author
 	}
+	var baseFee *big.Int
+	if header.BaseFee != nil {
+		baseFee = new(big.Int).Set(header.BaseFee)
+	}
 	return vm.Context{
 		CanTransfer: CanTransfer,
 		Transfer:    Transfer,
@@ -54,8 +58,23 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		Time:        new(big.Int).Set(header.Time),
 		Difficulty:  new(big.Int).Set(header.Difficulty),
 		GasLimit:    header.GasLimit,
-		GasPrice:    new(big.Int).Set(msg.GasPrice()),
+		GasPrice:    EffectiveGasPrice(msg, header.BaseFee),
+		BaseFee:     baseFee,
+	}
+}
+
+// EffectiveGasPrice returns the price per gas a message pays given the base fee
+// of the block: the base fee plus the tip, capped by the fee cap. A nil base fee
+// means the block predates EIP1559 and the plain gas price is paid.
+func EffectiveGasPrice(msg Message, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return new(big.Int).Set(msg.GasPrice())
+	}
+	price := new(big.Int).Add(baseFee, msg.GasTipCap())
+	if feeCap := msg.GasFeeCap(); price.Cmp(feeCap) > 0 {
+		price.Set(feeCap)
 	}
+	return price
 }
 
 // GetHashFn returns a GetHashFunc which retrieves header hashes by number
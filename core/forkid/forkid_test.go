@@ -0,0 +1,158 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package forkid
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+// The reference configuration of EIP-2124, the Ethereum mainnet up to Istanbul.
+var (
+	testGenesis = common.HexToHash("d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3")
+	testConfig  = &params.ChainConfig{
+		ChainId:             big.NewInt(1),
+		HomesteadBlock:      big.NewInt(1150000),
+		DAOForkBlock:        big.NewInt(1920000),
+		EIP150Block:         big.NewInt(2463000),
+		EIP155Block:         big.NewInt(2675000),
+		EIP158Block:         big.NewInt(2675000),
+		ByzantiumBlock:      big.NewInt(4370000),
+		ConstantinopleBlock: big.NewInt(7280000),
+		EIP1014Block:        big.NewInt(7280000),
+		IstanbulBlock:       big.NewInt(9069000),
+	}
+)
+
+// Tests that the fork ID is calculated correctly for the various fork states.
+func TestCreation(t *testing.T) {
+	tests := []struct {
+		head uint64
+		want ID
+	}{
+		{0, ID{Hash: checksumToBytes(0xfc64ec04), Next: 1150000}},       // Unsynced
+		{1149999, ID{Hash: checksumToBytes(0xfc64ec04), Next: 1150000}}, // Last Frontier block
+		{1150000, ID{Hash: checksumToBytes(0x97c2c34c), Next: 1920000}}, // First Homestead block
+		{1919999, ID{Hash: checksumToBytes(0x97c2c34c), Next: 1920000}}, // Last Homestead block
+		{1920000, ID{Hash: checksumToBytes(0x91d1f948), Next: 2463000}}, // First DAO block
+		{2463000, ID{Hash: checksumToBytes(0x7a64da13), Next: 2675000}}, // First Tangerine block
+		{2675000, ID{Hash: checksumToBytes(0x3edd5b10), Next: 4370000}}, // First Spurious block
+		{4370000, ID{Hash: checksumToBytes(0xa00bc324), Next: 7280000}}, // First Byzantium block
+		{7279999, ID{Hash: checksumToBytes(0xa00bc324), Next: 7280000}}, // Last Byzantium block
+		{7280000, ID{Hash: checksumToBytes(0x668db0af), Next: 9069000}}, // First Constantinople and Petersburg block
+		{9069000, ID{Hash: checksumToBytes(0x879d6e30), Next: 0}},       // First Istanbul block
+		{10000000, ID{Hash: checksumToBytes(0x879d6e30), Next: 0}},      // Future Istanbul block
+	}
+	for i, tt := range tests {
+		if have := newID(testConfig, testGenesis, tt.head); have != tt.want {
+			t.Errorf("test %d: fork ID mismatch: have %x, want %x", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that remote fork IDs are accepted or rejected depending on the local
+// fork state.
+func TestValidation(t *testing.T) {
+	tests := []struct {
+		head uint64
+		id   ID
+		err  error
+	}{
+		// Local is Petersburg, remote announces the same, with or without knowing
+		// about Istanbul. Connect.
+		{7987396, ID{Hash: checksumToBytes(0x668db0af), Next: 0}, nil},
+		{7987396, ID{Hash: checksumToBytes(0x668db0af), Next: 9069000}, nil},
+		{7987396, ID{Hash: checksumToBytes(0x668db0af), Next: math.MaxUint64}, nil},
+
+		// Local is Byzantium, remote announces the same, with or without knowing
+		// about Petersburg. Connect.
+		{7279999, ID{Hash: checksumToBytes(0xa00bc324), Next: 0}, nil},
+		{7279999, ID{Hash: checksumToBytes(0xa00bc324), Next: 7280000}, nil},
+
+		// Local is Petersburg, remote is still syncing through Byzantium or
+		// Spurious, announcing the forks we passed. Connect.
+		{7987396, ID{Hash: checksumToBytes(0xa00bc324), Next: 7280000}, nil},
+		{7987396, ID{Hash: checksumToBytes(0x3edd5b10), Next: 4370000}, nil},
+
+		// Local is Byzantium or Petersburg, remote already is ahead. Connect, as
+		// we are simply syncing.
+		{7279999, ID{Hash: checksumToBytes(0x668db0af), Next: 9069000}, nil},
+		{7987396, ID{Hash: checksumToBytes(0x879d6e30), Next: 0}, nil},
+
+		// Local is Petersburg, remote is Byzantium without knowing about
+		// Petersburg. Remote needs a software update.
+		{7987396, ID{Hash: checksumToBytes(0xa00bc324), Next: 0}, ErrRemoteStale},
+
+		// Local is Istanbul, remote is Petersburg but expects a fork at a block
+		// we already passed. Remote needs a software update.
+		{88888888, ID{Hash: checksumToBytes(0x668db0af), Next: 88888888}, ErrRemoteStale},
+
+		// Local is Petersburg, remote announces the same but with a fork that
+		// we already passed without knowing about it. Local is incompatible.
+		{7987396, ID{Hash: checksumToBytes(0x668db0af), Next: 7987396}, ErrLocalIncompatibleOrStale},
+
+		// Remote is on an unknown chain. Reject.
+		{7987396, ID{Hash: checksumToBytes(0x5cddc0e1), Next: 0}, ErrLocalIncompatibleOrStale},
+		{7279999, ID{Hash: checksumToBytes(0x5cddc0e1), Next: 0}, ErrLocalIncompatibleOrStale},
+	}
+	for i, tt := range tests {
+		filter := newFilter(testConfig, testGenesis, func() uint64 { return tt.head })
+		if err := filter(tt.id); err != tt.err {
+			t.Errorf("test %d: validation error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests that the static filter accepts nodes at any fork of the chain, but
+// rejects other chains.
+func TestStaticFilter(t *testing.T) {
+	filter := NewStaticFilter(testConfig, testGenesis)
+	for i, id := range []ID{
+		{Hash: checksumToBytes(0x3edd5b10), Next: 4370000},
+		{Hash: checksumToBytes(0xa00bc324), Next: 7280000},
+		{Hash: checksumToBytes(0x668db0af), Next: 9069000},
+		{Hash: checksumToBytes(0x879d6e30), Next: 0},
+	} {
+		if err := filter(id); err != nil {
+			t.Errorf("test %d: chain node rejected: %v", i, err)
+		}
+	}
+	if err := filter(ID{Hash: checksumToBytes(0x5cddc0e1), Next: 0}); err != ErrLocalIncompatibleOrStale {
+		t.Errorf("foreign node error mismatch: have %v, want %v", err, ErrLocalIncompatibleOrStale)
+	}
+}
+
+// Tests that fork blocks are gathered sorted and deduplicated, skipping the
+// ones activated at genesis.
+func TestGatherForks(t *testing.T) {
+	config := &params.ChainConfig{
+		HomesteadBlock:      big.NewInt(0),
+		EIP150Block:         big.NewInt(0),
+		ByzantiumBlock:      big.NewInt(30),
+		ConstantinopleBlock: big.NewInt(10),
+		EIP1014Block:        big.NewInt(10),
+		AdlnPrecompileBlock: big.NewInt(20),
+	}
+	if have, want := gatherForks(config), []uint64{10, 20, 30}; !reflect.DeepEqual(have, want) {
+		t.Errorf("fork list mismatch: have %v, want %v", have, want)
+	}
+}
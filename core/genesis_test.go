@@ -17,6 +17,7 @@
 package core
 
 import (
+	"errors"
 	"math/big"
 	"reflect"
 	"testing"
@@ -50,8 +51,10 @@ func TestSetupGenesis(t *testing.T) {
 			},
 		}
 		oldcustomg = customg
+		unorderedg = customg
 	)
 	oldcustomg.Config = &params.ChainConfig{HomesteadBlock: big.NewInt(2)}
+	unorderedg.Config = &params.ChainConfig{HomesteadBlock: big.NewInt(0), EIP150Block: big.NewInt(0), EIP155Block: big.NewInt(0), EIP158Block: big.NewInt(0), ByzantiumBlock: big.NewInt(0), IstanbulBlock: big.NewInt(0)}
 	tests := []struct {
 		name       string
 		fn         func(ethdb.Database) (*params.ChainConfig, common.Hash, error)
@@ -67,6 +70,14 @@ func TestSetupGenesis(t *testing.T) {
 			wantErr:    errGenesisNoConfig,
 			wantConfig: params.AllEthashProtocolChanges,
 		},
+		{
+			name: "genesis with unsupported fork ordering",
+			fn: func(db ethdb.Database) (*params.ChainConfig, common.Hash, error) {
+				return SetupGenesisBlock(db, &unorderedg)
+			},
+			wantErr:    errors.New("unsupported fork ordering: constantinopleBlock not enabled, but istanbulBlock enabled at 0"),
+			wantConfig: unorderedg.Config,
+		},
 		{
 			name: "no block in DB, genesis == nil",
 			fn: func(db ethdb.Database) (*params.ChainConfig, common.Hash, error) {
@@ -161,3 +172,23 @@ func TestSetupGenesis(t *testing.T) {
 		}
 	}
 }
+
+// Tests that ethash developer chains keep the given rules, but never the chain id
+// of the network the rules were taken from.
+func TestDeveloperEthashGenesisBlock(t *testing.T) {
+	mainnet := new(big.Int).Set(params.MainnetChainConfig.ChainId)
+
+	genesis := DeveloperEthashGenesisBlock(params.MainnetChainConfig, common.Address{1})
+	if genesis.Config.ChainId.Cmp(params.MainnetChainConfig.ChainId) == 0 {
+		t.Fatalf("developer chain reuses mainnet chain id %v", genesis.Config.ChainId)
+	}
+	if genesis.Config.ChainId.Cmp(big.NewInt(1337)) != 0 {
+		t.Errorf("chain id mismatch: have %v, want %v", genesis.Config.ChainId, 1337)
+	}
+	if genesis.Config.ByzantiumBlock != params.MainnetChainConfig.ByzantiumBlock || genesis.Config.Ethash == nil {
+		t.Errorf("developer chain rules differ from the given ones: %v", genesis.Config)
+	}
+	if params.MainnetChainConfig.ChainId.Cmp(mainnet) != 0 {
+		t.Errorf("mainnet chain id modified: %v", params.MainnetChainConfig.ChainId)
+	}
+}
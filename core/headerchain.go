@@ -153,7 +153,12 @@ func (hc *HeaderChain) WriteHeader(header *types.Header) (status WriteStatus, er
 	// If the total difficulty is higher than our known, add it to the canonical chain
 	// Second clause in the if statement reduces the vulnerability to selfish mining.
 	// Please refer to http://www.cs.cornell.edu/~ie53/publications/btcProcFC.pdf
-	if externTd.Cmp(localTd) > 0 || (externTd.Cmp(localTd) == 0 && mrand.Float64() < 0.5) {
+	reorg := externTd.Cmp(localTd) > 0 || (externTd.Cmp(localTd) == 0 && mrand.Float64() < 0.5)
+	if reorg && header.ParentHash != hc.currentHeaderHash && !hc.extendsFinalized(hc, header) {
+		log.Warn("Rejecting reorg below the finalized header", "number", number, "hash", hash)
+		reorg = false
+	}
+	if reorg {
 		// Delete any canonical number assignments above the new head
 		for i := number + 1; ; i++ {
 			hash := GetCanonicalHash(hc.chainDb, i)
@@ -196,6 +201,29 @@ func (hc *HeaderChain) WriteHeader(header *types.Header) (status WriteStatus, er
 	return
 }
 
+// extendsFinalized reports whether a header descends from the finalized header
+// of the given chain, i.e. whether it may become the new head without reverting
+// finalized blocks. Engines without deterministic finality never finalize any.
+func (hc *HeaderChain) extendsFinalized(chain consensus.ChainReader, header *types.Header) bool {
+	engine, ok := hc.engine.(consensus.FinalityEngine)
+	if !ok {
+		return true
+	}
+	final := engine.FinalizedHeader(chain)
+	if final == nil {
+		return true
+	}
+	number := final.Number.Uint64()
+	for header != nil && header.Number.Uint64() > number {
+		// Reaching the canonical chain above the finalized header is enough
+		if GetCanonicalHash(hc.chainDb, header.Number.Uint64()) == header.Hash() {
+			return true
+		}
+		header = hc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	}
+	return header != nil && header.Hash() == final.Hash()
+}
+
 // WhCallback is a callback function for inserting individual headers.
 // A callback is used for two reasons: first, in a LightChain, status should be
 // processed and light chain events sent, while in a BlockChain this is not
@@ -0,0 +1,92 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/AdelineCoin/go-adln/common"
+)
+
+// accessList is the set of accounts and storage slots already accessed by the
+// current transaction, which are warm in EIP-2929 terms.
+type accessList map[common.Address]map[common.Hash]struct{}
+
+// newAccessList creates an empty access list.
+func newAccessList() accessList {
+	return make(accessList)
+}
+
+// ContainsAddress returns whether the address is in the access list.
+func (al accessList) ContainsAddress(address common.Address) bool {
+	_, ok := al[address]
+	return ok
+}
+
+// Contains returns whether the address and the storage slot of it are in the
+// access list.
+func (al accessList) Contains(address common.Address, slot common.Hash) (addressOk bool, slotOk bool) {
+	slots, ok := al[address]
+	if !ok {
+		return false, false
+	}
+	_, slotOk = slots[slot]
+	return true, slotOk
+}
+
+// AddAddress adds an address to the access list, returning whether it was newly
+// added.
+func (al accessList) AddAddress(address common.Address) bool {
+	if _, ok := al[address]; ok {
+		return false
+	}
+	al[address] = make(map[common.Hash]struct{})
+	return true
+}
+
+// AddSlot adds a storage slot of an address to the access list, returning whether
+// the address and the slot were newly added.
+func (al accessList) AddSlot(address common.Address, slot common.Hash) (addrChange bool, slotChange bool) {
+	addrChange = al.AddAddress(address)
+	if _, ok := al[address][slot]; ok {
+		return addrChange, false
+	}
+	al[address][slot] = struct{}{}
+	return addrChange, true
+}
+
+// DeleteAddress removes an address from the access list. It's only ever used to
+// revert an addition, so the address is not expected to have any slots left.
+func (al accessList) DeleteAddress(address common.Address) {
+	delete(al, address)
+}
+
+// DeleteSlot removes a storage slot of an address from the access list. It's
+// only ever used to revert an addition.
+func (al accessList) DeleteSlot(address common.Address, slot common.Hash) {
+	delete(al[address], slot)
+}
+
+// Copy creates an independent copy of the access list.
+func (al accessList) Copy() accessList {
+	cpy := make(accessList, len(al))
+	for address, slots := range al {
+		cpy[address] = make(map[common.Hash]struct{}, len(slots))
+		for slot := range slots {
+			cpy[address][slot] = struct{}{}
+		}
+	}
+	return cpy
+}
@@ -74,6 +74,9 @@ type StateDB struct {
 
 	preimages map[common.Hash][]byte
 
+	// Accounts and storage slots already accessed by the current transaction
+	accessList accessList
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        journal
@@ -96,6 +99,7 @@ func New(root common.Hash, db Database) (*StateDB, error) {
 		stateObjectsDirty: make(map[common.Address]struct{}),
 		logs:              make(map[common.Hash][]*types.Log),
 		preimages:         make(map[common.Hash][]byte),
+		accessList:        newAccessList(),
 	}, nil
 }
 
@@ -126,6 +130,7 @@ func (self *StateDB) Reset(root common.Hash) error {
 	self.logs = make(map[common.Hash][]*types.Log)
 	self.logSize = 0
 	self.preimages = make(map[common.Hash][]byte)
+	self.accessList = newAccessList()
 	self.clearJournalAndRefund()
 	return nil
 }
@@ -173,6 +178,16 @@ func (self *StateDB) AddRefund(gas uint64) {
 	self.refund += gas
 }
 
+// SubRefund removes gas from the refund counter.
+// This method will panic if the refund counter goes below zero
+func (self *StateDB) SubRefund(gas uint64) {
+	self.journal = append(self.journal, refundChange{prev: self.refund})
+	if gas > self.refund {
+		panic("Refund counter below zero")
+	}
+	self.refund -= gas
+}
+
 // Exist reports whether the given account address exists in the state.
 // Notably this also returns true for suicided accounts.
 func (self *StateDB) Exist(addr common.Address) bool {
@@ -243,6 +258,16 @@ func (self *StateDB) GetState(a common.Address, b common.Hash) common.Hash {
 	return common.Hash{}
 }
 
+// GetCommittedState retrieves a value from the given account's committed
+// storage trie, as it was before the current transaction.
+func (self *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
+	stateObject := self.getStateObject(addr)
+	if stateObject != nil {
+		return stateObject.GetCommittedState(self.db, hash)
+	}
+	return common.Hash{}
+}
+
 // Database retrieves the low level database supporting the lower level trie ops.
 func (self *StateDB) Database() Database {
 	return self.db
@@ -472,6 +497,7 @@ func (self *StateDB) Copy() *StateDB {
 		logs:              make(map[common.Hash][]*types.Log, len(self.logs)),
 		logSize:           self.logSize,
 		preimages:         make(map[common.Hash][]byte),
+		accessList:        self.accessList.Copy(),
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range self.stateObjectsDirty {
@@ -554,6 +580,62 @@ func (self *StateDB) Prepare(thash, bhash common.Hash, ti int) {
 	self.txIndex = ti
 }
 
+// PrepareAccessList resets the access list for the execution of a transaction
+// and warms up the accounts and storage slots accessed regardless of the code
+// run (EIP-2929): the sender, the recipient, the precompiled contracts and the
+// entries of the transaction's own access list (EIP-2930).
+func (self *StateDB) PrepareAccessList(sender common.Address, dst *common.Address, precompiles []common.Address, list types.AccessList) {
+	self.accessList = newAccessList()
+
+	self.accessList.AddAddress(sender)
+	if dst != nil {
+		self.accessList.AddAddress(*dst)
+	}
+	for _, addr := range precompiles {
+		self.accessList.AddAddress(addr)
+	}
+	for _, tuple := range list {
+		self.accessList.AddAddress(tuple.Address)
+		for _, key := range tuple.StorageKeys {
+			self.accessList.AddSlot(tuple.Address, key)
+		}
+	}
+}
+
+// AddAddressToAccessList adds the given address to the access list.
+func (self *StateDB) AddAddressToAccessList(addr common.Address) {
+	if self.accessList.AddAddress(addr) {
+		self.journal = append(self.journal, accessListAddAccountChange{&addr})
+	}
+}
+
+// AddSlotToAccessList adds the given storage slot of an address to the access
+// list, adding the address too if it's not yet present.
+func (self *StateDB) AddSlotToAccessList(addr common.Address, slot common.Hash) {
+	addrMod, slotMod := self.accessList.AddSlot(addr, slot)
+	if addrMod {
+		// In practice, this should not happen, since there is no way to enter the
+		// scope of 'address' without having the 'address' become already added
+		// to the access list (via call-variant, create, etc). Better safe than
+		// sorry, though.
+		self.journal = append(self.journal, accessListAddAccountChange{&addr})
+	}
+	if slotMod {
+		self.journal = append(self.journal, accessListAddSlotChange{address: &addr, slot: &slot})
+	}
+}
+
+// AddressInAccessList returns whether the given address is in the access list.
+func (self *StateDB) AddressInAccessList(addr common.Address) bool {
+	return self.accessList.ContainsAddress(addr)
+}
+
+// SlotInAccessList returns whether the given address and storage slot of it are
+// in the access list.
+func (self *StateDB) SlotInAccessList(addr common.Address, slot common.Hash) (addressOk bool, slotOk bool) {
+	return self.accessList.Contains(addr, slot)
+}
+
 // DeleteSuicides flags the suicided objects for deletion so that it
 // won't be referenced again when called / queried up on.
 //
@@ -421,3 +421,46 @@ func (s *StateSuite) TestTouchDelete(c *check.C) {
 		c.Fatal("expected no dirty state object")
 	}
 }
+
+// Tests that the access list is prepared from the transaction and that entries
+// added during execution are dropped again when reverting.
+func TestAccessList(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+
+	var (
+		sender = common.HexToAddress("0x01")
+		dest   = common.HexToAddress("0x02")
+		listed = common.HexToAddress("0x03")
+		other  = common.HexToAddress("0x04")
+		slot   = common.HexToHash("0x01")
+	)
+	state.PrepareAccessList(sender, &dest, nil, types.AccessList{{Address: listed, StorageKeys: []common.Hash{slot}}})
+
+	for _, addr := range []common.Address{sender, dest, listed} {
+		if !state.AddressInAccessList(addr) {
+			t.Errorf("address %x missing from prepared access list", addr)
+		}
+	}
+	if _, slotOk := state.SlotInAccessList(listed, slot); !slotOk {
+		t.Errorf("slot missing from prepared access list")
+	}
+	// Add entries in a snapshot and ensure they're gone after reverting it
+	snap := state.Snapshot()
+	state.AddSlotToAccessList(other, slot)
+	if addrOk, slotOk := state.SlotInAccessList(other, slot); !addrOk || !slotOk {
+		t.Errorf("added slot missing: address %v, slot %v", addrOk, slotOk)
+	}
+	state.RevertToSnapshot(snap)
+	if state.AddressInAccessList(other) {
+		t.Errorf("reverted address still in access list")
+	}
+	if !state.AddressInAccessList(listed) {
+		t.Errorf("prepared address dropped by revert")
+	}
+	// Ensure a new transaction starts with a fresh list
+	state.PrepareAccessList(other, nil, nil, nil)
+	if state.AddressInAccessList(listed) {
+		t.Errorf("access list of previous transaction retained")
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 
 	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/core/vm"
 	"github.com/AdelineCoin/go-adln/log"
 	"github.com/AdelineCoin/go-adln/params"
@@ -67,7 +68,10 @@ type Message interface {
 	To() *common.Address
 
 	GasPrice() *big.Int
+	GasFeeCap() *big.Int
+	GasTipCap() *big.Int
 	Gas() uint64
+	AccessList() types.AccessList
 	Value() *big.Int
 
 	Nonce() uint64
@@ -75,8 +79,9 @@ type Message interface {
 	Data() []byte
 }
 
-// IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, contractCreation, homestead bool) (uint64, error) {
+// IntrinsicGas computes the 'intrinsic gas' for a message with the given data
+// and access list.
+func IntrinsicGas(data []byte, accessList types.AccessList, contractCreation, homestead bool) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if contractCreation && homestead {
@@ -105,6 +110,10 @@ func IntrinsicGas(data []byte, contractCreation, homestead bool) (uint64, error)
 		}
 		gas += z * params.TxDataZeroGas
 	}
+	if accessList != nil {
+		gas += uint64(len(accessList)) * params.TxAccessListAddressGas
+		gas += uint64(accessList.StorageKeys()) * params.TxAccessListStorageKeyGas
+	}
 	return gas, nil
 }
 
@@ -114,7 +123,7 @@ func NewStateTransition(evm *vm.EVM, msg Message, gp *GasPool) *StateTransition
 		gp:       gp,
 		evm:      evm,
 		msg:      msg,
-		gasPrice: msg.GasPrice(),
+		gasPrice: new(big.Int).Set(evm.GasPrice),
 		value:    msg.Value(),
 		data:     msg.Data(),
 		state:    evm.StateDB,
@@ -171,7 +180,13 @@ func (st *StateTransition) buyGas() error {
 		sender = st.from()
 	)
 	mgval := new(big.Int).Mul(new(big.Int).SetUint64(st.msg.Gas()), st.gasPrice)
-	if state.GetBalance(sender.Address()).Cmp(mgval) < 0 {
+
+	// After EIP1559 the sender must afford the fee cap, not just the price paid
+	balance := mgval
+	if st.evm.BaseFee != nil {
+		balance = new(big.Int).Mul(new(big.Int).SetUint64(st.msg.Gas()), st.msg.GasFeeCap())
+	}
+	if state.GetBalance(sender.Address()).Cmp(balance) < 0 {
 		return errInsufficientBalanceForGas
 	}
 	if err := st.gp.SubGas(st.msg.Gas()); err != nil {
@@ -196,6 +211,16 @@ func (st *StateTransition) preCheck() error {
 		} else if nonce > msg.Nonce() {
 			return ErrNonceTooLow
 		}
+		// Make sure the fee caps cover the base fee after EIP1559. Calls
+		// without nonce checks are exempt, as they are not real transactions.
+		if baseFee := st.evm.BaseFee; baseFee != nil {
+			if msg.GasTipCap().Cmp(msg.GasFeeCap()) > 0 {
+				return ErrTipAboveFeeCap
+			}
+			if msg.GasFeeCap().Cmp(baseFee) < 0 {
+				return ErrFeeCapTooLow
+			}
+		}
 	}
 	return st.buyGas()
 }
@@ -214,13 +239,18 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 	contractCreation := msg.To() == nil
 
 	// Pay intrinsic gas
-	gas, err := IntrinsicGas(st.data, contractCreation, homestead)
+	gas, err := IntrinsicGas(st.data, msg.AccessList(), contractCreation, homestead)
 	if err != nil {
 		return nil, 0, false, err
 	}
 	if err = st.useGas(gas); err != nil {
 		return nil, 0, false, err
 	}
+	// Warm up the accounts and slots accessed regardless of the code executed
+	if st.evm.ChainConfig().IsEIP2718(st.evm.BlockNumber) {
+		precompiles := vm.ActivePrecompileAddresses(st.evm.ChainConfig(), st.evm.BlockNumber)
+		st.state.PrepareAccessList(sender.Address(), msg.To(), precompiles, msg.AccessList())
+	}
 
 	var (
 		evm = st.evm
@@ -246,11 +276,34 @@ func (st *StateTransition) TransitionDb() (ret []byte, usedGas uint64, failed bo
 		}
 	}
 	st.refundGas()
-	st.state.AddBalance(st.evm.Coinbase, new(big.Int).Mul(new(big.Int).SetUint64(st.gasUsed()), st.gasPrice))
+	st.payFees()
 
 	return ret, st.gasUsed(), vmerr != nil, err
 }
 
+// payFees credits the miner with the fees of the used gas. After EIP1559 the
+// miner only receives the tip, while the base fee is either burned or credited
+// to the fee collector of the chain.
+func (st *StateTransition) payFees() {
+	gasUsed := new(big.Int).SetUint64(st.gasUsed())
+
+	baseFee := st.evm.BaseFee
+	if baseFee == nil {
+		st.state.AddBalance(st.evm.Coinbase, new(big.Int).Mul(gasUsed, st.gasPrice))
+		return
+	}
+	if tip := new(big.Int).Sub(st.gasPrice, baseFee); tip.Sign() > 0 {
+		st.state.AddBalance(st.evm.Coinbase, tip.Mul(tip, gasUsed))
+	}
+	if collector := st.evm.ChainConfig().FeeCollector; collector != nil {
+		burnt := baseFee
+		if st.gasPrice.Cmp(baseFee) < 0 {
+			burnt = st.gasPrice
+		}
+		st.state.AddBalance(*collector, new(big.Int).Mul(burnt, gasUsed))
+	}
+}
+
 func (st *StateTransition) refundGas() {
 	// Apply refund counter, capped to half of the used gas.
 	refund := st.gasUsed() / 2
@@ -245,18 +245,22 @@ func (l *txList) Overlaps(tx *types.Transaction) bool {
 
 // Add tries to insert a new transaction into the list, returning whether the
 // transaction was accepted, and if yes, any previous transaction it replaced.
+// Replacements need to bump the tip effectively paid at the given base fee, which
+// is the whole gas price before EIP-1559 (nil base fee).
 //
 // If the new transaction is accepted into the list, the lists' cost and gas
 // thresholds are also potentially updated.
-func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Transaction) {
+func (l *txList) Add(tx *types.Transaction, priceBump uint64, baseFee *big.Int) (bool, *types.Transaction) {
 	// If there's an older better transaction, abort
 	old := l.txs.Get(tx.Nonce())
 	if old != nil {
-		threshold := new(big.Int).Div(new(big.Int).Mul(old.GasPrice(), big.NewInt(100+int64(priceBump))), big.NewInt(100))
-		// Have to ensure that the new gas price is higher than the old gas
-		// price as well as checking the percentage threshold to ensure that
-		// this is accurate for low (Wei-level) gas price replacements
-		if old.GasPrice().Cmp(tx.GasPrice()) >= 0 || threshold.Cmp(tx.GasPrice()) > 0 {
+		oldTip, newTip := old.EffectiveGasTip(baseFee), tx.EffectiveGasTip(baseFee)
+
+		threshold := new(big.Int).Div(new(big.Int).Mul(oldTip, big.NewInt(100+int64(priceBump))), big.NewInt(100))
+		// Have to ensure that the new tip is higher than the old tip as well as
+		// checking the percentage threshold to ensure that this is accurate for
+		// low (Wei-level) gas price replacements
+		if oldTip.Cmp(newTip) >= 0 || threshold.Cmp(newTip) > 0 {
 			return false, nil
 		}
 	}
@@ -364,22 +368,36 @@ func (l *txList) Flatten() types.Transactions {
 }
 
 // priceHeap is a heap.Interface implementation over transactions for retrieving
-// price-sorted transactions to discard when the pool fills up.
-type priceHeap []*types.Transaction
+// price-sorted transactions to discard when the pool fills up. Transactions are
+// ordered by the tip they effectively pay at the base fee, falling back to the
+// fee and tip caps on equality.
+type priceHeap struct {
+	baseFee *big.Int // Base fee of the next block, nil before EIP-1559
+	list    []*types.Transaction
+}
 
-func (h priceHeap) Len() int           { return len(h) }
-func (h priceHeap) Less(i, j int) bool { return h[i].GasPrice().Cmp(h[j].GasPrice()) < 0 }
-func (h priceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *priceHeap) Len() int      { return len(h.list) }
+func (h *priceHeap) Swap(i, j int) { h.list[i], h.list[j] = h.list[j], h.list[i] }
+
+func (h *priceHeap) Less(i, j int) bool {
+	if c := h.list[i].EffectiveGasTip(h.baseFee).Cmp(h.list[j].EffectiveGasTip(h.baseFee)); c != 0 {
+		return c < 0
+	}
+	if c := h.list[i].GasFeeCap().Cmp(h.list[j].GasFeeCap()); c != 0 {
+		return c < 0
+	}
+	return h.list[i].GasTipCap().Cmp(h.list[j].GasTipCap()) < 0
+}
 
 func (h *priceHeap) Push(x interface{}) {
-	*h = append(*h, x.(*types.Transaction))
+	h.list = append(h.list, x.(*types.Transaction))
 }
 
 func (h *priceHeap) Pop() interface{} {
-	old := *h
+	old := h.list
 	n := len(old)
 	x := old[n-1]
-	*h = old[0 : n-1]
+	h.list = old[0 : n-1]
 	return x
 }
 
@@ -410,39 +428,52 @@ func (l *txPricedList) Put(tx *types.Transaction) {
 func (l *txPricedList) Removed() {
 	// Bump the stale counter, but exit if still too low (< 25%)
 	l.stales++
-	if l.stales <= len(*l.items)/4 {
+	if l.stales <= l.items.Len()/4 {
 		return
 	}
 	// Seems we've reached a critical number of stale transactions, reheap
-	reheap := make(priceHeap, 0, len(*l.all))
+	l.reheap(l.items.baseFee)
+}
+
+// SetBaseFee updates the base fee the transactions are ordered at, reheaping all
+// of them as their effective tips changed.
+func (l *txPricedList) SetBaseFee(baseFee *big.Int) {
+	l.reheap(baseFee)
+}
 
-	l.stales, l.items = 0, &reheap
+// reheap drops all stale transactions and rebuilds the heap at the given base fee.
+func (l *txPricedList) reheap(baseFee *big.Int) {
+	reheap := &priceHeap{baseFee: baseFee, list: make([]*types.Transaction, 0, len(*l.all))}
+
+	l.stales, l.items = 0, reheap
 	for _, tx := range *l.all {
-		*l.items = append(*l.items, tx)
+		l.items.list = append(l.items.list, tx)
 	}
 	heap.Init(l.items)
 }
 
-// Cap finds all the transactions below the given price threshold, drops them
-// from the priced list and returs them for further removal from the entire pool.
+// Cap finds all the transactions with a tip cap below the given price threshold,
+// drops them from the priced list and returs them for further removal from the
+// entire pool.
 func (l *txPricedList) Cap(threshold *big.Int, local *accountSet) types.Transactions {
 	drop := make(types.Transactions, 0, 128) // Remote underpriced transactions to drop
 	save := make(types.Transactions, 0, 64)  // Local underpriced transactions to keep
 
-	for len(*l.items) > 0 {
+	for l.items.Len() > 0 {
 		// Discard stale transactions if found during cleanup
 		tx := heap.Pop(l.items).(*types.Transaction)
 		if _, ok := (*l.all)[tx.Hash()]; !ok {
 			l.stales--
 			continue
 		}
-		// Stop the discards if we've reached the threshold
-		if tx.GasPrice().Cmp(threshold) >= 0 {
+		// Stop the discards once the effective tips reach the threshold, as the
+		// tip caps are never lower than them
+		if tx.EffectiveGasTip(l.items.baseFee).Cmp(threshold) >= 0 {
 			save = append(save, tx)
 			break
 		}
-		// Non stale transaction found, discard unless local
-		if local.containsTx(tx) {
+		// Non stale transaction found, discard unless local or above the threshold
+		if local.containsTx(tx) || tx.GasTipCap().Cmp(threshold) >= 0 {
 			save = append(save, tx)
 		} else {
 			drop = append(drop, tx)
@@ -462,8 +493,8 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 		return false
 	}
 	// Discard stale price points if found at the heap start
-	for len(*l.items) > 0 {
-		head := []*types.Transaction(*l.items)[0]
+	for l.items.Len() > 0 {
+		head := l.items.list[0]
 		if _, ok := (*l.all)[head.Hash()]; !ok {
 			l.stales--
 			heap.Pop(l.items)
@@ -472,12 +503,12 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 		break
 	}
 	// Check if the transaction is underpriced or not
-	if len(*l.items) == 0 {
+	if l.items.Len() == 0 {
 		log.Error("Pricing query for empty pool") // This cannot happen, print to catch programming errors
 		return false
 	}
-	cheapest := []*types.Transaction(*l.items)[0]
-	return cheapest.GasPrice().Cmp(tx.GasPrice()) >= 0
+	cheapest := l.items.list[0]
+	return cheapest.EffectiveGasTip(l.items.baseFee).Cmp(tx.EffectiveGasTip(l.items.baseFee)) >= 0
 }
 
 // Discard finds a number of most underpriced transactions, removes them from the
@@ -486,7 +517,7 @@ func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions
 	drop := make(types.Transactions, 0, count) // Remote underpriced transactions to drop
 	save := make(types.Transactions, 0, 64)    // Local underpriced transactions to keep
 
-	for len(*l.items) > 0 && count > 0 {
+	for l.items.Len() > 0 && count > 0 {
 		// Discard stale transactions if found during cleanup
 		tx := heap.Pop(l.items).(*types.Transaction)
 		if _, ok := (*l.all)[tx.Hash()]; !ok {
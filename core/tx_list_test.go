@@ -17,9 +17,12 @@
 package core
 
 import (
+	"container/heap"
+	"math/big"
 	"math/rand"
 	"testing"
 
+	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/crypto"
 )
@@ -37,7 +40,7 @@ func TestStrictTxListAdd(t *testing.T) {
 	// Insert the transactions in a random order
 	list := newTxList(true)
 	for _, v := range rand.Perm(len(txs)) {
-		list.Add(txs[v], DefaultTxPoolConfig.PriceBump)
+		list.Add(txs[v], DefaultTxPoolConfig.PriceBump, nil)
 	}
 	// Verify internal state
 	if len(list.txs.items) != len(txs) {
@@ -49,3 +52,30 @@ func TestStrictTxListAdd(t *testing.T) {
 		}
 	}
 }
+
+// Tests that dynamic fee transactions are replaced and priced by the tip they
+// effectively pay at the base fee, not by their fee caps.
+func TestDynamicFeeTxListPricing(t *testing.T) {
+	dynamic := func(nonce uint64, tip, cap int64) *types.Transaction {
+		return types.NewDynamicFeeTransaction(big.NewInt(1), nonce, &common.Address{}, new(big.Int), 21000, big.NewInt(tip), big.NewInt(cap), nil, nil)
+	}
+	baseFee := big.NewInt(95)
+
+	// A higher tip cap not raising the effective tip is no valid replacement
+	list := newTxList(true)
+	list.Add(dynamic(0, 10, 100), DefaultTxPoolConfig.PriceBump, baseFee)
+
+	if inserted, _ := list.Add(dynamic(0, 50, 100), DefaultTxPoolConfig.PriceBump, baseFee); inserted {
+		t.Errorf("replacement without effective tip bump accepted")
+	}
+	if inserted, _ := list.Add(dynamic(0, 50, 120), DefaultTxPoolConfig.PriceBump, baseFee); !inserted {
+		t.Errorf("replacement with effective tip bump rejected")
+	}
+	// A high fee cap must not make a transaction paying a low tip expensive
+	cheap, dear := dynamic(0, 1, 1000), dynamic(0, 4, 100)
+	h := &priceHeap{baseFee: baseFee, list: []*types.Transaction{dear, cheap}}
+	heap.Init(h)
+	if first := heap.Pop(h).(*types.Transaction); first != cheap {
+		t.Errorf("cheapest transaction mismatch: have tip %v, want tip %v", first.GasTipCap(), cheap.GasTipCap())
+	}
+}
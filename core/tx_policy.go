@@ -0,0 +1,250 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/types"
+)
+
+var (
+	// ErrDeniedAccount is returned if a transaction is sent from or to an account
+	// on the deny list of the transaction pool.
+	ErrDeniedAccount = errors.New("account denied by pool policy")
+
+	// ErrDeniedSelector is returned if a transaction invokes a contract method
+	// whose selector is on the deny list of the transaction pool.
+	ErrDeniedSelector = errors.New("method denied by pool policy")
+
+	// ErrCreationUnderpriced is returned if a contract creation's gas price is
+	// below the minimum configured for contract creations.
+	ErrCreationUnderpriced = errors.New("contract creation underpriced")
+
+	// ErrSenderRateLimited is returned if a sender submitted more transactions
+	// in the current rate limiting period than allowed by the pool.
+	ErrSenderRateLimited = errors.New("sender rate limited")
+)
+
+// senderRatePeriod is the time window over which the per sender transaction rate
+// limit is enforced.
+const senderRatePeriod = time.Minute
+
+// TxPolicy is an admission rule consulted by the transaction pool for every new
+// transaction, after all the built-in validity and DOS protection checks passed.
+// Policies allow operators to restrict what enters the pool without having to
+// modify the pool itself.
+type TxPolicy interface {
+	// Name returns a short identifier of the policy, used for logging.
+	Name() string
+
+	// Validate checks whether a transaction sent by from is allowed to enter the
+	// pool, returning the reason of the rejection otherwise. The local flag is
+	// set if the transaction or its sender is tracked locally.
+	Validate(tx *types.Transaction, from common.Address, local bool) error
+}
+
+// TxQuotaPolicy is an admission policy charging a quota for the transactions it
+// lets into the pool. Quotas are only charged once a transaction was actually
+// inserted, so rejected transactions (e.g. underpriced or failed replacements)
+// don't count. Transactions re-injected after a reorg were already admitted once
+// and skip quota policies altogether.
+type TxQuotaPolicy interface {
+	TxPolicy
+
+	// Admitted charges the quota of a transaction that passed validation and was
+	// inserted into the pool.
+	Admitted(tx *types.Transaction, from common.Address, local bool)
+}
+
+// policies assembles the admission policies configured for the pool: first the
+// built-in ones enabled via the configuration fields, then any custom ones.
+func (config *TxPoolConfig) policies() []TxPolicy {
+	var policies []TxPolicy
+	if len(config.DenyList) > 0 {
+		policies = append(policies, NewDenyListPolicy(config.DenyList))
+	}
+	if len(config.DenySelectors) > 0 {
+		selectors := make([][]byte, len(config.DenySelectors))
+		for i, selector := range config.DenySelectors {
+			selectors[i] = selector
+		}
+		policies = append(policies, NewSelectorPolicy(selectors))
+	}
+	if config.CreationPriceLimit > 0 {
+		policies = append(policies, NewCreationPricePolicy(new(big.Int).SetUint64(config.CreationPriceLimit)))
+	}
+	if config.SenderRateLimit > 0 {
+		policies = append(policies, NewSenderRatePolicy(config.SenderRateLimit, senderRatePeriod))
+	}
+	return append(policies, config.Policies...)
+}
+
+// denyListPolicy rejects all transactions sent from or to a set of accounts.
+type denyListPolicy struct {
+	denied map[common.Address]struct{}
+}
+
+// NewDenyListPolicy creates an admission policy rejecting all transactions that
+// are sent from, or addressed to any of the given accounts.
+func NewDenyListPolicy(addrs []common.Address) TxPolicy {
+	policy := &denyListPolicy{denied: make(map[common.Address]struct{})}
+	for _, addr := range addrs {
+		policy.denied[addr] = struct{}{}
+	}
+	return policy
+}
+
+// Name implements TxPolicy, returning the identifier of the policy.
+func (p *denyListPolicy) Name() string { return "denylist" }
+
+// Validate implements TxPolicy, rejecting transactions touching a denied account.
+func (p *denyListPolicy) Validate(tx *types.Transaction, from common.Address, local bool) error {
+	if _, ok := p.denied[from]; ok {
+		return ErrDeniedAccount
+	}
+	if to := tx.To(); to != nil {
+		if _, ok := p.denied[*to]; ok {
+			return ErrDeniedAccount
+		}
+	}
+	return nil
+}
+
+// selectorPolicy rejects all contract calls invoking a set of method selectors.
+type selectorPolicy struct {
+	denied [][]byte
+}
+
+// NewSelectorPolicy creates an admission policy rejecting all transactions whose
+// call data starts with any of the given method selectors.
+func NewSelectorPolicy(selectors [][]byte) TxPolicy {
+	policy := &selectorPolicy{denied: make([][]byte, 0, len(selectors))}
+	for _, selector := range selectors {
+		if len(selector) > 0 {
+			policy.denied = append(policy.denied, common.CopyBytes(selector))
+		}
+	}
+	return policy
+}
+
+// Name implements TxPolicy, returning the identifier of the policy.
+func (p *selectorPolicy) Name() string { return "selector" }
+
+// Validate implements TxPolicy, rejecting calls to denied contract methods.
+func (p *selectorPolicy) Validate(tx *types.Transaction, from common.Address, local bool) error {
+	// Contract creations have no method to invoke, only filter calls
+	if tx.To() == nil {
+		return nil
+	}
+	data := tx.Data()
+	for _, selector := range p.denied {
+		if bytes.HasPrefix(data, selector) {
+			return ErrDeniedSelector
+		}
+	}
+	return nil
+}
+
+// creationPricePolicy enforces a minimum gas price on remote contract creations.
+type creationPricePolicy struct {
+	minPrice *big.Int
+}
+
+// NewCreationPricePolicy creates an admission policy rejecting all remote contract
+// creations with a gas price below the given minimum.
+func NewCreationPricePolicy(minPrice *big.Int) TxPolicy {
+	return &creationPricePolicy{minPrice: new(big.Int).Set(minPrice)}
+}
+
+// Name implements TxPolicy, returning the identifier of the policy.
+func (p *creationPricePolicy) Name() string { return "creationprice" }
+
+// Validate implements TxPolicy, rejecting underpriced remote contract creations.
+func (p *creationPricePolicy) Validate(tx *types.Transaction, from common.Address, local bool) error {
+	if !local && tx.To() == nil && tx.GasPrice().Cmp(p.minPrice) < 0 {
+		return ErrCreationUnderpriced
+	}
+	return nil
+}
+
+// senderRatePolicy limits the number of remote transactions accepted from any
+// single sender within a fixed time period.
+type senderRatePolicy struct {
+	limit  uint64        // Maximum number of transactions allowed per period
+	period time.Duration // Length of a rate limiting period
+
+	start  time.Time                 // Start of the current rate limiting period
+	counts map[common.Address]uint64 // Number of transactions seen in the current period
+	lock   sync.Mutex                // Protects the period and the counters
+}
+
+// NewSenderRatePolicy creates an admission policy rejecting remote transactions
+// from senders who already submitted limit transactions in the current period.
+func NewSenderRatePolicy(limit uint64, period time.Duration) TxPolicy {
+	return &senderRatePolicy{
+		limit:  limit,
+		period: period,
+		start:  time.Now(),
+		counts: make(map[common.Address]uint64),
+	}
+}
+
+// Name implements TxPolicy, returning the identifier of the policy.
+func (p *senderRatePolicy) Name() string { return "senderrate" }
+
+// Validate implements TxPolicy, rejecting remote transactions above the rate limit.
+func (p *senderRatePolicy) Validate(tx *types.Transaction, from common.Address, local bool) error {
+	if local {
+		return nil
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.rotate()
+	if p.counts[from] >= p.limit {
+		return ErrSenderRateLimited
+	}
+	return nil
+}
+
+// Admitted implements TxQuotaPolicy, counting a remote transaction that entered
+// the pool against the rate limit of its sender.
+func (p *senderRatePolicy) Admitted(tx *types.Transaction, from common.Address, local bool) {
+	if local {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.rotate()
+	p.counts[from]++
+}
+
+// rotate starts a new period with clean counters if the current one elapsed.
+//
+// Note, this method assumes the policy lock is held!
+func (p *senderRatePolicy) rotate() {
+	if now := time.Now(); now.Sub(p.start) >= p.period {
+		p.start, p.counts = now, make(map[common.Address]uint64)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/common/hexutil"
+	"github.com/AdelineCoin/go-adln/core/state"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/event"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+// errTestPolicy is the rejection reason of the custom tester policy.
+var errTestPolicy = errors.New("rejected by tester")
+
+// testPolicy is a custom admission policy rejecting transactions of a given nonce.
+type testPolicy struct {
+	nonce uint64
+}
+
+func (p *testPolicy) Name() string { return "tester" }
+
+func (p *testPolicy) Validate(tx *types.Transaction, from common.Address, local bool) error {
+	if tx.Nonce() == p.nonce {
+		return errTestPolicy
+	}
+	return nil
+}
+
+// Tests that the built-in admission policies enabled via the pool configuration,
+// as well as custom ones are all enforced when adding transactions.
+func TestTransactionPolicies(t *testing.T) {
+	t.Parallel()
+
+	// Create the accounts to check the various policies with
+	var (
+		key, _    = crypto.GenerateKey()
+		denied, _ = crypto.GenerateKey()
+		contract  = common.HexToAddress("0xdeadbeef")
+		selector  = []byte{0x12, 0x34, 0x56, 0x78}
+	)
+	config := testTxPoolConfig
+	config.DenyList = []common.Address{crypto.PubkeyToAddress(denied.PublicKey), contract}
+	config.DenySelectors = []hexutil.Bytes{selector}
+	config.CreationPriceLimit = 10
+	config.SenderRateLimit = 4
+	config.Policies = []TxPolicy{&testPolicy{nonce: 2}}
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(denied.PublicKey), big.NewInt(1000000000))
+
+	sign := func(tx *types.Transaction, key *ecdsa.PrivateKey) *types.Transaction {
+		signed, _ := types.SignTx(tx, types.HomesteadSigner{}, key)
+		return signed
+	}
+	tests := []struct {
+		tx  *types.Transaction
+		err error
+	}{
+		{transaction(0, 100000, denied), ErrDeniedAccount},
+		{sign(types.NewTransaction(0, contract, big.NewInt(0), 100000, big.NewInt(1), nil), key), ErrDeniedAccount},
+		{sign(types.NewTransaction(0, common.Address{1}, big.NewInt(0), 100000, big.NewInt(1), append(selector, 0x01)), key), ErrDeniedSelector},
+		{sign(types.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(1), selector), key), ErrCreationUnderpriced},
+		{sign(types.NewContractCreation(0, big.NewInt(0), 100000, big.NewInt(10), selector), key), nil},
+		{transaction(1, 100000, key), nil},
+		{transaction(2, 100000, key), errTestPolicy},
+		{transaction(3, 100000, key), nil},
+		{transaction(4, 100000, key), nil},
+		{transaction(5, 100000, key), ErrSenderRateLimited},
+	}
+	for i, tt := range tests {
+		if err := pool.AddRemote(tt.tx); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+	// Ensure local transactions are exempt from the rate limit
+	if err := pool.AddLocal(transaction(5, 100000, key)); err != nil {
+		t.Errorf("local transaction rate limited: %v", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the sender rate limiting policy resets its counters when a new rate
+// limiting period starts.
+func TestSenderRatePolicyPeriods(t *testing.T) {
+	t.Parallel()
+
+	policy := NewSenderRatePolicy(1, 50*time.Millisecond)
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	if err := policy.Validate(transaction(0, 100000, key), from, false); err != nil {
+		t.Fatalf("first transaction rejected: %v", err)
+	}
+	policy.(TxQuotaPolicy).Admitted(transaction(0, 100000, key), from, false)
+
+	if err := policy.Validate(transaction(1, 100000, key), from, false); err != ErrSenderRateLimited {
+		t.Fatalf("second transaction error mismatch: have %v, want %v", err, ErrSenderRateLimited)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := policy.Validate(transaction(1, 100000, key), from, false); err != nil {
+		t.Fatalf("transaction rejected in new period: %v", err)
+	}
+}
+
+// Tests that only transactions actually entering the pool are charged against
+// the sender rate limit, not ones rejected after the policies were consulted.
+func TestSenderRatePolicyRejectedReplacement(t *testing.T) {
+	t.Parallel()
+
+	config := testTxPoolConfig
+	config.SenderRateLimit = 2
+
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(2), key)); err != nil {
+		t.Fatalf("failed to add original transaction: %v", err)
+	}
+	// Replace it without the required price bump, which must not consume quota
+	for i := 0; i < 3; i++ {
+		if err := pool.AddRemote(pricedTransaction(0, 100001, big.NewInt(2), key)); err != ErrReplaceUnderpriced {
+			t.Fatalf("replacement %d: error mismatch: have %v, want %v", i, err, ErrReplaceUnderpriced)
+		}
+	}
+	if err := pool.AddRemote(pricedTransaction(1, 100000, big.NewInt(2), key)); err != nil {
+		t.Fatalf("transaction rate limited by rejected replacements: %v", err)
+	}
+	if err := pool.AddRemote(pricedTransaction(2, 100000, big.NewInt(2), key)); err != ErrSenderRateLimited {
+		t.Fatalf("quota exceeding transaction error mismatch: have %v, want %v", err, ErrSenderRateLimited)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
@@ -26,6 +26,8 @@ import (
 	"time"
 
 	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/common/hexutil"
+	"github.com/AdelineCoin/go-adln/consensus/misc"
 	"github.com/AdelineCoin/go-adln/core/state"
 	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/event"
@@ -78,6 +80,10 @@ var (
 	// than some meaningful limit a user might use. This is not a consensus error
 	// making the transaction invalid, rather a DOS protection.
 	ErrOversizedData = errors.New("oversized data")
+
+	// ErrPrivateTxExpired is returned if a private transaction is submitted with
+	// an expiry block the local chain has already reached.
+	ErrPrivateTxExpired = errors.New("private transaction already expired")
 )
 
 var (
@@ -101,6 +107,10 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
+
+	// Metrics for private transactions
+	privateDropCounter    = metrics.NewRegisteredCounter("txpool/private/drop", nil)    // Dropped due to expiry
+	privateReleaseCounter = metrics.NewRegisteredCounter("txpool/private/release", nil) // Published due to expiry
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -138,6 +148,13 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	DenyList           []common.Address // Accounts whose sent or received transactions are rejected
+	DenySelectors      []hexutil.Bytes  // Contract method selectors whose invocations are rejected
+	CreationPriceLimit uint64           // Minimum gas price to enforce for remote contract creations
+	SenderRateLimit    uint64           // Maximum number of remote transactions accepted per sender per minute
+
+	Policies []TxPolicy `toml:"-"` // Custom admission policies to enforce after the built-in ones
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -185,6 +202,7 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 // two states over time as they are received and processed.
 type TxPool struct {
 	config       TxPoolConfig
+	policies     []TxPolicy
 	chainconfig  *params.ChainConfig
 	chain        blockChain
 	gasPrice     *big.Int
@@ -202,6 +220,8 @@ type TxPool struct {
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	private map[common.Hash]*privateTx // Transactions to keep away from the network
+
 	pending map[common.Address]*txList         // All currently processable transactions
 	queue   map[common.Address]*txList         // Queued but non-processable transactions
 	beats   map[common.Address]time.Time       // Last heartbeat from each known account
@@ -211,6 +231,9 @@ type TxPool struct {
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
+	eip2718   bool     // Whether the next block accepts access list transactions
+	eip1559   bool     // Whether the next block accepts dynamic fee transactions
+	baseFee   *big.Int // Base fee of the next block, nil before EIP-1559
 }
 
 // NewTxPool creates a new transaction pool to gather, sort and filter inbound
@@ -222,13 +245,15 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	// Create the transaction pool with its initial settings
 	pool := &TxPool{
 		config:      config,
+		policies:    config.policies(),
 		chainconfig: chainconfig,
 		chain:       chain,
-		signer:      types.NewEIP155Signer(chainconfig.ChainId),
+		signer:      types.LatestSigner(chainconfig),
 		pending:     make(map[common.Address]*txList),
 		queue:       make(map[common.Address]*txList),
 		beats:       make(map[common.Address]time.Time),
 		all:         make(map[common.Hash]*types.Transaction),
+		private:     make(map[common.Hash]*privateTx),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
 	}
@@ -410,10 +435,20 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
+	pool.eip2718 = pool.chainconfig.IsEIP2718(next) || pool.chainconfig.IsEIP1559(next)
+	pool.eip1559 = pool.chainconfig.IsEIP1559(next)
+
+	// Order the transactions by the tips they'd pay in the next block
+	pool.baseFee = nil
+	if pool.eip1559 {
+		pool.baseFee = misc.CalcBaseFee(pool.chainconfig, newHead)
+	}
+	pool.priced.SetBaseFee(pool.baseFee)
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
-	pool.addTxsLocked(reinject, false)
+	pool.addTxsLocked(reinject, false, true)
 
 	// validate the pool of pending transactions, this will remove
 	// any transactions that have been included in the block or
@@ -429,6 +464,9 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	// Check the queue and move transactions over to the pending if possible
 	// or remove those that have become invalid
 	pool.promoteExecutables(nil)
+
+	// Drop or publish any private transactions that outlived their expiry
+	pool.expirePrivate(newHead.Number.Uint64())
 }
 
 // Stop terminates the transaction pool.
@@ -538,22 +576,52 @@ func (pool *TxPool) Pending() (map[common.Address]types.Transactions, error) {
 // local retrieves all currently known local transactions, groupped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
+//
+// Private transactions are omitted, they must not survive a node restart.
 func (pool *TxPool) local() map[common.Address]types.Transactions {
 	txs := make(map[common.Address]types.Transactions)
 	for addr := range pool.locals.accounts {
 		if pending := pool.pending[addr]; pending != nil {
-			txs[addr] = append(txs[addr], pending.Flatten()...)
+			txs[addr] = append(txs[addr], pool.public(pending.Flatten())...)
 		}
 		if queued := pool.queue[addr]; queued != nil {
-			txs[addr] = append(txs[addr], queued.Flatten()...)
+			txs[addr] = append(txs[addr], pool.public(queued.Flatten())...)
 		}
 	}
 	return txs
 }
 
+// public filters out all the private transactions from the given list.
+func (pool *TxPool) public(txs types.Transactions) types.Transactions {
+	if len(pool.private) == 0 {
+		return txs
+	}
+	filtered := make(types.Transactions, 0, len(txs))
+	for _, tx := range txs {
+		if _, ok := pool.private[tx.Hash()]; !ok {
+			filtered = append(filtered, tx)
+		}
+	}
+	return filtered
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
-func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
+func (pool *TxPool) validateTx(tx *types.Transaction, local, reinject bool) error {
+	// Accept typed transactions only once their fork is active
+	switch tx.Type() {
+	case types.LegacyTxType:
+	case types.AccessListTxType:
+		if !pool.eip2718 {
+			return types.ErrTxTypeNotSupported
+		}
+	case types.DynamicFeeTxType:
+		if !pool.eip1559 {
+			return types.ErrTxTypeNotSupported
+		}
+	default:
+		return types.ErrTxTypeNotSupported
+	}
 	// Heuristic limit, reject transactions over 32KB to prevent DOS attacks
 	if tx.Size() > 32*1024 {
 		return ErrOversizedData
@@ -567,14 +635,18 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentMaxGas < tx.Gas() {
 		return ErrGasLimit
 	}
+	// Ensure the tip doesn't exceed the total fee cap
+	if tx.GasTipCap().Cmp(tx.GasFeeCap()) > 0 {
+		return ErrTipAboveFeeCap
+	}
 	// Make sure the transaction is signed properly
 	from, err := types.Sender(pool.signer, tx)
 	if err != nil {
 		return ErrInvalidSender
 	}
-	// Drop non-local transactions under our own minimal accepted gas price
+	// Drop non-local transactions under our own minimal accepted gas price or tip
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
-	if !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
+	if !local && pool.gasPrice.Cmp(tx.GasTipCap()) > 0 {
 		return ErrUnderpriced
 	}
 	// Ensure the transaction adheres to nonce ordering
@@ -586,13 +658,23 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
 		return ErrInsufficientFunds
 	}
-	intrGas, err := IntrinsicGas(tx.Data(), tx.To() == nil, pool.homestead)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, pool.homestead)
 	if err != nil {
 		return err
 	}
 	if tx.Gas() < intrGas {
 		return ErrIntrinsicGas
 	}
+	// Enforce any admission policies configured by the operator
+	for _, policy := range pool.policies {
+		if _, ok := policy.(TxQuotaPolicy); ok && reinject {
+			continue
+		}
+		if err := policy.Validate(tx, from, local); err != nil {
+			log.Trace("Transaction rejected by pool policy", "hash", tx.Hash(), "policy", policy.Name(), "err", err)
+			return err
+		}
+	}
 	return nil
 }
 
@@ -603,8 +685,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 //
 // If a newly added transaction is marked as local, its sending account will be
 // whitelisted, preventing any associated transaction from being dropped out of
-// the pool due to pricing constraints.
-func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
+// the pool due to pricing constraints. Transactions flagged as reinject are ones
+// dropped from the chain by a reorg, which are not charged to quota policies.
+func (pool *TxPool) add(tx *types.Transaction, local, reinject bool) (bool, error) {
 	// If the transaction is already known, discard it
 	hash := tx.Hash()
 	if pool.all[hash] != nil {
@@ -612,7 +695,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		return false, fmt.Errorf("known transaction: %x", hash)
 	}
 	// If the transaction fails basic validation, discard it
-	if err := pool.validateTx(tx, local); err != nil {
+	if err := pool.validateTx(tx, local, reinject); err != nil {
 		log.Trace("Discarding invalid transaction", "hash", hash, "err", err)
 		invalidTxCounter.Inc(1)
 		return false, err
@@ -637,7 +720,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
-		inserted, old := list.Add(tx, pool.config.PriceBump)
+		inserted, old := list.Add(tx, pool.config.PriceBump, pool.baseFee)
 		if !inserted {
 			pendingDiscardCounter.Inc(1)
 			return false, ErrReplaceUnderpriced
@@ -651,7 +734,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		pool.all[tx.Hash()] = tx
 		pool.priced.Put(tx)
 		pool.journalTx(from, tx)
-
+		if !reinject {
+			pool.admitted(tx, from, local)
+		}
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
 		// We've directly injected a replacement transaction, notify subsystems
@@ -669,11 +754,23 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		pool.locals.add(from)
 	}
 	pool.journalTx(from, tx)
-
+	if !reinject {
+		pool.admitted(tx, from, local)
+	}
 	log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replace, nil
 }
 
+// admitted notifies the quota policies of the pool that a transaction entered it.
+func (pool *TxPool) admitted(tx *types.Transaction, from common.Address, local bool) {
+	local = local || pool.locals.contains(from)
+	for _, policy := range pool.policies {
+		if quota, ok := policy.(TxQuotaPolicy); ok {
+			quota.Admitted(tx, from, local)
+		}
+	}
+}
+
 // enqueueTx inserts a new transaction into the non-executable transaction queue.
 //
 // Note, this method assumes the pool lock is held!
@@ -683,7 +780,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 	if pool.queue[from] == nil {
 		pool.queue[from] = newTxList(false)
 	}
-	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump)
+	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump, pool.baseFee)
 	if !inserted {
 		// An older transaction was better, discard this
 		queuedDiscardCounter.Inc(1)
@@ -707,6 +804,10 @@ func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
 	if pool.journal == nil || !pool.locals.contains(from) {
 		return
 	}
+	// Private transactions must not be resurrected as public ones on restart
+	if _, ok := pool.private[tx.Hash()]; ok {
+		return
+	}
 	if err := pool.journal.insert(tx); err != nil {
 		log.Warn("Failed to journal local transaction", "err", err)
 	}
@@ -722,7 +823,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	}
 	list := pool.pending[addr]
 
-	inserted, old := list.Add(tx, pool.config.PriceBump)
+	inserted, old := list.Add(tx, pool.config.PriceBump, pool.baseFee)
 	if !inserted {
 		// An older transaction was better, discard this
 		delete(pool.all, hash)
@@ -757,6 +858,76 @@ func (pool *TxPool) AddLocal(tx *types.Transaction) error {
 	return pool.addTx(tx, !pool.config.NoLocals)
 }
 
+// AddPrivate enqueues a single transaction into the pool the same way as AddLocal
+// does, but marks it private: the transaction is made available to the local
+// miner, but it is never announced to remote peers. Once the local chain reaches
+// the expiry block, the transaction is either dropped or, if release is set,
+// announced to the network as any other transaction.
+func (pool *TxPool) AddPrivate(tx *types.Transaction, expiry uint64, release bool) error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if head := pool.chain.CurrentBlock().NumberU64(); expiry <= head {
+		return ErrPrivateTxExpired
+	}
+	// Mark the transaction private before insertion to suppress any announcement
+	hash := tx.Hash()
+	if _, ok := pool.private[hash]; !ok && pool.all[hash] == nil {
+		pool.private[hash] = &privateTx{expiry: expiry, release: release}
+	}
+	replace, err := pool.add(tx, !pool.config.NoLocals, false)
+	if err != nil {
+		if pool.all[hash] == nil {
+			delete(pool.private, hash)
+		}
+		return err
+	}
+	if !replace {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		pool.promoteExecutables([]common.Address{from})
+	}
+	return nil
+}
+
+// IsPrivate reports whether the transaction with the given hash was submitted
+// privately and must thus not be propagated to the network.
+func (pool *TxPool) IsPrivate(hash common.Hash) bool {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	_, ok := pool.private[hash]
+	return ok
+}
+
+// expirePrivate forgets about private transactions that left the pool and drops
+// or publishes the ones whose expiry block was reached by the local chain.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) expirePrivate(number uint64) {
+	for hash, ptx := range pool.private {
+		tx := pool.all[hash]
+		if tx == nil {
+			delete(pool.private, hash) // included, replaced or evicted
+			continue
+		}
+		if number < ptx.expiry {
+			continue
+		}
+		delete(pool.private, hash)
+		if ptx.release {
+			log.Debug("Releasing expired private transaction", "hash", hash)
+			privateReleaseCounter.Inc(1)
+
+			go pool.txFeed.Send(TxPreEvent{tx})
+			continue
+		}
+		log.Debug("Dropping expired private transaction", "hash", hash)
+		privateDropCounter.Inc(1)
+
+		pool.removeTx(hash)
+	}
+}
+
 // AddRemote enqueues a single transaction into the pool if it is valid. If the
 // sender is not among the locally tracked ones, full pricing constraints will
 // apply.
@@ -784,7 +955,7 @@ func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 	defer pool.mu.Unlock()
 
 	// Try to inject the transaction and update any state
-	replace, err := pool.add(tx, local)
+	replace, err := pool.add(tx, local, false)
 	if err != nil {
 		return err
 	}
@@ -801,19 +972,19 @@ func (pool *TxPool) addTxs(txs []*types.Transaction, local bool) []error {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
-	return pool.addTxsLocked(txs, local)
+	return pool.addTxsLocked(txs, local, false)
 }
 
 // addTxsLocked attempts to queue a batch of transactions if they are valid,
 // whilst assuming the transaction pool lock is already held.
-func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local bool) []error {
+func (pool *TxPool) addTxsLocked(txs []*types.Transaction, local, reinject bool) []error {
 	// Add the batch of transaction, tracking the accepted ones
 	dirty := make(map[common.Address]struct{})
 	errs := make([]error, len(txs))
 
 	for i, tx := range txs {
 		var replace bool
-		if replace, errs[i] = pool.add(tx, local); errs[i] == nil {
+		if replace, errs[i] = pool.add(tx, local, reinject); errs[i] == nil {
 			if !replace {
 				from, _ := types.Sender(pool.signer, tx) // already validated
 				dirty[from] = struct{}{}
@@ -1114,6 +1285,13 @@ func (pool *TxPool) demoteUnexecutables() {
 	}
 }
 
+// privateTx is the bookkeeping of a transaction which must be kept away from the
+// network until its expiry block.
+type privateTx struct {
+	expiry  uint64 // Block number at which the transaction is dropped or released
+	release bool   // Whether to announce the transaction instead of dropping it
+}
+
 // addressByHeartbeat is an account address tagged with its last activity timestamp.
 type addressByHeartbeat struct {
 	address   common.Address
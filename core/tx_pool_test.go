@@ -52,6 +52,7 @@ type testBlockChain struct {
 
 func (bc *testBlockChain) CurrentBlock() *types.Block {
 	return types.NewBlock(&types.Header{
+		Number:   new(big.Int),
 		GasLimit: bc.gasLimit,
 	}, nil, nil, nil)
 }
@@ -347,14 +348,14 @@ func TestTransactionChainFork(t *testing.T) {
 	resetState()
 
 	tx := transaction(0, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, false); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	pool.removeTx(tx.Hash())
 
 	// reset the pool's internal state
 	resetState()
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, false); err != nil {
 		t.Error("didn't expect error", err)
 	}
 }
@@ -382,10 +383,10 @@ func TestTransactionDoubleNonce(t *testing.T) {
 	tx3, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 1000000, big.NewInt(1), nil), signer, key)
 
 	// Add the first two transaction, ensure higher priced stays only
-	if replace, err := pool.add(tx1, false); err != nil || replace {
+	if replace, err := pool.add(tx1, false, false); err != nil || replace {
 		t.Errorf("first transaction insert failed (%v) or reported replacement (%v)", err, replace)
 	}
-	if replace, err := pool.add(tx2, false); err != nil || !replace {
+	if replace, err := pool.add(tx2, false, false); err != nil || !replace {
 		t.Errorf("second transaction insert failed (%v) or not reported replacement (%v)", err, replace)
 	}
 	pool.promoteExecutables([]common.Address{addr})
@@ -396,7 +397,7 @@ func TestTransactionDoubleNonce(t *testing.T) {
 		t.Errorf("transaction mismatch: have %x, want %x", tx.Hash(), tx2.Hash())
 	}
 	// Add the third transaction and ensure it's not saved (smaller price)
-	pool.add(tx3, false)
+	pool.add(tx3, false, false)
 	pool.promoteExecutables([]common.Address{addr})
 	if pool.pending[addr].Len() != 1 {
 		t.Error("expected 1 pending transactions, got", pool.pending[addr].Len())
@@ -419,7 +420,7 @@ func TestTransactionMissingNonce(t *testing.T) {
 	addr := crypto.PubkeyToAddress(key.PublicKey)
 	pool.currentState.AddBalance(addr, big.NewInt(100000000000000))
 	tx := transaction(1, 100000, key)
-	if _, err := pool.add(tx, false); err != nil {
+	if _, err := pool.add(tx, false, false); err != nil {
 		t.Error("didn't expect error", err)
 	}
 	if len(pool.pending) != 0 {
@@ -1681,6 +1682,83 @@ func TestTransactionStatusCheck(t *testing.T) {
 	}
 }
 
+// Tests that private transactions are tracked until their expiry block, after
+// which they are either dropped or released to the network.
+func TestTransactionPrivateExpiry(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the private transaction lifecycle with
+	pool, _ := setupTxPool()
+	defer pool.Stop()
+
+	events := make(chan TxPreEvent, 32)
+	sub := pool.txFeed.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	dropped := transaction(0, 100000, keys[0])
+	released := transaction(0, 100000, keys[1])
+	public := transaction(0, 100000, keys[2])
+
+	if err := pool.AddPrivate(dropped, 0, false); err != ErrPrivateTxExpired {
+		t.Fatalf("expired private transaction error mismatch: have %v, want %v", err, ErrPrivateTxExpired)
+	}
+	if err := pool.AddPrivate(dropped, 2, false); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddPrivate(released, 2, true); err != nil {
+		t.Fatalf("failed to add private transaction: %v", err)
+	}
+	if err := pool.AddLocal(public); err != nil {
+		t.Fatalf("failed to add public transaction: %v", err)
+	}
+	if err := validateEvents(events, 3); err != nil {
+		t.Fatalf("original event firing failed: %v", err)
+	}
+	if !pool.IsPrivate(dropped.Hash()) || !pool.IsPrivate(released.Hash()) {
+		t.Fatalf("private transactions not tracked as private")
+	}
+	if pool.IsPrivate(public.Hash()) {
+		t.Fatalf("public transaction tracked as private")
+	}
+	journaled := 0
+	for _, txs := range pool.local() {
+		journaled += len(txs)
+	}
+	if journaled != 1 {
+		t.Fatalf("journalable transaction count mismatch: have %d, want %d", journaled, 1)
+	}
+	// Advance the chain before the expiry and ensure nothing changes
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(1), GasLimit: 1000000})
+	if pending, _ := pool.Stats(); pending != 3 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 3)
+	}
+	if err := validateEvents(events, 0); err != nil {
+		t.Fatalf("pre-expiry event firing failed: %v", err)
+	}
+	// Reach the expiry block and ensure private transactions are handled
+	pool.lockedReset(nil, &types.Header{Number: big.NewInt(2), GasLimit: 1000000})
+	if pending, _ := pool.Stats(); pending != 2 {
+		t.Fatalf("pending transactions mismatched: have %d, want %d", pending, 2)
+	}
+	if pool.Get(dropped.Hash()) != nil {
+		t.Fatalf("expired private transaction not dropped")
+	}
+	if pool.IsPrivate(released.Hash()) {
+		t.Fatalf("expired private transaction not released")
+	}
+	if err := validateEvents(events, 1); err != nil {
+		t.Fatalf("release event firing failed: %v", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Benchmarks the speed of validating the contents of the pending queue of the
 // transaction pool.
 func BenchmarkPendingDemotion100(b *testing.B)   { benchmarkPendingDemotion(b, 100) }
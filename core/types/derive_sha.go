@@ -24,11 +24,16 @@ import (
 	"github.com/AdelineCoin/go-adln/trie"
 )
 
+// DerivableList is a list of items that can be committed to in a trie keyed by
+// their index. GetRlp returns the canonical encoding of an item, which for the
+// typed transactions and receipts of EIP2718 is the type byte followed by the
+// RLP payload, rather than their RLP string wrapping.
 type DerivableList interface {
 	Len() int
 	GetRlp(i int) []byte
 }
 
+// DeriveSha returns the root hash of the trie holding the items of the list.
 func DeriveSha(list DerivableList) common.Hash {
 	keybuf := new(bytes.Buffer)
 	trie := new(trie.Trie)
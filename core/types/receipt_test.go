@@ -0,0 +1,73 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/rlp"
+)
+
+// Tests that typed receipts are encoded with their type prefix, both in the
+// consensus and in the storage encoding.
+func TestTypedReceiptEncoding(t *testing.T) {
+	receipt := NewReceipt(nil, false, 21000)
+	receipt.Type = AccessListTxType
+
+	enc, err := receipt.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode receipt: %v", err)
+	}
+	if enc[0] != AccessListTxType {
+		t.Errorf("type prefix mismatch: have %x, want %x", enc[0], AccessListTxType)
+	}
+	dec := new(Receipt)
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode receipt: %v", err)
+	}
+	if dec.Type != receipt.Type || dec.CumulativeGasUsed != receipt.CumulativeGasUsed || dec.Status != receipt.Status {
+		t.Errorf("decoded receipt mismatch: have %+v, want %+v", dec, receipt)
+	}
+	// Typed receipts are wrapped into RLP strings within lists
+	blob, err := rlp.EncodeToBytes(Receipts{NewReceipt(nil, true, 21000), receipt})
+	if err != nil {
+		t.Fatalf("failed to encode receipt list: %v", err)
+	}
+	var receipts Receipts
+	if err := rlp.DecodeBytes(blob, &receipts); err != nil {
+		t.Fatalf("failed to decode receipt list: %v", err)
+	}
+	if len(receipts) != 2 || receipts[0].Type != LegacyTxType || receipts[1].Type != AccessListTxType {
+		t.Errorf("receipt list mismatch: have %v", receipts)
+	}
+	if !bytes.Equal(receipts.GetRlp(1), enc) {
+		t.Errorf("derivable encoding mismatch: have %x, want %x", receipts.GetRlp(1), enc)
+	}
+	// The storage encoding retains the type
+	stored, err := rlp.EncodeToBytes((*ReceiptForStorage)(receipt))
+	if err != nil {
+		t.Fatalf("failed to encode stored receipt: %v", err)
+	}
+	restored := new(ReceiptForStorage)
+	if err := rlp.DecodeBytes(stored, restored); err != nil {
+		t.Fatalf("failed to decode stored receipt: %v", err)
+	}
+	if restored.Type != AccessListTxType {
+		t.Errorf("stored type mismatch: have %d, want %d", restored.Type, AccessListTxType)
+	}
+}
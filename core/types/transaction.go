@@ -33,8 +33,17 @@ import (
 //go:generate gencodec -type txdata -field-override txdataMarshaling -out gen_tx_json.go
 
 var (
-	ErrInvalidSig = errors.New("invalid transaction v, r, s values")
-	errNoSigner   = errors.New("missing signing methods")
+	ErrInvalidSig         = errors.New("invalid transaction v, r, s values")
+	ErrTxTypeNotSupported = errors.New("transaction type not supported")
+	errNoSigner           = errors.New("missing signing methods")
+	errEmptyTypedTx       = errors.New("empty typed transaction bytes")
+)
+
+// Transaction types of the EIP-2718 envelope.
+const (
+	LegacyTxType     = 0x00
+	AccessListTxType = 0x01
+	DynamicFeeTxType = 0x02
 )
 
 // deriveSigner makes a *best* guess about which signer to use.
@@ -56,7 +65,7 @@ type Transaction struct {
 
 type txdata struct {
 	AccountNonce uint64          `json:"nonce"    gencodec:"required"`
-	Price        *big.Int        `json:"gasPrice" gencodec:"required"`
+	Price        *big.Int        `json:"gasPrice" gencodec:"required"` // Fee cap of dynamic fee transactions
 	GasLimit     uint64          `json:"gas"      gencodec:"required"`
 	Recipient    *common.Address `json:"to"       rlp:"nil"` // nil means contract creation
 	Amount       *big.Int        `json:"value"    gencodec:"required"`
@@ -67,6 +76,13 @@ type txdata struct {
 	R *big.Int `json:"r" gencodec:"required"`
 	S *big.Int `json:"s" gencodec:"required"`
 
+	// Typed transaction fields, only part of the typed encodings.
+	Type       uint8       `json:"type"                           rlp:"-"`
+	ChainID    *big.Int    `json:"chainId,omitempty"              rlp:"-"`
+	AccessList *AccessList `json:"accessList,omitempty"           rlp:"-"`
+	GasTipCap  *big.Int    `json:"maxPriorityFeePerGas,omitempty" rlp:"-"`
+	GasFeeCap  *big.Int    `json:"maxFeePerGas,omitempty"         rlp:"-"` // Always equals Price
+
 	// This is only used when marshaling to JSON.
 	Hash *common.Hash `json:"hash" rlp:"-"`
 }
@@ -80,6 +96,48 @@ type txdataMarshaling struct {
 	V            *hexutil.Big
 	R            *hexutil.Big
 	S            *hexutil.Big
+	Type         hexutil.Uint64
+	ChainID      *hexutil.Big
+	GasTipCap    *hexutil.Big
+	GasFeeCap    *hexutil.Big
+}
+
+// accessListTxdata is the consensus encoding of EIP-2930 access list
+// transactions, following the transaction type byte.
+type accessListTxdata struct {
+	ChainID      *big.Int
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"` // nil means contract creation
+	Amount       *big.Int
+	Payload      []byte
+	AccessList   AccessList
+
+	// Signature values
+	V *big.Int
+	R *big.Int
+	S *big.Int
+}
+
+// dynamicFeeTxdata is the consensus encoding of EIP-1559 dynamic fee
+// transactions, following the transaction type byte. The access list is part
+// of the encoding even when empty, the field order must never change.
+type dynamicFeeTxdata struct {
+	ChainID      *big.Int
+	AccountNonce uint64
+	GasTipCap    *big.Int
+	GasFeeCap    *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"` // nil means contract creation
+	Amount       *big.Int
+	Payload      []byte
+	AccessList   AccessList
+
+	// Signature values
+	V *big.Int
+	R *big.Int
+	S *big.Int
 }
 
 func NewTransaction(nonce uint64, to common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
@@ -115,13 +173,59 @@ func newTransaction(nonce uint64, to *common.Address, amount *big.Int, gasLimit
 	return &Transaction{data: d}
 }
 
+// NewAccessListTransaction creates an unsigned EIP-2930 transaction declaring
+// the accounts and storage slots it accesses. A nil recipient means contract
+// creation.
+func NewAccessListTransaction(chainID *big.Int, nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, accessList AccessList) *Transaction {
+	return newTypedTransaction(AccessListTxType, chainID, nonce, to, amount, gasLimit, gasPrice, data, accessList)
+}
+
+// NewDynamicFeeTransaction creates an unsigned EIP-1559 transaction paying the
+// base fee of its block plus a tip of at most gasTipCap, in total no more than
+// gasFeeCap per unit of gas. A nil recipient means contract creation.
+func NewDynamicFeeTransaction(chainID *big.Int, nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasTipCap, gasFeeCap *big.Int, data []byte, accessList AccessList) *Transaction {
+	tx := newTypedTransaction(DynamicFeeTxType, chainID, nonce, to, amount, gasLimit, gasFeeCap, data, accessList)
+	tx.data.GasTipCap = new(big.Int)
+	if gasTipCap != nil {
+		tx.data.GasTipCap.Set(gasTipCap)
+	}
+	tx.data.GasFeeCap = tx.data.Price
+	return tx
+}
+
+func newTypedTransaction(typ uint8, chainID *big.Int, nonce uint64, to *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, accessList AccessList) *Transaction {
+	tx := newTransaction(nonce, to, amount, gasLimit, gasPrice, data)
+	tx.data.Type = typ
+	tx.data.ChainID = new(big.Int)
+	if chainID != nil {
+		tx.data.ChainID.Set(chainID)
+	}
+	al := accessList.Copy()
+	if al == nil {
+		al = AccessList{}
+	}
+	tx.data.AccessList = &al
+	return tx
+}
+
+// Type returns the transaction type.
+func (tx *Transaction) Type() uint8 {
+	return tx.data.Type
+}
+
 // ChainId returns which chain id this transaction was signed for (if at all)
 func (tx *Transaction) ChainId() *big.Int {
+	if tx.data.Type != LegacyTxType {
+		return new(big.Int).Set(tx.data.ChainID)
+	}
 	return deriveChainId(tx.data.V)
 }
 
 // Protected returns whether the transaction is protected from replay protection.
 func (tx *Transaction) Protected() bool {
+	if tx.data.Type != LegacyTxType {
+		return true
+	}
 	return isProtectedV(tx.data.V)
 }
 
@@ -134,20 +238,153 @@ func isProtectedV(V *big.Int) bool {
 	return true
 }
 
-// EncodeRLP implements rlp.Encoder
+// EncodeRLP implements rlp.Encoder. Legacy transactions are encoded as an RLP
+// list, typed ones as an RLP string wrapping the type byte and the payload.
 func (tx *Transaction) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, &tx.data)
+	if tx.data.Type == LegacyTxType {
+		return rlp.Encode(w, &tx.data)
+	}
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, enc)
 }
 
 // DecodeRLP implements rlp.Decoder
 func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
-	_, size, _ := s.Kind()
-	err := s.Decode(&tx.data)
-	if err == nil {
+	kind, size, err := s.Kind()
+	switch {
+	case err != nil:
+		return err
+	case kind == rlp.List:
+		var data txdata
+		if err := s.Decode(&data); err != nil {
+			return err
+		}
+		tx.data = data
 		tx.size.Store(common.StorageSize(rlp.ListSize(size)))
+		return nil
+	default:
+		enc, err := s.Bytes()
+		if err != nil {
+			return err
+		}
+		return tx.UnmarshalBinary(enc)
 	}
+}
 
-	return err
+// MarshalBinary returns the canonical encoding of the transaction: the RLP list
+// for legacy transactions, and the type byte followed by the RLP encoded payload
+// for typed ones.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	switch tx.data.Type {
+	case LegacyTxType:
+		return rlp.EncodeToBytes(&tx.data)
+	case AccessListTxType:
+		return prefixedRlp(tx.data.Type, &accessListTxdata{
+			ChainID:      tx.data.ChainID,
+			AccountNonce: tx.data.AccountNonce,
+			Price:        tx.data.Price,
+			GasLimit:     tx.data.GasLimit,
+			Recipient:    tx.data.Recipient,
+			Amount:       tx.data.Amount,
+			Payload:      tx.data.Payload,
+			AccessList:   tx.AccessList(),
+			V:            tx.data.V,
+			R:            tx.data.R,
+			S:            tx.data.S,
+		})
+	case DynamicFeeTxType:
+		return prefixedRlp(tx.data.Type, &dynamicFeeTxdata{
+			ChainID:      tx.data.ChainID,
+			AccountNonce: tx.data.AccountNonce,
+			GasTipCap:    tx.data.GasTipCap,
+			GasFeeCap:    tx.data.Price,
+			GasLimit:     tx.data.GasLimit,
+			Recipient:    tx.data.Recipient,
+			Amount:       tx.data.Amount,
+			Payload:      tx.data.Payload,
+			AccessList:   tx.AccessList(),
+			V:            tx.data.V,
+			R:            tx.data.R,
+			S:            tx.data.S,
+		})
+	default:
+		return nil, ErrTxTypeNotSupported
+	}
+}
+
+// prefixedRlp returns the RLP encoding of x prefixed with the given type byte.
+func prefixedRlp(prefix byte, x interface{}) ([]byte, error) {
+	enc, err := rlp.EncodeToBytes(x)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{prefix}, enc...), nil
+}
+
+// UnmarshalBinary decodes the canonical encoding of transactions.
+func (tx *Transaction) UnmarshalBinary(b []byte) error {
+	if len(b) > 0 && b[0] > 0x7f {
+		// It's a legacy transaction, an RLP list
+		var data txdata
+		if err := rlp.DecodeBytes(b, &data); err != nil {
+			return err
+		}
+		*tx = Transaction{data: data}
+		tx.size.Store(common.StorageSize(len(b)))
+		return nil
+	}
+	if len(b) == 0 {
+		return errEmptyTypedTx
+	}
+	switch b[0] {
+	case AccessListTxType:
+		var dec accessListTxdata
+		if err := rlp.DecodeBytes(b[1:], &dec); err != nil {
+			return err
+		}
+		*tx = Transaction{data: txdata{
+			AccountNonce: dec.AccountNonce,
+			Price:        dec.Price,
+			GasLimit:     dec.GasLimit,
+			Recipient:    dec.Recipient,
+			Amount:       dec.Amount,
+			Payload:      dec.Payload,
+			V:            dec.V,
+			R:            dec.R,
+			S:            dec.S,
+			Type:         AccessListTxType,
+			ChainID:      dec.ChainID,
+			AccessList:   &dec.AccessList,
+		}}
+	case DynamicFeeTxType:
+		var dec dynamicFeeTxdata
+		if err := rlp.DecodeBytes(b[1:], &dec); err != nil {
+			return err
+		}
+		*tx = Transaction{data: txdata{
+			AccountNonce: dec.AccountNonce,
+			Price:        dec.GasFeeCap,
+			GasLimit:     dec.GasLimit,
+			Recipient:    dec.Recipient,
+			Amount:       dec.Amount,
+			Payload:      dec.Payload,
+			V:            dec.V,
+			R:            dec.R,
+			S:            dec.S,
+			Type:         DynamicFeeTxType,
+			ChainID:      dec.ChainID,
+			AccessList:   &dec.AccessList,
+			GasTipCap:    dec.GasTipCap,
+			GasFeeCap:    dec.GasFeeCap,
+		}}
+	default:
+		return ErrTxTypeNotSupported
+	}
+	tx.size.Store(common.StorageSize(len(b)))
+	return nil
 }
 
 // MarshalJSON encodes the web3 RPC transaction format.
@@ -165,10 +402,27 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 		return err
 	}
 	var V byte
-	if isProtectedV(dec.V) {
+	switch {
+	case dec.Type == AccessListTxType:
+		if dec.ChainID == nil || dec.AccessList == nil {
+			return errors.New("missing required fields 'chainId', 'accessList' for access list transaction")
+		}
+		V = byte(dec.V.Uint64())
+	case dec.Type == DynamicFeeTxType:
+		if dec.ChainID == nil || dec.GasTipCap == nil || dec.AccessList == nil {
+			return errors.New("missing required fields 'chainId', 'maxPriorityFeePerGas', 'accessList' for dynamic fee transaction")
+		}
+		if dec.GasFeeCap != nil && dec.GasFeeCap.Cmp(dec.Price) != 0 {
+			return errors.New("mismatching fields 'gasPrice' and 'maxFeePerGas' for dynamic fee transaction")
+		}
+		dec.GasFeeCap = dec.Price
+		V = byte(dec.V.Uint64())
+	case dec.Type != LegacyTxType:
+		return ErrTxTypeNotSupported
+	case isProtectedV(dec.V):
 		chainID := deriveChainId(dec.V).Uint64()
 		V = byte(dec.V.Uint64() - 35 - 2*chainID)
-	} else {
+	default:
 		V = byte(dec.V.Uint64() - 27)
 	}
 	if !crypto.ValidateSignatureValues(V, dec.R, dec.S, false) {
@@ -178,12 +432,44 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 	return nil
 }
 
-func (tx *Transaction) Data() []byte       { return common.CopyBytes(tx.data.Payload) }
-func (tx *Transaction) Gas() uint64        { return tx.data.GasLimit }
-func (tx *Transaction) GasPrice() *big.Int { return new(big.Int).Set(tx.data.Price) }
-func (tx *Transaction) Value() *big.Int    { return new(big.Int).Set(tx.data.Amount) }
-func (tx *Transaction) Nonce() uint64      { return tx.data.AccountNonce }
-func (tx *Transaction) CheckNonce() bool   { return true }
+func (tx *Transaction) Data() []byte        { return common.CopyBytes(tx.data.Payload) }
+func (tx *Transaction) Gas() uint64         { return tx.data.GasLimit }
+func (tx *Transaction) GasPrice() *big.Int  { return new(big.Int).Set(tx.data.Price) }
+func (tx *Transaction) GasFeeCap() *big.Int { return new(big.Int).Set(tx.data.Price) }
+func (tx *Transaction) Value() *big.Int     { return new(big.Int).Set(tx.data.Amount) }
+func (tx *Transaction) Nonce() uint64       { return tx.data.AccountNonce }
+func (tx *Transaction) CheckNonce() bool    { return true }
+
+// AccessList returns the access list of the transaction, nil for legacy ones.
+func (tx *Transaction) AccessList() AccessList {
+	if tx.data.AccessList == nil {
+		return nil
+	}
+	return *tx.data.AccessList
+}
+
+// GasTipCap returns the maximum tip per gas paid to the miner on top of the base
+// fee. It's the gas price of legacy transactions.
+func (tx *Transaction) GasTipCap() *big.Int {
+	if tx.data.Type == DynamicFeeTxType {
+		return new(big.Int).Set(tx.data.GasTipCap)
+	}
+	return new(big.Int).Set(tx.data.Price)
+}
+
+// EffectiveGasTip returns the tip per gas paid to the miner given the base fee
+// of the block, which is negative if the fee cap is below the base fee. A nil
+// base fee means the block predates EIP-1559 and the whole gas price is the tip.
+func (tx *Transaction) EffectiveGasTip(baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return tx.GasPrice()
+	}
+	tip := new(big.Int).Sub(tx.data.Price, baseFee)
+	if cap := tx.GasTipCap(); tip.Cmp(cap) > 0 {
+		return cap
+	}
+	return tip
+}
 
 // To returns the recipient address of the transaction.
 // It returns nil if the transaction is a contract creation.
@@ -201,7 +487,13 @@ func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
 		return hash.(common.Hash)
 	}
-	v := rlpHash(tx)
+	var v common.Hash
+	if tx.data.Type == LegacyTxType {
+		v = rlpHash(tx)
+	} else {
+		enc, _ := tx.MarshalBinary()
+		v = crypto.Keccak256Hash(enc)
+	}
 	tx.hash.Store(v)
 	return v
 }
@@ -212,8 +504,13 @@ func (tx *Transaction) Size() common.StorageSize {
 	if size := tx.size.Load(); size != nil {
 		return size.(common.StorageSize)
 	}
-	c := writeCounter(0)
-	rlp.Encode(&c, &tx.data)
+	var c writeCounter
+	if tx.data.Type == LegacyTxType {
+		rlp.Encode(&c, &tx.data)
+	} else {
+		enc, _ := tx.MarshalBinary()
+		c = writeCounter(len(enc))
+	}
 	tx.size.Store(common.StorageSize(c))
 	return common.StorageSize(c)
 }
@@ -228,6 +525,9 @@ func (tx *Transaction) AsMessage(s Signer) (Message, error) {
 		nonce:      tx.data.AccountNonce,
 		gasLimit:   tx.data.GasLimit,
 		gasPrice:   new(big.Int).Set(tx.data.Price),
+		gasFeeCap:  new(big.Int).Set(tx.data.Price),
+		gasTipCap:  tx.GasTipCap(),
+		accessList: tx.AccessList(),
 		to:         tx.data.Recipient,
 		amount:     tx.data.Amount,
 		data:       tx.data.Payload,
@@ -268,6 +568,9 @@ func (tx *Transaction) String() string {
 		// make a best guess about the signer and use that to derive
 		// the sender.
 		signer := deriveSigner(tx.data.V)
+		if tx.data.Type != LegacyTxType {
+			signer = NewEIP1559Signer(tx.data.ChainID)
+		}
 		if f, err := Sender(signer, tx); err != nil { // derive but don't cache
 			from = "[invalid sender: invalid sig]"
 		} else {
@@ -282,7 +585,7 @@ func (tx *Transaction) String() string {
 	} else {
 		to = fmt.Sprintf("%x", tx.data.Recipient[:])
 	}
-	enc, _ := rlp.EncodeToBytes(&tx.data)
+	enc, _ := tx.MarshalBinary()
 	return fmt.Sprintf(`
 	TX(%x)
 	Contract: %v
@@ -323,9 +626,10 @@ func (s Transactions) Len() int { return len(s) }
 // Swap swaps the i'th and the j'th element in s.
 func (s Transactions) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
 
-// GetRlp implements Rlpable and returns the i'th element of s in rlp.
+// GetRlp implements Rlpable and returns the canonical encoding of the i'th
+// element of s, the plain rlp for legacy transactions.
 func (s Transactions) GetRlp(i int) []byte {
-	enc, _ := rlp.EncodeToBytes(s[i])
+	enc, _ := s[i].MarshalBinary()
 	return enc
 }
 
@@ -376,25 +680,51 @@ func (s *TxByPrice) Pop() interface{} {
 	return x
 }
 
+// txByTip implements the heap interface, sorting transactions by the tip they
+// pay to the miner given the base fee of the block.
+type txByTip struct {
+	txs     Transactions
+	baseFee *big.Int
+}
+
+func (s txByTip) Len() int { return len(s.txs) }
+func (s txByTip) Less(i, j int) bool {
+	return s.txs[i].EffectiveGasTip(s.baseFee).Cmp(s.txs[j].EffectiveGasTip(s.baseFee)) > 0
+}
+func (s txByTip) Swap(i, j int) { s.txs[i], s.txs[j] = s.txs[j], s.txs[i] }
+
+func (s *txByTip) Push(x interface{}) {
+	s.txs = append(s.txs, x.(*Transaction))
+}
+
+func (s *txByTip) Pop() interface{} {
+	old := s.txs
+	n := len(old)
+	x := old[n-1]
+	s.txs = old[0 : n-1]
+	return x
+}
+
 // TransactionsByPriceAndNonce represents a set of transactions that can return
 // transactions in a profit-maximizing sorted order, while supporting removing
 // entire batches of transactions for non-executable accounts.
 type TransactionsByPriceAndNonce struct {
 	txs    map[common.Address]Transactions // Per account nonce-sorted list of transactions
-	heads  TxByPrice                       // Next transaction for each unique account (price heap)
+	heads  txByTip                         // Next transaction for each unique account (tip heap)
 	signer Signer                          // Signer for the set of transactions
 }
 
 // NewTransactionsByPriceAndNonce creates a transaction set that can retrieve
-// price sorted transactions in a nonce-honouring way.
+// price sorted transactions in a nonce-honouring way. After EIP1559 the price
+// is the tip paid on top of the given base fee, which is nil before the fork.
 //
 // Note, the input map is reowned so the caller should not interact any more with
 // if after providing it to the constructor.
-func NewTransactionsByPriceAndNonce(signer Signer, txs map[common.Address]Transactions) *TransactionsByPriceAndNonce {
+func NewTransactionsByPriceAndNonce(signer Signer, txs map[common.Address]Transactions, baseFee *big.Int) *TransactionsByPriceAndNonce {
 	// Initialize a price based heap with the head transactions
-	heads := make(TxByPrice, 0, len(txs))
+	heads := txByTip{txs: make(Transactions, 0, len(txs)), baseFee: baseFee}
 	for _, accTxs := range txs {
-		heads = append(heads, accTxs[0])
+		heads.txs = append(heads.txs, accTxs[0])
 		// Ensure the sender address is from the signer
 		acc, _ := Sender(signer, accTxs[0])
 		txs[acc] = accTxs[1:]
@@ -411,17 +741,17 @@ func NewTransactionsByPriceAndNonce(signer Signer, txs map[common.Address]Transa
 
 // Peek returns the next transaction by price.
 func (t *TransactionsByPriceAndNonce) Peek() *Transaction {
-	if len(t.heads) == 0 {
+	if len(t.heads.txs) == 0 {
 		return nil
 	}
-	return t.heads[0]
+	return t.heads.txs[0]
 }
 
 // Shift replaces the current best head with the next one from the same account.
 func (t *TransactionsByPriceAndNonce) Shift() {
-	acc, _ := Sender(t.signer, t.heads[0])
+	acc, _ := Sender(t.signer, t.heads.txs[0])
 	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
-		t.heads[0], t.txs[acc] = txs[0], txs[1:]
+		t.heads.txs[0], t.txs[acc] = txs[0], txs[1:]
 		heap.Fix(&t.heads, 0)
 	} else {
 		heap.Pop(&t.heads)
@@ -445,7 +775,10 @@ type Message struct {
 	amount     *big.Int
 	gasLimit   uint64
 	gasPrice   *big.Int
+	gasFeeCap  *big.Int
+	gasTipCap  *big.Int
 	data       []byte
+	accessList AccessList
 	checkNonce bool
 }
 
@@ -457,16 +790,37 @@ func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *b
 		amount:     amount,
 		gasLimit:   gasLimit,
 		gasPrice:   gasPrice,
+		gasFeeCap:  gasPrice,
+		gasTipCap:  gasPrice,
 		data:       data,
 		checkNonce: checkNonce,
 	}
 }
 
-func (m Message) From() common.Address { return m.from }
-func (m Message) To() *common.Address  { return m.to }
-func (m Message) GasPrice() *big.Int   { return m.gasPrice }
-func (m Message) Value() *big.Int      { return m.amount }
-func (m Message) Gas() uint64          { return m.gasLimit }
-func (m Message) Nonce() uint64        { return m.nonce }
-func (m Message) Data() []byte         { return m.data }
-func (m Message) CheckNonce() bool     { return m.checkNonce }
+// NewAccessListMessage creates a message declaring the accounts and storage slots
+// it accesses ahead of execution.
+func NewAccessListMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte, accessList AccessList, checkNonce bool) Message {
+	msg := NewMessage(from, to, nonce, amount, gasLimit, gasPrice, data, checkNonce)
+	msg.accessList = accessList
+	return msg
+}
+
+// NewDynamicFeeMessage creates a message paying the base fee plus a tip of at
+// most gasTipCap, in total no more than gasFeeCap per unit of gas.
+func NewDynamicFeeMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasTipCap, gasFeeCap *big.Int, data []byte, checkNonce bool) Message {
+	msg := NewMessage(from, to, nonce, amount, gasLimit, gasFeeCap, data, checkNonce)
+	msg.gasTipCap = gasTipCap
+	return msg
+}
+
+func (m Message) From() common.Address   { return m.from }
+func (m Message) To() *common.Address    { return m.to }
+func (m Message) GasPrice() *big.Int     { return m.gasPrice }
+func (m Message) GasFeeCap() *big.Int    { return m.gasFeeCap }
+func (m Message) GasTipCap() *big.Int    { return m.gasTipCap }
+func (m Message) AccessList() AccessList { return m.accessList }
+func (m Message) Value() *big.Int        { return m.amount }
+func (m Message) Gas() uint64            { return m.gasLimit }
+func (m Message) Nonce() uint64          { return m.nonce }
+func (m Message) Data() []byte           { return m.data }
+func (m Message) CheckNonce() bool       { return m.checkNonce }
@@ -42,6 +42,10 @@ type sigCache struct {
 func MakeSigner(config *params.ChainConfig, blockNumber *big.Int) Signer {
 	var signer Signer
 	switch {
+	case config.IsEIP1559(blockNumber):
+		signer = NewEIP1559Signer(config.ChainId)
+	case config.IsEIP2718(blockNumber):
+		signer = NewEIP2930Signer(config.ChainId)
 	case config.IsEIP155(blockNumber):
 		signer = NewEIP155Signer(config.ChainId)
 	case config.IsHomestead(blockNumber):
@@ -52,6 +56,23 @@ func MakeSigner(config *params.ChainConfig, blockNumber *big.Int) Signer {
 	return signer
 }
 
+// LatestSigner returns the most permissive Signer available for the given chain
+// config, accepting all transaction types that are scheduled to be enabled at
+// some point. It's meant for places not tied to a specific block, such as the
+// transaction pool or the RPC API.
+func LatestSigner(config *params.ChainConfig) Signer {
+	if config.EIP1559Block != nil {
+		return NewEIP1559Signer(config.ChainId)
+	}
+	if config.EIP2718Block != nil {
+		return NewEIP2930Signer(config.ChainId)
+	}
+	if config.EIP155Block != nil {
+		return NewEIP155Signer(config.ChainId)
+	}
+	return HomesteadSigner{}
+}
+
 // SignTx signs the transaction using the given signer and private key
 func SignTx(tx *Transaction, s Signer, prv *ecdsa.PrivateKey) (*Transaction, error) {
 	h := s.Hash(tx)
@@ -102,6 +123,126 @@ type Signer interface {
 	Equal(Signer) bool
 }
 
+// EIP1559Signer implements Signer using the EIP1559 rules, accepting dynamic fee
+// transactions in addition to the ones supported by EIP2930Signer.
+type EIP1559Signer struct{ EIP2930Signer }
+
+func NewEIP1559Signer(chainId *big.Int) EIP1559Signer {
+	return EIP1559Signer{NewEIP2930Signer(chainId)}
+}
+
+func (s EIP1559Signer) Equal(s2 Signer) bool {
+	eip1559, ok := s2.(EIP1559Signer)
+	return ok && eip1559.chainId.Cmp(s.chainId) == 0
+}
+
+func (s EIP1559Signer) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != DynamicFeeTxType {
+		return s.EIP2930Signer.Sender(tx)
+	}
+	return typedSender(s, s.chainId, tx)
+}
+
+// SignatureValues returns signature values. This signature
+// needs to be in the [R || S || V] format where V is 0 or 1.
+func (s EIP1559Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() != DynamicFeeTxType {
+		return s.EIP2930Signer.SignatureValues(tx, sig)
+	}
+	return typedSignatureValues(s.chainId, tx, sig)
+}
+
+// Hash returns the hash to be signed by the sender.
+// It does not uniquely identify the transaction.
+func (s EIP1559Signer) Hash(tx *Transaction) common.Hash {
+	if tx.Type() != DynamicFeeTxType {
+		return s.EIP2930Signer.Hash(tx)
+	}
+	return prefixedRlpHash(tx.Type(), []interface{}{
+		s.chainId,
+		tx.data.AccountNonce,
+		tx.data.GasTipCap,
+		tx.data.Price,
+		tx.data.GasLimit,
+		tx.data.Recipient,
+		tx.data.Amount,
+		tx.data.Payload,
+		tx.AccessList(),
+	})
+}
+
+// EIP2930Signer implements Signer using the EIP2930 rules, accepting access list
+// transactions in addition to the ones supported by EIP155Signer.
+type EIP2930Signer struct{ EIP155Signer }
+
+func NewEIP2930Signer(chainId *big.Int) EIP2930Signer {
+	return EIP2930Signer{NewEIP155Signer(chainId)}
+}
+
+func (s EIP2930Signer) Equal(s2 Signer) bool {
+	eip2930, ok := s2.(EIP2930Signer)
+	return ok && eip2930.chainId.Cmp(s.chainId) == 0
+}
+
+func (s EIP2930Signer) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != AccessListTxType {
+		return s.EIP155Signer.Sender(tx)
+	}
+	return typedSender(s, s.chainId, tx)
+}
+
+// SignatureValues returns signature values. This signature
+// needs to be in the [R || S || V] format where V is 0 or 1.
+func (s EIP2930Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() != AccessListTxType {
+		return s.EIP155Signer.SignatureValues(tx, sig)
+	}
+	return typedSignatureValues(s.chainId, tx, sig)
+}
+
+// Hash returns the hash to be signed by the sender.
+// It does not uniquely identify the transaction.
+func (s EIP2930Signer) Hash(tx *Transaction) common.Hash {
+	if tx.Type() != AccessListTxType {
+		return s.EIP155Signer.Hash(tx)
+	}
+	return prefixedRlpHash(tx.Type(), []interface{}{
+		s.chainId,
+		tx.data.AccountNonce,
+		tx.data.Price,
+		tx.data.GasLimit,
+		tx.data.Recipient,
+		tx.data.Amount,
+		tx.data.Payload,
+		tx.AccessList(),
+	})
+}
+
+// typedSender recovers the sender of a typed transaction, which carries the
+// plain signature parity in V.
+func typedSender(s Signer, chainId *big.Int, tx *Transaction) (common.Address, error) {
+	if tx.data.ChainID.Cmp(chainId) != 0 {
+		return common.Address{}, ErrInvalidChainId
+	}
+	V := new(big.Int).Add(tx.data.V, big.NewInt(27))
+	return recoverPlain(s.Hash(tx), tx.data.R, tx.data.S, V, true)
+}
+
+// typedSignatureValues returns the signature values of a typed transaction,
+// with V being the plain signature parity.
+func typedSignatureValues(chainId *big.Int, tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.data.ChainID.Sign() != 0 && tx.data.ChainID.Cmp(chainId) != 0 {
+		return nil, nil, nil, ErrInvalidChainId
+	}
+	if len(sig) != 65 {
+		panic(fmt.Sprintf("wrong size for signature: got %d, want 65", len(sig)))
+	}
+	R = new(big.Int).SetBytes(sig[:32])
+	S = new(big.Int).SetBytes(sig[32:64])
+	V = big.NewInt(int64(sig[64]))
+	return R, S, V, nil
+}
+
 // EIP155Transaction implements Signer using the EIP155 rules.
 type EIP155Signer struct {
 	chainId, chainIdMul *big.Int
@@ -125,6 +266,9 @@ func (s EIP155Signer) Equal(s2 Signer) bool {
 var big8 = big.NewInt(8)
 
 func (s EIP155Signer) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != LegacyTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
 	if !tx.Protected() {
 		return HomesteadSigner{}.Sender(tx)
 	}
@@ -139,6 +283,9 @@ func (s EIP155Signer) Sender(tx *Transaction) (common.Address, error) {
 // WithSignature returns a new transaction with the given signature. This signature
 // needs to be in the [R || S || V] format where V is 0 or 1.
 func (s EIP155Signer) SignatureValues(tx *Transaction, sig []byte) (R, S, V *big.Int, err error) {
+	if tx.Type() != LegacyTxType {
+		return nil, nil, nil, ErrTxTypeNotSupported
+	}
 	R, S, V, err = HomesteadSigner{}.SignatureValues(tx, sig)
 	if err != nil {
 		return nil, nil, nil, err
@@ -180,6 +327,9 @@ func (hs HomesteadSigner) SignatureValues(tx *Transaction, sig []byte) (r, s, v
 }
 
 func (hs HomesteadSigner) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != LegacyTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
 	return recoverPlain(hs.Hash(tx), tx.data.R, tx.data.S, tx.data.V, true)
 }
 
@@ -193,6 +343,9 @@ func (s FrontierSigner) Equal(s2 Signer) bool {
 // SignatureValues returns signature values. This signature
 // needs to be in the [R || S || V] format where V is 0 or 1.
 func (fs FrontierSigner) SignatureValues(tx *Transaction, sig []byte) (r, s, v *big.Int, err error) {
+	if tx.Type() != LegacyTxType {
+		return nil, nil, nil, ErrTxTypeNotSupported
+	}
 	if len(sig) != 65 {
 		panic(fmt.Sprintf("wrong size for signature: got %d, want 65", len(sig)))
 	}
@@ -216,6 +369,9 @@ func (fs FrontierSigner) Hash(tx *Transaction) common.Hash {
 }
 
 func (fs FrontierSigner) Sender(tx *Transaction) (common.Address, error) {
+	if tx.Type() != LegacyTxType {
+		return common.Address{}, ErrTxTypeNotSupported
+	}
 	return recoverPlain(fs.Hash(tx), tx.data.R, tx.data.S, tx.data.V, false)
 }
 
@@ -144,7 +144,7 @@ func TestTransactionPriceNonceSort(t *testing.T) {
 		}
 	}
 	// Sort the transactions and cross check the nonce ordering
-	txset := NewTransactionsByPriceAndNonce(signer, groups)
+	txset := NewTransactionsByPriceAndNonce(signer, groups, nil)
 
 	txs := Transactions{}
 	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
@@ -233,3 +233,175 @@ func TestTransactionJSON(t *testing.T) {
 		}
 	}
 }
+
+// Tests that dynamic fee transactions survive the canonical, RLP and JSON
+// encodings, and that only the EIP1559 signer accepts them.
+func TestDynamicFeeTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	signer := NewEIP1559Signer(common.Big1)
+
+	to := common.Address{1}
+	tx, err := SignTx(NewDynamicFeeTransaction(common.Big1, 3, &to, big.NewInt(10), 21000, big.NewInt(2), big.NewInt(10), []byte("abcdef"), nil), signer, key)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if tx.Type() != DynamicFeeTxType {
+		t.Fatalf("type mismatch: have %d, want %d", tx.Type(), DynamicFeeTxType)
+	}
+	if from, err := Sender(signer, tx); err != nil || from != addr {
+		t.Fatalf("sender mismatch: have %x (err %v), want %x", from, err, addr)
+	}
+	if _, err := Sender(NewEIP155Signer(common.Big1), tx); err != ErrTxTypeNotSupported {
+		t.Errorf("legacy signer error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	if _, err := Sender(NewEIP1559Signer(common.Big2), tx); err != ErrInvalidChainId {
+		t.Errorf("chain id error mismatch: have %v, want %v", err, ErrInvalidChainId)
+	}
+	// Check the canonical encoding and its wrapping into RLP lists
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	if enc[0] != DynamicFeeTxType {
+		t.Errorf("type prefix mismatch: have %x, want %x", enc[0], DynamicFeeTxType)
+	}
+	if hash := crypto.Keccak256Hash(enc); tx.Hash() != hash {
+		t.Errorf("hash mismatch: have %x, want %x", tx.Hash(), hash)
+	}
+	dec := new(Transaction)
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Errorf("decoded hash mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	blob, err := rlp.EncodeToBytes(Transactions{rightvrsTx, tx})
+	if err != nil {
+		t.Fatalf("failed to encode transaction list: %v", err)
+	}
+	var txs Transactions
+	if err := rlp.DecodeBytes(blob, &txs); err != nil {
+		t.Fatalf("failed to decode transaction list: %v", err)
+	}
+	if len(txs) != 2 || txs[0].Hash() != rightvrsTx.Hash() || txs[1].Hash() != tx.Hash() {
+		t.Errorf("transaction list mismatch: have %v", txs)
+	}
+	// Check the JSON encoding
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	parsed := new(Transaction)
+	if err := json.Unmarshal(data, parsed); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if parsed.Hash() != tx.Hash() {
+		t.Errorf("parsed hash mismatch: have %x, want %x", parsed.Hash(), tx.Hash())
+	}
+	// Check the tip paid under various base fees
+	tests := []struct {
+		baseFee *big.Int
+		tip     int64
+	}{
+		{nil, 10}, {big.NewInt(5), 2}, {big.NewInt(9), 1}, {big.NewInt(12), -2},
+	}
+	for i, tt := range tests {
+		if tip := tx.EffectiveGasTip(tt.baseFee); tip.Int64() != tt.tip {
+			t.Errorf("test %d: tip mismatch: have %v, want %d", i, tip, tt.tip)
+		}
+	}
+}
+
+// Tests that the consensus encoding of dynamic fee transactions follows the
+// EIP-1559 field order, access list included, and that payloads lacking the
+// access list are rejected instead of being misinterpreted.
+func TestDynamicFeeTransactionEncoding(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := NewEIP1559Signer(common.Big1)
+
+	to := common.Address{1}
+	accesses := AccessList{{Address: common.Address{2}, StorageKeys: []common.Hash{{1}}}}
+	tx, err := SignTx(NewDynamicFeeTransaction(common.Big1, 3, &to, big.NewInt(10), 30000, big.NewInt(2), big.NewInt(10), []byte("abcdef"), accesses), signer, key)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	v, r, s := tx.RawSignatureValues()
+	fields := []interface{}{common.Big1, uint64(3), big.NewInt(2), big.NewInt(10), uint64(30000), &to, big.NewInt(10), []byte("abcdef"), accesses, v, r, s}
+
+	want, err := prefixedRlp(DynamicFeeTxType, fields)
+	if err != nil {
+		t.Fatalf("failed to encode fields: %v", err)
+	}
+	have, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	if !bytes.Equal(have, want) {
+		t.Fatalf("encoding mismatch:\nhave %x\nwant %x", have, want)
+	}
+	if hash := prefixedRlpHash(DynamicFeeTxType, fields[:9]); signer.Hash(tx) != hash {
+		t.Errorf("signing hash mismatch: have %x, want %x", signer.Hash(tx), hash)
+	}
+	// Drop the access list and ensure the payload doesn't decode
+	legacy, err := prefixedRlp(DynamicFeeTxType, append(fields[:8:8], v, r, s))
+	if err != nil {
+		t.Fatalf("failed to encode fields: %v", err)
+	}
+	if err := new(Transaction).UnmarshalBinary(legacy); err == nil {
+		t.Errorf("decoded dynamic fee transaction without access list")
+	}
+}
+
+// Tests that access list transactions are signed, encoded and decoded correctly.
+func TestAccessListTransaction(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	signer := NewEIP2930Signer(common.Big1)
+
+	to := common.Address{1}
+	accesses := AccessList{{Address: common.Address{2}, StorageKeys: []common.Hash{{1}, {2}}}}
+	tx, err := SignTx(NewAccessListTransaction(common.Big1, 3, &to, big.NewInt(10), 30000, big.NewInt(2), []byte("abcdef"), accesses), signer, key)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if tx.Type() != AccessListTxType {
+		t.Fatalf("type mismatch: have %d, want %d", tx.Type(), AccessListTxType)
+	}
+	for _, s := range []Signer{signer, NewEIP1559Signer(common.Big1)} {
+		if from, err := Sender(s, tx); err != nil || from != addr {
+			t.Fatalf("sender mismatch: have %x (err %v), want %x", from, err, addr)
+		}
+	}
+	if _, err := Sender(NewEIP155Signer(common.Big1), tx); err != ErrTxTypeNotSupported {
+		t.Errorf("legacy signer error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("failed to encode transaction: %v", err)
+	}
+	if enc[0] != AccessListTxType {
+		t.Errorf("type prefix mismatch: have %x, want %x", enc[0], AccessListTxType)
+	}
+	dec := new(Transaction)
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	if dec.Hash() != tx.Hash() {
+		t.Errorf("decoded hash mismatch: have %x, want %x", dec.Hash(), tx.Hash())
+	}
+	if al := dec.AccessList(); len(al) != 1 || al.StorageKeys() != 2 || al[0].Address != accesses[0].Address {
+		t.Errorf("decoded access list mismatch: have %v, want %v", al, accesses)
+	}
+	data, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatalf("json.Marshal failed: %v", err)
+	}
+	parsed := new(Transaction)
+	if err := json.Unmarshal(data, parsed); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	if parsed.Hash() != tx.Hash() {
+		t.Errorf("parsed hash mismatch: have %x, want %x", parsed.Hash(), tx.Hash())
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core/types"
+)
+
+// accessList is an accumulator for the accounts and storage slots touched
+// during execution.
+type accessList map[common.Address]map[common.Hash]struct{}
+
+// addAddress adds an address to the list, if not yet present.
+func (al accessList) addAddress(address common.Address) {
+	if _, ok := al[address]; !ok {
+		al[address] = make(map[common.Hash]struct{})
+	}
+}
+
+// addSlot adds a storage slot of an address to the list.
+func (al accessList) addSlot(address common.Address, slot common.Hash) {
+	al.addAddress(address)
+	al[address][slot] = struct{}{}
+}
+
+// equal reports whether two access lists contain the same entries.
+func (al accessList) equal(other accessList) bool {
+	if len(al) != len(other) {
+		return false
+	}
+	for addr, slots := range al {
+		otherSlots, ok := other[addr]
+		if !ok || len(slots) != len(otherSlots) {
+			return false
+		}
+		for slot := range slots {
+			if _, ok := otherSlots[slot]; !ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// accessList converts the accumulator into its consensus representation.
+func (al accessList) accessList() types.AccessList {
+	acl := make(types.AccessList, 0, len(al))
+	for addr, slots := range al {
+		tuple := types.AccessTuple{Address: addr, StorageKeys: []common.Hash{}}
+		for slot := range slots {
+			tuple.StorageKeys = append(tuple.StorageKeys, slot)
+		}
+		acl = append(acl, tuple)
+	}
+	return acl
+}
+
+// AccessListTracer is a tracer collecting the accounts and storage slots
+// accessed by a transaction, to be used as its EIP-2930 access list. The sender,
+// the recipient and the precompiled contracts are left out as they are always
+// accessed anyway.
+type AccessListTracer struct {
+	excl map[common.Address]struct{}
+	list accessList
+}
+
+// NewAccessListTracer creates a tracer seeded with the given access list,
+// excluding the sender, the recipient and the precompiled contracts.
+func NewAccessListTracer(acl types.AccessList, from, to common.Address, precompiles []common.Address) *AccessListTracer {
+	excl := map[common.Address]struct{}{
+		from: {},
+		to:   {},
+	}
+	for _, addr := range precompiles {
+		excl[addr] = struct{}{}
+	}
+	tracer := &AccessListTracer{excl: excl, list: make(accessList)}
+	for _, tuple := range acl {
+		tracer.addAddress(tuple.Address)
+		for _, slot := range tuple.StorageKeys {
+			tracer.list.addSlot(tuple.Address, slot)
+		}
+	}
+	return tracer
+}
+
+// CaptureStart implements Tracer, doing nothing.
+func (a *AccessListTracer) CaptureStart(from common.Address, to common.Address, call bool, input []byte, gas uint64, value *big.Int) error {
+	return nil
+}
+
+// CaptureState records the storage slots and accounts accessed by the opcode
+// about to be executed.
+func (a *AccessListTracer) CaptureState(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	size := len(stack.Data())
+	switch {
+	case (op == SLOAD || op == SSTORE) && size >= 1:
+		a.list.addSlot(contract.Address(), common.BigToHash(stack.Back(0)))
+	case (op == EXTCODECOPY || op == EXTCODESIZE || op == BALANCE || op == SELFDESTRUCT) && size >= 1:
+		a.addAddress(common.BigToAddress(stack.Back(0)))
+	case (op == CALL || op == CALLCODE || op == DELEGATECALL || op == STATICCALL) && size >= 5:
+		a.addAddress(common.BigToAddress(stack.Back(1)))
+	}
+	return nil
+}
+
+// addAddress adds an accessed account to the list unless it's excluded.
+func (a *AccessListTracer) addAddress(addr common.Address) {
+	if _, ok := a.excl[addr]; !ok {
+		a.list.addAddress(addr)
+	}
+}
+
+// CaptureFault implements Tracer, doing nothing.
+func (a *AccessListTracer) CaptureFault(env *EVM, pc uint64, op OpCode, gas, cost uint64, memory *Memory, stack *Stack, contract *Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd implements Tracer, doing nothing.
+func (a *AccessListTracer) CaptureEnd(output []byte, gasUsed uint64, t time.Duration, err error) error {
+	return nil
+}
+
+// AccessList returns the current access list.
+func (a *AccessListTracer) AccessList() types.AccessList {
+	return a.list.accessList()
+}
+
+// Equal reports whether the access lists of two tracers are the same.
+func (a *AccessListTracer) Equal(other *AccessListTracer) bool {
+	return a.list.equal(other.list)
+}
@@ -18,14 +18,18 @@ package vm
 
 import (
 	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"math/big"
 
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/common/math"
 	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/crypto/blake2b"
 	"github.com/AdelineCoin/go-adln/crypto/bn256"
+	"github.com/AdelineCoin/go-adln/crypto/sha3"
 	"github.com/AdelineCoin/go-adln/params"
+	"golang.org/x/crypto/ed25519"
 	"golang.org/x/crypto/ripemd160"
 )
 
@@ -358,3 +362,113 @@ func (c *bn256Pairing) Run(input []byte) ([]byte, error) {
 	}
 	return false32Byte, nil
 }
+
+const blake2FInputLength = 213
+
+var (
+	// errBlake2FInvalidInputLength is returned if the BLAKE2b compression input
+	// isn't exactly 213 bytes.
+	errBlake2FInvalidInputLength = errors.New("invalid input length")
+
+	// errBlake2FInvalidFinalFlag is returned if the final block indicator flag
+	// of the BLAKE2b compression input is neither 0 nor 1.
+	errBlake2FInvalidFinalFlag = errors.New("invalid final flag")
+)
+
+// blake2F implements the BLAKE2b compression function F as a native contract
+// (EIP-152).
+type blake2F struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract,
+// the number of rounds requested.
+func (c *blake2F) RequiredGas(input []byte) uint64 {
+	// If the input is malformed, we can't calculate the gas, return 0 and let the
+	// actual call choke and fault.
+	if len(input) != blake2FInputLength {
+		return 0
+	}
+	return uint64(binary.BigEndian.Uint32(input[0:4])) * params.Blake2FRoundGas
+}
+
+func (c *blake2F) Run(input []byte) ([]byte, error) {
+	// Make sure the input is valid (correct length and final flag)
+	if len(input) != blake2FInputLength {
+		return nil, errBlake2FInvalidInputLength
+	}
+	if input[212] != 0 && input[212] != 1 {
+		return nil, errBlake2FInvalidFinalFlag
+	}
+	// Parse the input into the BLAKE2b call parameters
+	var (
+		rounds = binary.BigEndian.Uint32(input[0:4])
+		final  = input[212] == 1
+
+		h [8]uint64
+		m [16]uint64
+		t [2]uint64
+	)
+	for i := 0; i < 8; i++ {
+		offset := 4 + i*8
+		h[i] = binary.LittleEndian.Uint64(input[offset : offset+8])
+	}
+	for i := 0; i < 16; i++ {
+		offset := 68 + i*8
+		m[i] = binary.LittleEndian.Uint64(input[offset : offset+8])
+	}
+	t[0] = binary.LittleEndian.Uint64(input[196:204])
+	t[1] = binary.LittleEndian.Uint64(input[204:212])
+
+	// Execute the compression function, extract and return the result
+	blake2b.F(&h, m, t, final, rounds)
+
+	output := make([]byte, 64)
+	for i := 0; i < 8; i++ {
+		offset := i * 8
+		binary.LittleEndian.PutUint64(output[offset:offset+8], h[i])
+	}
+	return output, nil
+}
+
+// ed25519Verify implements ed25519 signature verification as a native contract.
+// The input is the 32 byte public key, followed by the 64 byte signature and the
+// signed message. It returns a true word if the signature is valid, a false one
+// otherwise.
+type ed25519Verify struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+//
+// This method does not require any overflow checking as the input size gas costs
+// required for anything significant is so high it's impossible to pay for.
+func (c *ed25519Verify) RequiredGas(input []byte) uint64 {
+	return uint64(len(input)+31)/32*params.Ed25519VerifyPerWordGas + params.Ed25519VerifyBaseGas
+}
+
+func (c *ed25519Verify) Run(input []byte) ([]byte, error) {
+	const prefix = ed25519.PublicKeySize + ed25519.SignatureSize
+
+	if len(input) < prefix {
+		return false32Byte, nil
+	}
+	pubkey, sig, msg := input[:ed25519.PublicKeySize], input[ed25519.PublicKeySize:prefix], input[prefix:]
+	if ed25519.Verify(ed25519.PublicKey(pubkey), msg, sig) {
+		return true32Byte, nil
+	}
+	return false32Byte, nil
+}
+
+// sha3512hash implements the SHA3-512 (FIPS 202) hash as a native contract.
+type sha3512hash struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+//
+// This method does not require any overflow checking as the input size gas costs
+// required for anything significant is so high it's impossible to pay for.
+func (c *sha3512hash) RequiredGas(input []byte) uint64 {
+	return uint64(len(input)+31)/32*params.Sha3512PerWordGas + params.Sha3512BaseGas
+}
+
+func (c *sha3512hash) Run(input []byte) ([]byte, error) {
+	hasher := sha3.New512()
+	hasher.Write(input)
+	return hasher.Sum(nil), nil
+}
@@ -19,9 +19,12 @@ package vm
 import (
 	"fmt"
 	"math/big"
+	"reflect"
 	"testing"
 
 	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/params"
+	"golang.org/x/crypto/ed25519"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -32,6 +35,20 @@ type precompiledTest struct {
 	noBenchmark     bool // Benchmark primarily the worst-cases
 }
 
+// blake2FTests are the test and benchmark data for the BLAKE2b compression
+// precompiled contract (EIP-152).
+var blake2FTests = []precompiledTest{
+	{
+		input:    "0000000048c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000001",
+		expected: "08c9bcf367e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d282e6ad7f520e511f6c3e2b8c68059b9442be0454267ce079217e1319cde05b",
+		name:     "vector 4",
+	}, {
+		input:    "0000000c48c9bdf267e6096a3ba7ca8485ae67bb2bf894fe72f36e3cf1361d5f3af54fa5d182e6ad7f520e511f6c3e2b8c68059b6bbd41fbabd9831f79217e1319cde05b61626300000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000300000000000000000000000000000001",
+		expected: "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+		name:     "vector 5",
+	},
+}
+
 // modexpTests are the test and benchmark data for the modexp precompiled contract.
 var modexpTests = []precompiledTest{
 	{
@@ -336,8 +353,16 @@ var bn256PairingTests = []precompiledTest{
 	},
 }
 
+// allPrecompiles contains every precompiled contract of the registry.
+var allPrecompiles = ActivePrecompiles(&params.ChainConfig{
+	ChainId:             big.NewInt(1),
+	ByzantiumBlock:      big.NewInt(0),
+	IstanbulBlock:       big.NewInt(0),
+	AdlnPrecompileBlock: big.NewInt(0),
+}, big.NewInt(0))
+
 func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
-	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
+	p := allPrecompiles[common.HexToAddress(addr)]
 	in := common.Hex2Bytes(test.input)
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
 		nil, new(big.Int), p.RequiredGas(in))
@@ -354,7 +379,7 @@ func benchmarkPrecompiled(addr string, test precompiledTest, bench *testing.B) {
 	if test.noBenchmark {
 		return
 	}
-	p := PrecompiledContractsByzantium[common.HexToAddress(addr)]
+	p := allPrecompiles[common.HexToAddress(addr)]
 	in := common.Hex2Bytes(test.input)
 	reqGas := p.RequiredGas(in)
 	contract := NewContract(AccountRef(common.HexToAddress("1337")),
@@ -481,3 +506,107 @@ func BenchmarkPrecompiledBn256Pairing(bench *testing.B) {
 		benchmarkPrecompiled("08", test, bench)
 	}
 }
+
+// Tests the sample inputs of the BLAKE2b compression precompile.
+func TestPrecompiledBlake2F(t *testing.T) {
+	for _, test := range blake2FTests {
+		testPrecompiled("09", test, t)
+	}
+}
+
+// Tests that malformed BLAKE2b compression inputs are rejected.
+func TestPrecompiledBlake2FFailure(t *testing.T) {
+	valid := common.Hex2Bytes(blake2FTests[1].input)
+
+	tests := []struct {
+		input []byte
+		err   error
+	}{
+		{nil, errBlake2FInvalidInputLength},
+		{valid[:len(valid)-1], errBlake2FInvalidInputLength},
+		{append(valid, 0), errBlake2FInvalidInputLength},
+		{append(append([]byte{}, valid[:len(valid)-1]...), 2), errBlake2FInvalidFinalFlag},
+	}
+	p := allPrecompiles[common.HexToAddress("09")]
+	for i, tt := range tests {
+		if _, err := p.Run(tt.input); err != tt.err {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.err)
+		}
+	}
+}
+
+// Tests the sample inputs of the SHA3-512 precompile.
+func TestPrecompiledSha3512(t *testing.T) {
+	testPrecompiled("0101", precompiledTest{
+		input:    "616263",
+		expected: "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0",
+		name:     "abc",
+	}, t)
+}
+
+// Tests that the ed25519 precompile accepts valid signatures only.
+func TestPrecompiledEd25519Verify(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	msg := []byte("adln precompile")
+	sig := ed25519.Sign(key, msg)
+
+	input := append(append(append([]byte{}, pub...), sig...), msg...)
+	tampered := append(append([]byte{}, input[:len(input)-1]...), 'x')
+
+	tests := []struct {
+		input    []byte
+		expected []byte
+	}{
+		{input, true32Byte},
+		{tampered, false32Byte},
+		{input[:ed25519.PublicKeySize+ed25519.SignatureSize-1], false32Byte},
+	}
+	p := allPrecompiles[common.HexToAddress("0100")]
+	for i, tt := range tests {
+		res, err := p.Run(tt.input)
+		if err != nil {
+			t.Fatalf("test %d: failed to run: %v", i, err)
+		}
+		if common.Bytes2Hex(res) != common.Bytes2Hex(tt.expected) {
+			t.Errorf("test %d: result mismatch: have %x, want %x", i, res, tt.expected)
+		}
+	}
+}
+
+// Tests that precompiled contracts are activated by their forks.
+func TestActivePrecompiles(t *testing.T) {
+	config := &params.ChainConfig{
+		ByzantiumBlock:      big.NewInt(10),
+		IstanbulBlock:       big.NewInt(20),
+		AdlnPrecompileBlock: big.NewInt(30),
+	}
+	tests := []struct {
+		number int64
+		count  int
+	}{
+		{0, 4}, {10, 8}, {20, 9}, {30, 11},
+	}
+	for _, tt := range tests {
+		if have := len(ActivePrecompiles(config, big.NewInt(tt.number))); have != tt.count {
+			t.Errorf("block %d: precompile count mismatch: have %d, want %d", tt.number, have, tt.count)
+		}
+		if have := len(ActivePrecompileAddresses(config, big.NewInt(tt.number))); have != tt.count {
+			t.Errorf("block %d: precompile address count mismatch: have %d, want %d", tt.number, have, tt.count)
+		}
+	}
+	// Blocks within the same fork must share the cached set
+	first, second := ActivePrecompiles(config, big.NewInt(10)), ActivePrecompiles(config, big.NewInt(19))
+	if reflect.ValueOf(first).Pointer() != reflect.ValueOf(second).Pointer() {
+		t.Errorf("precompile set of the same fork not cached")
+	}
+	// The registry is frozen once active sets were handed out
+	defer func() {
+		if err := recover(); err != "precompiled contract registered at 0000000000000000000000000000000000000fff after initialization" {
+			t.Errorf("late registration panic mismatch: have %v", err)
+		}
+	}()
+	RegisterPrecompiledContract(common.BytesToAddress([]byte{0x0f, 0xff}), &dataCopy{}, alwaysActive)
+}
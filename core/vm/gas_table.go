@@ -116,6 +116,12 @@ func gasReturnDataCopy(gt params.GasTable, evm *EVM, contract *Contract, stack *
 }
 
 func gasSStore(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	if evm.chainRules.IsEIP2718 {
+		return gasSStoreEIP2929(gt, evm, contract, stack, mem, memorySize)
+	}
+	if evm.chainRules.IsIstanbul {
+		return gasSStoreEIP2200(gt, evm, contract, stack, mem, memorySize)
+	}
 	var (
 		y, x = stack.Back(1), stack.Back(0)
 		val  = evm.StateDB.GetState(contract.Address(), common.BigToHash(x))
@@ -137,6 +143,121 @@ func gasSStore(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, m
 	}
 }
 
+// gasSStoreEIP2200 calculates the net gas metered SSTORE cost of EIP-2200.
+// Writes to a slot already modified in the current transaction (dirty slots)
+// and no-op writes only cost an SLOAD, while the full set or reset price is
+// charged for the first modification of a clean slot. Refunds are adjusted
+// when a dirty slot is cleared, recreated or reset to its original value.
+// Execution fails if no more than the call stipend is left, preventing
+// reentrancy through cheap storage writes in value transfers.
+func gasSStoreEIP2200(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	// If we fail the minimum gas availability invariant, fail (0)
+	if contract.Gas <= params.SstoreSentryGasEIP2200 {
+		return 0, ErrOutOfGas
+	}
+	// Gas sentry honoured, do the actual gas calculation based on the stored value
+	var (
+		y, x    = stack.Back(1), common.BigToHash(stack.Back(0))
+		current = evm.StateDB.GetState(contract.Address(), x)
+	)
+	value := common.BigToHash(y)
+
+	if current == value { // noop (1)
+		return params.SloadGasEIP2200, nil
+	}
+	original := evm.StateDB.GetCommittedState(contract.Address(), x)
+	if original == current {
+		if original == (common.Hash{}) { // create slot (2.1.1)
+			return params.SstoreSetGasEIP2200, nil
+		}
+		if value == (common.Hash{}) { // delete slot (2.1.2b)
+			evm.StateDB.AddRefund(params.SstoreClearsScheduleRefundEIP2200)
+		}
+		return params.SstoreResetGasEIP2200, nil // write existing slot (2.1.2)
+	}
+	if original != (common.Hash{}) {
+		if current == (common.Hash{}) { // recreate slot (2.2.1.1)
+			evm.StateDB.SubRefund(params.SstoreClearsScheduleRefundEIP2200)
+		} else if value == (common.Hash{}) { // delete slot (2.2.1.2)
+			evm.StateDB.AddRefund(params.SstoreClearsScheduleRefundEIP2200)
+		}
+	}
+	if original == value {
+		if original == (common.Hash{}) { // reset to original inexistent slot (2.2.2.1)
+			evm.StateDB.AddRefund(params.SstoreSetGasEIP2200 - params.SloadGasEIP2200)
+		} else { // reset to original existing slot (2.2.2.2)
+			evm.StateDB.AddRefund(params.SstoreResetGasEIP2200 - params.SloadGasEIP2200)
+		}
+	}
+	return params.SloadGasEIP2200, nil // dirty update (2.2)
+}
+
+// gasSStoreEIP2929 calculates the SSTORE cost of EIP-2200, repriced by EIP-2929:
+// the first access of a slot in a transaction costs an extra cold SLOAD, while
+// the reads previously charged at the SLOAD price cost a warm access.
+func gasSStoreEIP2929(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	// If we fail the minimum gas availability invariant, fail (0)
+	if contract.Gas <= params.SstoreSentryGasEIP2200 {
+		return 0, ErrOutOfGas
+	}
+	// Gas sentry honoured, charge the cold access and do the actual gas
+	// calculation based on the stored value
+	var (
+		y, x = stack.Back(1), common.BigToHash(stack.Back(0))
+		cost uint64
+	)
+	if _, slotOk := evm.StateDB.SlotInAccessList(contract.Address(), x); !slotOk {
+		cost = params.ColdSloadCostEIP2929
+		evm.StateDB.AddSlotToAccessList(contract.Address(), x)
+	}
+	var (
+		current = evm.StateDB.GetState(contract.Address(), x)
+		value   = common.BigToHash(y)
+	)
+	if current == value { // noop (1)
+		return cost + params.WarmStorageReadCostEIP2929, nil
+	}
+	original := evm.StateDB.GetCommittedState(contract.Address(), x)
+	if original == current {
+		if original == (common.Hash{}) { // create slot (2.1.1)
+			return cost + params.SstoreSetGasEIP2200, nil
+		}
+		if value == (common.Hash{}) { // delete slot (2.1.2b)
+			evm.StateDB.AddRefund(params.SstoreClearsScheduleRefundEIP2200)
+		}
+		return cost + (params.SstoreResetGasEIP2200 - params.ColdSloadCostEIP2929), nil // write existing slot (2.1.2)
+	}
+	if original != (common.Hash{}) {
+		if current == (common.Hash{}) { // recreate slot (2.2.1.1)
+			evm.StateDB.SubRefund(params.SstoreClearsScheduleRefundEIP2200)
+		} else if value == (common.Hash{}) { // delete slot (2.2.1.2)
+			evm.StateDB.AddRefund(params.SstoreClearsScheduleRefundEIP2200)
+		}
+	}
+	if original == value {
+		if original == (common.Hash{}) { // reset to original inexistent slot (2.2.2.1)
+			evm.StateDB.AddRefund(params.SstoreSetGasEIP2200 - params.WarmStorageReadCostEIP2929)
+		} else { // reset to original existing slot (2.2.2.2)
+			evm.StateDB.AddRefund((params.SstoreResetGasEIP2200 - params.ColdSloadCostEIP2929) - params.WarmStorageReadCostEIP2929)
+		}
+	}
+	return cost + params.WarmStorageReadCostEIP2929, nil // dirty update (2.2)
+}
+
+// accountAccessGas returns the gas for accessing an account, which is the given
+// fork specific price before EIP-2929, and the cold or warm access price after
+// it. Cold accounts are warmed up for the remainder of the transaction.
+func accountAccessGas(evm *EVM, address common.Address, gas uint64) uint64 {
+	if !evm.chainRules.IsEIP2718 {
+		return gas
+	}
+	if evm.StateDB.AddressInAccessList(address) {
+		return params.WarmStorageReadCostEIP2929
+	}
+	evm.StateDB.AddAddressToAccessList(address)
+	return params.ColdAccountAccessCostEIP2929
+}
+
 func makeGasLog(n uint64) gasFunc {
 	return func(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 		requestedSize, overflow := bigUint64(stack.Back(1))
@@ -222,7 +343,7 @@ func gasExtCodeCopy(gt params.GasTable, evm *EVM, contract *Contract, stack *Sta
 	}
 
 	var overflow bool
-	if gas, overflow = math.SafeAdd(gas, gt.ExtcodeCopy); overflow {
+	if gas, overflow = math.SafeAdd(gas, accountAccessGas(evm, common.BigToAddress(stack.Back(0)), gt.ExtcodeCopy)); overflow {
 		return 0, errGasUintOverflow
 	}
 
@@ -289,16 +410,52 @@ func gasCreate(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, m
 	return gas, nil
 }
 
+func gasCreate2(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	var overflow bool
+	gas, err := memoryGasCost(mem, memorySize)
+	if err != nil {
+		return 0, err
+	}
+	if gas, overflow = math.SafeAdd(gas, params.Create2Gas); overflow {
+		return 0, errGasUintOverflow
+	}
+	// The init code is hashed to derive the contract address
+	wordGas, overflow := bigUint64(stack.Back(2))
+	if overflow {
+		return 0, errGasUintOverflow
+	}
+	if wordGas, overflow = math.SafeMul(toWordSize(wordGas), params.Sha3WordGas); overflow {
+		return 0, errGasUintOverflow
+	}
+	if gas, overflow = math.SafeAdd(gas, wordGas); overflow {
+		return 0, errGasUintOverflow
+	}
+	return gas, nil
+}
+
 func gasBalance(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
-	return gt.Balance, nil
+	return accountAccessGas(evm, common.BigToAddress(stack.Back(0)), gt.Balance), nil
 }
 
 func gasExtCodeSize(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
-	return gt.ExtcodeSize, nil
+	return accountAccessGas(evm, common.BigToAddress(stack.Back(0)), gt.ExtcodeSize), nil
+}
+
+func gasExtCodeHash(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
+	return accountAccessGas(evm, common.BigToAddress(stack.Back(0)), gt.ExtcodeHash), nil
 }
 
 func gasSLoad(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
-	return gt.SLoad, nil
+	if !evm.chainRules.IsEIP2718 {
+		return gt.SLoad, nil
+	}
+	// Charge a cold read for the first access of the slot in the transaction
+	slot := common.BigToHash(stack.Back(0))
+	if _, slotOk := evm.StateDB.SlotInAccessList(contract.Address(), slot); slotOk {
+		return params.WarmStorageReadCostEIP2929, nil
+	}
+	evm.StateDB.AddSlotToAccessList(contract.Address(), slot)
+	return params.ColdSloadCostEIP2929, nil
 }
 
 func gasExp(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
@@ -316,10 +473,10 @@ func gasExp(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem
 
 func gasCall(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
 	var (
-		gas            = gt.Calls
 		transfersValue = stack.Back(2).Sign() != 0
 		address        = common.BigToAddress(stack.Back(1))
 		eip158         = evm.ChainConfig().IsEIP158(evm.BlockNumber)
+		gas            = accountAccessGas(evm, address, gt.Calls)
 	)
 	if eip158 {
 		if transfersValue && evm.StateDB.Empty(address) {
@@ -351,7 +508,7 @@ func gasCall(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem
 }
 
 func gasCallCode(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack, mem *Memory, memorySize uint64) (uint64, error) {
-	gas := gt.Calls
+	gas := accountAccessGas(evm, common.BigToAddress(stack.Back(1)), gt.Calls)
 	if stack.Back(2).Sign() != 0 {
 		gas += params.CallValueTransferGas
 	}
@@ -402,6 +559,11 @@ func gasSuicide(gt params.GasTable, evm *EVM, contract *Contract, stack *Stack,
 		}
 	}
 
+	// Sending the funds to a cold account costs an extra access (EIP-2929)
+	if address := common.BigToAddress(stack.Back(0)); evm.chainRules.IsEIP2718 && !evm.StateDB.AddressInAccessList(address) {
+		evm.StateDB.AddAddressToAccessList(address)
+		gas += params.ColdAccountAccessCostEIP2929
+	}
 	if !evm.StateDB.HasSuicided(contract.Address()) {
 		evm.StateDB.AddRefund(params.SuicideRefundGas)
 	}
@@ -414,7 +576,7 @@ func gasDelegateCall(gt params.GasTable, evm *EVM, contract *Contract, stack *St
 		return 0, err
 	}
 	var overflow bool
-	if gas, overflow = math.SafeAdd(gas, gt.Calls); overflow {
+	if gas, overflow = math.SafeAdd(gas, accountAccessGas(evm, common.BigToAddress(stack.Back(1)), gt.Calls)); overflow {
 		return 0, errGasUintOverflow
 	}
 
@@ -434,7 +596,7 @@ func gasStaticCall(gt params.GasTable, evm *EVM, contract *Contract, stack *Stac
 		return 0, err
 	}
 	var overflow bool
-	if gas, overflow = math.SafeAdd(gas, gt.Calls); overflow {
+	if gas, overflow = math.SafeAdd(gas, accountAccessGas(evm, common.BigToAddress(stack.Back(1)), gt.Calls)); overflow {
 		return 0, errGasUintOverflow
 	}
 
@@ -16,7 +16,18 @@
 
 package vm
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/common/hexutil"
+	"github.com/AdelineCoin/go-adln/core/state"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/params"
+)
 
 func TestMemoryGasCost(t *testing.T) {
 	//size := uint64(math.MaxUint64 - 64)
@@ -34,3 +45,129 @@ func TestMemoryGasCost(t *testing.T) {
 		t.Error("expected error")
 	}
 }
+
+var eip2200Tests = []struct {
+	original byte
+	gaspool  uint64
+	input    string
+	used     uint64
+	refund   uint64
+	failure  error
+}{
+	{0, math.MaxUint64, "0x60006000556000600055", 1612, 0, nil},                // 0 -> 0 -> 0
+	{0, math.MaxUint64, "0x60006000556001600055", 20812, 0, nil},               // 0 -> 0 -> 1
+	{0, math.MaxUint64, "0x60016000556000600055", 20812, 19200, nil},           // 0 -> 1 -> 0
+	{0, math.MaxUint64, "0x60016000556002600055", 20812, 0, nil},               // 0 -> 1 -> 2
+	{0, math.MaxUint64, "0x60016000556001600055", 20812, 0, nil},               // 0 -> 1 -> 1
+	{1, math.MaxUint64, "0x60006000556000600055", 5812, 15000, nil},            // 1 -> 0 -> 0
+	{1, math.MaxUint64, "0x60006000556001600055", 5812, 4200, nil},             // 1 -> 0 -> 1
+	{1, math.MaxUint64, "0x60006000556002600055", 5812, 0, nil},                // 1 -> 0 -> 2
+	{1, math.MaxUint64, "0x60026000556000600055", 5812, 15000, nil},            // 1 -> 2 -> 0
+	{1, math.MaxUint64, "0x60026000556003600055", 5812, 0, nil},                // 1 -> 2 -> 3
+	{1, math.MaxUint64, "0x60026000556001600055", 5812, 4200, nil},             // 1 -> 2 -> 1
+	{1, math.MaxUint64, "0x60026000556002600055", 5812, 0, nil},                // 1 -> 2 -> 2
+	{1, math.MaxUint64, "0x60016000556000600055", 5812, 15000, nil},            // 1 -> 1 -> 0
+	{1, math.MaxUint64, "0x60016000556002600055", 5812, 0, nil},                // 1 -> 1 -> 2
+	{1, math.MaxUint64, "0x60016000556001600055", 1612, 0, nil},                // 1 -> 1 -> 1
+	{0, math.MaxUint64, "0x600160005560006000556001600055", 40818, 19200, nil}, // 0 -> 1 -> 0 -> 1
+	{1, math.MaxUint64, "0x600060005560016000556000600055", 10818, 19200, nil}, // 1 -> 0 -> 1 -> 0
+	{1, 2306, "0x6001600055", 2306, 0, ErrOutOfGas},                            // 1 -> 1 (2300 sentry + 2xPUSH)
+	{1, 2307, "0x6001600055", 806, 0, nil},                                     // 1 -> 1 (2301 sentry + 2xPUSH)
+}
+
+// Tests the net gas metering of SSTORE operations after Istanbul (EIP-2200).
+func TestEIP2200(t *testing.T) {
+	config := *params.TestChainConfig
+	config.ConstantinopleBlock = big.NewInt(0)
+	config.EIP1014Block = big.NewInt(0)
+	config.IstanbulBlock = big.NewInt(0)
+
+	for i, tt := range eip2200Tests {
+		address := common.BytesToAddress([]byte("contract"))
+
+		db, _ := ethdb.NewMemDatabase()
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, hexutil.MustDecode(tt.input))
+		statedb.SetState(address, common.Hash{}, common.BytesToHash([]byte{tt.original}))
+		statedb.Finalise(true) // Push the state into the "original" slot
+
+		vmctx := Context{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: big.NewInt(0),
+		}
+		vmenv := NewEVM(vmctx, statedb, &config, Config{})
+
+		_, gas, err := vmenv.Call(AccountRef(common.Address{}), address, nil, tt.gaspool, new(big.Int))
+		if err != tt.failure {
+			t.Errorf("test %d: failure mismatch: have %v, want %v", i, err, tt.failure)
+		}
+		if used := tt.gaspool - gas; used != tt.used {
+			t.Errorf("test %d: gas used mismatch: have %v, want %v", i, used, tt.used)
+		}
+		if refund := vmenv.StateDB.GetRefund(); refund != tt.refund {
+			t.Errorf("test %d: gas refund mismatch: have %v, want %v", i, refund, tt.refund)
+		}
+	}
+}
+
+var eip2929Tests = []struct {
+	input    string
+	prepared bool // Whether the transaction's access list and the precompiles are warm
+	used     uint64
+}{
+	{"0x600054600054", false, 2206},                     // cold SLOAD, warm SLOAD
+	{"0x600054600054", true, 206},                       // SLOAD of a slot in the access list
+	{"0x60ff3160ff31", false, 2706},                     // cold BALANCE, warm BALANCE
+	{"0x60ff3160ff31", true, 206},                       // BALANCE of an account in the access list
+	{"0x600131600131", false, 2706},                     // BALANCE of a cold precompile
+	{"0x600131600131", true, 206},                       // BALANCE of a warm precompile
+	{"0x60ff3b60ff3f", false, 2706},                     // cold EXTCODESIZE, warm EXTCODEHASH
+	{"0x6001600055", false, 22106},                      // SSTORE to a cold clean slot
+	{"0x6001600055", true, 20006},                       // SSTORE to a warm clean slot
+	{"0x6000600060006000600060ff61fffff1", false, 2621}, // CALL to a cold account
+	{"0x6000600060006000600060ff61fffff1", true, 121},   // CALL to an account in the access list
+}
+
+// Tests the warm and cold access pricing of accounts and storage slots after
+// EIP-2929.
+func TestEIP2929(t *testing.T) {
+	config := *params.TestChainConfig
+	config.ConstantinopleBlock = big.NewInt(0)
+	config.EIP1014Block = big.NewInt(0)
+	config.IstanbulBlock = big.NewInt(0)
+	config.EIP2718Block = big.NewInt(0)
+
+	for i, tt := range eip2929Tests {
+		address := common.BytesToAddress([]byte("contract"))
+
+		db, _ := ethdb.NewMemDatabase()
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, hexutil.MustDecode(tt.input))
+		statedb.Finalise(true)
+
+		precompiles := ActivePrecompileAddresses(&config, big.NewInt(0))
+		if tt.prepared {
+			list := types.AccessList{{Address: common.BytesToAddress([]byte{0xff})}, {Address: address, StorageKeys: []common.Hash{{}}}}
+			statedb.PrepareAccessList(common.Address{}, &address, precompiles, list)
+		} else {
+			statedb.PrepareAccessList(common.Address{}, &address, nil, nil)
+		}
+		vmctx := Context{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: big.NewInt(0),
+		}
+		vmenv := NewEVM(vmctx, statedb, &config, Config{})
+
+		_, gas, err := vmenv.Call(AccountRef(common.Address{}), address, nil, math.MaxUint64, new(big.Int))
+		if err != nil {
+			t.Errorf("test %d: execution failed: %v", i, err)
+		}
+		if used := math.MaxUint64 - gas; used != tt.used {
+			t.Errorf("test %d: gas used mismatch: have %v, want %v", i, used, tt.used)
+		}
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"testing"
 
 	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/crypto"
 	"github.com/AdelineCoin/go-adln/params"
 )
 
@@ -425,3 +426,83 @@ func BenchmarkOpIsZero(b *testing.B) {
 	x := "FBCDEF090807060504030201ffffffffFBCDEF090807060504030201ffffffff"
 	opBenchmark(b, opIszero, x)
 }
+
+// Tests the CREATE2 address derivation and gas cost against the EIP-1014 vectors.
+func TestCreate2Addresses(t *testing.T) {
+	type testcase struct {
+		origin   string
+		salt     string
+		code     string
+		gas      uint64
+		expected string
+	}
+	for i, tt := range []testcase{
+		{
+			origin:   "0x0000000000000000000000000000000000000000",
+			salt:     "0x0000000000000000000000000000000000000000",
+			code:     "0x00",
+			gas:      32006,
+			expected: "0x4d1a2e2bb4f88f0250f26ffff098b0b30b26bf38",
+		},
+		{
+			origin:   "0xdeadbeef00000000000000000000000000000000",
+			salt:     "0x0000000000000000000000000000000000000000",
+			code:     "0x00",
+			gas:      32006,
+			expected: "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3",
+		},
+		{
+			origin:   "0xdeadbeef00000000000000000000000000000000",
+			salt:     "0xfeed000000000000000000000000000000000000",
+			code:     "0x00",
+			gas:      32006,
+			expected: "0xD04116cDd17beBE565EB2422F2497E06cC1C9833",
+		},
+		{
+			origin:   "0x0000000000000000000000000000000000000000",
+			salt:     "0x0000000000000000000000000000000000000000",
+			code:     "0xdeadbeef",
+			gas:      32006,
+			expected: "0x70f2b2914A2a4b783FaEFb75f459A580616Fcb5e",
+		},
+		{
+			origin:   "0x00000000000000000000000000000000deadbeef",
+			salt:     "0xcafebabe",
+			code:     "0xdeadbeef",
+			gas:      32006,
+			expected: "0x60f3f640a8508fC6a86d45DF051962668E1e8AC7",
+		},
+		{
+			origin:   "0x00000000000000000000000000000000deadbeef",
+			salt:     "0xcafebabe",
+			code:     "0xdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+			gas:      32012,
+			expected: "0x1d8bfDC5D46DC4f61D6b6115972536eBE6A8854C",
+		},
+		{
+			origin:   "0x0000000000000000000000000000000000000000",
+			salt:     "0x0000000000000000000000000000000000000000",
+			code:     "0x",
+			gas:      32000,
+			expected: "0xE33C0C7F7df4809055C3ebA6c09CFe4BaF1BD9e0",
+		},
+	} {
+		origin := common.BytesToAddress(common.FromHex(tt.origin))
+		salt := common.BytesToHash(common.FromHex(tt.salt))
+		code := common.FromHex(tt.code)
+
+		stack := newstack()
+		stack.push(big.NewInt(0))                // salt, irrelevant for the gas
+		stack.push(big.NewInt(int64(len(code)))) // size
+		stack.push(big.NewInt(0))                // memstart
+		stack.push(big.NewInt(0))                // value
+		gas, _ := gasCreate2(params.GasTable{}, nil, nil, stack, nil, 0)
+		if gas != tt.gas {
+			t.Errorf("test %d: gas mismatch: have %v, want %v", i, gas, tt.gas)
+		}
+		address := crypto.CreateAddress2(origin, salt, crypto.Keccak256(code))
+		if expected := common.HexToAddress(tt.expected); address != expected {
+			t.Errorf("test %d: address mismatch: have %x, want %x", i, address, expected)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+// ForkFunc reports whether a fork is active at the given block number of a
+// chain. The fork checks of params.ChainConfig can be used as method expressions,
+// e.g. (*params.ChainConfig).IsByzantium.
+type ForkFunc func(config *params.ChainConfig, num *big.Int) bool
+
+// precompile is an entry in the precompiled contract registry, binding a
+// contract to its address and to the fork activating it. The gas required
+// by a precompile is defined by its RequiredGas method.
+type precompile struct {
+	address  common.Address
+	contract PrecompiledContract
+	active   ForkFunc
+}
+
+// alwaysActive activates a precompiled contract from the genesis block.
+func alwaysActive(*params.ChainConfig, *big.Int) bool { return true }
+
+var (
+	registryLock sync.RWMutex
+
+	// registry contains every known precompiled contract along with the fork
+	// activating it. It is frozen as soon as the first active set is derived,
+	// so the cached sets below never go stale.
+	registry = []precompile{
+		{common.BytesToAddress([]byte{1}), &ecrecover{}, alwaysActive},
+		{common.BytesToAddress([]byte{2}), &sha256hash{}, alwaysActive},
+		{common.BytesToAddress([]byte{3}), &ripemd160hash{}, alwaysActive},
+		{common.BytesToAddress([]byte{4}), &dataCopy{}, alwaysActive},
+		{common.BytesToAddress([]byte{5}), &bigModExp{}, (*params.ChainConfig).IsByzantium},
+		{common.BytesToAddress([]byte{6}), &bn256Add{}, (*params.ChainConfig).IsByzantium},
+		{common.BytesToAddress([]byte{7}), &bn256ScalarMul{}, (*params.ChainConfig).IsByzantium},
+		{common.BytesToAddress([]byte{8}), &bn256Pairing{}, (*params.ChainConfig).IsByzantium},
+		{common.BytesToAddress([]byte{9}), &blake2F{}, (*params.ChainConfig).IsIstanbul},
+		{common.BytesToAddress([]byte{1, 0}), &ed25519Verify{}, (*params.ChainConfig).IsAdlnPrecompile},
+		{common.BytesToAddress([]byte{1, 1}), &sha3512hash{}, (*params.ChainConfig).IsAdlnPrecompile},
+	}
+	registryFrozen bool
+
+	// activeSets caches the precompiled contracts for every combination of
+	// activated forks seen so far, keyed by the bitset of active entries.
+	activeSets = make(map[string]*activeSet)
+)
+
+// activeSet is a set of precompiled contracts active at some point of a chain.
+type activeSet struct {
+	contracts map[common.Address]PrecompiledContract
+	addresses []common.Address
+}
+
+// RegisterPrecompiledContract adds a chain specific precompiled contract to
+// the registry, active from the fork reported by active. It is meant to be
+// called from init functions and panics if the address is already taken or
+// if the registry was already frozen by the creation of an EVM.
+func RegisterPrecompiledContract(address common.Address, contract PrecompiledContract, active ForkFunc) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if registryFrozen {
+		panic(fmt.Sprintf("precompiled contract registered at %x after initialization", address))
+	}
+	for _, p := range registry {
+		if p.address == address {
+			panic(fmt.Sprintf("precompiled contract already registered at %x", address))
+		}
+	}
+	registry = append(registry, precompile{address, contract, active})
+}
+
+// activeKey returns the bitset of registry entries active on the given chain
+// at the given block number. The registry lock must be held.
+func activeKey(config *params.ChainConfig, num *big.Int) string {
+	key := make([]byte, (len(registry)+7)/8)
+	for i, p := range registry {
+		if p.active(config, num) {
+			key[i/8] |= 1 << uint(i%8)
+		}
+	}
+	return string(key)
+}
+
+// activePrecompiles returns the cached set of precompiled contracts active on
+// the given chain at the given block number, freezing the registry.
+func activePrecompiles(config *params.ChainConfig, num *big.Int) *activeSet {
+	registryLock.RLock()
+	set := activeSets[activeKey(config, num)]
+	registryLock.RUnlock()
+
+	if set != nil {
+		return set
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	registryFrozen = true
+
+	key := activeKey(config, num)
+	if set = activeSets[key]; set != nil {
+		return set
+	}
+	set = &activeSet{contracts: make(map[common.Address]PrecompiledContract)}
+	for i, p := range registry {
+		if key[i/8]&(1<<uint(i%8)) != 0 {
+			set.contracts[p.address] = p.contract
+			set.addresses = append(set.addresses, p.address)
+		}
+	}
+	activeSets[key] = set
+	return set
+}
+
+// ActivePrecompiles returns the precompiled contracts active on the given
+// chain at the given block number. The returned map is shared and must not
+// be modified.
+func ActivePrecompiles(config *params.ChainConfig, num *big.Int) map[common.Address]PrecompiledContract {
+	return activePrecompiles(config, num).contracts
+}
+
+// ActivePrecompileAddresses returns the addresses of the precompiled contracts
+// active on the given chain at the given block number. The returned slice is
+// shared and must not be modified.
+func ActivePrecompileAddresses(config *params.ChainConfig, num *big.Int) []common.Address {
+	return activePrecompiles(config, num).addresses
+}
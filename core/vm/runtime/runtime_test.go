@@ -25,7 +25,9 @@ import (
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/core/state"
 	"github.com/AdelineCoin/go-adln/core/vm"
+	"github.com/AdelineCoin/go-adln/crypto"
 	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/params"
 )
 
 func TestDefaults(t *testing.T) {
@@ -117,6 +119,95 @@ func TestCall(t *testing.T) {
 	}
 }
 
+// constantinopleConfig is a chain config with CREATE2 and EXTCODEHASH active.
+var constantinopleConfig = &params.ChainConfig{
+	ChainId:             big.NewInt(1),
+	HomesteadBlock:      new(big.Int),
+	EIP150Block:         new(big.Int),
+	EIP155Block:         new(big.Int),
+	EIP158Block:         new(big.Int),
+	ByzantiumBlock:      new(big.Int),
+	ConstantinopleBlock: new(big.Int),
+	EIP1014Block:        new(big.Int),
+}
+
+// Tests that contracts deployed by CREATE2 end up at the addresses given by the
+// EIP-1014 examples.
+func TestCreate2(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	// Deploy the single byte init code 0x00 with a zero salt from 0xdeadbeef00..
+	deployer := common.HexToAddress("0xdeadbeef00000000000000000000000000000000")
+	statedb.SetCode(deployer, []byte{
+		byte(vm.PUSH1), 0, // salt
+		byte(vm.PUSH1), 1, // size
+		byte(vm.PUSH1), 0, // offset
+		byte(vm.PUSH1), 0, // value
+		byte(vm.CREATE2),
+		byte(vm.PUSH1), 0,
+		byte(vm.MSTORE),
+		byte(vm.PUSH1), 32,
+		byte(vm.PUSH1), 0,
+		byte(vm.RETURN),
+	})
+	ret, _, err := Call(deployer, nil, &Config{ChainConfig: constantinopleConfig, State: statedb})
+	if err != nil {
+		t.Fatalf("failed to run CREATE2: %v", err)
+	}
+	want := common.HexToAddress("0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3")
+	if have := common.BytesToAddress(ret); have != want {
+		t.Fatalf("created address mismatch: have %x, want %x", have, want)
+	}
+	if nonce := statedb.GetNonce(want); nonce != 1 {
+		t.Errorf("created account nonce mismatch: have %d, want %d", nonce, 1)
+	}
+}
+
+// Tests the EXTCODEHASH semantics of EIP-1052: zero for empty accounts, the
+// hash of the empty code for accounts without code, the code hash otherwise.
+func TestExtCodeHash(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	var (
+		missing  = common.HexToAddress("0x01")
+		empty    = common.HexToAddress("0x02")
+		funded   = common.HexToAddress("0x03")
+		contract = common.HexToAddress("0x04")
+		code     = []byte{byte(vm.PUSH1), 0, byte(vm.STOP)}
+	)
+	statedb.CreateAccount(empty)
+	statedb.AddBalance(funded, big.NewInt(1))
+	statedb.SetCode(contract, code)
+
+	tests := []struct {
+		addr common.Address
+		hash common.Hash
+	}{
+		{missing, common.Hash{}},
+		{empty, common.Hash{}},
+		{funded, crypto.Keccak256Hash(nil)},
+		{contract, crypto.Keccak256Hash(code)},
+	}
+	for i, tt := range tests {
+		ret, _, err := Execute(append(append([]byte{byte(vm.PUSH20)}, tt.addr.Bytes()...),
+			byte(vm.EXTCODEHASH),
+			byte(vm.PUSH1), 0,
+			byte(vm.MSTORE),
+			byte(vm.PUSH1), 32,
+			byte(vm.PUSH1), 0,
+			byte(vm.RETURN),
+		), nil, &Config{ChainConfig: constantinopleConfig, State: statedb})
+		if err != nil {
+			t.Fatalf("test %d: failed to run EXTCODEHASH: %v", i, err)
+		}
+		if have := common.BytesToHash(ret); have != tt.hash {
+			t.Errorf("test %d: hash mismatch: have %x, want %x", i, have, tt.hash)
+		}
+	}
+}
+
 func BenchmarkCall(b *testing.B) {
 	var definition = `[{"constant":true,"inputs":[],"name":"seller","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"abort","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"value","outputs":[{"name":"","type":"uint256"}],"type":"function"},{"constant":false,"inputs":[],"name":"refund","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"buyer","outputs":[{"name":"","type":"address"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmReceived","outputs":[],"type":"function"},{"constant":true,"inputs":[],"name":"state","outputs":[{"name":"","type":"uint8"}],"type":"function"},{"constant":false,"inputs":[],"name":"confirmPurchase","outputs":[],"type":"function"},{"inputs":[],"type":"constructor"},{"anonymous":false,"inputs":[],"name":"Aborted","type":"event"},{"anonymous":false,"inputs":[],"name":"PurchaseConfirmed","type":"event"},{"anonymous":false,"inputs":[],"name":"ItemReceived","type":"event"},{"anonymous":false,"inputs":[],"name":"Refunded","type":"event"}]`
 
@@ -19,14 +19,17 @@ package eth
 import (
 	"compress/gzip"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"math/big"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/common/hexutil"
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
 	"github.com/AdelineCoin/go-adln/core"
 	"github.com/AdelineCoin/go-adln/core/state"
 	"github.com/AdelineCoin/go-adln/core/types"
@@ -73,7 +76,7 @@ type PublicMinerAPI struct {
 
 // NewPublicMinerAPI create a new PublicMinerAPI instance.
 func NewPublicMinerAPI(e *Ethereum) *PublicMinerAPI {
-	agent := miner.NewRemoteAgent(e.BlockChain(), e.Engine())
+	agent := miner.NewRemoteAgent(e.BlockChain(), e.Engine(), e.config.MinerNotify)
 	e.Miner().Register(agent)
 
 	return &PublicMinerAPI{e, agent}
@@ -177,6 +180,41 @@ func (api *PrivateMinerAPI) SetExtra(extra string) (bool, error) {
 	return true, nil
 }
 
+// SetRecommitInterval updates the interval for the miner to recreate the mining
+// block with any newly arrived transactions. Zero disables periodic recommits.
+func (api *PrivateMinerAPI) SetRecommitInterval(interval int) {
+	api.e.Miner().SetRecommitInterval(time.Duration(interval) * time.Millisecond)
+}
+
+// StratumWorkers retrieves the statistics of the workers mining via the built-in
+// stratum server.
+func (api *PrivateMinerAPI) StratumWorkers() ([]miner.StratumWorker, error) {
+	if api.e.stratum == nil {
+		return nil, errors.New("stratum server not enabled")
+	}
+	return api.e.stratum.Workers(), nil
+}
+
+// SendBundle adds an ordered group of signed transactions to the bundle pool, to
+// be included by the local miner contiguously in the target block, or not at
+// all. Bundles are never propagated to the network. The returned hash uniquely
+// identifies the bundle.
+func (api *PrivateMinerAPI) SendBundle(encodedTxs []hexutil.Bytes, targetBlock hexutil.Uint64) (common.Hash, error) {
+	txs := make(types.Transactions, len(encodedTxs))
+	for i, encodedTx := range encodedTxs {
+		txs[i] = new(types.Transaction)
+		if err := txs[i].UnmarshalBinary(encodedTx); err != nil {
+			return common.Hash{}, fmt.Errorf("transaction %d: %v", i, err)
+		}
+	}
+	bundle := core.NewBundle(txs, uint64(targetBlock))
+	if err := api.e.bundlePool.Add(bundle); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted transaction bundle", "hash", bundle.Hash(), "target", bundle.TargetBlock, "txs", len(txs))
+	return bundle.Hash(), nil
+}
+
 // SetGasPrice sets the minimum accepted gas price for the miner.
 func (api *PrivateMinerAPI) SetGasPrice(gasPrice hexutil.Big) bool {
 	api.e.lock.Lock()
@@ -198,6 +236,51 @@ func (api *PrivateMinerAPI) GetHashrate() uint64 {
 	return uint64(api.e.miner.HashRate())
 }
 
+// devMineTimeout is the maximum time to wait for a block requested via evm_mine.
+const devMineTimeout = 10 * time.Second
+
+// PrivateDevAPI provides private RPC methods to drive the sealing of developer
+// chains from test harnesses.
+type PrivateDevAPI struct {
+	e      *Ethereum
+	engine *ethash.Ethash
+}
+
+// NewPrivateDevAPI creates a new RPC service which controls the sealing of a
+// developer chain.
+func NewPrivateDevAPI(e *Ethereum, engine *ethash.Ethash) *PrivateDevAPI {
+	return &PrivateDevAPI{e: e, engine: engine}
+}
+
+// Mine seals the block currently being mined right away, even if it contains no
+// transactions, and returns its hash once it's been imported.
+func (api *PrivateDevAPI) Mine() (common.Hash, error) {
+	if !api.e.IsMining() {
+		return common.Hash{}, errors.New("miner not running")
+	}
+	heads := make(chan core.ChainHeadEvent, 1)
+	sub := api.e.blockchain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	if err := api.engine.MineNext(devMineTimeout); err != nil {
+		return common.Hash{}, err
+	}
+	select {
+	case head := <-heads:
+		return head.Block.Hash(), nil
+	case err := <-sub.Err():
+		return common.Hash{}, err
+	case <-time.After(devMineTimeout):
+		return common.Hash{}, errors.New("timed out waiting for block")
+	}
+}
+
+// IncreaseTime shifts the clock of the developer chain forward by the given
+// number of seconds, returning the total shift.
+func (api *PrivateDevAPI) IncreaseTime(seconds int64) (int64, error) {
+	return api.engine.IncreaseTime(seconds)
+}
+
 // PrivateAdminAPI is the collection of Ethereum full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -312,11 +395,9 @@ func (api *PublicDebugAPI) DumpBlock(blockNr rpc.BlockNumber) (state.Dump, error
 		_, stateDb := api.eth.miner.Pending()
 		return stateDb.RawDump(), nil
 	}
-	var block *types.Block
-	if blockNr == rpc.LatestBlockNumber {
-		block = api.eth.blockchain.CurrentBlock()
-	} else {
-		block = api.eth.blockchain.GetBlockByNumber(uint64(blockNr))
+	block, err := api.eth.ApiBackend.BlockByNumber(context.Background(), blockNr)
+	if err != nil {
+		return state.Dump{}, err
 	}
 	if block == nil {
 		return state.Dump{}, fmt.Errorf("block #%d not found", blockNr)
@@ -23,6 +23,7 @@ import (
 	"github.com/AdelineCoin/go-adln/accounts"
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/common/math"
+	"github.com/AdelineCoin/go-adln/consensus"
 	"github.com/AdelineCoin/go-adln/core"
 	"github.com/AdelineCoin/go-adln/core/bloombits"
 	"github.com/AdelineCoin/go-adln/core/state"
@@ -65,6 +66,13 @@ func (b *EthApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNum
 	if blockNr == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock().Header(), nil
 	}
+	if blockNr == rpc.FinalizedBlockNumber {
+		engine, ok := b.eth.engine.(consensus.FinalityEngine)
+		if !ok {
+			return nil, consensus.ErrNoFinality
+		}
+		return engine.FinalizedHeader(b.eth.blockchain), nil
+	}
 	return b.eth.blockchain.GetHeaderByNumber(uint64(blockNr)), nil
 }
 
@@ -78,6 +86,13 @@ func (b *EthApiBackend) BlockByNumber(ctx context.Context, blockNr rpc.BlockNumb
 	if blockNr == rpc.LatestBlockNumber {
 		return b.eth.blockchain.CurrentBlock(), nil
 	}
+	if blockNr == rpc.FinalizedBlockNumber {
+		header, err := b.HeaderByNumber(ctx, blockNr)
+		if header == nil || err != nil {
+			return nil, err
+		}
+		return b.eth.blockchain.GetBlock(header.Hash(), header.Number.Uint64()), nil
+	}
 	return b.eth.blockchain.GetBlockByNumber(uint64(blockNr)), nil
 }
 
@@ -152,6 +167,10 @@ func (b *EthApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.AddLocal(signedTx)
 }
 
+func (b *EthApiBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, expiry uint64, release bool) error {
+	return b.eth.txPool.AddPrivate(signedTx, expiry, release)
+}
+
 func (b *EthApiBackend) GetPoolTransactions() (types.Transactions, error) {
 	pending, err := b.eth.txPool.Pending()
 	if err != nil {
@@ -196,6 +215,14 @@ func (b *EthApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *EthApiBackend) SuggestTipCap(ctx context.Context) (*big.Int, error) {
+	return b.gpo.SuggestTipCap(ctx)
+}
+
+func (b *EthApiBackend) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
+	return b.gpo.FeeHistory(ctx, blocks, lastBlock, rewardPercentiles)
+}
+
 func (b *EthApiBackend) ChainDb() ethdb.Database {
 	return b.eth.ChainDb()
 }
@@ -29,6 +29,7 @@ import (
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/common/hexutil"
 	"github.com/AdelineCoin/go-adln/consensus"
+	"github.com/AdelineCoin/go-adln/consensus/authority"
 	"github.com/AdelineCoin/go-adln/consensus/clique"
 	"github.com/AdelineCoin/go-adln/consensus/ethash"
 	"github.com/AdelineCoin/go-adln/core"
@@ -63,11 +64,13 @@ type Ethereum struct {
 	chainConfig *params.ChainConfig
 
 	// Channel for shutting down the service
-	shutdownChan  chan bool    // Channel for shutting down the ethereum
-	stopDbUpgrade func() error // stop chain db sequential key upgrade
+	shutdownChan  chan bool          // Channel for shutting down the ethereum
+	stopDbUpgrade func() error       // stop chain db sequential key upgrade
+	ethEntrySub   event.Subscription // Chain head subscription updating the "eth" ENR entry
 
 	// Handlers
 	txPool          *core.TxPool
+	bundlePool      *core.BundlePool
 	blockchain      *core.BlockChain
 	protocolManager *ProtocolManager
 	lesServer       LesServer
@@ -85,6 +88,7 @@ type Ethereum struct {
 	ApiBackend *EthApiBackend
 
 	miner     *miner.Miner
+	stratum   *miner.StratumServer
 	gasPrice  *big.Int
 	etherbase common.Address
 
@@ -164,12 +168,17 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
 	eth.txPool = core.NewTxPool(config.TxPool, eth.chainConfig, eth.blockchain)
+	eth.bundlePool = core.NewBundlePool(eth.chainConfig, eth.blockchain)
 
 	if eth.protocolManager, err = NewProtocolManager(eth.chainConfig, config.SyncMode, config.NetworkId, eth.eventMux, eth.txPool, eth.engine, eth.blockchain, chainDb); err != nil {
 		return nil, err
 	}
-	eth.miner = miner.New(eth, eth.chainConfig, eth.EventMux(), eth.engine)
+	eth.miner = miner.New(eth, eth.chainConfig, eth.EventMux(), eth.engine, config.MinerRecommit)
 	eth.miner.SetExtra(makeExtraData(config.ExtraData))
+	if config.StratumAddr != "" {
+		eth.stratum = miner.NewStratumServer(eth.blockchain, eth.engine, config.StratumDifficulty)
+		eth.miner.Register(eth.stratum)
+	}
 
 	eth.ApiBackend = &EthApiBackend{eth, nil}
 	gpoParams := config.GPO
@@ -216,6 +225,9 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *ethash.Config, chai
 	if chainConfig.Clique != nil {
 		return clique.New(chainConfig.Clique, db)
 	}
+	if chainConfig.Authority != nil {
+		return authority.New(chainConfig.Authority, db)
+	}
 	// Otherwise assume proof-of-work
 	switch {
 	case config.PowMode == ethash.ModeFake:
@@ -224,6 +236,9 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *ethash.Config, chai
 	case config.PowMode == ethash.ModeTest:
 		log.Warn("Ethash used in test mode")
 		return ethash.NewTester()
+	case config.PowMode == ethash.ModeDev:
+		log.Warn("Ethash used in developer mode")
+		return ethash.NewDeveloper(config.DevPeriod)
 	case config.PowMode == ethash.ModeShared:
 		log.Warn("Ethash used in shared mode")
 		return ethash.NewShared()
@@ -235,6 +250,8 @@ func CreateConsensusEngine(ctx *node.ServiceContext, config *ethash.Config, chai
 			DatasetDir:     config.DatasetDir,
 			DatasetsInMem:  config.DatasetsInMem,
 			DatasetsOnDisk: config.DatasetsOnDisk,
+			SealProofs:     config.SealProofs,
+			DagRoots:       config.DagRoots,
 		})
 		engine.SetThreads(-1) // Disable CPU mining
 		return engine
@@ -249,6 +266,15 @@ func (s *Ethereum) APIs() []rpc.API {
 	// Append any APIs exposed explicitly by the consensus engine
 	apis = append(apis, s.engine.APIs(s.BlockChain())...)
 
+	// Append the sealing controls of developer chains
+	if engine, ok := s.engine.(*ethash.Ethash); ok && engine.Developer() {
+		apis = append(apis, rpc.API{
+			Namespace: "evm",
+			Version:   "1.0",
+			Service:   NewPrivateDevAPI(s, engine),
+		})
+	}
+
 	// Append all the local APIs and return
 	return append(apis, []rpc.API{
 		{
@@ -348,6 +374,14 @@ func (s *Ethereum) StartMining(local bool) error {
 		}
 		clique.Authorize(eb, wallet.SignHash)
 	}
+	if authority, ok := s.engine.(*authority.Authority); ok {
+		wallet, err := s.accountManager.Find(accounts.Account{Address: eb})
+		if wallet == nil || err != nil {
+			log.Error("Etherbase account unavailable locally", "err", err)
+			return fmt.Errorf("validator missing: %v", err)
+		}
+		authority.Authorize(eb, wallet.SignHash)
+	}
 	if local {
 		// If local (CPU) mining is started, we can disable the transaction rejection
 		// mechanism introduced to speed sync times. CPU mining on mainnet is ludicrous
@@ -366,6 +400,7 @@ func (s *Ethereum) Miner() *miner.Miner { return s.miner }
 func (s *Ethereum) AccountManager() *accounts.Manager  { return s.accountManager }
 func (s *Ethereum) BlockChain() *core.BlockChain       { return s.blockchain }
 func (s *Ethereum) TxPool() *core.TxPool               { return s.txPool }
+func (s *Ethereum) BundlePool() *core.BundlePool       { return s.bundlePool }
 func (s *Ethereum) EventMux() *event.TypeMux           { return s.eventMux }
 func (s *Ethereum) Engine() consensus.Engine           { return s.engine }
 func (s *Ethereum) ChainDb() ethdb.Database            { return s.chainDb }
@@ -389,6 +424,11 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	// Start the bloom bits servicing goroutines
 	s.startBloomHandlers()
 
+	// Advertise the fork ID in the local node record
+	if ln := srvr.LocalNode(); ln != nil {
+		ln.Set(s.currentEthEntry())
+		s.startEthEntryUpdate(ln)
+	}
 	// Start the RPC service
 	s.netRPCService = ethapi.NewPublicNetAPI(srvr, s.NetVersion())
 
@@ -405,6 +445,12 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	if s.lesServer != nil {
 		s.lesServer.Start(srvr)
 	}
+	// Start serving external miners if requested
+	if s.stratum != nil {
+		if err := s.stratum.Listen(s.config.StratumAddr); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -414,6 +460,9 @@ func (s *Ethereum) Stop() error {
 	if s.stopDbUpgrade != nil {
 		s.stopDbUpgrade()
 	}
+	if s.ethEntrySub != nil {
+		s.ethEntrySub.Unsubscribe()
+	}
 	s.bloomIndexer.Close()
 	s.blockchain.Stop()
 	s.protocolManager.Stop()
@@ -421,7 +470,11 @@ func (s *Ethereum) Stop() error {
 		s.lesServer.Stop()
 	}
 	s.txPool.Stop()
+	s.bundlePool.Stop()
 	s.miner.Stop()
+	if s.stratum != nil {
+		s.stratum.Close()
+	}
 	s.eventMux.Stop()
 
 	s.chainDb.Close()
@@ -49,6 +49,7 @@ var DefaultConfig = Config{
 	TrieCache:     256,
 	TrieTimeout:   5 * time.Minute,
 	GasPrice:      big.NewInt(18 * params.Shannon),
+	MinerRecommit: 3 * time.Second,
 
 	TxPool: core.DefaultTxPoolConfig,
 	GPO: gasprice.Config{
@@ -84,8 +85,9 @@ type Config struct {
 	NoPruning bool
 
 	// Light client options
-	LightServ  int `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
-	LightPeers int `toml:",omitempty"` // Maximum number of LES client peers
+	LightServ           int  `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
+	LightPeers          int  `toml:",omitempty"` // Maximum number of LES client peers
+	LightServSealProofs bool `toml:",omitempty"` // Serve ethash seal proofs, building the datasets of recent epochs
 
 	// Database options
 	SkipBcVersionCheck bool `toml:"-"`
@@ -95,10 +97,16 @@ type Config struct {
 	TrieTimeout        time.Duration
 
 	// Mining-related options
-	Etherbase    common.Address `toml:",omitempty"`
-	MinerThreads int            `toml:",omitempty"`
-	ExtraData    []byte         `toml:",omitempty"`
-	GasPrice     *big.Int
+	Etherbase     common.Address `toml:",omitempty"`
+	MinerThreads  int            `toml:",omitempty"`
+	ExtraData     []byte         `toml:",omitempty"`
+	GasPrice      *big.Int
+	MinerRecommit time.Duration
+	MinerNotify   []string `toml:",omitempty"`
+
+	// Stratum server options
+	StratumAddr       string `toml:",omitempty"`
+	StratumDifficulty uint64 `toml:",omitempty"`
 
 	// Ethash options
 	Ethash ethash.Config
@@ -0,0 +1,64 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"github.com/AdelineCoin/go-adln/core"
+	"github.com/AdelineCoin/go-adln/core/forkid"
+	"github.com/AdelineCoin/go-adln/event"
+	"github.com/AdelineCoin/go-adln/p2p/discover"
+	"github.com/AdelineCoin/go-adln/rlp"
+)
+
+// ethEntry is the "eth" ENR entry which advertises eth protocol
+// on the discovery network.
+type ethEntry struct {
+	ForkID forkid.ID // Fork identifier per EIP-2124
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+// ENRKey implements enr.Entry.
+func (e ethEntry) ENRKey() string {
+	return "eth"
+}
+
+// startEthEntryUpdate keeps the "eth" entry of the local node record up to
+// date as the chain passes fork blocks, until the service is stopped.
+func (eth *Ethereum) startEthEntryUpdate(ln *discover.LocalNode) {
+	var newHead = make(chan core.ChainHeadEvent, 10)
+	eth.ethEntrySub = eth.blockchain.SubscribeChainHeadEvent(newHead)
+
+	go func(sub event.Subscription) {
+		for {
+			select {
+			case <-newHead:
+				ln.Set(eth.currentEthEntry())
+			case <-sub.Err():
+				return
+			case <-eth.shutdownChan:
+				return
+			}
+		}
+	}(eth.ethEntrySub)
+}
+
+// currentEthEntry returns the "eth" entry for the current chain head.
+func (eth *Ethereum) currentEthEntry() *ethEntry {
+	return &ethEntry{ForkID: forkid.NewID(eth.blockchain)}
+}
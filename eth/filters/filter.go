@@ -102,6 +102,19 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	}
 	head := header.Number.Uint64()
 
+	// Resolve the finalized block tag via the consensus engine of the backend
+	if f.begin == rpc.FinalizedBlockNumber.Int64() || f.end == rpc.FinalizedBlockNumber.Int64() {
+		final, err := f.backend.HeaderByNumber(ctx, rpc.FinalizedBlockNumber)
+		if final == nil || err != nil {
+			return nil, err
+		}
+		if f.begin == rpc.FinalizedBlockNumber.Int64() {
+			f.begin = final.Number.Int64()
+		}
+		if f.end == rpc.FinalizedBlockNumber.Int64() {
+			f.end = final.Number.Int64()
+		}
+	}
 	if f.begin == -1 {
 		f.begin = int64(head)
 	}
@@ -183,6 +196,11 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 	var logs []*types.Log
 
 	for ; f.begin <= int64(end); f.begin++ {
+		select {
+		case <-ctx.Done():
+			return logs, ctx.Err()
+		default:
+		}
 		header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(f.begin))
 		if header == nil || err != nil {
 			return logs, err
@@ -275,6 +293,12 @@ Logs:
 	return ret
 }
 
+// FilterLogs returns the logs matching the given address and topic criteria,
+// using the same positional topic semantics as log filters.
+func FilterLogs(logs []*types.Log, addresses []common.Address, topics [][]common.Hash) []*types.Log {
+	return filterLogs(logs, nil, nil, addresses, topics)
+}
+
 func bloomFilter(bloom types.Bloom, addresses []common.Address, topics [][]common.Hash) bool {
 	if len(addresses) > 0 {
 		var included bool
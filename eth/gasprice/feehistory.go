@@ -0,0 +1,172 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package gasprice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/AdelineCoin/go-adln/consensus/misc"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/rpc"
+)
+
+// maxFeeHistory is the maximum number of blocks that can be retrieved in a
+// single fee history request.
+const maxFeeHistory = 1024
+
+var (
+	errInvalidPercentile = errors.New("invalid reward percentile")
+	errMissingBlock      = errors.New("missing block")
+)
+
+// txGasAndReward is the gas used and the tip paid by a single transaction.
+type txGasAndReward struct {
+	gasUsed uint64
+	reward  *big.Int
+}
+
+type sortGasAndReward []txGasAndReward
+
+func (s sortGasAndReward) Len() int           { return len(s) }
+func (s sortGasAndReward) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s sortGasAndReward) Less(i, j int) bool { return s[i].reward.Cmp(s[j].reward) < 0 }
+
+// FeeHistory returns the fee market history of a range of blocks ending with
+// lastBlock: the base fee and gas usage ratio of each block, and the tips paid
+// at the requested percentiles of each block's gas usage, weighted by gas used.
+//
+// The returned base fees contain one more entry than the other slices, the base
+// fee of the block following the range. Blocks before the fee market report
+// a zero base fee.
+func (gpo *Oracle) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
+	if blocks < 1 {
+		return new(big.Int), nil, nil, nil, nil
+	}
+	if blocks > maxFeeHistory {
+		blocks = maxFeeHistory
+	}
+	for i, p := range rewardPercentiles {
+		if p < 0 || p > 100 || (i > 0 && p < rewardPercentiles[i-1]) {
+			return nil, nil, nil, nil, fmt.Errorf("%v: #%d: %f", errInvalidPercentile, i, p)
+		}
+	}
+	last, err := gpo.backend.HeaderByNumber(ctx, lastBlock)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+	if last == nil {
+		return nil, nil, nil, nil, errMissingBlock
+	}
+	if head := last.Number.Uint64(); uint64(blocks) > head+1 {
+		blocks = int(head + 1)
+	}
+	var (
+		oldest   = last.Number.Uint64() + 1 - uint64(blocks)
+		reward   = make([][]*big.Int, blocks)
+		baseFee  = make([]*big.Int, blocks+1)
+		gasRatio = make([]float64, blocks)
+		config   = gpo.backend.ChainConfig()
+	)
+	for i := 0; i < blocks; i++ {
+		number := oldest + uint64(i)
+
+		var header *types.Header
+		if number == last.Number.Uint64() {
+			header = last
+		} else if header, err = gpo.backend.HeaderByNumber(ctx, rpc.BlockNumber(number)); err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if header == nil {
+			return nil, nil, nil, nil, errMissingBlock
+		}
+		baseFee[i] = new(big.Int)
+		if header.BaseFee != nil {
+			baseFee[i].Set(header.BaseFee)
+		}
+		if header.GasLimit > 0 {
+			gasRatio[i] = float64(header.GasUsed) / float64(header.GasLimit)
+		}
+		if len(rewardPercentiles) > 0 {
+			if reward[i], err = gpo.blockRewards(ctx, header, rewardPercentiles); err != nil {
+				return nil, nil, nil, nil, err
+			}
+		}
+		if number == last.Number.Uint64() {
+			baseFee[blocks] = new(big.Int)
+			if config.IsEIP1559(new(big.Int).SetUint64(number + 1)) {
+				baseFee[blocks] = misc.CalcBaseFee(config, header)
+			}
+		}
+	}
+	if len(rewardPercentiles) == 0 {
+		reward = nil
+	}
+	return new(big.Int).SetUint64(oldest), reward, baseFee, gasRatio, nil
+}
+
+// blockRewards calculates the tips paid at the given percentiles of the gas used
+// in a block.
+func (gpo *Oracle) blockRewards(ctx context.Context, header *types.Header, percentiles []float64) ([]*big.Int, error) {
+	rewards := make([]*big.Int, len(percentiles))
+
+	block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(header.Number.Uint64()))
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errMissingBlock
+	}
+	if len(block.Transactions()) == 0 {
+		for i := range rewards {
+			rewards[i] = new(big.Int)
+		}
+		return rewards, nil
+	}
+	receipts, err := gpo.backend.GetReceipts(ctx, block.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) != len(block.Transactions()) {
+		return nil, errMissingBlock
+	}
+	sorted := make([]txGasAndReward, len(receipts))
+	for i, tx := range block.Transactions() {
+		gasUsed := receipts[i].CumulativeGasUsed
+		if i > 0 {
+			gasUsed -= receipts[i-1].CumulativeGasUsed
+		}
+		sorted[i] = txGasAndReward{gasUsed: gasUsed, reward: tx.EffectiveGasTip(block.BaseFee())}
+	}
+	sort.Sort(sortGasAndReward(sorted))
+
+	var txIndex int
+	sumGasUsed := sorted[0].gasUsed
+
+	for i, p := range percentiles {
+		threshold := uint64(float64(block.GasUsed()) * p / 100)
+		for sumGasUsed < threshold && txIndex < len(sorted)-1 {
+			txIndex++
+			sumGasUsed += sorted[txIndex].gasUsed
+		}
+		rewards[i] = sorted[txIndex].reward
+	}
+	return rewards, nil
+}
@@ -23,6 +23,7 @@ import (
 	"sync"
 
 	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus/misc"
 	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/internal/ethapi"
 	"github.com/AdelineCoin/go-adln/params"
@@ -73,8 +74,27 @@ func NewOracle(backend ethapi.Backend, params Config) *Oracle {
 	}
 }
 
-// SuggestPrice returns the recommended gas price.
+// SuggestPrice returns the recommended gas price: the recommended tip plus the
+// base fee of the next block once the fee market is active.
 func (gpo *Oracle) SuggestPrice(ctx context.Context) (*big.Int, error) {
+	tip, err := gpo.SuggestTipCap(ctx)
+	if err != nil {
+		return tip, err
+	}
+	head, err := gpo.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return tip, err
+	}
+	config := gpo.backend.ChainConfig()
+	if config.IsEIP1559(new(big.Int).Add(head.Number, common.Big1)) {
+		return new(big.Int).Add(tip, misc.CalcBaseFee(config, head)), nil
+	}
+	return tip, nil
+}
+
+// SuggestTipCap returns the recommended tip paid to the miner on top of the
+// base fee, which is the full gas price before the fee market is active.
+func (gpo *Oracle) SuggestTipCap(ctx context.Context) (*big.Int, error) {
 	gpo.cacheLock.RLock()
 	lastHead := gpo.lastHead
 	lastPrice := gpo.lastPrice
@@ -152,14 +172,20 @@ type getBlockPricesResult struct {
 	err   error
 }
 
-type transactionsByGasPrice []*types.Transaction
+type transactionsByGasTip struct {
+	txs     []*types.Transaction
+	baseFee *big.Int
+}
 
-func (t transactionsByGasPrice) Len() int           { return len(t) }
-func (t transactionsByGasPrice) Swap(i, j int)      { t[i], t[j] = t[j], t[i] }
-func (t transactionsByGasPrice) Less(i, j int) bool { return t[i].GasPrice().Cmp(t[j].GasPrice()) < 0 }
+func (t transactionsByGasTip) Len() int      { return len(t.txs) }
+func (t transactionsByGasTip) Swap(i, j int) { t.txs[i], t.txs[j] = t.txs[j], t.txs[i] }
+func (t transactionsByGasTip) Less(i, j int) bool {
+	return t.txs[i].EffectiveGasTip(t.baseFee).Cmp(t.txs[j].EffectiveGasTip(t.baseFee)) < 0
+}
 
-// getBlockPrices calculates the lowest transaction gas price in a given block
-// and sends it to the result channel. If the block is empty, price is nil.
+// getBlockPrices calculates the lowest transaction tip paid to the miner in a
+// given block (the gas price before EIP1559) and sends it to the result channel.
+// If the block is empty, price is nil.
 func (gpo *Oracle) getBlockPrices(ctx context.Context, signer types.Signer, blockNum uint64, ch chan getBlockPricesResult) {
 	block, err := gpo.backend.BlockByNumber(ctx, rpc.BlockNumber(blockNum))
 	if block == nil {
@@ -170,12 +196,12 @@ func (gpo *Oracle) getBlockPrices(ctx context.Context, signer types.Signer, bloc
 	blockTxs := block.Transactions()
 	txs := make([]*types.Transaction, len(blockTxs))
 	copy(txs, blockTxs)
-	sort.Sort(transactionsByGasPrice(txs))
+	sort.Sort(transactionsByGasTip{txs, block.BaseFee()})
 
 	for _, tx := range txs {
 		sender, err := types.Sender(signer, tx)
 		if err == nil && sender != block.Coinbase() {
-			ch <- getBlockPricesResult{tx.GasPrice(), nil}
+			ch <- getBlockPricesResult{tx.EffectiveGasTip(block.BaseFee()), nil}
 			return
 		}
 	}
@@ -30,6 +30,7 @@ import (
 	"github.com/AdelineCoin/go-adln/consensus"
 	"github.com/AdelineCoin/go-adln/consensus/misc"
 	"github.com/AdelineCoin/go-adln/core"
+	"github.com/AdelineCoin/go-adln/core/forkid"
 	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/eth/downloader"
 	"github.com/AdelineCoin/go-adln/eth/fetcher"
@@ -72,6 +73,7 @@ type ProtocolManager struct {
 	txpool      txPool
 	blockchain  *core.BlockChain
 	chainconfig *params.ChainConfig
+	forkFilter  forkid.Filter // Fork ID filter, constant across the lifetime of the node
 	maxPeers    int
 
 	downloader *downloader.Downloader
@@ -106,6 +108,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		txpool:      txpool,
 		blockchain:  blockchain,
 		chainconfig: config,
+		forkFilter:  forkid.NewFilter(blockchain),
 		peers:       newPeerSet(),
 		newPeerCh:   make(chan *peer),
 		noMorePeers: make(chan struct{}),
@@ -263,7 +266,7 @@ func (pm *ProtocolManager) handle(p *peer) error {
 		number  = head.Number.Uint64()
 		td      = pm.blockchain.GetTd(hash, number)
 	)
-	if err := p.Handshake(pm.networkId, td, hash, genesis.Hash()); err != nil {
+	if err := p.Handshake(pm.networkId, td, hash, genesis.Hash(), forkid.NewID(pm.blockchain), pm.forkFilter); err != nil {
 		p.Log().Debug("Ethereum handshake failed", "err", err)
 		return err
 	}
@@ -740,7 +743,10 @@ func (self *ProtocolManager) txBroadcastLoop() {
 	for {
 		select {
 		case event := <-self.txCh:
-			self.BroadcastTx(event.Tx.Hash(), event.Tx)
+			// Private transactions are only ever included by the local miner
+			if hash := event.Tx.Hash(); !self.txpool.IsPrivate(hash) {
+				self.BroadcastTx(hash, event.Tx)
+			}
 
 		// Err() channel will be closed when unsubscribing.
 		case <-self.txSub.Err():
@@ -0,0 +1,134 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/metrics"
+	"github.com/AdelineCoin/go-adln/node"
+)
+
+// healthCheckKey is the database key written and deleted to probe whether the
+// chain database is writable.
+var healthCheckKey = []byte("health-check")
+
+// databaseCheckInterval is the minimum time between two write probes of the
+// chain database. The health endpoints are open to unauthenticated clients, so
+// the outcome of the last probe is reported in between.
+const databaseCheckInterval = 5 * time.Second
+
+var (
+	errSyncing   = errors.New("chain synchronisation in progress")
+	errNotSynced = errors.New("no sync cycle completed and no peer head known")
+)
+
+// HealthChecks implements node.HealthService, returning the checks gating the
+// liveness and readiness of the Ethereum protocol.
+func (s *Ethereum) HealthChecks(config *node.HealthConfig) []node.HealthCheck {
+	checks := []node.HealthCheck{
+		{Name: "database", Check: databaseCheck(s.chainDb)},
+	}
+	if config.MaxHeadAge > 0 {
+		checks = append(checks, node.HealthCheck{Name: "head", Ready: true, Check: headAgeCheck(s.blockchain, config.MaxHeadAge)})
+	}
+	if config.NotSyncing {
+		checks = append(checks, node.HealthCheck{Name: "sync", Ready: true, Check: syncCheck(s.protocolManager)})
+	}
+	return checks
+}
+
+// databaseCheck creates a health check verifying that the database accepts
+// writes, probing it at most once per databaseCheckInterval.
+func databaseCheck(db ethdb.Database) metrics.Healthcheck {
+	var (
+		last time.Time // Time of the last write probe
+		err  error     // Outcome of the last write probe
+	)
+	return metrics.NewHealthcheckForced(func(h metrics.Healthcheck) {
+		if last.IsZero() || time.Since(last) >= databaseCheckInterval {
+			err, last = probeDatabase(db), time.Now()
+		}
+		if err != nil {
+			h.Unhealthy(err)
+			return
+		}
+		h.Healthy()
+	})
+}
+
+// probeDatabase writes and deletes a key to check whether the database accepts
+// writes.
+func probeDatabase(db ethdb.Database) error {
+	if err := db.Put(healthCheckKey, []byte{0x01}); err != nil {
+		return fmt.Errorf("database not writable: %v", err)
+	}
+	if err := db.Delete(healthCheckKey); err != nil {
+		return fmt.Errorf("database not writable: %v", err)
+	}
+	return nil
+}
+
+// headAgeCheck creates a health check verifying that the chain head block is
+// no older than maxAge.
+func headAgeCheck(chain *core.BlockChain, maxAge time.Duration) metrics.Healthcheck {
+	return metrics.NewHealthcheckForced(func(h metrics.Healthcheck) {
+		head := chain.CurrentBlock()
+		if age := time.Since(time.Unix(head.Time().Int64(), 0)); age > maxAge {
+			h.Unhealthy(fmt.Errorf("head block #%d is %v old, max %v", head.NumberU64(), common.PrettyDuration(age), maxAge))
+			return
+		}
+		h.Healthy()
+	})
+}
+
+// syncCheck creates a health check verifying that the downloader is neither busy
+// synchronising the chain nor aware of blocks it didn't import yet. As a freshly
+// started node knows of no blocks at all, it is only considered synced once a
+// sync cycle completed, or a peer reported a head not ahead of the local one.
+func syncCheck(pm *ProtocolManager) metrics.Healthcheck {
+	return metrics.NewHealthcheckForced(func(h metrics.Healthcheck) {
+		d := pm.downloader
+		if progress := d.Progress(); d.Synchronising() || progress.CurrentBlock < progress.HighestBlock {
+			h.Unhealthy(errSyncing)
+			return
+		}
+		if atomic.LoadUint32(&pm.acceptTxs) == 0 && !pm.caughtUp() {
+			h.Unhealthy(errNotSynced)
+			return
+		}
+		h.Healthy()
+	})
+}
+
+// caughtUp reports whether the best peer's head is known and not ahead of the
+// local chain head.
+func (pm *ProtocolManager) caughtUp() bool {
+	best := pm.peers.BestPeer()
+	if best == nil {
+		return false
+	}
+	head := pm.blockchain.CurrentBlock()
+	_, td := best.Head()
+	return td.Cmp(pm.blockchain.GetTd(head.Hash(), head.NumberU64())) <= 0
+}
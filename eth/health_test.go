@@ -0,0 +1,90 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/eth/downloader"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/p2p"
+	"github.com/AdelineCoin/go-adln/p2p/discover"
+)
+
+// Tests that a freshly started node without peers isn't reported as synced, as
+// it doesn't know of any blocks to sync yet.
+func TestSyncCheck(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	defer pm.Stop()
+
+	check := syncCheck(pm)
+	if check.Check(); check.Error() != errNotSynced {
+		t.Fatalf("fresh node: have %v, want %v", check.Error(), errNotSynced)
+	}
+	// A peer ahead of the local chain must not make the node synced
+	genesis := pm.blockchain.Genesis()
+	td := pm.blockchain.GetTd(genesis.Hash(), 0)
+
+	p := pm.newPeer(63, p2p.NewPeer(discover.NodeID{1}, "peer", nil), nil)
+	p.head, p.td = genesis.Hash(), new(big.Int).Add(td, big.NewInt(1))
+	if err := pm.peers.Register(p); err != nil {
+		t.Fatalf("failed to register peer: %v", err)
+	}
+	if check.Check(); check.Error() != errNotSynced {
+		t.Fatalf("peer ahead: have %v, want %v", check.Error(), errNotSynced)
+	}
+	// A peer at the local head proves the node is synced
+	p.td = td
+	if check.Check(); check.Error() != nil {
+		t.Fatalf("peer at head: have %v, want nil", check.Error())
+	}
+	// Completing a sync cycle makes the node synced without peers too
+	pm.peers.Unregister(p.id)
+	atomic.StoreUint32(&pm.acceptTxs, 1)
+	if check.Check(); check.Error() != nil {
+		t.Fatalf("sync cycle done: have %v, want nil", check.Error())
+	}
+}
+
+// countingDatabase is an in-memory database counting the writes it receives.
+type countingDatabase struct {
+	*ethdb.MemDatabase
+	puts int
+}
+
+func (db *countingDatabase) Put(key []byte, value []byte) error {
+	db.puts++
+	return db.MemDatabase.Put(key, value)
+}
+
+// Tests that repeated database checks don't write to the database on each run.
+func TestDatabaseCheckInterval(t *testing.T) {
+	mem, _ := ethdb.NewMemDatabase()
+	db := &countingDatabase{MemDatabase: mem}
+
+	check := databaseCheck(db)
+	for i := 0; i < 10; i++ {
+		if check.Check(); check.Error() != nil {
+			t.Fatalf("run %d: database check failed: %v", i, check.Error())
+		}
+	}
+	if db.puts != 1 {
+		t.Fatalf("database probed %d times, want 1", db.puts)
+	}
+}
@@ -290,6 +290,8 @@ type Tracer struct {
 	contractWrapper *contractWrapper // Wrapper around the contract object
 	dbWrapper       *dbWrapper       // Wrapper around the VM environment
 
+	precompiles map[common.Address]vm.PrecompiledContract // Precompiled contracts active in the traced block
+
 	pcValue    *uint   // Swappable pc value wrapped by a log accessor
 	gasValue   *uint   // Swappable gas value wrapped by a log accessor
 	costValue  *uint   // Swappable cost value wrapped by a log accessor
@@ -366,7 +368,11 @@ func New(code string) (*Tracer, error) {
 		return 1
 	})
 	tracer.vm.PushGlobalGoFunction("isPrecompiled", func(ctx *duktape.Context) int {
-		_, ok := vm.PrecompiledContractsByzantium[common.BytesToAddress(popSlice(ctx))]
+		precompiles := tracer.precompiles
+		if precompiles == nil {
+			precompiles = vm.PrecompiledContractsByzantium
+		}
+		_, ok := precompiles[common.BytesToAddress(popSlice(ctx))]
 		ctx.PushBoolean(ok)
 		return 1
 	})
@@ -515,6 +521,7 @@ func (jst *Tracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost
 		// Initialize the context if it wasn't done yet
 		if !jst.inited {
 			jst.ctx["block"] = env.BlockNumber.Uint64()
+			jst.precompiles = vm.ActivePrecompiles(env.ChainConfig(), env.BlockNumber)
 			jst.inited = true
 		}
 		// If tracing was interrupted, set the error and stop
@@ -28,7 +28,6 @@ import (
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/common/hexutil"
 	"github.com/AdelineCoin/go-adln/core/types"
-	"github.com/AdelineCoin/go-adln/rlp"
 	"github.com/AdelineCoin/go-adln/rpc"
 )
 
@@ -469,7 +468,7 @@ func (ec *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64
 // If the transaction was a contract creation use the TransactionReceipt method to get the
 // contract address after the transaction has been mined.
 func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,109 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"html"
+	"net/http"
+)
+
+// serveExplorer serves the query explorer, querying the GraphQL endpoint of the
+// same node.
+func serveExplorer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "only GET allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(explorer)
+}
+
+// explorer is the query explorer page. It is entirely self contained, so that
+// the UI of a node doesn't depend on any third party to load its assets.
+var explorer = []byte(`
+<!DOCTYPE html>
+<html>
+	<head>
+		<meta charset="utf-8">
+		<title>GraphQL explorer</title>
+		<style>
+			body { margin: 0; height: 100vh; display: flex; font-family: sans-serif; font-size: 13px; }
+			.pane { flex: 1; display: flex; flex-direction: column; border-right: 1px solid #ccc; min-width: 0; }
+			.pane h3 { margin: 0; padding: 6px 8px; background: #f3f3f3; border-bottom: 1px solid #ccc; font-size: 13px; }
+			textarea, pre { flex: 1; margin: 0; padding: 8px; border: 0; font-family: monospace; font-size: 13px; overflow: auto; resize: none; }
+			#variables { flex: 0 0 25%; border-top: 1px solid #ccc; }
+			button { margin-left: 8px; }
+		</style>
+	</head>
+	<body>
+		<div class="pane">
+			<h3>Query <button id="run" title="Ctrl-Enter">Run</button></h3>
+			<textarea id="query" spellcheck="false">{
+  block {
+    number
+    hash
+  }
+}</textarea>
+			<h3>Variables</h3>
+			<textarea id="variables" spellcheck="false"></textarea>
+		</div>
+		<div class="pane">
+			<h3>Result</h3>
+			<pre id="result"></pre>
+		</div>
+		<div class="pane">
+			<h3>Schema</h3>
+			<pre>` + html.EscapeString(schema) + `</pre>
+		</div>
+		<script>
+			function run() {
+				var result = document.getElementById("result");
+				var body = {query: document.getElementById("query").value};
+				var variables = document.getElementById("variables").value.trim();
+				try {
+					if (variables) {
+						body.variables = JSON.parse(variables);
+					}
+				} catch (error) {
+					result.textContent = "Invalid variables: " + error;
+					return;
+				}
+				var req = new XMLHttpRequest();
+				req.open("POST", "` + endpointPath + `");
+				req.withCredentials = true;
+				req.onload = function () {
+					try {
+						result.textContent = JSON.stringify(JSON.parse(req.responseText), null, 2);
+					} catch (error) {
+						result.textContent = req.responseText;
+					}
+				};
+				req.onerror = function () {
+					result.textContent = "Request failed";
+				};
+				req.send(JSON.stringify(body));
+			}
+			document.getElementById("run").onclick = run;
+			document.onkeydown = function (event) {
+				if (event.ctrlKey && event.key === "Enter") {
+					run();
+				}
+			};
+		</script>
+	</body>
+</html>
+`)
@@ -0,0 +1,802 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package graphql provides a GraphQL interface to Ethereum node data.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+
+	ethereum "github.com/AdelineCoin/go-adln"
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/common/hexutil"
+	"github.com/AdelineCoin/go-adln/core"
+	"github.com/AdelineCoin/go-adln/core/state"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/eth/filters"
+	"github.com/AdelineCoin/go-adln/internal/ethapi"
+	"github.com/AdelineCoin/go-adln/rpc"
+)
+
+const (
+	maxBlocksRange = 1024  // Maximum number of blocks returned by a single blocks query
+	maxLogsRange   = 10000 // Maximum number of blocks searched by a single logs query
+	maxLogs        = 10000 // Maximum number of logs returned by a single logs query
+)
+
+var (
+	errMissingState       = errors.New("state not available")
+	errMissingTd          = errors.New("total difficulty not available")
+	errInvalidBlockRange  = errors.New("invalid block range")
+	errInvalidBlockNumber = errors.New("invalid block number")
+	errBlockRangeTooLarge = errors.New("block range too large")
+	errTooManyLogs        = errors.New("too many logs")
+	errNoLogFilter        = errors.New("log filtering not supported by backend")
+)
+
+// Account represents an Ethereum account at a particular block.
+type Account struct {
+	backend     ethapi.Backend
+	address     common.Address
+	blockNumber rpc.BlockNumber
+}
+
+// getState fetches the state database the account is resolved against.
+func (a *Account) getState(ctx context.Context) (*state.StateDB, error) {
+	state, _, err := a.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil {
+		return nil, errMissingState
+	}
+	return state, nil
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.GetBalance(a.address)), state.Error()
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (hexutil.Uint64, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(state.GetNonce(a.address)), state.Error()
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(state.GetCode(a.address)), state.Error()
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.GetState(a.address, args.Slot), state.Error()
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     ethapi.Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context) *Account {
+	return &Account{
+		backend:     l.backend,
+		address:     l.log.Address,
+		blockNumber: rpc.BlockNumber(l.log.BlockNumber),
+	}
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(l.log.Data)
+}
+
+// Transaction represents an Ethereum transaction. The backend and hash are
+// mandatory, all other fields are lazily resolved.
+type Transaction struct {
+	backend ethapi.Backend
+	hash    common.Hash
+
+	lock  sync.Mutex
+	tx    *types.Transaction
+	block *Block
+	index uint64
+}
+
+// resolve returns the internal transaction object, fetching it from the chain
+// or the transaction pool if needed.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.tx != nil {
+		return t.tx, nil
+	}
+	tx, blockHash, _, index := core.GetTransaction(t.backend.ChainDb(), t.hash)
+	if tx == nil {
+		t.tx = t.backend.GetPoolTransaction(t.hash)
+		return t.tx, nil
+	}
+	block, err := t.backend.GetBlock(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block != nil {
+		t.block = &Block{backend: t.backend, block: block}
+	}
+	t.tx, t.index = tx, index
+	return t.tx, nil
+}
+
+// getBlock returns the block containing the transaction, or nil if it is still
+// pending.
+func (t *Transaction) getBlock(ctx context.Context) (*Block, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+// account returns an account resolved against the state of the block containing
+// the transaction, or against the pending state if it was not yet mined.
+func (t *Transaction) account(ctx context.Context, address common.Address) (*Account, error) {
+	block, err := t.getBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	number := rpc.PendingBlockNumber
+	if block != nil {
+		number = rpc.BlockNumber(block.block.NumberU64())
+	}
+	return &Account{backend: t.backend, address: address, blockNumber: number}, nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(tx.Data()), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Gas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) MaxFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.Type() == types.LegacyTxType {
+		return nil, err
+	}
+	return (*hexutil.Big)(tx.GasFeeCap()), nil
+}
+
+func (t *Transaction) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.Type() == types.LegacyTxType {
+		return nil, err
+	}
+	return (*hexutil.Big)(tx.GasTipCap()), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (hexutil.Uint64, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return hexutil.Uint64(tx.Nonce()), nil
+}
+
+func (t *Transaction) Type(ctx context.Context) (int32, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return int32(tx.Type()), nil
+}
+
+func (t *Transaction) To(ctx context.Context) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.To() == nil {
+		return nil, err
+	}
+	return t.account(ctx, *tx.To())
+}
+
+func (t *Transaction) From(ctx context.Context) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	block, err := t.getBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signer := types.LatestSigner(t.backend.ChainConfig())
+	if block != nil {
+		signer = types.MakeSigner(t.backend.ChainConfig(), block.block.Number())
+	}
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	return t.account(ctx, from)
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	return t.getBlock(ctx)
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	block, err := t.getBlock(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+func (t *Transaction) Receipt(ctx context.Context) (*Receipt, error) {
+	block, err := t.getBlock(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	receipts, err := block.getReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.index >= uint64(len(receipts)) {
+		return nil, nil
+	}
+	return &Receipt{transaction: t, receipt: receipts[t.index]}, nil
+}
+
+// Receipt represents the outcome of executing a mined transaction.
+type Receipt struct {
+	transaction *Transaction
+	receipt     *types.Receipt
+}
+
+func (r *Receipt) Status(ctx context.Context) *hexutil.Uint64 {
+	if len(r.receipt.PostState) > 0 {
+		return nil
+	}
+	status := hexutil.Uint64(r.receipt.Status)
+	return &status
+}
+
+func (r *Receipt) GasUsed(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.receipt.GasUsed)
+}
+
+func (r *Receipt) CumulativeGasUsed(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(r.receipt.CumulativeGasUsed)
+}
+
+func (r *Receipt) CreatedContract(ctx context.Context) (*Account, error) {
+	if r.receipt.ContractAddress == (common.Address{}) {
+		return nil, nil
+	}
+	return r.transaction.account(ctx, r.receipt.ContractAddress)
+}
+
+func (r *Receipt) Logs(ctx context.Context) []*Log {
+	logs := make([]*Log, 0, len(r.receipt.Logs))
+	for _, log := range r.receipt.Logs {
+		logs = append(logs, &Log{backend: r.transaction.backend, transaction: r.transaction, log: log})
+	}
+	return logs
+}
+
+func (r *Receipt) LogsBloom(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(r.receipt.Bloom.Bytes())
+}
+
+// Block represents an Ethereum block. The backend and block are mandatory, the
+// receipts are lazily resolved.
+type Block struct {
+	backend ethapi.Backend
+	block   *types.Block
+
+	lock     sync.Mutex
+	receipts types.Receipts
+}
+
+// getReceipts returns the receipts of all transactions in the block.
+func (b *Block) getReceipts(ctx context.Context) (types.Receipts, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.receipts == nil {
+		receipts, err := b.backend.GetReceipts(ctx, b.block.Hash())
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = receipts
+	}
+	return b.receipts, nil
+}
+
+// transaction wraps the transaction at the given index, which must be in range.
+func (b *Block) transaction(index int) *Transaction {
+	tx := b.block.Transactions()[index]
+	return &Transaction{
+		backend: b.backend,
+		hash:    tx.Hash(),
+		tx:      tx,
+		block:   b,
+		index:   uint64(index),
+	}
+}
+
+func (b *Block) Number(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.block.NumberU64())
+}
+
+func (b *Block) Hash(ctx context.Context) common.Hash {
+	return b.block.Hash()
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	if b.block.NumberU64() == 0 {
+		return nil, nil
+	}
+	parent, err := b.backend.GetBlock(ctx, b.block.ParentHash())
+	if err != nil || parent == nil {
+		return nil, err
+	}
+	return &Block{backend: b.backend, block: parent}, nil
+}
+
+func (b *Block) Nonce(ctx context.Context) hexutil.Bytes {
+	nonce := b.block.Header().Nonce
+	return hexutil.Bytes(nonce[:])
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) common.Hash {
+	return b.block.TxHash()
+}
+
+func (b *Block) StateRoot(ctx context.Context) common.Hash {
+	return b.block.Root()
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) common.Hash {
+	return b.block.ReceiptHash()
+}
+
+func (b *Block) Miner(ctx context.Context) *Account {
+	return b.Account(ctx, struct{ Address common.Address }{b.block.Coinbase()})
+}
+
+func (b *Block) ExtraData(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(b.block.Extra())
+}
+
+func (b *Block) GasLimit(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.block.GasLimit())
+}
+
+func (b *Block) GasUsed(ctx context.Context) hexutil.Uint64 {
+	return hexutil.Uint64(b.block.GasUsed())
+}
+
+func (b *Block) BaseFeePerGas(ctx context.Context) *hexutil.Big {
+	if baseFee := b.block.BaseFee(); baseFee != nil {
+		return (*hexutil.Big)(baseFee)
+	}
+	return nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*b.block.Time())
+}
+
+func (b *Block) LogsBloom(ctx context.Context) hexutil.Bytes {
+	return hexutil.Bytes(b.block.Bloom().Bytes())
+}
+
+func (b *Block) MixHash(ctx context.Context) common.Hash {
+	return b.block.MixDigest()
+}
+
+func (b *Block) Difficulty(ctx context.Context) hexutil.Big {
+	return hexutil.Big(*b.block.Difficulty())
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	td := b.backend.GetTd(b.block.Hash())
+	if td == nil {
+		return hexutil.Big{}, errMissingTd
+	}
+	return hexutil.Big(*td), nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) int32 {
+	return int32(len(b.block.Transactions()))
+}
+
+func (b *Block) Transactions(ctx context.Context) []*Transaction {
+	txs := make([]*Transaction, len(b.block.Transactions()))
+	for i := range txs {
+		txs[i] = b.transaction(i)
+	}
+	return txs
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) *Transaction {
+	if args.Index < 0 || int(args.Index) >= len(b.block.Transactions()) {
+		return nil
+	}
+	return b.transaction(int(args.Index))
+}
+
+// BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
+// a block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+	Topics    *[][]common.Hash  // restricts matches to events with the given topic prefixes
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	var (
+		addresses []common.Address
+		topics    [][]common.Hash
+	)
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	receipts, err := b.getReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var logs []*Log
+	for i, receipt := range receipts {
+		if i >= len(b.block.Transactions()) {
+			break
+		}
+		matches := filters.FilterLogs(receipt.Logs, addresses, topics)
+		if len(matches) == 0 {
+			continue
+		}
+		tx := b.transaction(i)
+		for _, log := range matches {
+			logs = append(logs, &Log{backend: b.backend, transaction: tx, log: log})
+		}
+	}
+	return logs, nil
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) *Account {
+	return &Account{
+		backend:     b.backend,
+		address:     args.Address,
+		blockNumber: rpc.BlockNumber(b.block.NumberU64()),
+	}
+}
+
+// Pending represents the current pending state.
+type Pending struct {
+	backend ethapi.Backend
+}
+
+func (p *Pending) TransactionCount(ctx context.Context) (int32, error) {
+	txs, err := p.backend.GetPoolTransactions()
+	return int32(len(txs)), err
+}
+
+func (p *Pending) Transactions(ctx context.Context) ([]*Transaction, error) {
+	txs, err := p.backend.GetPoolTransactions()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		ret = append(ret, &Transaction{backend: p.backend, hash: tx.Hash(), tx: tx})
+	}
+	return ret, nil
+}
+
+func (p *Pending) Account(ctx context.Context, args struct{ Address common.Address }) *Account {
+	return &Account{
+		backend:     p.backend,
+		address:     args.Address,
+		blockNumber: rpc.PendingBlockNumber,
+	}
+}
+
+// SyncState represents the synchronisation status of the node.
+type SyncState struct {
+	progress ethereum.SyncProgress
+}
+
+func (s *SyncState) StartingBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.progress.StartingBlock)
+}
+
+func (s *SyncState) CurrentBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.progress.CurrentBlock)
+}
+
+func (s *SyncState) HighestBlock() hexutil.Uint64 {
+	return hexutil.Uint64(s.progress.HighestBlock)
+}
+
+func (s *SyncState) PulledStates() *hexutil.Uint64 {
+	ret := hexutil.Uint64(s.progress.PulledStates)
+	return &ret
+}
+
+func (s *SyncState) KnownStates() *hexutil.Uint64 {
+	ret := hexutil.Uint64(s.progress.KnownStates)
+	return &ret
+}
+
+// blockNumber converts a block number argument into its RPC representation. The
+// numbers colliding with the negative special block tags (latest, pending and
+// finalized) are rejected, as the schema has no notion of those.
+func blockNumber(number hexutil.Uint64) (rpc.BlockNumber, error) {
+	if uint64(number) > math.MaxInt64 {
+		return 0, errInvalidBlockNumber
+	}
+	return rpc.BlockNumber(number), nil
+}
+
+// Resolver is the top-level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend ethapi.Backend
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *hexutil.Uint64
+	Hash   *common.Hash
+}) (*Block, error) {
+	var (
+		block *types.Block
+		err   error
+	)
+	switch {
+	case args.Hash != nil:
+		block, err = r.backend.GetBlock(ctx, *args.Hash)
+	case args.Number != nil:
+		var number rpc.BlockNumber
+		if number, err = blockNumber(*args.Number); err != nil {
+			return nil, err
+		}
+		block, err = r.backend.BlockByNumber(ctx, number)
+	default:
+		block, err = r.backend.BlockByNumber(ctx, rpc.LatestBlockNumber)
+	}
+	if err != nil || block == nil {
+		return nil, err
+	}
+	return &Block{backend: r.backend, block: block}, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From hexutil.Uint64
+	To   *hexutil.Uint64
+}) ([]*Block, error) {
+	from := uint64(args.From)
+
+	to := r.backend.CurrentBlock().NumberU64()
+	if args.To != nil {
+		to = uint64(*args.To)
+	}
+	if to > math.MaxInt64 {
+		return nil, errInvalidBlockNumber
+	}
+	if to < from {
+		return nil, errInvalidBlockRange
+	}
+	if to-from >= maxBlocksRange {
+		return nil, errBlockRangeTooLarge
+	}
+	var blocks []*Block
+	for number := from; number <= to; number++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := r.backend.BlockByNumber(ctx, rpc.BlockNumber(number))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			break
+		}
+		blocks = append(blocks, &Block{backend: r.backend, block: block})
+	}
+	return blocks, nil
+}
+
+func (r *Resolver) Pending(ctx context.Context) *Pending {
+	return &Pending{backend: r.backend}
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{backend: r.backend, hash: args.Hash}
+	// Resolve the transaction; if it doesn't exist, return nil
+	t, err := tx.resolve(ctx)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (r *Resolver) Account(ctx context.Context, args struct {
+	Address     common.Address
+	BlockNumber *hexutil.Uint64
+}) (*Account, error) {
+	number := rpc.LatestBlockNumber
+	if args.BlockNumber != nil {
+		var err error
+		if number, err = blockNumber(*args.BlockNumber); err != nil {
+			return nil, err
+		}
+	}
+	return &Account{backend: r.backend, address: args.Address, blockNumber: number}, nil
+}
+
+// FilterCriteria encapsulates the arguments to `logs` on the root resolver object.
+type FilterCriteria struct {
+	FromBlock *hexutil.Uint64   // beginning of the queried range, nil means latest block
+	ToBlock   *hexutil.Uint64   // end of the range, nil means latest block
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+	Topics    *[][]common.Hash  // restricts matches to events with the given topic prefixes
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	// Convert the RPC block numbers into internal representations
+	begin, end := rpc.LatestBlockNumber.Int64(), rpc.LatestBlockNumber.Int64()
+	if args.Filter.FromBlock != nil {
+		number, err := blockNumber(*args.Filter.FromBlock)
+		if err != nil {
+			return nil, err
+		}
+		begin = number.Int64()
+	}
+	if args.Filter.ToBlock != nil {
+		number, err := blockNumber(*args.Filter.ToBlock)
+		if err != nil {
+			return nil, err
+		}
+		end = number.Int64()
+	}
+	// Reject ranges searching too many blocks, resolving the latest block first
+	head := int64(r.backend.CurrentBlock().NumberU64())
+	from, to := begin, end
+	if from == rpc.LatestBlockNumber.Int64() {
+		from = head
+	}
+	if to == rpc.LatestBlockNumber.Int64() {
+		to = head
+	}
+	if to >= from && to-from >= maxLogsRange {
+		return nil, errBlockRangeTooLarge
+	}
+	backend, ok := r.backend.(filters.Backend)
+	if !ok {
+		return nil, errNoLogFilter
+	}
+	var (
+		addresses []common.Address
+		topics    [][]common.Hash
+	)
+	if args.Filter.Addresses != nil {
+		addresses = *args.Filter.Addresses
+	}
+	if args.Filter.Topics != nil {
+		topics = *args.Filter.Topics
+	}
+	// Construct the range filter and run it
+	logs, err := filters.New(backend, begin, end, addresses, topics).Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(logs) > maxLogs {
+		return nil, errTooManyLogs
+	}
+	ret := make([]*Log, 0, len(logs))
+	for _, log := range logs {
+		ret = append(ret, &Log{
+			backend:     r.backend,
+			transaction: &Transaction{backend: r.backend, hash: log.TxHash},
+			log:         log,
+		})
+	}
+	return ret, nil
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.SuggestPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*price), nil
+}
+
+func (r *Resolver) ProtocolVersion(ctx context.Context) int32 {
+	return int32(r.backend.ProtocolVersion())
+}
+
+func (r *Resolver) Syncing() *SyncState {
+	progress := r.backend.Downloader().Progress()
+
+	// Return not syncing if the synchronisation already completed
+	if progress.CurrentBlock >= progress.HighestBlock {
+		return nil
+	}
+	return &SyncState{progress}
+}
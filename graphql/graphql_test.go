@@ -0,0 +1,426 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/core"
+	"github.com/AdelineCoin/go-adln/core/state"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/internal/ethapi"
+	"github.com/AdelineCoin/go-adln/params"
+	"github.com/AdelineCoin/go-adln/rpc"
+)
+
+var (
+	testKey, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr     = crypto.PubkeyToAddress(testKey.PublicKey)
+	testContract = common.HexToAddress("0x0000000000000000000000000000000000000100")
+	testTopic    = common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000042")
+)
+
+// testBackend is a minimal chain backend serving a fixed set of blocks. Methods
+// not needed by the resolvers are left to the embedded nil interface.
+type testBackend struct {
+	ethapi.Backend
+
+	db       ethdb.Database
+	state    state.Database
+	blocks   []*types.Block
+	receipts map[common.Hash]types.Receipts
+	pool     types.Transactions
+}
+
+func (b *testBackend) ChainDb() ethdb.Database          { return b.db }
+func (b *testBackend) ChainConfig() *params.ChainConfig { return params.TestChainConfig }
+func (b *testBackend) CurrentBlock() *types.Block       { return b.blocks[len(b.blocks)-1] }
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number < 0 {
+		return b.CurrentBlock(), nil
+	}
+	if int(number) >= len(b.blocks) {
+		return nil, nil
+	}
+	return b.blocks[number], nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	for _, block := range b.blocks {
+		if block.Hash() == hash {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.receipts[hash], nil
+}
+
+func (b *testBackend) GetTd(hash common.Hash) *big.Int {
+	td := new(big.Int)
+	for _, block := range b.blocks {
+		td.Add(td, block.Difficulty())
+		if block.Hash() == hash {
+			return td
+		}
+	}
+	return nil
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	block, _ := b.BlockByNumber(ctx, number)
+	if block == nil {
+		return nil, nil, nil
+	}
+	statedb, err := state.New(block.Root(), b.state)
+	return statedb, block.Header(), err
+}
+
+func (b *testBackend) GetPoolTransactions() (types.Transactions, error) {
+	return b.pool, nil
+}
+
+func (b *testBackend) GetPoolTransaction(hash common.Hash) *types.Transaction {
+	for _, tx := range b.pool {
+		if tx.Hash() == hash {
+			return tx
+		}
+	}
+	return nil
+}
+
+// newTestBackend creates a two block chain, the second block containing a value
+// transfer to a contract which emits a single log.
+func newTestBackend(t *testing.T) *testBackend {
+	db, _ := ethdb.NewMemDatabase()
+	sdb := state.NewDatabase(db)
+
+	statedb, _ := state.New(common.Hash{}, sdb)
+	statedb.AddBalance(testAddr, big.NewInt(1000000))
+	statedb.AddBalance(testContract, big.NewInt(5))
+	statedb.SetCode(testContract, []byte{0x60, 0x00})
+	root, err := statedb.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	signer := types.MakeSigner(params.TestChainConfig, big.NewInt(1))
+	tx, err := types.SignTx(types.NewTransaction(0, testContract, big.NewInt(1), 21000, big.NewInt(1), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	pending, err := types.SignTx(types.NewTransaction(1, testContract, big.NewInt(2), 21000, big.NewInt(1), nil), signer, testKey)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	receipt := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		CumulativeGasUsed: 21000,
+		GasUsed:           21000,
+		TxHash:            tx.Hash(),
+		Logs: []*types.Log{{
+			Address:     testContract,
+			Topics:      []common.Hash{testTopic},
+			Data:        []byte{0x01},
+			BlockNumber: 1,
+			TxHash:      tx.Hash(),
+		}},
+	}
+	genesis := types.NewBlock(&types.Header{Number: big.NewInt(0), Difficulty: big.NewInt(1), Time: big.NewInt(0), Root: root}, nil, nil, nil)
+	block := types.NewBlock(&types.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		Difficulty: big.NewInt(2),
+		Time:       big.NewInt(10),
+		GasLimit:   1000000,
+		GasUsed:    21000,
+		Root:       root,
+	}, []*types.Transaction{tx}, nil, []*types.Receipt{receipt})
+
+	if err := core.WriteBody(db, block.Hash(), 1, block.Body()); err != nil {
+		t.Fatalf("failed to write body: %v", err)
+	}
+	if err := core.WriteTxLookupEntries(db, block); err != nil {
+		t.Fatalf("failed to write lookup entries: %v", err)
+	}
+	return &testBackend{
+		db:       db,
+		state:    sdb,
+		blocks:   []*types.Block{genesis, block},
+		receipts: map[common.Hash]types.Receipts{block.Hash(): {receipt}},
+		pool:     types.Transactions{pending},
+	}
+}
+
+// queryResponse is the decoded form of a GraphQL response.
+type queryResponse struct {
+	Data   json.RawMessage
+	Errors []struct{ Message string }
+}
+
+// execute runs a GraphQL query against the handler and decodes the response.
+func execute(t *testing.T, handler http.Handler, query string) queryResponse {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, endpointPath, strings.NewReader(string(body))))
+
+	var resp queryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+// query executes a GraphQL query against the handler and decodes the result.
+func query(t *testing.T, handler http.Handler, query string, result interface{}) {
+	resp := execute(t, handler, query)
+	if len(resp.Errors) > 0 {
+		t.Fatalf("query failed: %+v", resp.Errors)
+	}
+	if err := json.Unmarshal(resp.Data, result); err != nil {
+		t.Fatalf("failed to decode data %s: %v", resp.Data, err)
+	}
+}
+
+// Tests that nested queries resolve from a block down through its transactions,
+// receipts and logs to the state of the accounts involved.
+func TestNestedQuery(t *testing.T) {
+	backend := newTestBackend(t)
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	var result struct {
+		Block struct {
+			Number          string
+			TotalDifficulty string
+			Parent          struct{ Number string }
+			Transactions    []struct {
+				Hash    common.Hash
+				Index   int
+				From    struct{ Address common.Address }
+				Receipt struct {
+					Status  string
+					GasUsed string
+					Logs    []struct {
+						Topics  []common.Hash
+						Account struct {
+							Address common.Address
+							Balance string
+							Code    string
+						}
+					}
+				}
+			}
+		}
+	}
+	query(t, handler, `{
+		block(number: 1) {
+			number
+			totalDifficulty
+			parent { number }
+			transactions {
+				hash
+				index
+				from { address }
+				receipt {
+					status
+					gasUsed
+					logs {
+						topics
+						account { address balance code }
+					}
+				}
+			}
+		}
+	}`, &result)
+
+	block := result.Block
+	if block.Number != "0x1" || block.Parent.Number != "0x0" || block.TotalDifficulty != "0x3" {
+		t.Fatalf("block mismatch: %+v", block)
+	}
+	if len(block.Transactions) != 1 {
+		t.Fatalf("transaction count mismatch: have %d, want 1", len(block.Transactions))
+	}
+	tx := block.Transactions[0]
+	if tx.Hash != backend.blocks[1].Transactions()[0].Hash() || tx.Index != 0 || tx.From.Address != testAddr {
+		t.Errorf("transaction mismatch: %+v", tx)
+	}
+	if tx.Receipt.Status != "0x1" || tx.Receipt.GasUsed != "0x5208" || len(tx.Receipt.Logs) != 1 {
+		t.Fatalf("receipt mismatch: %+v", tx.Receipt)
+	}
+	log := tx.Receipt.Logs[0]
+	if len(log.Topics) != 1 || log.Topics[0] != testTopic {
+		t.Errorf("log topics mismatch: %v", log.Topics)
+	}
+	if log.Account.Address != testContract || log.Account.Balance != "0x5" || log.Account.Code != "0x6000" {
+		t.Errorf("log account mismatch: %+v", log.Account)
+	}
+}
+
+// Tests transaction lookups by hash for both mined and pending transactions, as
+// well as access to the pending state.
+func TestTransactionQuery(t *testing.T) {
+	backend := newTestBackend(t)
+	handler, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	type txResult struct {
+		Nonce string
+		Index *int
+		Block *struct{ Number string }
+	}
+	var result struct {
+		Mined   *txResult
+		Queued  *txResult
+		Missing *txResult
+		State   struct {
+			TransactionCount int
+			Account          struct{ Balance string }
+		} `json:"pending"`
+	}
+	query(t, handler, `{
+		mined: transaction(hash: "`+backend.blocks[1].Transactions()[0].Hash().Hex()+`") { nonce index block { number } }
+		queued: transaction(hash: "`+backend.pool[0].Hash().Hex()+`") { nonce index block { number } }
+		missing: transaction(hash: "`+common.Hash{}.Hex()+`") { nonce }
+	}`, &result)
+
+	if result.Mined == nil || result.Mined.Nonce != "0x0" || result.Mined.Index == nil || result.Mined.Block == nil || result.Mined.Block.Number != "0x1" {
+		t.Errorf("mined transaction mismatch: %+v", result.Mined)
+	}
+	if result.Queued == nil || result.Queued.Nonce != "0x1" || result.Queued.Index != nil || result.Queued.Block != nil {
+		t.Errorf("pending transaction mismatch: %+v", result.Queued)
+	}
+	if result.Missing != nil {
+		t.Errorf("unknown transaction resolved: %+v", result.Missing)
+	}
+	query(t, handler, `{
+		pending { transactionCount account(address: "`+testAddr.Hex()+`") { balance } }
+	}`, &result)
+	if result.State.TransactionCount != 1 || result.State.Account.Balance != "0xf4240" {
+		t.Errorf("pending state mismatch: %+v", result.State)
+	}
+}
+
+// Tests that block logs are filtered by address and topic.
+func TestBlockLogsFilter(t *testing.T) {
+	handler, err := newHandler(newTestBackend(t))
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	var result struct {
+		Block struct {
+			Matching []struct{ Index int }
+			Missing  []struct{ Index int }
+		}
+	}
+	query(t, handler, `{
+		block {
+			matching: logs(filter: {addresses: ["`+testContract.Hex()+`"], topics: [["`+testTopic.Hex()+`"]]}) { index }
+			missing: logs(filter: {topics: [[], ["`+testTopic.Hex()+`"]]}) { index }
+		}
+	}`, &result)
+
+	if len(result.Block.Matching) != 1 {
+		t.Errorf("matching log count mismatch: have %d, want 1", len(result.Block.Matching))
+	}
+	if len(result.Block.Missing) != 0 {
+		t.Errorf("non-matching log count mismatch: have %d, want 0", len(result.Block.Missing))
+	}
+}
+
+// Tests that queries spanning too many blocks are rejected.
+func TestRangeLimits(t *testing.T) {
+	handler, err := newHandler(newTestBackend(t))
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	var result struct {
+		Blocks []struct{ Number string }
+	}
+	query(t, handler, `{ blocks(from: 0) { number } }`, &result)
+	if len(result.Blocks) != 2 {
+		t.Errorf("block count mismatch: have %d, want 2", len(result.Blocks))
+	}
+	tests := []string{
+		fmt.Sprintf(`{ blocks(from: 0, to: %d) { number } }`, maxBlocksRange),
+		fmt.Sprintf(`{ logs(filter: {fromBlock: 0, toBlock: %d}) { index } }`, maxLogsRange),
+	}
+	for _, q := range tests {
+		resp := execute(t, handler, q)
+		if len(resp.Errors) != 1 || resp.Errors[0].Message != errBlockRangeTooLarge.Error() {
+			t.Errorf("%s: error mismatch: have %+v, want %q", q, resp.Errors, errBlockRangeTooLarge)
+		}
+	}
+}
+
+// Tests that queries nested too deeply are rejected.
+func TestDepthLimit(t *testing.T) {
+	handler, err := newHandler(newTestBackend(t))
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	resp := execute(t, handler, `{ block { parent { parent { parent { parent { parent { parent { parent { number } } } } } } } } }`)
+	if len(resp.Errors) == 0 {
+		t.Fatalf("deeply nested query accepted")
+	}
+	if !strings.Contains(resp.Errors[0].Message, "exceeds max depth") {
+		t.Errorf("error mismatch: have %+v", resp.Errors)
+	}
+}
+
+// Tests that the query explorer is only served when enabled, without loading
+// any assets from third parties.
+func TestExplorerToggle(t *testing.T) {
+	for _, ui := range []bool{false, true} {
+		service, err := New(newTestBackend(t), ui)
+		if err != nil {
+			t.Fatalf("failed to create service: %v", err)
+		}
+		handlers := service.HTTPHandlers()
+		if _, ok := handlers[endpointPath]; !ok {
+			t.Errorf("ui %v: query endpoint missing", ui)
+		}
+		explorer, ok := handlers[uiPath]
+		if ok != ui {
+			t.Fatalf("ui %v: explorer presence mismatch: have %v", ui, ok)
+		}
+		if ui {
+			rec := httptest.NewRecorder()
+			explorer.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, uiPath, nil))
+			if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "GraphQL explorer") {
+				t.Errorf("explorer not served: code %d", rec.Code)
+			}
+			if body := rec.Body.String(); strings.Contains(body, "<script src") || strings.Contains(body, "<link") {
+				t.Errorf("explorer loads external assets")
+			}
+		}
+	}
+}
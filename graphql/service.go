@@ -0,0 +1,92 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"net/http"
+
+	"github.com/AdelineCoin/go-adln/internal/ethapi"
+	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/p2p"
+	"github.com/AdelineCoin/go-adln/rpc"
+	graphqlgo "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+)
+
+const (
+	endpointPath = "/graphql"    // URL path the GraphQL queries are served on
+	uiPath       = "/graphql/ui" // URL path the query explorer is served on
+
+	maxQueryDepth       = 8 // Maximum field nesting depth of a query
+	maxQueryParallelism = 8 // Maximum number of resolvers run in parallel for a query
+)
+
+// Service encapsulates a GraphQL endpoint mounted on the node's HTTP server.
+type Service struct {
+	handler http.Handler // Handler executing the GraphQL queries
+	ui      bool         // Whether to serve the query explorer too
+}
+
+// New constructs a new GraphQL service resolving queries against the given
+// backend, optionally serving the query explorer too.
+func New(backend ethapi.Backend, ui bool) (*Service, error) {
+	handler, err := newHandler(backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{handler: handler, ui: ui}, nil
+}
+
+// newHandler parses the schema and creates the HTTP handler executing queries.
+// As nested fields may each require state lookups, both the depth of queries
+// and the resolvers running concurrently are limited.
+func newHandler(backend ethapi.Backend) (http.Handler, error) {
+	s, err := graphqlgo.ParseSchema(schema, &Resolver{backend: backend}, graphqlgo.MaxDepth(maxQueryDepth), graphqlgo.MaxParallelism(maxQueryParallelism))
+	if err != nil {
+		return nil, err
+	}
+	return &relay.Handler{Schema: s}, nil
+}
+
+// Protocols implements node.Service, returning the P2P network protocols used
+// by the GraphQL service (nil as it doesn't use the devp2p overlay network).
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs implements node.Service, returning the RPC API endpoints provided by the
+// GraphQL service (nil as it serves plain HTTP instead).
+func (s *Service) APIs() []rpc.API { return nil }
+
+// HTTPHandlers implements node.HTTPService, returning the handlers to mount on
+// the node's HTTP endpoint.
+func (s *Service) HTTPHandlers() map[string]http.Handler {
+	handlers := map[string]http.Handler{endpointPath: s.handler}
+	if s.ui {
+		handlers[uiPath] = http.HandlerFunc(serveExplorer)
+	}
+	return handlers
+}
+
+// Start implements node.Service, starting the GraphQL service.
+func (s *Service) Start(server *p2p.Server) error {
+	log.Info("GraphQL endpoint enabled", "path", endpointPath, "ui", s.ui)
+	return nil
+}
+
+// Stop implements node.Service, stopping the GraphQL service.
+func (s *Service) Stop() error {
+	return nil
+}
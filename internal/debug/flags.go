@@ -28,6 +28,7 @@ import (
 	"github.com/AdelineCoin/go-adln/log/term"
 	"github.com/AdelineCoin/go-adln/metrics"
 	"github.com/AdelineCoin/go-adln/metrics/exp"
+	"github.com/AdelineCoin/go-adln/metrics/prometheus"
 	colorable "github.com/mattn/go-colorable"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -133,6 +134,9 @@ func Setup(ctx *cli.Context) error {
 		// from the registry into expvar, and execute regular expvar handler.
 		exp.Exp(metrics.DefaultRegistry)
 
+		// Expose the same metrics in the Prometheus text format for scraping.
+		http.Handle("/debug/metrics/prometheus", prometheus.Handler(metrics.DefaultRegistry))
+
 		address := fmt.Sprintf("%s:%d", ctx.GlobalString(pprofAddrFlag.Name), ctx.GlobalInt(pprofPortFlag.Name))
 		go func() {
 			log.Info("Starting pprof server", "addr", fmt.Sprintf("http://%s/debug/pprof", address))
@@ -31,6 +31,7 @@ import (
 	"github.com/AdelineCoin/go-adln/common/hexutil"
 	"github.com/AdelineCoin/go-adln/common/math"
 	"github.com/AdelineCoin/go-adln/consensus/ethash"
+	"github.com/AdelineCoin/go-adln/consensus/misc"
 	"github.com/AdelineCoin/go-adln/core"
 	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/core/vm"
@@ -46,6 +47,10 @@ import (
 
 const (
 	defaultGasPrice = 50 * params.Shannon
+
+	// maxAccessListIterations is the maximum number of times a call is retraced
+	// when creating an access list, before giving up on it stabilising.
+	maxAccessListIterations = 16
 )
 
 // PublicEthereumAPI provides an API to access Ethereum related information.
@@ -64,6 +69,53 @@ func (s *PublicEthereumAPI) GasPrice(ctx context.Context) (*big.Int, error) {
 	return s.b.SuggestPrice(ctx)
 }
 
+// MaxPriorityFeePerGas returns a suggestion for the tip paid to the miner on top
+// of the base fee by dynamic fee transactions.
+func (s *PublicEthereumAPI) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	tip, err := s.b.SuggestTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(tip), nil
+}
+
+// FeeHistoryResult is the fee market history of a range of blocks.
+type FeeHistoryResult struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+
+// FeeHistory returns the base fees, gas usage ratios and the tips paid at the
+// given percentiles of gas usage for a range of blocks ending with lastBlock.
+func (s *PublicEthereumAPI) FeeHistory(ctx context.Context, blockCount hexutil.Uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*FeeHistoryResult, error) {
+	oldest, reward, baseFee, gasUsed, err := s.b.FeeHistory(ctx, int(blockCount), lastBlock, rewardPercentiles)
+	if err != nil {
+		return nil, err
+	}
+	results := &FeeHistoryResult{
+		OldestBlock:  (*hexutil.Big)(oldest),
+		GasUsedRatio: gasUsed,
+	}
+	if reward != nil {
+		results.Reward = make([][]*hexutil.Big, len(reward))
+		for i, w := range reward {
+			results.Reward[i] = make([]*hexutil.Big, len(w))
+			for j, v := range w {
+				results.Reward[i][j] = (*hexutil.Big)(v)
+			}
+		}
+	}
+	if baseFee != nil {
+		results.BaseFee = make([]*hexutil.Big, len(baseFee))
+		for i, v := range baseFee {
+			results.BaseFee[i] = (*hexutil.Big)(v)
+		}
+	}
+	return results, nil
+}
+
 // ProtocolVersion returns the current Ethereum protocol version this node supports
 func (s *PublicEthereumAPI) ProtocolVersion() hexutil.Uint {
 	return hexutil.Uint(s.b.ProtocolVersion())
@@ -394,7 +446,7 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs
 	if err != nil {
 		return nil, err
 	}
-	data, err := rlp.EncodeToBytes(signed)
+	data, err := signed.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -609,6 +661,9 @@ type CallArgs struct {
 	GasPrice hexutil.Big     `json:"gasPrice"`
 	Value    hexutil.Big     `json:"value"`
 	Data     hexutil.Bytes   `json:"data"`
+
+	// Accounts and storage slots declared ahead of execution
+	AccessList *types.AccessList `json:"accessList,omitempty"`
 }
 
 func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config, timeout time.Duration) ([]byte, uint64, bool, error) {
@@ -637,7 +692,11 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	}
 
 	// Create new call message
-	msg := types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+	var accessList types.AccessList
+	if args.AccessList != nil {
+		accessList = *args.AccessList
+	}
+	msg := types.NewAccessListMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, accessList, false)
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -680,6 +739,65 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args CallArgs, blockNr r
 	return (hexutil.Bytes)(result), err
 }
 
+// AccessListResult is the result of an access list creation, the list along with
+// the gas used by the transaction when executed with it.
+type AccessListResult struct {
+	AccessList *types.AccessList `json:"accessList"`
+	GasUsed    hexutil.Uint64    `json:"gasUsed"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// CreateAccessList creates an EIP-2930 access list for the given transaction,
+// executing it on the state of the given block until the accessed accounts and
+// storage slots no longer change, or failing after maxAccessListIterations.
+func (s *PublicBlockChainAPI) CreateAccessList(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber) (*AccessListResult, error) {
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	// Resolve the sender and recipient up front, they are always accessed
+	if args.From == (common.Address{}) {
+		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
+				args.From = accounts[0].Address
+			}
+		}
+	}
+	var to common.Address
+	if args.To != nil {
+		to = *args.To
+	} else {
+		to = crypto.CreateAddress(args.From, state.GetNonce(args.From))
+	}
+	// Gather the precompiled contracts, also left out of the list
+	excl := vm.ActivePrecompileAddresses(s.b.ChainConfig(), header.Number)
+	// Retrace the call with the list collected so far until it stabilises
+	var prev types.AccessList
+	if args.AccessList != nil {
+		prev = *args.AccessList
+	}
+	prevTracer := vm.NewAccessListTracer(prev, args.From, to, excl)
+	for i := 0; i < maxAccessListIterations; i++ {
+		accessList := prevTracer.AccessList()
+		args.AccessList = &accessList
+
+		tracer := vm.NewAccessListTracer(accessList, args.From, to, excl)
+		_, gas, failed, err := s.doCall(ctx, args, blockNr, vm.Config{Debug: true, Tracer: tracer}, 5*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		if tracer.Equal(prevTracer) {
+			result := &AccessListResult{AccessList: &accessList, GasUsed: hexutil.Uint64(gas)}
+			if failed {
+				result.Error = "execution reverted or failed"
+			}
+			return result, nil
+		}
+		prevTracer = tracer
+	}
+	return nil, fmt.Errorf("access list not stable after %d iterations", maxAccessListIterations)
+}
+
 // EstimateGas returns an estimate of the amount of gas needed to execute the
 // given transaction against the current pending block.
 func (s *PublicBlockChainAPI) EstimateGas(ctx context.Context, args CallArgs) (hexutil.Uint64, error) {
@@ -815,6 +933,9 @@ func (s *PublicBlockChainAPI) rpcOutputBlock(b *types.Block, inclTx bool, fullTx
 		"transactionsRoot": head.TxHash,
 		"receiptsRoot":     head.ReceiptHash,
 	}
+	if head.BaseFee != nil {
+		fields["baseFeePerGas"] = (*hexutil.Big)(head.BaseFee)
+	}
 
 	if inclTx {
 		formatTx := func(tx *types.Transaction) (interface{}, error) {
@@ -850,27 +971,37 @@ func (s *PublicBlockChainAPI) rpcOutputBlock(b *types.Block, inclTx bool, fullTx
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	BlockHash        common.Hash     `json:"blockHash"`
-	BlockNumber      *hexutil.Big    `json:"blockNumber"`
-	From             common.Address  `json:"from"`
-	Gas              hexutil.Uint64  `json:"gas"`
-	GasPrice         *hexutil.Big    `json:"gasPrice"`
-	Hash             common.Hash     `json:"hash"`
-	Input            hexutil.Bytes   `json:"input"`
-	Nonce            hexutil.Uint64  `json:"nonce"`
-	To               *common.Address `json:"to"`
-	TransactionIndex hexutil.Uint    `json:"transactionIndex"`
-	Value            *hexutil.Big    `json:"value"`
-	V                *hexutil.Big    `json:"v"`
-	R                *hexutil.Big    `json:"r"`
-	S                *hexutil.Big    `json:"s"`
+	BlockHash        common.Hash       `json:"blockHash"`
+	BlockNumber      *hexutil.Big      `json:"blockNumber"`
+	From             common.Address    `json:"from"`
+	Gas              hexutil.Uint64    `json:"gas"`
+	GasPrice         *hexutil.Big      `json:"gasPrice"`
+	Hash             common.Hash       `json:"hash"`
+	Input            hexutil.Bytes     `json:"input"`
+	Nonce            hexutil.Uint64    `json:"nonce"`
+	To               *common.Address   `json:"to"`
+	TransactionIndex hexutil.Uint      `json:"transactionIndex"`
+	Value            *hexutil.Big      `json:"value"`
+	V                *hexutil.Big      `json:"v"`
+	R                *hexutil.Big      `json:"r"`
+	S                *hexutil.Big      `json:"s"`
+	Type             hexutil.Uint64    `json:"type"`
+	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
+	GasFeeCap        *hexutil.Big      `json:"maxFeePerGas,omitempty"`
+	GasTipCap        *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
+	Accesses         *types.AccessList `json:"accessList,omitempty"`
 }
 
 // newRPCTransaction returns a transaction that will serialize to the RPC
-// representation, with the given location metadata set (if available).
-func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber uint64, index uint64) *RPCTransaction {
+// representation, with the given location metadata set (if available). The gas
+// price of included dynamic fee transactions is the effective one paid given
+// the base fee of their block.
+func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber uint64, baseFee *big.Int, index uint64) *RPCTransaction {
 	var signer types.Signer = types.FrontierSigner{}
-	if tx.Protected() {
+	switch {
+	case tx.Type() != types.LegacyTxType:
+		signer = types.NewEIP1559Signer(tx.ChainId())
+	case tx.Protected():
 		signer = types.NewEIP155Signer(tx.ChainId())
 	}
 	from, _ := types.Sender(signer, tx)
@@ -888,18 +1019,32 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		V:        (*hexutil.Big)(v),
 		R:        (*hexutil.Big)(r),
 		S:        (*hexutil.Big)(s),
+		Type:     hexutil.Uint64(tx.Type()),
 	}
 	if blockHash != (common.Hash{}) {
 		result.BlockHash = blockHash
 		result.BlockNumber = (*hexutil.Big)(new(big.Int).SetUint64(blockNumber))
 		result.TransactionIndex = hexutil.Uint(index)
 	}
+	if tx.Type() != types.LegacyTxType {
+		al := tx.AccessList()
+		result.Accesses = &al
+		result.ChainID = (*hexutil.Big)(tx.ChainId())
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		result.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+		result.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		if blockHash != (common.Hash{}) && baseFee != nil {
+			price := new(big.Int).Add(baseFee, tx.EffectiveGasTip(baseFee))
+			result.GasPrice = (*hexutil.Big)(price)
+		}
+	}
 	return result
 }
 
 // newRPCPendingTransaction returns a pending transaction that will serialize to the RPC representation
 func newRPCPendingTransaction(tx *types.Transaction) *RPCTransaction {
-	return newRPCTransaction(tx, common.Hash{}, 0, 0)
+	return newRPCTransaction(tx, common.Hash{}, 0, nil, 0)
 }
 
 // newRPCTransactionFromBlockIndex returns a transaction that will serialize to the RPC representation.
@@ -908,7 +1053,7 @@ func newRPCTransactionFromBlockIndex(b *types.Block, index uint64) *RPCTransacti
 	if index >= uint64(len(txs)) {
 		return nil
 	}
-	return newRPCTransaction(txs[index], b.Hash(), b.NumberU64(), index)
+	return newRPCTransaction(txs[index], b.Hash(), b.NumberU64(), b.BaseFee(), index)
 }
 
 // newRPCRawTransactionFromBlockIndex returns the bytes of a transaction given a block and a transaction index.
@@ -917,7 +1062,7 @@ func newRPCRawTransactionFromBlockIndex(b *types.Block, index uint64) hexutil.By
 	if index >= uint64(len(txs)) {
 		return nil
 	}
-	blob, _ := rlp.EncodeToBytes(txs[index])
+	blob, _ := txs[index].MarshalBinary()
 	return blob
 }
 
@@ -1006,7 +1151,11 @@ func (s *PublicTransactionPoolAPI) GetTransactionCount(ctx context.Context, addr
 func (s *PublicTransactionPoolAPI) GetTransactionByHash(ctx context.Context, hash common.Hash) *RPCTransaction {
 	// Try to return an already finalized transaction
 	if tx, blockHash, blockNumber, index := core.GetTransaction(s.b.ChainDb(), hash); tx != nil {
-		return newRPCTransaction(tx, blockHash, blockNumber, index)
+		var baseFee *big.Int
+		if header, _ := s.b.HeaderByNumber(ctx, rpc.BlockNumber(blockNumber)); header != nil {
+			baseFee = header.BaseFee
+		}
+		return newRPCTransaction(tx, blockHash, blockNumber, baseFee, index)
 	}
 	// No finalized transaction, try to retrieve it from the pool
 	if tx := s.b.GetPoolTransaction(hash); tx != nil {
@@ -1027,8 +1176,8 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context,
 			return nil, nil
 		}
 	}
-	// Serialize to RLP and return
-	return rlp.EncodeToBytes(tx)
+	// Serialize to the canonical encoding and return
+	return tx.MarshalBinary()
 }
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
@@ -1047,7 +1196,10 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	receipt := receipts[index]
 
 	var signer types.Signer = types.FrontierSigner{}
-	if tx.Protected() {
+	switch {
+	case tx.Type() != types.LegacyTxType:
+		signer = types.NewEIP1559Signer(tx.ChainId())
+	case tx.Protected():
 		signer = types.NewEIP155Signer(tx.ChainId())
 	}
 	from, _ := types.Sender(signer, tx)
@@ -1064,6 +1216,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 		"contractAddress":   nil,
 		"logs":              receipt.Logs,
 		"logsBloom":         receipt.Bloom,
+		"type":              hexutil.Uint(tx.Type()),
 	}
 
 	// Assign receipt status or post state.
@@ -1107,6 +1260,15 @@ type SendTxArgs struct {
 	GasPrice *hexutil.Big    `json:"gasPrice"`
 	Value    *hexutil.Big    `json:"value"`
 	Nonce    *hexutil.Uint64 `json:"nonce"`
+
+	// Fee caps of dynamic fee transactions, mutually exclusive with gasPrice
+	MaxFeePerGas         *hexutil.Big `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big `json:"maxPriorityFeePerGas"`
+	ChainID              *hexutil.Big `json:"chainId,omitempty"`
+
+	// Accounts and storage slots declared ahead of execution (typed transactions only)
+	AccessList *types.AccessList `json:"accessList,omitempty"`
+
 	// We accept "data" and "input" for backwards-compatibility reasons. "input" is the
 	// newer name and should be preferred by clients.
 	Data  *hexutil.Bytes `json:"data"`
@@ -1119,12 +1281,8 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 		args.Gas = new(hexutil.Uint64)
 		*(*uint64)(args.Gas) = 90000
 	}
-	if args.GasPrice == nil {
-		price, err := b.SuggestPrice(ctx)
-		if err != nil {
-			return err
-		}
-		args.GasPrice = (*hexutil.Big)(price)
+	if err := args.setFeeDefaults(ctx, b); err != nil {
+		return err
 	}
 	if args.Value == nil {
 		args.Value = new(hexutil.Big)
@@ -1154,6 +1312,57 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 	return nil
 }
 
+// setFeeDefaults fills in the fee fields of a transaction. Once the fee market
+// of the next block is active, transactions not specifying a gas price become
+// dynamic fee ones, paying at most twice the current base fee plus the tip.
+func (args *SendTxArgs) setFeeDefaults(ctx context.Context, b Backend) error {
+	dynamic := args.MaxFeePerGas != nil || args.MaxPriorityFeePerGas != nil
+	if dynamic && args.GasPrice != nil {
+		return errors.New("both gasPrice and (maxFeePerGas or maxPriorityFeePerGas) specified")
+	}
+	var (
+		config = b.ChainConfig()
+		head   = b.CurrentBlock().Header()
+		next   = new(big.Int).Add(head.Number, common.Big1)
+	)
+	if args.AccessList != nil && !config.IsEIP2718(next) && !config.IsEIP1559(next) {
+		return errors.New("access list transactions not yet supported")
+	}
+	if !config.IsEIP1559(next) {
+		if dynamic {
+			return errors.New("dynamic fee transactions not yet supported")
+		}
+	} else if args.GasPrice == nil {
+		if args.MaxPriorityFeePerGas == nil {
+			tip, err := b.SuggestTipCap(ctx)
+			if err != nil {
+				return err
+			}
+			args.MaxPriorityFeePerGas = (*hexutil.Big)(tip)
+		}
+		if args.MaxFeePerGas == nil {
+			feeCap := new(big.Int).Mul(misc.CalcBaseFee(config, head), common.Big2)
+			args.MaxFeePerGas = (*hexutil.Big)(feeCap.Add(feeCap, (*big.Int)(args.MaxPriorityFeePerGas)))
+		}
+		if args.MaxFeePerGas.ToInt().Cmp(args.MaxPriorityFeePerGas.ToInt()) < 0 {
+			return fmt.Errorf("maxFeePerGas (%v) < maxPriorityFeePerGas (%v)", args.MaxFeePerGas, args.MaxPriorityFeePerGas)
+		}
+		args.ChainID = (*hexutil.Big)(config.ChainId)
+		return nil
+	}
+	if args.GasPrice == nil {
+		price, err := b.SuggestPrice(ctx)
+		if err != nil {
+			return err
+		}
+		args.GasPrice = (*hexutil.Big)(price)
+	}
+	if args.AccessList != nil {
+		args.ChainID = (*hexutil.Big)(config.ChainId)
+	}
+	return nil
+}
+
 func (args *SendTxArgs) toTransaction() *types.Transaction {
 	var input []byte
 	if args.Data != nil {
@@ -1161,6 +1370,16 @@ func (args *SendTxArgs) toTransaction() *types.Transaction {
 	} else if args.Input != nil {
 		input = *args.Input
 	}
+	var accessList types.AccessList
+	if args.AccessList != nil {
+		accessList = *args.AccessList
+	}
+	if args.MaxFeePerGas != nil {
+		return types.NewDynamicFeeTransaction((*big.Int)(args.ChainID), uint64(*args.Nonce), args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.MaxPriorityFeePerGas), (*big.Int)(args.MaxFeePerGas), input, accessList)
+	}
+	if args.AccessList != nil {
+		return types.NewAccessListTransaction((*big.Int)(args.ChainID), uint64(*args.Nonce), args.To, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input, accessList)
+	}
 	if args.To == nil {
 		return types.NewContractCreation(uint64(*args.Nonce), (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
 	}
@@ -1227,12 +1446,44 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 // The sender is responsible for signing the transaction and using the correct nonce.
 func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
 	tx := new(types.Transaction)
-	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
 		return common.Hash{}, err
 	}
 	return submitTransaction(ctx, s.b, tx)
 }
 
+// defaultPrivateTxBlocks is the number of blocks a private transaction is kept
+// in the pool for if the submitter didn't request an explicit expiry.
+const defaultPrivateTxBlocks = 25
+
+// SendPrivateTxArgs represents the arguments to submit a new private transaction
+// into the transaction pool.
+type SendPrivateTxArgs struct {
+	Tx             hexutil.Bytes   `json:"tx"`
+	MaxBlockNumber *hexutil.Uint64 `json:"maxBlockNumber"`
+	Release        bool            `json:"release"`
+}
+
+// SendPrivateTransaction will add the signed transaction to the transaction pool
+// for inclusion by the local miner only, without announcing it to the network.
+// If the transaction isn't mined by maxBlockNumber, it is dropped from the pool,
+// or if release is set, it is announced to the network as a regular transaction.
+func (s *PublicTransactionPoolAPI) SendPrivateTransaction(ctx context.Context, args SendPrivateTxArgs) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(args.Tx); err != nil {
+		return common.Hash{}, err
+	}
+	expiry := s.b.CurrentBlock().NumberU64() + defaultPrivateTxBlocks
+	if args.MaxBlockNumber != nil {
+		expiry = uint64(*args.MaxBlockNumber)
+	}
+	if err := s.b.SendPrivateTx(ctx, tx, expiry, args.Release); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted private transaction", "fullhash", tx.Hash().Hex(), "expiry", expiry, "release", args.Release)
+	return tx.Hash(), nil
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
@@ -1284,7 +1535,7 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Sen
 	if err != nil {
 		return nil, err
 	}
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -1302,7 +1553,10 @@ func (s *PublicTransactionPoolAPI) PendingTransactions() ([]*RPCTransaction, err
 	transactions := make([]*RPCTransaction, 0, len(pending))
 	for _, tx := range pending {
 		var signer types.Signer = types.HomesteadSigner{}
-		if tx.Protected() {
+		switch {
+		case tx.Type() != types.LegacyTxType:
+			signer = types.NewEIP1559Signer(tx.ChainId())
+		case tx.Protected():
 			signer = types.NewEIP155Signer(tx.ChainId())
 		}
 		from, _ := types.Sender(signer, tx)
@@ -1330,7 +1584,10 @@ func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxAr
 
 	for _, p := range pending {
 		var signer types.Signer = types.HomesteadSigner{}
-		if p.Protected() {
+		switch {
+		case p.Type() != types.LegacyTxType:
+			signer = types.NewEIP1559Signer(p.ChainId())
+		case p.Protected():
 			signer = types.NewEIP155Signer(p.ChainId())
 		}
 		wantSigHash := signer.Hash(matchTx)
@@ -1338,7 +1595,12 @@ func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxAr
 		if pFrom, err := types.Sender(signer, p); err == nil && pFrom == sendArgs.From && signer.Hash(p) == wantSigHash {
 			// Match. Re-sign and send the transaction.
 			if gasPrice != nil && (*big.Int)(gasPrice).Sign() != 0 {
-				sendArgs.GasPrice = gasPrice
+				// Dynamic fee transactions are repriced via their fee cap
+				if sendArgs.MaxFeePerGas != nil {
+					sendArgs.MaxFeePerGas = gasPrice
+				} else {
+					sendArgs.GasPrice = gasPrice
+				}
 			}
 			if gasLimit != nil && *gasLimit != 0 {
 				sendArgs.Gas = gasLimit
@@ -19,10 +19,13 @@ package web3ext
 
 var Modules = map[string]string{
 	"admin":      Admin_JS,
+	"authority":  Authority_JS,
 	"chequebook": Chequebook_JS,
 	"clique":     Clique_JS,
 	"debug":      Debug_JS,
 	"eth":        Eth_JS,
+	"ethash":     Ethash_JS,
+	"evm":        Evm_JS,
 	"miner":      Miner_JS,
 	"net":        Net_JS,
 	"personal":   Personal_JS,
@@ -63,6 +66,32 @@ web3._extend({
 });
 `
 
+const Authority_JS = `
+web3._extend({
+	property: 'authority',
+	methods: [
+		new web3._extend.Method({
+			name: 'getSnapshot',
+			call: 'authority_getSnapshot',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getValidators',
+			call: 'authority_getValidators',
+			params: 1,
+			inputFormatter: [null]
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'finalizedHeader',
+			getter: 'authority_getFinalizedHeader'
+		}),
+	]
+});
+`
+
 const Clique_JS = `
 web3._extend({
 	property: 'clique',
@@ -99,6 +128,23 @@ web3._extend({
 			call: 'clique_discard',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'status',
+			call: 'clique_status',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getVotes',
+			call: 'clique_getVotes',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getSignerHistory',
+			call: 'clique_getSignerHistory',
+			params: 1
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -123,6 +169,10 @@ web3._extend({
 			call: 'admin_removePeer',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'rotateNodeKey',
+			call: 'admin_rotateNodeKey'
+		}),
 		new web3._extend.Method({
 			name: 'exportChain',
 			call: 'admin_exportChain',
@@ -418,6 +468,11 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'sendPrivateTransaction',
+			call: 'eth_sendPrivateTransaction',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'getRawTransaction',
 			call: 'eth_getRawTransactionByHash',
@@ -431,8 +486,25 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter, web3._extend.utils.toHex]
 		}),
+		new web3._extend.Method({
+			name: 'feeHistory',
+			call: 'eth_feeHistory',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter, null]
+		}),
+		new web3._extend.Method({
+			name: 'createAccessList',
+			call: 'eth_createAccessList',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
 	],
 	properties: [
+		new web3._extend.Property({
+			name: 'maxPriorityFeePerGas',
+			getter: 'eth_maxPriorityFeePerGas',
+			outputFormatter: web3._extend.utils.toBigNumber
+		}),
 		new web3._extend.Property({
 			name: 'pendingTransactions',
 			getter: 'eth_pendingTransactions',
@@ -449,6 +521,48 @@ web3._extend({
 });
 `
 
+const Ethash_JS = `
+web3._extend({
+	property: 'ethash',
+	methods: [
+		new web3._extend.Method({
+			name: 'generateDag',
+			call: 'ethash_generateDag',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'pruneDags',
+			call: 'ethash_pruneDags'
+		}),
+	],
+	properties: [
+		new web3._extend.Property({
+			name: 'dagStatus',
+			getter: 'ethash_dagStatus'
+		}),
+	]
+});
+`
+
+const Evm_JS = `
+web3._extend({
+	property: 'evm',
+	methods: [
+		new web3._extend.Method({
+			name: 'mine',
+			call: 'evm_mine',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'increaseTime',
+			call: 'evm_increaseTime',
+			params: 1
+		}),
+	]
+});
+`
+
 const Miner_JS = `
 web3._extend({
 	property: 'miner',
@@ -474,6 +588,17 @@ web3._extend({
 			call: 'miner_setExtra',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'setRecommitInterval',
+			call: 'miner_setRecommitInterval',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'sendBundle',
+			call: 'miner_sendBundle',
+			params: 2,
+			inputFormatter: [null, web3._extend.utils.fromDecimal]
+		}),
 		new web3._extend.Method({
 			name: 'setGasPrice',
 			call: 'miner_setGasPrice',
@@ -485,7 +610,12 @@ web3._extend({
 			call: 'miner_getHashrate'
 		}),
 	],
-	properties: []
+	properties: [
+		new web3._extend.Property({
+			name: 'stratumWorkers',
+			getter: 'miner_stratumWorkers'
+		}),
+	]
 });
 `
 
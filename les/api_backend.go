@@ -18,11 +18,13 @@ package les
 
 import (
 	"context"
+	"errors"
 	"math/big"
 
 	"github.com/AdelineCoin/go-adln/accounts"
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/common/math"
+	"github.com/AdelineCoin/go-adln/consensus"
 	"github.com/AdelineCoin/go-adln/core"
 	"github.com/AdelineCoin/go-adln/core/bloombits"
 	"github.com/AdelineCoin/go-adln/core/state"
@@ -59,7 +61,13 @@ func (b *LesApiBackend) HeaderByNumber(ctx context.Context, blockNr rpc.BlockNum
 	if blockNr == rpc.LatestBlockNumber || blockNr == rpc.PendingBlockNumber {
 		return b.eth.blockchain.CurrentHeader(), nil
 	}
-
+	if blockNr == rpc.FinalizedBlockNumber {
+		engine, ok := b.eth.engine.(consensus.FinalityEngine)
+		if !ok {
+			return nil, consensus.ErrNoFinality
+		}
+		return engine.FinalizedHeader(b.eth.blockchain.HeaderChain()), nil
+	}
 	return b.eth.blockchain.GetHeaderByNumberOdr(ctx, uint64(blockNr))
 }
 
@@ -105,6 +113,10 @@ func (b *LesApiBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return b.eth.txPool.Add(ctx, signedTx)
 }
 
+func (b *LesApiBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction, expiry uint64, release bool) error {
+	return errors.New("private transactions are not supported by light clients")
+}
+
 func (b *LesApiBackend) RemoveTx(txHash common.Hash) {
 	b.eth.txPool.RemoveTx(txHash)
 }
@@ -165,6 +177,14 @@ func (b *LesApiBackend) SuggestPrice(ctx context.Context) (*big.Int, error) {
 	return b.gpo.SuggestPrice(ctx)
 }
 
+func (b *LesApiBackend) SuggestTipCap(ctx context.Context) (*big.Int, error) {
+	return b.gpo.SuggestTipCap(ctx)
+}
+
+func (b *LesApiBackend) FeeHistory(ctx context.Context, blocks int, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*big.Int, [][]*big.Int, []*big.Int, []float64, error) {
+	return b.gpo.FeeHistory(ctx, blocks, lastBlock, rewardPercentiles)
+}
+
 func (b *LesApiBackend) ChainDb() ethdb.Database {
 	return b.eth.chainDb
 }
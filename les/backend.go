@@ -18,6 +18,7 @@
 package les
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
@@ -26,6 +27,7 @@ import (
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/common/hexutil"
 	"github.com/AdelineCoin/go-adln/consensus"
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
 	"github.com/AdelineCoin/go-adln/core"
 	"github.com/AdelineCoin/go-adln/core/bloombits"
 	"github.com/AdelineCoin/go-adln/core/types"
@@ -45,6 +47,10 @@ import (
 	rpc "github.com/AdelineCoin/go-adln/rpc"
 )
 
+// sealProofTimeout is the maximum time allowed for retrieving the seal proof of a
+// header from the network when verifying ethash seals via proofs.
+const sealProofTimeout = 10 * time.Second
+
 type LightEthereum struct {
 	config *eth.Config
 
@@ -114,6 +120,9 @@ func New(ctx *node.ServiceContext, config *eth.Config) (*LightEthereum, error) {
 	leth.serverPool = newServerPool(chainDb, quitSync, &leth.wg)
 	leth.retriever = newRetrieveManager(peers, leth.reqDist, leth.serverPool)
 	leth.odr = NewLesOdr(chainDb, leth.chtIndexer, leth.bloomTrieIndexer, leth.bloomIndexer, leth.retriever)
+	if engine, ok := leth.engine.(*ethash.Ethash); ok && config.Ethash.SealProofs {
+		engine.SetSealProofRetriever(leth.retrieveSealProof)
+	}
 	if leth.blockchain, err = light.NewLightChain(leth.odr, leth.chainConfig, leth.engine); err != nil {
 		return nil, err
 	}
@@ -138,6 +147,19 @@ func New(ctx *node.ServiceContext, config *eth.Config) (*LightEthereum, error) {
 	return leth, nil
 }
 
+// retrieveSealProof fetches the proof of the dataset pages accessed by the seal
+// of a header from the serving peers, verified against the given dataset root.
+func (s *LightEthereum) retrieveSealProof(header *types.Header, root common.Hash, size uint64) (*ethash.SealProof, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), sealProofTimeout)
+	defer cancel()
+
+	req := &light.SealProofRequest{Header: header, Root: root, Size: size}
+	if err := s.odr.Retrieve(ctx, req); err != nil {
+		return nil, err
+	}
+	return req.Proof, nil
+}
+
 func lesTopic(genesisHash common.Hash, protocolVersion uint) discv5.Topic {
 	var name string
 	switch protocolVersion {
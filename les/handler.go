@@ -28,6 +28,7 @@ import (
 
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/consensus"
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
 	"github.com/AdelineCoin/go-adln/core"
 	"github.com/AdelineCoin/go-adln/core/state"
 	"github.com/AdelineCoin/go-adln/core/types"
@@ -58,6 +59,7 @@ const (
 	MaxHelperTrieProofsFetch = 64  // Amount of merkle proofs to be fetched per retrieval request
 	MaxTxSend                = 64  // Amount of transactions to be send per request
 	MaxTxStatus              = 256 // Amount of transactions to queried per request
+	MaxSealProofsFetch       = 8   // Amount of ethash seal proofs to be fetched per retrieval request
 
 	disableClientRemovePeer = false
 )
@@ -86,6 +88,15 @@ type BlockChain interface {
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 }
 
+// sealProver is implemented by consensus engines able to prove the validity of
+// block seals to light clients that do not hold the full verification data.
+// Proofs are only served for epochs prepared in advance, as preparing one needs
+// the full dataset of the epoch and is far too expensive to do on request.
+type sealProver interface {
+	PrepareSealProofs(block uint64)
+	SealProof(header *types.Header) (*ethash.SealProof, error)
+}
+
 type txPool interface {
 	AddRemotes(txs []*types.Transaction) []error
 	Status(hashes []common.Hash) []core.TxStatus
@@ -99,6 +110,7 @@ type ProtocolManager struct {
 	chainConfig *params.ChainConfig
 	blockchain  BlockChain
 	chainDb     ethdb.Database
+	engine      consensus.Engine
 	odr         *LesOdr
 	server      *LesServer
 	serverPool  *serverPool
@@ -111,6 +123,8 @@ type ProtocolManager struct {
 	peers      *peerSet
 	maxPeers   int
 
+	serveSealProofs bool // Whether ethash seal proofs are prepared and served
+
 	SubProtocols []p2p.Protocol
 
 	eventMux *event.TypeMux
@@ -135,6 +149,7 @@ func NewProtocolManager(chainConfig *params.ChainConfig, lightSync bool, protoco
 		blockchain:  blockchain,
 		chainConfig: chainConfig,
 		chainDb:     chainDb,
+		engine:      engine,
 		odr:         odr,
 		networkId:   networkId,
 		txpool:      txpool,
@@ -253,6 +268,16 @@ func (pm *ProtocolManager) Stop() {
 	log.Info("Light Ethereum protocol stopped")
 }
 
+// sealProver returns the engine serving ethash seal proofs, or nil if serving
+// them is not enabled or not supported by the consensus engine.
+func (pm *ProtocolManager) sealProver() sealProver {
+	if !pm.serveSealProofs {
+		return nil
+	}
+	prover, _ := pm.engine.(sealProver)
+	return prover
+}
+
 func (pm *ProtocolManager) newPeer(pv int, nv uint64, p *p2p.Peer, rw p2p.MsgReadWriter) *peer {
 	return newPeer(pv, nv, p, newMeteredMsgWriter(rw))
 }
@@ -330,7 +355,7 @@ func (pm *ProtocolManager) handle(p *peer) error {
 	}
 }
 
-var reqList = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, GetProofsV1Msg, SendTxMsg, SendTxV2Msg, GetTxStatusMsg, GetHeaderProofsMsg, GetProofsV2Msg, GetHelperTrieProofsMsg}
+var reqList = []uint64{GetBlockHeadersMsg, GetBlockBodiesMsg, GetCodeMsg, GetReceiptsMsg, GetProofsV1Msg, SendTxMsg, SendTxV2Msg, GetTxStatusMsg, GetHeaderProofsMsg, GetProofsV2Msg, GetHelperTrieProofsMsg, GetSealProofsMsg}
 
 // handleMsg is invoked whenever an inbound message is received from a remote
 // peer. The remote connection is torn down upon returning any error.
@@ -1076,6 +1101,72 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 		p.fcServer.GotReply(resp.ReqID, resp.BV)
 
+	case GetSealProofsMsg:
+		p.Log().Trace("Received seal proofs request")
+		// Decode the retrieval message
+		var req struct {
+			ReqID  uint64
+			Hashes []common.Hash
+		}
+		if err := msg.Decode(&req); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		prover := pm.sealProver()
+		if prover == nil {
+			return errResp(ErrRequestRejected, "")
+		}
+		// Gather the proofs of the requested headers, skipping unknown ones and the
+		// ones of epochs not prepared for serving
+		var (
+			bytes  int
+			proofs []*ethash.SealProof
+		)
+		reqCnt := len(req.Hashes)
+		if reject(uint64(reqCnt), MaxSealProofsFetch) {
+			return errResp(ErrRequestRejected, "")
+		}
+		for _, hash := range req.Hashes {
+			if bytes >= softResponseLimit {
+				break
+			}
+			header := pm.blockchain.GetHeaderByHash(hash)
+			if header == nil {
+				continue
+			}
+			proof, err := prover.SealProof(header)
+			if err != nil {
+				p.Log().Debug("Failed to create seal proof", "hash", hash, "err", err)
+				continue
+			}
+			proofs = append(proofs, proof)
+			for i := range proof.Pages {
+				bytes += len(proof.Pages[i]) + len(proof.Branches[i])*common.HashLength
+			}
+		}
+		bv, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
+		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
+		return p.SendSealProofs(req.ReqID, bv, proofs)
+
+	case SealProofsMsg:
+		if pm.odr == nil {
+			return errResp(ErrUnexpectedResponse, "")
+		}
+
+		p.Log().Trace("Received seal proofs response")
+		var resp struct {
+			ReqID, BV uint64
+			Data      []*ethash.SealProof
+		}
+		if err := msg.Decode(&resp); err != nil {
+			return errResp(ErrDecode, "msg %v: %v", msg, err)
+		}
+		p.fcServer.GotReply(resp.ReqID, resp.BV)
+		deliverMsg = &Msg{
+			MsgType: MsgSealProofs,
+			ReqID:   resp.ReqID,
+			Obj:     resp.Data,
+		}
+
 	default:
 		p.Log().Trace("Received unknown message", "code", msg.Code)
 		return errResp(ErrInvalidMsgCode, "%v", msg.Code)
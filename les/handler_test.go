@@ -489,6 +489,51 @@ func TestGetBloombitsProofs(t *testing.T) {
 	}
 }
 
+// Tests that ethash seal proofs can be correctly retrieved, but only once their
+// epoch was prepared for serving.
+func TestGetSealProofsLes3(t *testing.T) {
+	db, _ := ethdb.NewMemDatabase()
+	pm := newTestProtocolManagerMust(t, false, 4, nil, nil, nil, db)
+	bc := pm.blockchain.(*core.BlockChain)
+
+	prover := ethash.NewTester()
+	pm.engine = prover
+	if pm.sealProver() != nil {
+		t.Fatalf("seal proofs served without being enabled")
+	}
+	pm.serveSealProofs = true
+
+	// Request the proofs of a known and an unknown block before preparation
+	header := bc.GetHeaderByNumber(2)
+	hashes := []common.Hash{header.Hash(), {}}
+
+	peer, _ := newTestPeer(t, "peer", lpv3, pm, true)
+	defer peer.close()
+
+	cost := peer.GetRequestCost(GetSealProofsMsg, len(hashes))
+	if cost == 0 {
+		t.Fatalf("seal proofs served for free")
+	}
+	sendRequest(peer.app, GetSealProofsMsg, 41, cost, hashes)
+	if err := expectResponse(peer.app, SealProofsMsg, 41, testBufLimit-cost, []*ethash.SealProof{}); err != nil {
+		t.Errorf("unprepared seal proofs mismatch: %v", err)
+	}
+	// Request the same proofs once prepared
+	if _, err := prover.DatasetRoot(header.Number.Uint64()); err != nil {
+		t.Fatalf("failed to prepare seal proofs: %v", err)
+	}
+	proof, err := prover.SealProof(header)
+	if err != nil {
+		t.Fatalf("failed to create seal proof: %v", err)
+	}
+	time.Sleep(time.Duration(2*cost) * time.Millisecond) // recharge the buffer
+
+	sendRequest(peer.app, GetSealProofsMsg, 42, cost, hashes)
+	if err := expectResponse(peer.app, SealProofsMsg, 42, testBufLimit-cost, []*ethash.SealProof{proof}); err != nil {
+		t.Errorf("seal proofs mismatch: %v", err)
+	}
+}
+
 func TestTransactionStatusLes2(t *testing.T) {
 	db, _ := ethdb.NewMemDatabase()
 	pm := newTestProtocolManagerMust(t, false, 0, nil, nil, nil, db)
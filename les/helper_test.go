@@ -24,6 +24,7 @@ import (
 	"math/big"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/consensus/ethash"
@@ -128,7 +129,7 @@ func testRCL() RequestCostList {
 	for i, code := range reqList {
 		cl[i].MsgCode = code
 		cl[i].BaseCost = 0
-		cl[i].ReqCost = 0
+		cl[i].ReqCost = uint64(minRequestTimes[code] / time.Millisecond)
 	}
 	return cl
 }
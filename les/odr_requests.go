@@ -24,6 +24,7 @@ import (
 	"fmt"
 
 	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
 	"github.com/AdelineCoin/go-adln/core"
 	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/crypto"
@@ -68,6 +69,8 @@ func LesRequest(req light.OdrRequest) LesOdrRequest {
 		return (*ChtRequest)(r)
 	case *light.BloomRequest:
 		return (*BloomRequest)(r)
+	case *light.SealProofRequest:
+		return (*SealProofRequest)(r)
 	default:
 		return nil
 	}
@@ -193,7 +196,7 @@ func (r *TrieRequest) GetCost(peer *peer) uint64 {
 	switch peer.version {
 	case lpv1:
 		return peer.GetRequestCost(GetProofsV1Msg, 1)
-	case lpv2:
+	case lpv2, lpv3:
 		return peer.GetRequestCost(GetProofsV2Msg, 1)
 	default:
 		panic(nil)
@@ -353,7 +356,7 @@ func (r *ChtRequest) GetCost(peer *peer) uint64 {
 	switch peer.version {
 	case lpv1:
 		return peer.GetRequestCost(GetHeaderProofsMsg, 1)
-	case lpv2:
+	case lpv2, lpv3:
 		return peer.GetRequestCost(GetHelperTrieProofsMsg, 1)
 	default:
 		panic(nil)
@@ -564,3 +567,45 @@ func (db *readTraceDB) Has(key []byte) (bool, error) {
 	_, err := db.Get(key)
 	return err == nil, nil
 }
+
+// SealProofRequest is the ODR request type for ethash seal proofs
+type SealProofRequest light.SealProofRequest
+
+// GetCost returns the cost of the given ODR request according to the serving
+// peer's cost table (implementation of LesOdrRequest)
+func (r *SealProofRequest) GetCost(peer *peer) uint64 {
+	return peer.GetRequestCost(GetSealProofsMsg, 1)
+}
+
+// CanSend tells if a certain peer is suitable for serving the given request
+func (r *SealProofRequest) CanSend(peer *peer) bool {
+	return peer.version >= lpv3 && peer.HasBlock(r.Header.Hash(), r.Header.Number.Uint64())
+}
+
+// Request sends an ODR request to the LES network (implementation of LesOdrRequest)
+func (r *SealProofRequest) Request(reqID uint64, peer *peer) error {
+	peer.Log().Debug("Requesting seal proof", "hash", r.Header.Hash())
+	return peer.RequestSealProofs(reqID, r.GetCost(peer), []common.Hash{r.Header.Hash()})
+}
+
+// Valid processes an ODR request reply message from the LES network
+// returns true and stores results in memory if the message was a valid reply
+// to the request (implementation of LesOdrRequest)
+func (r *SealProofRequest) Validate(db ethdb.Database, msg *Msg) error {
+	log.Debug("Validating seal proof", "hash", r.Header.Hash())
+
+	// Ensure we have a correct message with a single seal proof
+	if msg.MsgType != MsgSealProofs {
+		return errInvalidMessageType
+	}
+	proofs := msg.Obj.([]*ethash.SealProof)
+	if len(proofs) != 1 {
+		return errInvalidEntryCount
+	}
+	// Verify the proof against the trusted dataset root
+	if err := ethash.VerifySealProof(r.Header, proofs[0], r.Root, r.Size); err != nil {
+		return err
+	}
+	r.Proof = proofs[0]
+	return nil
+}
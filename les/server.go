@@ -22,6 +22,7 @@ import (
 	"encoding/binary"
 	"math"
 	"sync"
+	"time"
 
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/core"
@@ -92,6 +93,7 @@ func NewLesServer(eth *eth.Ethereum, config *eth.Config) (*LesServer, error) {
 
 	srv.chtIndexer.Start(eth.BlockChain())
 	pm.server = srv
+	pm.serveSealProofs = config.LightServSealProofs
 
 	srv.defParams = &flowcontrol.ServerParams{
 		BufLimit:    300000000,
@@ -162,6 +164,14 @@ func (list RequestCostList) decode() requestCostTable {
 	return table
 }
 
+// minRequestTimes are lower bounds for the serving time charged per requested
+// item, for requests whose serving time statistics may not reflect their real
+// cost yet. Creating a seal proof rehashes the dataset pages around each of the
+// 64 pages accessed by the seal, about 8K keccak hashes.
+var minRequestTimes = map[uint64]time.Duration{
+	GetSealProofsMsg: 10 * time.Millisecond,
+}
+
 type linReg struct {
 	sumX, sumY, sumXX, sumXY float64
 	cnt                      uint64
@@ -279,7 +289,7 @@ func (s *requestCostStats) store() {
 	}
 }
 
-func (s *requestCostStats) getCurrentList() RequestCostList {
+func (s *requestCostStats) getCurrentList(minRecharge uint64) RequestCostList {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
@@ -299,6 +309,12 @@ func (s *requestCostStats) getCurrentList() RequestCostList {
 		list[idx].MsgCode = code
 		list[idx].BaseCost = uint64(b * 2)
 		list[idx].ReqCost = uint64(m * 2)
+
+		if min, ok := minRequestTimes[code]; ok {
+			if cost := uint64(min/time.Millisecond) * minRecharge; list[idx].ReqCost < cost {
+				list[idx].ReqCost = cost
+			}
+		}
 	}
 	return list
 }
@@ -318,12 +334,21 @@ func (pm *ProtocolManager) blockLoop() {
 	pm.wg.Add(1)
 	headCh := make(chan core.ChainHeadEvent, 10)
 	headSub := pm.blockchain.SubscribeChainHeadEvent(headCh)
+
+	// Keep seal proofs available for the epoch of the chain head if enabled
+	prover := pm.sealProver()
+	if prover != nil {
+		prover.PrepareSealProofs(pm.blockchain.CurrentHeader().Number.Uint64())
+	}
 	go func() {
 		var lastHead *types.Header
 		lastBroadcastTd := common.Big0
 		for {
 			select {
 			case ev := <-headCh:
+				if prover != nil {
+					prover.PrepareSealProofs(ev.Block.NumberU64())
+				}
 				peers := pm.peers.AllPeers()
 				if len(peers) > 0 {
 					header := ev.Block.Header()
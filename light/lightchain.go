@@ -139,6 +139,12 @@ func (self *LightChain) Odr() OdrBackend {
 	return self.odr
 }
 
+// HeaderChain returns the underlying header chain, which can be used as a
+// consensus.ChainReader.
+func (self *LightChain) HeaderChain() *core.HeaderChain {
+	return self.hc
+}
+
 // loadLastState loads the last known chain state from the database. This method
 // assumes that the chain manager mutex is held.
 func (self *LightChain) loadLastState() error {
@@ -23,6 +23,7 @@ import (
 	"math/big"
 
 	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
 	"github.com/AdelineCoin/go-adln/core"
 	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/ethdb"
@@ -169,3 +170,16 @@ func (req *BloomRequest) StoreResult(db ethdb.Database) {
 		core.WriteBloomBits(db, req.BitIdx, sectionIdx, sectionHead, req.BloomBits[i])
 	}
 }
+
+// SealProofRequest is the ODR request type for retrieving the Merkle proofs of
+// the ethash dataset items accessed by the seal of a header
+type SealProofRequest struct {
+	OdrRequest
+	Header *types.Header
+	Root   common.Hash
+	Size   uint64
+	Proof  *ethash.SealProof
+}
+
+// StoreResult does nothing, seal proofs are only needed once for verification
+func (req *SealProofRequest) StoreResult(db ethdb.Database) {}
@@ -17,6 +17,12 @@ func NewHealthcheck(f func(Healthcheck)) Healthcheck {
 	return &StandardHealthcheck{nil, f}
 }
 
+// NewHealthcheckForced constructs a new Healthcheck which will use the given
+// function to update its status, even if metrics collection is disabled.
+func NewHealthcheckForced(f func(Healthcheck)) Healthcheck {
+	return &StandardHealthcheck{nil, f}
+}
+
 // NilHealthcheck is a no-op.
 type NilHealthcheck struct{}
 
@@ -0,0 +1,152 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AdelineCoin/go-adln/metrics"
+)
+
+var (
+	typeGaugeTpl   = "# TYPE %s gauge\n"
+	typeCounterTpl = "# TYPE %s counter\n"
+	typeSummaryTpl = "# TYPE %s summary\n"
+	keyValueTpl    = "%s %s\n"
+	keyQuantileTpl = "%s{quantile=\"%s\"} %s\n"
+)
+
+var (
+	// summaryQuantiles are the quantiles reported for histograms and timers.
+	summaryQuantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999, 0.9999}
+
+	// resettingQuantiles are the percentiles reported for resetting timers.
+	resettingQuantiles = []float64{50, 95, 99}
+)
+
+// collector is a byte buffer aggregating the Prometheus reports of different
+// metric types.
+type collector struct {
+	buff *bytes.Buffer
+	seen map[string]bool // Sanitised names already reported, to skip collisions
+}
+
+// newCollector creates a new Prometheus metric aggregator.
+func newCollector() *collector {
+	return &collector{
+		buff: &bytes.Buffer{},
+		seen: make(map[string]bool),
+	}
+}
+
+// add renders a single metric into the report, dispatching on its type. Metric
+// types not known to the collector are silently skipped.
+func (c *collector) add(name string, i interface{}) {
+	name = mutateKey(name)
+	if c.seen[name] {
+		return
+	}
+	c.seen[name] = true
+
+	switch m := i.(type) {
+	case metrics.Counter:
+		// Counters may be decremented, so they can't be exposed as Prometheus
+		// counters, which are required to be monotonic.
+		c.writeGauge(name, float64(m.Count()))
+	case metrics.Gauge:
+		c.writeGauge(name, float64(m.Value()))
+	case metrics.GaugeFloat64:
+		c.writeGauge(name, m.Value())
+	case metrics.Meter:
+		m = m.Snapshot()
+		c.buff.WriteString(fmt.Sprintf(typeCounterTpl, name))
+		c.buff.WriteString(fmt.Sprintf(keyValueTpl, name, formatValue(float64(m.Count()))))
+		c.writeGauge(name+"_rate1", m.Rate1())
+		c.writeGauge(name+"_rate5", m.Rate5())
+		c.writeGauge(name+"_rate15", m.Rate15())
+	case metrics.Histogram:
+		h := m.Snapshot()
+		c.writeSummary(name, h.Count(), float64(h.Sum()), summaryQuantiles, h.Percentiles(summaryQuantiles))
+	case metrics.Timer:
+		t := m.Snapshot()
+		c.writeSummary(name, t.Count(), float64(t.Sum()), summaryQuantiles, t.Percentiles(summaryQuantiles))
+	case metrics.ResettingTimer:
+		t := m.Snapshot()
+		values := t.Values()
+		if len(values) == 0 {
+			return
+		}
+		var sum float64
+		for _, v := range values {
+			sum += float64(v)
+		}
+		ps := t.Percentiles(resettingQuantiles)
+		vals := make([]float64, len(ps))
+		for i, p := range ps {
+			vals[i] = float64(p)
+		}
+		quantiles := make([]float64, len(resettingQuantiles))
+		for i, q := range resettingQuantiles {
+			quantiles[i] = q / 100
+		}
+		c.writeSummary(name, int64(len(values)), sum, quantiles, vals)
+	}
+}
+
+// writeGauge renders a single valued gauge metric.
+func (c *collector) writeGauge(name string, value float64) {
+	c.buff.WriteString(fmt.Sprintf(typeGaugeTpl, name))
+	c.buff.WriteString(fmt.Sprintf(keyValueTpl, name, formatValue(value)))
+}
+
+// writeSummary renders a summary metric with the given quantile values, along
+// with the sum and count of the observations.
+func (c *collector) writeSummary(name string, count int64, sum float64, quantiles []float64, values []float64) {
+	c.buff.WriteString(fmt.Sprintf(typeSummaryTpl, name))
+	for i, q := range quantiles {
+		c.buff.WriteString(fmt.Sprintf(keyQuantileTpl, name, formatValue(q), formatValue(values[i])))
+	}
+	c.buff.WriteString(fmt.Sprintf(keyValueTpl, name+"_sum", formatValue(sum)))
+	c.buff.WriteString(fmt.Sprintf(keyValueTpl, name+"_count", formatValue(float64(count))))
+}
+
+// formatValue renders a sample value in the Prometheus text format, which also
+// covers the NaN and ±Inf special values.
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// mutateKey sanitises a metric name into a valid Prometheus one, replacing all
+// characters outside of [a-zA-Z0-9_:] with underscores and ensuring it doesn't
+// start with a digit.
+func mutateKey(key string) string {
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		key = "_" + key
+	}
+	return key
+}
@@ -0,0 +1,172 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/metrics"
+)
+
+func init() {
+	metrics.Enabled = true
+}
+
+func TestMutateKey(t *testing.T) {
+	tests := []struct {
+		key, want string
+	}{
+		{"chain/head/block", "chain_head_block"},
+		{"p2p/InboundTraffic", "p2p_InboundTraffic"},
+		{"rpc/duration/eth_call", "rpc_duration_eth_call"},
+		{"eth/db/chaindata/compact-time", "eth_db_chaindata_compact_time"},
+		{"les/peer.count", "les_peer_count"},
+		{"2fa", "_2fa"},
+		{"ns:sub", "ns:sub"},
+		{"", "_"},
+	}
+	for _, tt := range tests {
+		if have := mutateKey(tt.key); have != tt.want {
+			t.Errorf("%q: sanitised name mismatch: have %q, want %q", tt.key, have, tt.want)
+		}
+	}
+}
+
+func TestCollector(t *testing.T) {
+	c := newCollector()
+
+	counter := metrics.NewCounter()
+	counter.Inc(12345)
+	c.add("test/counter", counter)
+
+	gauge := metrics.NewGauge()
+	gauge.Update(23456)
+	c.add("test/gauge", gauge)
+
+	gaugeFloat64 := metrics.NewGaugeFloat64()
+	gaugeFloat64.Update(34567.89)
+	c.add("test/gauge_float64", gaugeFloat64)
+
+	histogram := metrics.NewHistogram(metrics.NewUniformSample(100))
+	for i := int64(1); i <= 4; i++ {
+		histogram.Update(i)
+	}
+	c.add("test/histogram", histogram)
+
+	timer := metrics.NewTimer()
+	defer timer.Stop()
+	timer.Update(20 * time.Millisecond)
+	timer.Update(21 * time.Millisecond)
+	timer.Update(22 * time.Millisecond)
+	timer.Update(120 * time.Millisecond)
+	timer.Update(23 * time.Millisecond)
+	timer.Update(24 * time.Millisecond)
+	c.add("test/timer", timer)
+
+	resettingTimer := metrics.NewResettingTimer()
+	resettingTimer.Update(10 * time.Millisecond)
+	resettingTimer.Update(11 * time.Millisecond)
+	resettingTimer.Update(12 * time.Millisecond)
+	resettingTimer.Update(120 * time.Millisecond)
+	resettingTimer.Update(13 * time.Millisecond)
+	resettingTimer.Update(14 * time.Millisecond)
+	c.add("test/resetting_timer", resettingTimer)
+
+	emptyResettingTimer := metrics.NewResettingTimer()
+	c.add("test/empty_resetting_timer", emptyResettingTimer)
+
+	// Colliding sanitised names must only be reported once
+	c.add("test.counter", counter)
+
+	const expectedOutput = `# TYPE test_counter gauge
+test_counter 12345
+# TYPE test_gauge gauge
+test_gauge 23456
+# TYPE test_gauge_float64 gauge
+test_gauge_float64 34567.89
+# TYPE test_histogram summary
+test_histogram{quantile="0.5"} 2.5
+test_histogram{quantile="0.75"} 3.75
+test_histogram{quantile="0.95"} 4
+test_histogram{quantile="0.99"} 4
+test_histogram{quantile="0.999"} 4
+test_histogram{quantile="0.9999"} 4
+test_histogram_sum 10
+test_histogram_count 4
+# TYPE test_timer summary
+test_timer{quantile="0.5"} 22500000
+test_timer{quantile="0.75"} 48000000
+test_timer{quantile="0.95"} 120000000
+test_timer{quantile="0.99"} 120000000
+test_timer{quantile="0.999"} 120000000
+test_timer{quantile="0.9999"} 120000000
+test_timer_sum 230000000
+test_timer_count 6
+# TYPE test_resetting_timer summary
+test_resetting_timer{quantile="0.5"} 12000000
+test_resetting_timer{quantile="0.95"} 120000000
+test_resetting_timer{quantile="0.99"} 120000000
+test_resetting_timer_sum 180000000
+test_resetting_timer_count 6
+`
+	if have := c.buff.String(); have != expectedOutput {
+		t.Errorf("output mismatch:\nhave:\n%s\nwant:\n%s", have, expectedOutput)
+	}
+}
+
+// Tests that meters are exposed as a monotonic counter along with their rates.
+// The rates themselves depend on the meter ticks, so only their presence is
+// checked.
+func TestCollectorMeter(t *testing.T) {
+	c := newCollector()
+
+	meter := metrics.NewMeter()
+	defer meter.Stop()
+	meter.Mark(9999999)
+	c.add("test/meter", meter)
+
+	have := c.buff.String()
+	if !strings.HasPrefix(have, "# TYPE test_meter counter\ntest_meter 9999999\n") {
+		t.Errorf("meter count missing:\n%s", have)
+	}
+	for _, rate := range []string{"rate1", "rate5", "rate15"} {
+		if !strings.Contains(have, "# TYPE test_meter_"+rate+" gauge\n") {
+			t.Errorf("meter %s missing:\n%s", rate, have)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	metrics.NewRegisteredGauge("b/gauge", registry).Update(2)
+	metrics.NewRegisteredCounter("a/counter", registry).Inc(1)
+
+	rec := httptest.NewRecorder()
+	Handler(registry).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/metrics/prometheus", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("content type mismatch: have %q", ct)
+	}
+	want := "# TYPE a_counter gauge\na_counter 1\n# TYPE b_gauge gauge\nb_gauge 2\n"
+	if have := rec.Body.String(); have != want {
+		t.Errorf("output mismatch:\nhave:\n%s\nwant:\n%s", have, want)
+	}
+}
@@ -17,19 +17,27 @@
 package miner
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"math/big"
+	"net/http"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/common/hexutil"
 	"github.com/AdelineCoin/go-adln/consensus"
 	"github.com/AdelineCoin/go-adln/consensus/ethash"
 	"github.com/AdelineCoin/go-adln/core/types"
 	"github.com/AdelineCoin/go-adln/log"
 )
 
+// notifyTimeout is the maximum time allowed for delivering a work notification
+// to a single remote URL.
+const notifyTimeout = time.Second
+
 type hashrate struct {
 	ping time.Time
 	rate uint64
@@ -50,15 +58,31 @@ type RemoteAgent struct {
 	hashrateMu sync.RWMutex
 	hashrate   map[common.Hash]hashrate
 
+	notify       []string     // URLs to post new work packages to
+	notifyClient *http.Client // HTTP client used to deliver the work notifications
+
 	running int32 // running indicates whether the agent is active. Call atomically
 }
 
-func NewRemoteAgent(chain consensus.ChainReader, engine consensus.Engine) *RemoteAgent {
+// workNotification is the JSON payload posted to the notification URLs whenever
+// the sealing work changes.
+type workNotification struct {
+	HeaderHash common.Hash    `json:"headerHash"`
+	SeedHash   common.Hash    `json:"seedHash"`
+	Boundary   common.Hash    `json:"boundary"`
+	Number     hexutil.Uint64 `json:"number"`
+}
+
+// NewRemoteAgent creates an agent serving work to external miners. Every time the
+// work changes, it is also posted to the given notification URLs.
+func NewRemoteAgent(chain consensus.ChainReader, engine consensus.Engine, notify []string) *RemoteAgent {
 	return &RemoteAgent{
-		chain:    chain,
-		engine:   engine,
-		work:     make(map[common.Hash]*Work),
-		hashrate: make(map[common.Hash]hashrate),
+		chain:        chain,
+		engine:       engine,
+		work:         make(map[common.Hash]*Work),
+		hashrate:     make(map[common.Hash]hashrate),
+		notify:       notify,
+		notifyClient: &http.Client{Timeout: notifyTimeout},
 	}
 }
 
@@ -115,15 +139,10 @@ func (a *RemoteAgent) GetWork() ([3]string, error) {
 	if a.currentWork != nil {
 		block := a.currentWork.Block
 
-		res[0] = block.HashNoNonce().Hex()
-		seedHash := ethash.SeedHash(block.NumberU64())
-		res[1] = common.BytesToHash(seedHash).Hex()
-		// Calculate the "target" to be returned to the external miner
-		n := big.NewInt(1)
-		n.Lsh(n, 255)
-		n.Div(n, block.Difficulty())
-		n.Lsh(n, 1)
-		res[2] = common.BytesToHash(n.Bytes()).Hex()
+		pkg := newWorkNotification(block)
+		res[0] = pkg.HeaderHash.Hex()
+		res[1] = pkg.SeedHash.Hex()
+		res[2] = pkg.Boundary.Hex()
 
 		a.work[block.HashNoNonce()] = a.currentWork
 		return res, nil
@@ -131,6 +150,42 @@ func (a *RemoteAgent) GetWork() ([3]string, error) {
 	return res, errors.New("No work available yet, don't panic.")
 }
 
+// newWorkNotification assembles the work package of a block to be sealed.
+func newWorkNotification(block *types.Block) *workNotification {
+	// Calculate the "target" to be returned to the external miner
+	n := big.NewInt(1)
+	n.Lsh(n, 255)
+	n.Div(n, block.Difficulty())
+	n.Lsh(n, 1)
+
+	return &workNotification{
+		HeaderHash: block.HashNoNonce(),
+		SeedHash:   common.BytesToHash(ethash.SeedHash(block.NumberU64())),
+		Boundary:   common.BytesToHash(n.Bytes()),
+		Number:     hexutil.Uint64(block.NumberU64()),
+	}
+}
+
+// sendNotification posts a work package to all the notification URLs. Delivery
+// happens in the background, failures are only logged.
+func (a *RemoteAgent) sendNotification(work *Work) {
+	blob, err := json.Marshal(newWorkNotification(work.Block))
+	if err != nil {
+		log.Error("Failed to encode work notification", "err", err)
+		return
+	}
+	for _, url := range a.notify {
+		go func(url string) {
+			res, err := a.notifyClient.Post(url, "application/json", bytes.NewReader(blob))
+			if err != nil {
+				log.Warn("Failed to notify remote miner", "url", url, "err", err)
+				return
+			}
+			res.Body.Close()
+		}(url)
+	}
+}
+
 // SubmitWork tries to inject a pow solution into the remote agent, returning
 // whether the solution was accepted or not (not can be both a bad pow as well as
 // any other error, like no work pending).
@@ -179,7 +234,15 @@ func (a *RemoteAgent) loop(workCh chan *Work, quitCh chan struct{}) {
 		case work := <-workCh:
 			a.mu.Lock()
 			a.currentWork = work
+			// Notified miners never call GetWork, track the work for their solutions
+			if len(a.notify) > 0 {
+				a.work[work.Block.HashNoNonce()] = work
+			}
 			a.mu.Unlock()
+
+			if len(a.notify) > 0 {
+				a.sendNotification(work)
+			}
 		case <-ticker.C:
 			// cleanup
 			a.mu.Lock()
@@ -0,0 +1,68 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
+	"github.com/AdelineCoin/go-adln/core/types"
+)
+
+// Tests that new work packages are posted to the notification URLs, and that
+// solutions to notified work are accepted without a prior GetWork.
+func TestRemoteAgentNotify(t *testing.T) {
+	sink := make(chan *workNotification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		pkg := new(workNotification)
+		if err := json.NewDecoder(req.Body).Decode(pkg); err != nil {
+			t.Errorf("failed to decode work notification: %v", err)
+		}
+		sink <- pkg
+	}))
+	defer server.Close()
+
+	agent := NewRemoteAgent(nil, ethash.NewFaker(), []string{server.URL})
+	results := make(chan *Result, 1)
+	agent.SetReturnCh(results)
+	agent.Start()
+	defer agent.Stop()
+
+	work := newStratumWork(42, 1<<20)
+	agent.Work() <- work
+
+	select {
+	case pkg := <-sink:
+		if want := newWorkNotification(work.Block); *pkg != *want {
+			t.Fatalf("work notification mismatch: have %+v, want %+v", pkg, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("work notification timeout")
+	}
+	if !agent.SubmitWork(types.EncodeNonce(1), work.Block.MixDigest(), work.Block.HashNoNonce()) {
+		t.Fatalf("solution to notified work rejected")
+	}
+	select {
+	case <-results:
+	case <-time.After(time.Second):
+		t.Fatalf("sealed block not returned")
+	}
+}
@@ -0,0 +1,889 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus"
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/log"
+)
+
+const (
+	// stratumMaxSessions is the maximum number of miner connections served at once.
+	stratumMaxSessions = 1024
+
+	// stratumMaxLine is the maximum length of a single request line accepted.
+	stratumMaxLine = 16 * 1024
+
+	// stratumReadTimeout is the time after which an idle miner connection is dropped.
+	stratumReadTimeout = 10 * time.Minute
+
+	// stratumWriteTimeout is the maximum time allowed for sending a single message.
+	stratumWriteTimeout = 10 * time.Second
+
+	// stratumSendQueue is the number of messages queued for a miner before it is
+	// considered too slow and disconnected.
+	stratumSendQueue = 64
+
+	// stratumJobLifetime is the time for which solutions to past jobs are still
+	// accepted, matching the remote agent's work retention.
+	stratumJobLifetime = 7 * (12 * time.Second)
+
+	// stratumHashrateWindow is the period over which the accepted shares of a
+	// worker are used to estimate its hashrate.
+	stratumHashrateWindow = 10 * time.Minute
+
+	// stratumVersion is the protocol identifier of the EthereumStratum dialect.
+	stratumVersion = "EthereumStratum/1.0.0"
+)
+
+var (
+	// stratumDiffOne is the number of hashes represented by an EthereumStratum
+	// difficulty of 1.0.
+	stratumDiffOne = new(big.Float).SetInt(new(big.Int).Lsh(common.Big1, 32))
+
+	// maxUint256 is a big integer representing 2^256.
+	maxUint256 = new(big.Int).Lsh(common.Big1, 256)
+)
+
+var (
+	errStratumUnauthorized = errors.New("unauthorized worker")
+	errStratumUnsupported  = errors.New("unsupported method")
+	errStratumParams       = errors.New("invalid parameters")
+	errStratumNoWork       = errors.New("no work available")
+	errStratumUnknownJob   = errors.New("job not found")
+	errStratumDuplicate    = errors.New("duplicate share")
+	errStratumLowDiff      = errors.New("low difficulty share")
+	errStratumNoHashimoto  = errors.New("consensus engine cannot reconstruct seals")
+)
+
+// stratumDialect is the flavour of the stratum protocol spoken by a miner.
+type stratumDialect int
+
+const (
+	dialectUnknown stratumDialect = iota // No request received yet
+	dialectStratum                       // EthereumStratum/1.0.0 (mining.* methods)
+	dialectProxy                         // ethproxy (eth_* methods over raw TCP)
+)
+
+// hashimotoEngine is implemented by consensus engines able to recompute the seal
+// of a nonce, required to accept solutions from miners not reporting the digest.
+type hashimotoEngine interface {
+	Hashimoto(number uint64, hash common.Hash, nonce uint64) (common.Hash, common.Hash)
+}
+
+// stratumRequest is a single request sent by a stratum miner.
+type stratumRequest struct {
+	Id     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Worker string            `json:"worker"`
+}
+
+// param retrieves the string parameter at the given position, returning an empty
+// string if it does not exist or is not a string.
+func (req *stratumRequest) param(index int) string {
+	if index >= len(req.Params) {
+		return ""
+	}
+	var value string
+	if err := json.Unmarshal(req.Params[index], &value); err != nil {
+		return ""
+	}
+	return value
+}
+
+// stratumResponse is the reply sent to a miner request, also used for pushing
+// new work to ethproxy miners.
+type stratumResponse struct {
+	Id      json.RawMessage `json:"id"`
+	Version string          `json:"jsonrpc,omitempty"`
+	Result  interface{}     `json:"result"`
+	Error   interface{}     `json:"error"`
+}
+
+// stratumNotification is a server initiated message of the EthereumStratum dialect.
+type stratumNotification struct {
+	Id     interface{}   `json:"id"`
+	Method string        `json:"method"`
+	Params []interface{} `json:"params"`
+}
+
+// stratumJob is a mining job handed out to the stratum miners.
+type stratumJob struct {
+	id   string
+	work *Work
+
+	hash common.Hash // Sealing hash of the block (header hash without the nonce)
+	seed common.Hash // Seed hash of the ethash epoch of the block
+
+	shares map[uint64]struct{} // Nonces already submitted for this job
+}
+
+// stratumSubmission is a solution submitted by a miner, pending verification.
+type stratumSubmission struct {
+	job       *stratumJob
+	nonce     uint64
+	digest    common.Hash
+	hashimoto bool // Whether the digest needs to be recomputed from the nonce
+}
+
+// stratumShare is an accepted share, tracked for hashrate estimation.
+type stratumShare struct {
+	time       time.Time
+	difficulty float64
+}
+
+// stratumWorker tracks the statistics of a named worker across its connections.
+type stratumWorker struct {
+	name     string
+	sessions int       // Number of connections currently authorized as this worker
+	since    time.Time // Time the worker was first seen
+	seen     time.Time // Time the worker was last active
+
+	valid    uint64
+	stale    uint64
+	invalid  uint64
+	blocks   uint64
+	reported uint64 // Hashrate self-reported by the miner
+
+	lastShare time.Time
+	shares    []stratumShare // Shares accepted within the hashrate window
+}
+
+// hashrate estimates the hashrate of the worker from its recently accepted shares.
+func (w *stratumWorker) hashrate(now time.Time) uint64 {
+	window := now.Sub(w.since)
+	if window > stratumHashrateWindow {
+		window = stratumHashrateWindow
+	}
+	if window < time.Second {
+		return 0
+	}
+	var total float64
+	for _, share := range w.shares {
+		total += share.difficulty
+	}
+	return uint64(total / window.Seconds())
+}
+
+// prune drops all the shares which fell out of the hashrate window.
+func (w *stratumWorker) prune(now time.Time) {
+	var i int
+	for i < len(w.shares) && now.Sub(w.shares[i].time) > stratumHashrateWindow {
+		i++
+	}
+	w.shares = w.shares[i:]
+}
+
+// StratumWorker is a statistics snapshot of a worker mining via stratum.
+type StratumWorker struct {
+	Name          string    `json:"name"`
+	Sessions      int       `json:"sessions"`
+	ValidShares   uint64    `json:"validShares"`
+	StaleShares   uint64    `json:"staleShares"`
+	InvalidShares uint64    `json:"invalidShares"`
+	Blocks        uint64    `json:"blocks"`
+	Hashrate      uint64    `json:"hashrate"`
+	Reported      uint64    `json:"reportedHashrate"`
+	LastShare     time.Time `json:"lastShare"`
+}
+
+// StratumServer is a mining agent serving work to external miners over TCP via
+// the stratum protocol. Both the EthereumStratum/1.0.0 and the ethproxy dialects
+// are supported, the dialect being detected from the first request of a miner.
+type StratumServer struct {
+	chain      consensus.ChainReader
+	engine     consensus.Engine
+	difficulty *big.Int // Default share difficulty, nil to use the block difficulty
+
+	listener net.Listener
+	sessions map[*stratumSession]struct{}
+	workers  map[string]*stratumWorker
+	closed   bool
+
+	current     *stratumJob            // Job currently being mined
+	jobs        map[string]*stratumJob // Recent jobs still accepting solutions
+	jobSeq      uint64                 // Sequence number of the last job created
+	nonces      uint16                 // Sequence number of the last extranonce assigned
+	extranonces map[string]struct{}    // Extranonces assigned to connected miners
+
+	mu sync.Mutex
+	wg sync.WaitGroup
+
+	quitCh   chan struct{}
+	workCh   chan *Work
+	returnCh chan<- *Result
+
+	running int32 // running indicates whether the agent is active. Call atomically
+}
+
+// NewStratumServer creates a stratum mining agent. The difficulty is the share
+// difficulty assigned to workers by default, zero meaning that only full blocks
+// are accepted as shares.
+func NewStratumServer(chain consensus.ChainReader, engine consensus.Engine, difficulty uint64) *StratumServer {
+	server := &StratumServer{
+		chain:    chain,
+		engine:   engine,
+		sessions: make(map[*stratumSession]struct{}),
+		workers:  make(map[string]*stratumWorker),
+		jobs:     make(map[string]*stratumJob),
+
+		extranonces: make(map[string]struct{}),
+	}
+	if difficulty > 0 {
+		server.difficulty = new(big.Int).SetUint64(difficulty)
+	}
+	return server
+}
+
+// Listen starts accepting stratum miner connections on the given TCP address.
+func (s *StratumServer) Listen(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.listener = listener
+	s.mu.Unlock()
+
+	s.wg.Add(1)
+	go s.accept(listener)
+
+	log.Info("Stratum server started", "addr", listener.Addr())
+	return nil
+}
+
+// Addr returns the address the server is listening on, or nil if not listening.
+func (s *StratumServer) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Close stops accepting new connections and disconnects all the miners.
+func (s *StratumServer) Close() {
+	s.mu.Lock()
+	s.closed = true
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	for session := range s.sessions {
+		session.conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+func (s *StratumServer) Work() chan<- *Work {
+	return s.workCh
+}
+
+func (s *StratumServer) SetReturnCh(returnCh chan<- *Result) {
+	s.returnCh = returnCh
+}
+
+func (s *StratumServer) Start() {
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		return
+	}
+	s.quitCh = make(chan struct{})
+	s.workCh = make(chan *Work, 1)
+	go s.loop(s.workCh, s.quitCh)
+}
+
+func (s *StratumServer) Stop() {
+	if !atomic.CompareAndSwapInt32(&s.running, 1, 0) {
+		return
+	}
+	close(s.quitCh)
+	close(s.workCh)
+
+	// Stop handing out work, but keep serving solutions to the recent jobs
+	s.mu.Lock()
+	s.current = nil
+	s.mu.Unlock()
+}
+
+// GetHashRate returns the estimated hashrate of all the stratum workers combined.
+func (s *StratumServer) GetHashRate() (tot int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for _, worker := range s.workers {
+		tot += int64(worker.hashrate(now))
+	}
+	return
+}
+
+// Workers returns a statistics snapshot of all the known stratum workers.
+func (s *StratumServer) Workers() []StratumWorker {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	workers := make([]StratumWorker, 0, len(s.workers))
+	for _, worker := range s.workers {
+		workers = append(workers, StratumWorker{
+			Name:          worker.name,
+			Sessions:      worker.sessions,
+			ValidShares:   worker.valid,
+			StaleShares:   worker.stale,
+			InvalidShares: worker.invalid,
+			Blocks:        worker.blocks,
+			Hashrate:      worker.hashrate(now),
+			Reported:      worker.reported,
+			LastShare:     worker.lastShare,
+		})
+	}
+	return workers
+}
+
+// loop monitors mining events on the work and quit channels, notifying all the
+// miners of new work and periodically dropping stale jobs and idle workers.
+//
+// Note, the work and quit channels are passed as parameters for the same reason
+// as in the RemoteAgent: Start() recreates them on every invocation.
+func (s *StratumServer) loop(workCh chan *Work, quitCh chan struct{}) {
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-quitCh:
+			return
+		case work := <-workCh:
+			s.mu.Lock()
+			s.setWork(work)
+			s.mu.Unlock()
+		case <-ticker.C:
+			s.mu.Lock()
+			for id, job := range s.jobs {
+				if job != s.current && time.Since(job.work.createdAt) > stratumJobLifetime {
+					delete(s.jobs, id)
+				}
+			}
+			now := time.Now()
+			for name, worker := range s.workers {
+				worker.prune(now)
+				if worker.sessions == 0 && now.Sub(worker.seen) > stratumHashrateWindow {
+					delete(s.workers, name)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// setWork creates a new job from a work package and notifies all the authorized
+// miners about it.
+//
+// Note, this method assumes the server lock is held!
+func (s *StratumServer) setWork(work *Work) {
+	s.jobSeq++
+	job := &stratumJob{
+		id:     fmt.Sprintf("%x", s.jobSeq),
+		work:   work,
+		hash:   work.Block.HashNoNonce(),
+		seed:   common.BytesToHash(ethash.SeedHash(work.Block.NumberU64())),
+		shares: make(map[uint64]struct{}),
+	}
+	// Miners only need to discard their current job if the block height changed
+	clean := s.current == nil || s.current.work.Block.NumberU64() != work.Block.NumberU64()
+
+	s.current = job
+	s.jobs[job.id] = job
+
+	for session := range s.sessions {
+		if session.worker != nil {
+			session.notify(job, clean)
+		}
+	}
+	log.Debug("Stratum job created", "id", job.id, "number", work.Block.NumberU64(), "hash", job.hash, "miners", len(s.sessions))
+}
+
+// accept keeps accepting new miner connections until the listener is closed.
+func (s *StratumServer) accept(listener net.Listener) {
+	defer s.wg.Done()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				log.Debug("Temporary stratum accept error", "err", err)
+				time.Sleep(time.Second)
+				continue
+			}
+			return
+		}
+		s.mu.Lock()
+		if s.closed || len(s.sessions) >= stratumMaxSessions {
+			s.mu.Unlock()
+			log.Debug("Rejecting stratum connection", "addr", conn.RemoteAddr(), "sessions", len(s.sessions))
+			conn.Close()
+			continue
+		}
+		session := s.newSession(conn)
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go session.serve()
+	}
+}
+
+// newSession registers a new miner connection and starts sending the messages
+// queued for it.
+//
+// Note, this method assumes the server lock is held!
+func (s *StratumServer) newSession(conn net.Conn) *stratumSession {
+	session := &stratumSession{
+		server: s,
+		conn:   conn,
+		enc:    json.NewEncoder(conn),
+		sendCh: make(chan interface{}, stratumSendQueue),
+		quitCh: make(chan struct{}),
+	}
+	s.sessions[session] = struct{}{}
+
+	s.wg.Add(1)
+	go session.writeLoop()
+	return session
+}
+
+// allocExtranonce assigns an extranonce not used by any connected miner, so
+// that no two miners search the same nonce range.
+//
+// Note, this method assumes the server lock is held!
+func (s *StratumServer) allocExtranonce() string {
+	for {
+		s.nonces++
+		nonce := fmt.Sprintf("%04x", s.nonces)
+		if _, ok := s.extranonces[nonce]; !ok {
+			s.extranonces[nonce] = struct{}{}
+			return nonce
+		}
+	}
+}
+
+// shareDifficulty returns the share difficulty of a job for a miner, capped at
+// the difficulty of the block itself.
+func (s *StratumServer) shareDifficulty(session *stratumSession, job *stratumJob) *big.Int {
+	difficulty := session.difficulty
+	if difficulty == nil {
+		difficulty = s.difficulty
+	}
+	if block := job.work.Block.Difficulty(); difficulty == nil || difficulty.Cmp(block) > 0 {
+		return block
+	}
+	return difficulty
+}
+
+// submit verifies a solution of a miner, accounting it as a share and returning
+// the sealed block to the worker if it satisfies the block difficulty too.
+//
+// Note, this method assumes the server lock is NOT held, as recomputing and
+// verifying seals is expensive and must not hold up the other miners.
+func (s *StratumServer) submit(session *stratumSession, sub *stratumSubmission) error {
+	job, nonce := sub.job, sub.nonce
+
+	s.mu.Lock()
+	worker := session.worker
+	if _, ok := job.shares[nonce]; ok {
+		worker.invalid++
+		s.mu.Unlock()
+		return errStratumDuplicate
+	}
+	difficulty := s.shareDifficulty(session, job)
+	s.mu.Unlock()
+
+	digest := sub.digest
+	if sub.hashimoto {
+		digest, _ = s.engine.(hashimotoEngine).Hashimoto(job.work.Block.NumberU64(), job.hash, nonce)
+	}
+	header := job.work.Block.Header()
+	header.Nonce = types.EncodeNonce(nonce)
+	header.MixDigest = digest
+
+	// Make sure the solution satisfies the share difficulty of the miner, and
+	// check whether it seals the block too
+	share := types.CopyHeader(header)
+	share.Difficulty = difficulty
+
+	shareErr := s.engine.VerifySeal(s.chain, share)
+	sealed := shareErr == nil && s.engine.VerifySeal(s.chain, header) == nil
+
+	s.mu.Lock()
+	if shareErr != nil {
+		log.Debug("Invalid stratum share submitted", "worker", worker.name, "job", job.id, "err", shareErr)
+		worker.invalid++
+		s.mu.Unlock()
+		return errStratumLowDiff
+	}
+	// The same share might have been accepted meanwhile from another connection
+	if _, ok := job.shares[nonce]; ok {
+		worker.invalid++
+		s.mu.Unlock()
+		return errStratumDuplicate
+	}
+	job.shares[nonce] = struct{}{}
+
+	now := time.Now()
+	if job == s.current {
+		worker.valid++
+	} else {
+		worker.stale++
+	}
+	value, _ := new(big.Float).SetInt(difficulty).Float64()
+	worker.shares = append(worker.shares, stratumShare{now, value})
+	worker.lastShare, worker.seen = now, now
+	if sealed {
+		worker.blocks++
+	}
+	returnCh := s.returnCh
+	s.mu.Unlock()
+
+	// If the share seals the block too, return it to the miner
+	if sealed && returnCh != nil {
+		log.Info("Stratum worker sealed block", "worker", worker.name, "number", header.Number, "hash", header.Hash())
+		returnCh <- &Result{job.work, job.work.Block.WithSeal(header)}
+	}
+	return nil
+}
+
+// stratumSession is a single miner connection to the stratum server.
+type stratumSession struct {
+	server *StratumServer
+	conn   net.Conn
+	enc    *json.Encoder
+	sendCh chan interface{} // Messages queued for sending to the miner
+	quitCh chan struct{}    // Closed when the connection is dropped
+
+	dialect    stratumDialect
+	extranonce string         // Nonce prefix assigned to the miner (EthereumStratum only)
+	worker     *stratumWorker // Worker the connection is authorized as
+	difficulty *big.Int       // Share difficulty requested by the miner, nil for the default
+	announced  *big.Int       // Share difficulty last announced to the miner
+}
+
+// serve reads and handles the requests of the miner until the connection drops.
+func (sess *stratumSession) serve() {
+	defer sess.server.wg.Done()
+	defer sess.close()
+
+	log.Debug("Stratum miner connected", "addr", sess.conn.RemoteAddr())
+
+	scanner := bufio.NewScanner(sess.conn)
+	scanner.Buffer(make([]byte, 1024), stratumMaxLine)
+	for {
+		sess.conn.SetReadDeadline(time.Now().Add(stratumReadTimeout))
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				log.Debug("Stratum miner read failed", "addr", sess.conn.RemoteAddr(), "err", err)
+			}
+			return
+		}
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		req := new(stratumRequest)
+		if err := json.Unmarshal(line, req); err != nil {
+			log.Debug("Malformed stratum request", "addr", sess.conn.RemoteAddr(), "err", err)
+			return
+		}
+		sess.process(req)
+	}
+}
+
+// close disconnects the miner, detaching it from its worker.
+func (sess *stratumSession) close() {
+	sess.server.mu.Lock()
+	delete(sess.server.sessions, sess)
+	if sess.extranonce != "" {
+		delete(sess.server.extranonces, sess.extranonce)
+	}
+	if sess.worker != nil {
+		sess.worker.sessions--
+	}
+	sess.server.mu.Unlock()
+
+	close(sess.quitCh)
+	sess.conn.Close()
+	log.Debug("Stratum miner disconnected", "addr", sess.conn.RemoteAddr())
+}
+
+// writeLoop writes the queued messages to the miner until the connection is
+// dropped, dropping it itself if a write fails.
+func (sess *stratumSession) writeLoop() {
+	defer sess.server.wg.Done()
+
+	for {
+		select {
+		case msg := <-sess.sendCh:
+			sess.conn.SetWriteDeadline(time.Now().Add(stratumWriteTimeout))
+			if err := sess.enc.Encode(msg); err != nil {
+				log.Debug("Stratum miner write failed", "addr", sess.conn.RemoteAddr(), "err", err)
+				sess.conn.Close()
+				return
+			}
+		case <-sess.quitCh:
+			return
+		}
+	}
+}
+
+// send queues a message for the miner without blocking. Miners not keeping up
+// with the messages sent to them are disconnected instead of holding up others.
+func (sess *stratumSession) send(msg interface{}) {
+	select {
+	case sess.sendCh <- msg:
+	default:
+		log.Debug("Stratum miner too slow, disconnecting", "addr", sess.conn.RemoteAddr())
+		sess.conn.Close()
+	}
+}
+
+// process handles a single request of the miner and sends back the reply.
+func (sess *stratumSession) process(req *stratumRequest) {
+	server := sess.server
+
+	server.mu.Lock()
+	result, sub, err := sess.handle(req)
+	server.mu.Unlock()
+
+	if sub != nil {
+		if err = server.submit(sess, sub); err == nil {
+			result = true
+		}
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	reply := &stratumResponse{Id: req.Id, Result: result}
+	if sess.dialect == dialectProxy {
+		reply.Version = "2.0"
+	}
+	if err != nil {
+		if sess.dialect == dialectProxy {
+			reply.Error = map[string]interface{}{"code": -1, "message": err.Error()}
+		} else {
+			reply.Error = []interface{}{20, err.Error(), nil}
+		}
+	}
+	sess.send(reply)
+
+	// Newly authorized EthereumStratum miners expect the current job to be pushed
+	if req.Method == "mining.authorize" && err == nil && server.current != nil {
+		sess.notify(server.current, true)
+	}
+}
+
+// handle executes a single request of the miner, returning the result to reply.
+// Submitted solutions are returned for verification without the server lock, in
+// which case the result is the reply to send if the solution is rejected.
+//
+// Note, this method assumes the server lock is held!
+func (sess *stratumSession) handle(req *stratumRequest) (interface{}, *stratumSubmission, error) {
+	switch req.Method {
+	case "mining.subscribe":
+		sess.dialect = dialectStratum
+		if sess.extranonce == "" {
+			sess.extranonce = sess.server.allocExtranonce()
+		}
+		return []interface{}{[]string{"mining.notify", sess.extranonce, stratumVersion}, sess.extranonce}, nil, nil
+
+	case "mining.extranonce.subscribe":
+		return true, nil, nil
+
+	case "mining.authorize":
+		if sess.dialect != dialectStratum {
+			return nil, nil, errStratumUnauthorized
+		}
+		if err := sess.login(req.param(0), req.param(1)); err != nil {
+			return nil, nil, err
+		}
+		return true, nil, nil
+
+	case "mining.submit":
+		if sess.worker == nil || sess.dialect != dialectStratum {
+			return nil, nil, errStratumUnauthorized
+		}
+		job := sess.server.jobs[req.param(1)]
+		if job == nil {
+			sess.worker.invalid++
+			return nil, nil, errStratumUnknownJob
+		}
+		nonce, err := strconv.ParseUint(sess.extranonce+strings.TrimPrefix(req.param(2), "0x"), 16, 64)
+		if err != nil || len(sess.extranonce)+len(strings.TrimPrefix(req.param(2), "0x")) != 16 {
+			sess.worker.invalid++
+			return nil, nil, errStratumParams
+		}
+		if _, ok := sess.server.engine.(hashimotoEngine); !ok {
+			return nil, nil, errStratumNoHashimoto
+		}
+		return nil, &stratumSubmission{job: job, nonce: nonce, hashimoto: true}, nil
+
+	case "eth_submitLogin":
+		if sess.dialect == dialectUnknown {
+			sess.dialect = dialectProxy
+		}
+		name := req.param(0)
+		if req.Worker != "" {
+			name += "." + req.Worker
+		}
+		if err := sess.login(name, req.param(1)); err != nil {
+			return nil, nil, err
+		}
+		return true, nil, nil
+
+	case "eth_getWork":
+		if sess.worker == nil {
+			return nil, nil, errStratumUnauthorized
+		}
+		if sess.server.current == nil {
+			return nil, nil, errStratumNoWork
+		}
+		return sess.proxyWork(sess.server.current), nil, nil
+
+	case "eth_submitWork":
+		if sess.worker == nil {
+			return nil, nil, errStratumUnauthorized
+		}
+		var (
+			nonce  types.BlockNonce
+			hash   common.Hash
+			digest common.Hash
+		)
+		if len(req.Params) != 3 {
+			return false, nil, errStratumParams
+		}
+		for i, field := range []interface{}{&nonce, &hash, &digest} {
+			if err := json.Unmarshal(req.Params[i], field); err != nil {
+				sess.worker.invalid++
+				return false, nil, errStratumParams
+			}
+		}
+		var job *stratumJob
+		for _, candidate := range sess.server.jobs {
+			if candidate.hash == hash {
+				job = candidate
+				break
+			}
+		}
+		if job == nil {
+			sess.worker.invalid++
+			return false, nil, errStratumUnknownJob
+		}
+		return false, &stratumSubmission{job: job, nonce: nonce.Uint64(), digest: digest}, nil
+
+	case "eth_submitHashrate":
+		if sess.worker == nil {
+			return nil, nil, errStratumUnauthorized
+		}
+		rate, err := strconv.ParseUint(strings.TrimPrefix(req.param(0), "0x"), 16, 64)
+		if err != nil {
+			return false, nil, errStratumParams
+		}
+		sess.worker.reported = rate
+		return true, nil, nil
+
+	default:
+		return nil, nil, errStratumUnsupported
+	}
+}
+
+// login authorizes the connection as the named worker. The password may request
+// a custom share difficulty in the form of "d=<difficulty>".
+func (sess *stratumSession) login(name string, password string) error {
+	if name == "" {
+		return errStratumParams
+	}
+	for _, field := range strings.Split(password, ",") {
+		if strings.HasPrefix(field, "d=") {
+			difficulty, ok := new(big.Int).SetString(strings.TrimPrefix(field, "d="), 10)
+			if !ok || difficulty.Sign() <= 0 {
+				return errStratumParams
+			}
+			sess.difficulty = difficulty
+		}
+	}
+	server := sess.server
+
+	if sess.worker != nil {
+		sess.worker.sessions--
+	}
+	worker := server.workers[name]
+	if worker == nil {
+		worker = &stratumWorker{name: name, since: time.Now()}
+		server.workers[name] = worker
+	}
+	worker.sessions++
+	worker.seen = time.Now()
+	sess.worker = worker
+
+	log.Debug("Stratum worker authorized", "addr", sess.conn.RemoteAddr(), "worker", name, "difficulty", sess.difficulty)
+	return nil
+}
+
+// notify pushes a new job to the miner, announcing the share difficulty first if
+// it changed since the last job.
+func (sess *stratumSession) notify(job *stratumJob, clean bool) {
+	switch sess.dialect {
+	case dialectStratum:
+		if difficulty := sess.server.shareDifficulty(sess, job); sess.announced == nil || sess.announced.Cmp(difficulty) != 0 {
+			value, _ := new(big.Float).Quo(new(big.Float).SetInt(difficulty), stratumDiffOne).Float64()
+			sess.send(&stratumNotification{Method: "mining.set_difficulty", Params: []interface{}{value}})
+			sess.announced = difficulty
+		}
+		sess.send(&stratumNotification{
+			Method: "mining.notify",
+			Params: []interface{}{job.id, hex.EncodeToString(job.seed[:]), hex.EncodeToString(job.hash[:]), clean},
+		})
+
+	case dialectProxy:
+		sess.send(&stratumResponse{Id: json.RawMessage("0"), Version: "2.0", Result: sess.proxyWork(job)})
+	}
+}
+
+// proxyWork assembles the ethproxy work package of a job: the sealing hash, the
+// seed hash and the share boundary.
+func (sess *stratumSession) proxyWork(job *stratumJob) [3]string {
+	target := new(big.Int).Div(maxUint256, sess.server.shareDifficulty(sess, job))
+	return [3]string{job.hash.Hex(), job.seed.Hex(), common.BytesToHash(target.Bytes()).Hex()}
+}
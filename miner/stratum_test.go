@@ -0,0 +1,251 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
+	"github.com/AdelineCoin/go-adln/core/types"
+)
+
+// stratumTester is a miner connected to a stratum server under test.
+type stratumTester struct {
+	t    *testing.T
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+// newStratumTester starts a stratum server with a fake PoW engine and connects
+// a tester miner to it.
+func newStratumTester(t *testing.T, difficulty uint64) (*StratumServer, chan *Result, *stratumTester) {
+	server := NewStratumServer(nil, ethash.NewFaker(), difficulty)
+	if err := server.Listen("127.0.0.1:0"); err != nil {
+		t.Fatalf("failed to start stratum server: %v", err)
+	}
+	results := make(chan *Result, 1)
+	server.SetReturnCh(results)
+	server.Start()
+
+	conn, err := net.Dial("tcp", server.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to connect to stratum server: %v", err)
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	return server, results, &stratumTester{t: t, conn: conn, dec: json.NewDecoder(conn)}
+}
+
+// call sends a request to the server and reads back the next message.
+func (st *stratumTester) call(id int, method string, params ...interface{}) map[string]interface{} {
+	req := map[string]interface{}{"id": id, "method": method, "params": params}
+	if err := json.NewEncoder(st.conn).Encode(req); err != nil {
+		st.t.Fatalf("failed to send %s: %v", method, err)
+	}
+	return st.read()
+}
+
+// read retrieves the next message sent by the server.
+func (st *stratumTester) read() map[string]interface{} {
+	var msg map[string]interface{}
+	if err := st.dec.Decode(&msg); err != nil {
+		st.t.Fatalf("failed to read message: %v", err)
+	}
+	return msg
+}
+
+// newStratumWork creates a work package to mine on.
+func newStratumWork(number int64, difficulty int64) *Work {
+	header := &types.Header{Number: big.NewInt(number), Difficulty: big.NewInt(difficulty)}
+	return &Work{Block: types.NewBlockWithHeader(header), createdAt: time.Now()}
+}
+
+// Tests that EthereumStratum miners get notified of new work and their shares
+// get validated and accounted.
+func TestStratumEthereumStratum(t *testing.T) {
+	server, results, miner := newStratumTester(t, 1<<32)
+	defer server.Close()
+	defer server.Stop()
+
+	reply := miner.call(1, "mining.subscribe", "tester/1.0.0", stratumVersion)
+	result, ok := reply["result"].([]interface{})
+	if !ok || len(result) != 2 {
+		t.Fatalf("invalid subscription reply: %v", reply)
+	}
+	extranonce := result[1].(string)
+
+	if reply := miner.call(2, "mining.authorize", "tester.rig", "x"); reply["result"] != true {
+		t.Fatalf("authorization rejected: %v", reply)
+	}
+	server.Work() <- newStratumWork(1, 1<<40)
+
+	if msg := miner.read(); msg["method"] != "mining.set_difficulty" || msg["params"].([]interface{})[0] != 1.0 {
+		t.Fatalf("invalid difficulty notification: %v", msg)
+	}
+	msg := miner.read()
+	if msg["method"] != "mining.notify" {
+		t.Fatalf("invalid job notification: %v", msg)
+	}
+	job := msg["params"].([]interface{})[0].(string)
+
+	// Submit a share, a duplicate of it and one for an unknown job
+	nonce := fmt.Sprintf("%012x", 1)
+	if reply := miner.call(3, "mining.submit", "tester.rig", job, nonce); reply["result"] != true {
+		t.Fatalf("valid share rejected: %v", reply)
+	}
+	if reply := miner.call(4, "mining.submit", "tester.rig", job, nonce); reply["error"] == nil {
+		t.Fatalf("duplicate share accepted: %v", reply)
+	}
+	if reply := miner.call(5, "mining.submit", "tester.rig", "ff", nonce); reply["error"] == nil {
+		t.Fatalf("unknown job share accepted: %v", reply)
+	}
+	// The fake engine accepts all seals, so the share should seal the block too
+	select {
+	case result := <-results:
+		if want := extranonce + nonce; fmt.Sprintf("%016x", result.Block.Nonce()) != want {
+			t.Fatalf("sealed nonce mismatch: have %016x, want %s", result.Block.Nonce(), want)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("sealed block not returned")
+	}
+	workers := server.Workers()
+	if len(workers) != 1 {
+		t.Fatalf("worker count mismatch: have %d, want %d", len(workers), 1)
+	}
+	if w := workers[0]; w.Name != "tester.rig" || w.ValidShares != 1 || w.InvalidShares != 2 || w.Blocks != 1 {
+		t.Fatalf("worker statistics mismatch: %+v", w)
+	}
+}
+
+// Tests that ethproxy miners can log in, retrieve work with the boundary of their
+// requested share difficulty and submit solutions.
+func TestStratumEthproxy(t *testing.T) {
+	server, results, miner := newStratumTester(t, 0)
+	defer server.Close()
+	defer server.Stop()
+
+	if reply := miner.call(1, "eth_getWork"); reply["error"] == nil {
+		t.Fatalf("unauthorized work request served: %v", reply)
+	}
+	if reply := miner.call(2, "eth_submitLogin", "0x0000000000000000000000000000000000000001", "d=256"); reply["result"] != true {
+		t.Fatalf("login rejected: %v", reply)
+	}
+	if reply := miner.call(3, "eth_getWork"); reply["error"] == nil {
+		t.Fatalf("work served before any was available: %v", reply)
+	}
+	work := newStratumWork(1, 1<<20)
+	server.Work() <- work
+
+	// Wait for the new work to be pushed and make sure the boundary is correct
+	push := miner.read()
+	pkg, ok := push["result"].([]interface{})
+	if !ok || len(pkg) != 3 {
+		t.Fatalf("invalid work push: %v", push)
+	}
+	if pkg[0] != work.Block.HashNoNonce().Hex() {
+		t.Fatalf("work hash mismatch: have %v, want %v", pkg[0], work.Block.HashNoNonce().Hex())
+	}
+	if want := "0x0100000000000000000000000000000000000000000000000000000000000000"; pkg[2] != want {
+		t.Fatalf("work boundary mismatch: have %v, want %v", pkg[2], want)
+	}
+	nonce := "0x0000000000000042"
+	digest := "0x0000000000000000000000000000000000000000000000000000000000000000"
+	if reply := miner.call(4, "eth_submitWork", nonce, pkg[0], digest); reply["result"] != true {
+		t.Fatalf("valid solution rejected: %v", reply)
+	}
+	select {
+	case result := <-results:
+		if result.Block.Nonce() != 0x42 {
+			t.Fatalf("sealed nonce mismatch: have %x, want %x", result.Block.Nonce(), 0x42)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("sealed block not returned")
+	}
+	if reply := miner.call(5, "eth_submitHashrate", "0x100", "0x01"); reply["result"] != true {
+		t.Fatalf("hashrate rejected: %v", reply)
+	}
+	if workers := server.Workers(); len(workers) != 1 || workers[0].Reported != 256 {
+		t.Fatalf("reported hashrate mismatch: %+v", workers)
+	}
+}
+
+// Tests that miners not reading the messages sent to them get disconnected
+// instead of stalling the job notifications of everyone else.
+func TestStratumSlowMiner(t *testing.T) {
+	server := NewStratumServer(nil, ethash.NewFaker(), 0)
+	defer server.Close()
+
+	// Register a miner over a synchronous pipe that is never read from
+	conn, remote := net.Pipe()
+	defer remote.Close()
+
+	server.mu.Lock()
+	session := server.newSession(conn)
+	session.dialect = dialectStratum
+	session.worker = &stratumWorker{name: "slow"}
+	server.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 2*stratumSendQueue; i++ {
+			server.mu.Lock()
+			server.setWork(newStratumWork(int64(i+1), 1<<20))
+			server.mu.Unlock()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job notifications blocked by slow miner")
+	}
+	// The slow miner should have been disconnected
+	remote.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 4096)
+	for {
+		if _, err := remote.Read(buf); err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				t.Fatal("slow miner not disconnected")
+			}
+			break
+		}
+	}
+}
+
+// Tests that extranonces are not reassigned while still in use, even after the
+// extranonce counter wraps around.
+func TestStratumExtranonceReuse(t *testing.T) {
+	server := NewStratumServer(nil, ethash.NewFaker(), 0)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	first := server.allocExtranonce()
+	server.nonces = 0xffff
+	if nonce := server.allocExtranonce(); nonce == first {
+		t.Fatalf("extranonce %s reassigned while in use", nonce)
+	}
+	delete(server.extranonces, first)
+	server.nonces = 0xffff
+	if nonce := server.allocExtranonce(); nonce != first {
+		t.Fatalf("released extranonce not reused: have %s, want %s", nonce, first)
+	}
+}
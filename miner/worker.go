@@ -18,8 +18,10 @@ package miner
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -49,6 +51,22 @@ const (
 	chainHeadChanSize = 10
 	// chainSideChanSize is the size of channel listening to ChainSideEvent.
 	chainSideChanSize = 10
+
+	// minRecommitInterval is the minimal time interval to recreate the mining block
+	// with any newly arrived transactions.
+	minRecommitInterval = time.Second
+
+	// maxRecommitInterval is the maximum time interval the adaptive backoff may
+	// stretch the recreation of the mining block to.
+	maxRecommitInterval = time.Minute
+
+	// recommitSealRatio is the number of recommits aimed at while a single block
+	// is being sealed.
+	recommitSealRatio = 4
+
+	// recommitAdjustRatio is the impact a single interval adjustment has on the
+	// recommit interval.
+	recommitAdjustRatio = 0.1
 )
 
 // Agent can register themself with the worker
@@ -71,6 +89,7 @@ type Work struct {
 	family    *set.Set       // family set (used for checking uncle invalidity)
 	uncles    *set.Set       // uncle set
 	tcount    int            // tx count in cycle
+	gasPool   *core.GasPool  // available gas used to pack transactions
 
 	Block *types.Block // the new block
 
@@ -103,6 +122,10 @@ type worker struct {
 	chainSideSub event.Subscription
 	wg           sync.WaitGroup
 
+	recommit   time.Duration      // Minimum interval to recreate the mining block with new transactions
+	recommitCh chan time.Duration // Channel to update the recommit interval at runtime
+	sealTimeCh chan time.Duration // Channel to report the time it took to seal a local block
+
 	agents map[Agent]struct{}
 	recv   chan *Result
 
@@ -122,12 +145,17 @@ type worker struct {
 
 	unconfirmed *unconfirmedBlocks // set of locally mined blocks pending canonicalness confirmations
 
+	sealNumber uint64    // Number of the block currently being sealed (protected by currentMu)
+	sealStart  time.Time // Time the first work package of sealNumber was pushed (protected by currentMu)
+
+	bundleSims *bundleSimCache // Bundle simulations on top of the current parent (protected by currentMu)
+
 	// atomic status counters
 	mining int32
 	atWork int32
 }
 
-func newWorker(config *params.ChainConfig, engine consensus.Engine, coinbase common.Address, eth Backend, mux *event.TypeMux) *worker {
+func newWorker(config *params.ChainConfig, engine consensus.Engine, coinbase common.Address, eth Backend, mux *event.TypeMux, recommit time.Duration) *worker {
 	worker := &worker{
 		config:         config,
 		engine:         engine,
@@ -144,6 +172,9 @@ func newWorker(config *params.ChainConfig, engine consensus.Engine, coinbase com
 		coinbase:       coinbase,
 		agents:         make(map[Agent]struct{}),
 		unconfirmed:    newUnconfirmedBlocks(eth.BlockChain(), miningLogAtDepth),
+		recommit:       sanitizeRecommit(recommit),
+		recommitCh:     make(chan time.Duration, 1),
+		sealTimeCh:     make(chan time.Duration, 1),
 	}
 	// Subscribe TxPreEvent for tx pool
 	worker.txSub = eth.TxPool().SubscribeTxPreEvent(worker.txCh)
@@ -170,6 +201,57 @@ func (self *worker) setExtra(extra []byte) {
 	self.extra = extra
 }
 
+// setRecommitInterval updates the minimum interval of recreating the mining block
+// with any newly arrived transactions. Zero disables periodic recommits.
+func (self *worker) setRecommitInterval(interval time.Duration) {
+	sendLatest(self.recommitCh, sanitizeRecommit(interval))
+}
+
+// sendLatest delivers a value on a channel with a buffer of one without ever
+// blocking, replacing any earlier value the receiver hasn't picked up yet.
+func sendLatest(ch chan time.Duration, d time.Duration) {
+	for {
+		select {
+		case ch <- d:
+			return
+		default:
+		}
+		select {
+		case <-ch:
+		default:
+		}
+	}
+}
+
+// sanitizeRecommit ensures the recommit interval is either disabled, or is not
+// below the allowed minimum.
+func sanitizeRecommit(interval time.Duration) time.Duration {
+	if interval > 0 && interval < minRecommitInterval {
+		log.Warn("Sanitizing miner recommit interval", "provided", interval, "updated", minRecommitInterval)
+		interval = minRecommitInterval
+	}
+	return interval
+}
+
+// recalcRecommit calculates the next recommit interval based on the previous one
+// and on the time it took to seal the last local block: the longer sealing takes,
+// the more the recommits are backed off, never going below the configured minimum
+// or beyond the allowed maximum.
+func recalcRecommit(minimum, prev, sealTime time.Duration) time.Duration {
+	target := float64(sealTime / recommitSealRatio)
+	if target < float64(minimum) {
+		target = float64(minimum)
+	}
+	next := time.Duration(float64(prev)*(1-recommitAdjustRatio) + target*recommitAdjustRatio)
+	if next < minimum {
+		next = minimum
+	}
+	if next > maxRecommitInterval {
+		next = maxRecommitInterval
+	}
+	return next
+}
+
 func (self *worker) pending() (*types.Block, *state.StateDB) {
 	self.currentMu.Lock()
 	defer self.currentMu.Unlock()
@@ -245,12 +327,60 @@ func (self *worker) update() {
 	defer self.chainHeadSub.Unsubscribe()
 	defer self.chainSideSub.Unsubscribe()
 
+	// Periodically recreate the mining block if new transactions arrived meanwhile,
+	// so remote miners don't keep hashing on a stale template for a long time
+	var (
+		recommit = self.recommit
+		timer    = time.NewTimer(0)
+		stale    = false // Whether transactions arrived since the last mining block
+	)
+	defer timer.Stop()
+
+	<-timer.C // discard the initial tick
+	if recommit > 0 {
+		timer.Reset(recommit)
+	}
 	for {
 		// A real event arrived, process interesting content
 		select {
 		// Handle ChainHeadEvent
 		case <-self.chainHeadCh:
 			self.commitNewWork()
+			stale = false
+
+			if recommit > 0 {
+				resetTimer(timer, recommit)
+			}
+
+		// Handle periodic recommits
+		case <-timer.C:
+			if stale && atomic.LoadInt32(&self.mining) == 1 {
+				self.commitNewWork()
+				stale = false
+
+				log.Trace("Recommitted mining work", "next", recommit)
+			}
+			if recommit > 0 {
+				timer.Reset(recommit)
+			}
+
+		// Back off the recommits based on how long sealing takes
+		case sealTime := <-self.sealTimeCh:
+			if self.recommit > 0 {
+				recommit = recalcRecommit(self.recommit, recommit, sealTime)
+				log.Trace("Adjusted miner recommit interval", "sealtime", common.PrettyDuration(sealTime), "next", recommit)
+			}
+
+		// Handle recommit interval updates
+		case interval := <-self.recommitCh:
+			log.Info("Miner recommit interval updated", "from", self.recommit, "to", interval)
+			self.recommit, recommit = interval, interval
+
+			if recommit > 0 {
+				resetTimer(timer, recommit)
+			} else {
+				timer.Stop()
+			}
 
 		// Handle ChainSideEvent
 		case ev := <-self.chainSideCh:
@@ -265,14 +395,16 @@ func (self *worker) update() {
 				self.currentMu.Lock()
 				acc, _ := types.Sender(self.current.signer, ev.Tx)
 				txs := map[common.Address]types.Transactions{acc: {ev.Tx}}
-				txset := types.NewTransactionsByPriceAndNonce(self.current.signer, txs)
+				txset := types.NewTransactionsByPriceAndNonce(self.current.signer, txs, self.current.header.BaseFee)
 
 				self.current.commitTransactions(self.mux, txset, self.chain, self.coinbase)
 				self.currentMu.Unlock()
 			} else {
 				// If we're mining, but nothing is being processed, wake on new transactions
-				if self.config.Clique != nil && self.config.Clique.Period == 0 {
+				if self.instantSeal() {
 					self.commitNewWork()
+				} else {
+					stale = true
 				}
 			}
 
@@ -287,6 +419,30 @@ func (self *worker) update() {
 	}
 }
 
+// instantSeal returns whether blocks are sealed as soon as transactions arrive,
+// requiring new work to be committed for each of them.
+func (self *worker) instantSeal() bool {
+	if self.config.Clique != nil && self.config.Clique.Period == 0 {
+		return true
+	}
+	if self.config.Authority != nil && self.config.Authority.Period == 0 {
+		return true
+	}
+	if engine, ok := self.engine.(consensus.DevEngine); ok && engine.InstantSeal() {
+		return true
+	}
+	return false
+}
+
+// now returns the current time as seen by the consensus engine, which may be
+// shifted forward on developer chains.
+func (self *worker) now() time.Time {
+	if engine, ok := self.engine.(consensus.DevEngine); ok {
+		return engine.Now()
+	}
+	return time.Now()
+}
+
 func (self *worker) wait() {
 	for {
 		mustCommitNewWork := true
@@ -299,6 +455,14 @@ func (self *worker) wait() {
 			block := result.Block
 			work := result.Work
 
+			// Report the seal time of the block to the recommit backoff
+			self.currentMu.Lock()
+			if block.NumberU64() == self.sealNumber && !self.sealStart.IsZero() {
+				sendLatest(self.sealTimeCh, time.Since(self.sealStart))
+				self.sealStart = time.Time{}
+			}
+			self.currentMu.Unlock()
+
 			// Update the block hash in all logs since it is now available and not when the
 			// receipt/log of individual transactions were created.
 			for _, r := range work.receipts {
@@ -341,6 +505,18 @@ func (self *worker) wait() {
 	}
 }
 
+// resetTimer stops a timer, drains any pending expiration and restarts it with
+// the given interval.
+func resetTimer(t *time.Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+	t.Reset(d)
+}
+
 // push sends a new work task to currently live miner agents.
 func (self *worker) push(work *Work) {
 	if atomic.LoadInt32(&self.mining) != 1 {
@@ -362,7 +538,7 @@ func (self *worker) makeCurrent(parent *types.Block, header *types.Header) error
 	}
 	work := &Work{
 		config:    self.config,
-		signer:    types.NewEIP155Signer(self.config.ChainId),
+		signer:    types.LatestSigner(self.config),
 		state:     state,
 		ancestors: set.New(),
 		family:    set.New(),
@@ -397,12 +573,12 @@ func (self *worker) commitNewWork() {
 	tstart := time.Now()
 	parent := self.chain.CurrentBlock()
 
-	tstamp := tstart.Unix()
+	tstamp := self.now().Unix()
 	if parent.Time().Cmp(new(big.Int).SetInt64(tstamp)) >= 0 {
 		tstamp = parent.Time().Int64() + 1
 	}
 	// this will ensure we're not going off too far in the future
-	if now := time.Now().Unix(); tstamp > now+1 {
+	if now := self.now().Unix(); tstamp > now+1 {
 		wait := time.Duration(tstamp-now) * time.Second
 		log.Info("Mining too far in the future", "wait", common.PrettyDuration(wait))
 		time.Sleep(wait)
@@ -416,6 +592,10 @@ func (self *worker) commitNewWork() {
 		Extra:      self.extra,
 		Time:       big.NewInt(tstamp),
 	}
+	// Set the base fee if the fee market is already active
+	if self.config.IsEIP1559(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(self.config, parent.Header())
+	}
 	// Only set the coinbase if we are mining (avoid spurious block rewards)
 	if atomic.LoadInt32(&self.mining) == 1 {
 		header.Coinbase = self.coinbase
@@ -453,7 +633,18 @@ func (self *worker) commitNewWork() {
 		log.Error("Failed to fetch pending transactions", "err", err)
 		return
 	}
-	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending)
+	// Fill the block with the most profitable bundles first, then with the pool
+	if bundles := self.eth.BundlePool().Bundles(header.Number.Uint64()); len(bundles) > 0 {
+		if cache := self.bundleSims; cache == nil || cache.parent != parent.Hash() || cache.coinbase != self.coinbase {
+			self.bundleSims = &bundleSimCache{
+				parent:   parent.Hash(),
+				coinbase: self.coinbase,
+				sims:     make(map[common.Hash]*bundleSim),
+			}
+		}
+		work.commitBundles(self.mux, bundles, self.chain, self.coinbase, self.bundleSims.sims)
+	}
+	txs := types.NewTransactionsByPriceAndNonce(self.current.signer, pending, header.BaseFee)
 	work.commitTransactions(self.mux, txs, self.chain, self.coinbase)
 
 	// compute uncles for the new block.
@@ -487,6 +678,11 @@ func (self *worker) commitNewWork() {
 	if atomic.LoadInt32(&self.mining) == 1 {
 		log.Info("Commit new mining work", "number", work.Block.Number(), "txs", work.tcount, "uncles", len(uncles), "elapsed", common.PrettyDuration(time.Since(tstart)))
 		self.unconfirmed.Shift(work.Block.NumberU64() - 1)
+
+		// Recommits of the same block don't restart the seal time measurement
+		if number := work.Block.NumberU64(); number != self.sealNumber {
+			self.sealNumber, self.sealStart = number, time.Now()
+		}
 	}
 	self.push(work)
 }
@@ -507,7 +703,10 @@ func (self *worker) commitUncle(work *Work, uncle *types.Header) error {
 }
 
 func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsByPriceAndNonce, bc *core.BlockChain, coinbase common.Address) {
-	gp := new(core.GasPool).AddGas(env.header.GasLimit)
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	gp := env.gasPool
 
 	var coalescedLogs []*types.Log
 
@@ -525,7 +724,7 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 		// Error may be ignored here. The error has already been checked
 		// during transaction acceptance is the transaction pool.
 		//
-		// We use the eip155 signer regardless of the current hf.
+		// We use the latest signer regardless of the current hf.
 		from, _ := types.Sender(env.signer, tx)
 		// Check whether the tx is replay protected. If we're not in the EIP155 hf
 		// phase, start ignoring the sender until we do.
@@ -535,6 +734,20 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 			txs.Pop()
 			continue
 		}
+		// Typed transactions are only valid after their fork, and only if they can
+		// cover the base fee of the block
+		if !typedTxEnabled(env.config, tx, env.header.Number) {
+			log.Trace("Ignoring typed transaction", "hash", tx.Hash(), "type", tx.Type())
+
+			txs.Pop()
+			continue
+		}
+		if baseFee := env.header.BaseFee; baseFee != nil && tx.GasFeeCap().Cmp(baseFee) < 0 {
+			log.Trace("Ignoring underpriced transaction", "hash", tx.Hash(), "feecap", tx.GasFeeCap(), "basefee", baseFee)
+
+			txs.Pop()
+			continue
+		}
 		// Start executing the transaction
 		env.state.Prepare(tx.Hash(), common.Hash{}, env.tcount)
 
@@ -589,6 +802,20 @@ func (env *Work) commitTransactions(mux *event.TypeMux, txs *types.TransactionsB
 	}
 }
 
+// typedTxEnabled reports whether the type of a transaction is accepted in the
+// block with the given number. The dynamic fee fork implies the typed envelope.
+func typedTxEnabled(config *params.ChainConfig, tx *types.Transaction, number *big.Int) bool {
+	switch tx.Type() {
+	case types.LegacyTxType:
+		return true
+	case types.AccessListTxType:
+		return config.IsEIP2718(number) || config.IsEIP1559(number)
+	case types.DynamicFeeTxType:
+		return config.IsEIP1559(number)
+	}
+	return false
+}
+
 func (env *Work) commitTransaction(tx *types.Transaction, bc *core.BlockChain, coinbase common.Address, gp *core.GasPool) (error, []*types.Log) {
 	snap := env.state.Snapshot()
 
@@ -602,3 +829,183 @@ func (env *Work) commitTransaction(tx *types.Transaction, bc *core.BlockChain, c
 
 	return nil, receipt.Logs
 }
+
+// bundleSim is the outcome of simulating a transaction bundle on top of the
+// pending state of a block.
+type bundleSim struct {
+	bundle  *core.Bundle
+	senders map[common.Address]struct{} // Accounts whose nonces the bundle uses
+	profit  *big.Int                    // Coinbase balance increase caused by the bundle
+}
+
+// bundleSimCache holds the outcome of the bundle simulations done on top of a
+// parent block for a coinbase, so that recommits only simulate the bundles that
+// arrived since.
+type bundleSimCache struct {
+	parent   common.Hash
+	coinbase common.Address
+	sims     map[common.Hash]*bundleSim // Simulations by bundle hash, nil for failing bundles
+}
+
+// bundlesByProfit implements sort.Interface to order bundle simulations by the
+// profit they generate for the miner, highest first.
+type bundlesByProfit []*bundleSim
+
+func (s bundlesByProfit) Len() int           { return len(s) }
+func (s bundlesByProfit) Less(i, j int) bool { return s[i].profit.Cmp(s[j].profit) > 0 }
+func (s bundlesByProfit) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// commitBundles simulates all the given bundles on top of the current state and
+// commits the most profitable ones not conflicting with each other. Every bundle
+// is committed atomically: either all its transactions are included contiguously
+// and in order, or none of them.
+//
+// Simulations are looked up in, and added to, the given cache, which must only
+// hold simulations done on top of the same parent block and coinbase. Cached
+// outcomes may be slightly stale (e.g. the block timestamp differs between
+// recommits), but every bundle is still executed on the pending state when
+// committed, and rolled back if it fails there.
+//
+// Note, bundles are only considered conflicting if they share a sender. Bundles
+// of distinct senders touching the same contract state are not detected upfront,
+// they are simulated independently and a bundle invalidated by an earlier, more
+// profitable one is rolled back when it fails on the pending state.
+func (env *Work) commitBundles(mux *event.TypeMux, bundles []*core.Bundle, bc *core.BlockChain, coinbase common.Address, cache map[common.Hash]*bundleSim) {
+	if env.gasPool == nil {
+		env.gasPool = new(core.GasPool).AddGas(env.header.GasLimit)
+	}
+	// Simulate all the new bundles independently, discarding any failing ones
+	var (
+		sims      = make(bundlesByProfit, 0, len(bundles))
+		simulated int
+	)
+	for _, bundle := range bundles {
+		sim, ok := cache[bundle.Hash()]
+		if !ok {
+			var err error
+			if sim, err = env.simulateBundle(bundle, bc, coinbase); err != nil {
+				log.Trace("Discarding failing bundle", "hash", bundle.Hash(), "err", err)
+			}
+			cache[bundle.Hash()] = sim
+			simulated++
+		}
+		if sim != nil {
+			sims = append(sims, sim)
+		}
+	}
+	sort.Sort(sims)
+
+	// Commit the bundles by profitability, skipping conflicting ones
+	var (
+		senders       = make(map[common.Address]struct{})
+		coalescedLogs []*types.Log
+		committed     int
+	)
+	for _, sim := range sims {
+		conflict := false
+		for addr := range sim.senders {
+			if _, ok := senders[addr]; ok {
+				conflict = true
+				break
+			}
+		}
+		if conflict {
+			log.Trace("Skipping conflicting bundle", "hash", sim.bundle.Hash())
+			continue
+		}
+		logs, err := env.commitBundle(sim.bundle, bc, coinbase)
+		if err != nil {
+			log.Trace("Bundle failed on pending state", "hash", sim.bundle.Hash(), "err", err)
+			continue
+		}
+		for addr := range sim.senders {
+			senders[addr] = struct{}{}
+		}
+		coalescedLogs = append(coalescedLogs, logs...)
+		committed++
+	}
+	if committed > 0 {
+		log.Debug("Committed transaction bundles", "number", env.header.Number, "bundles", committed, "simulated", simulated, "cached", len(bundles)-simulated)
+	}
+	if len(coalescedLogs) > 0 {
+		// make a copy, the state caches the logs and these logs get "upgraded" from pending to mined
+		// logs by filling in the block hash when the block was mined by the local miner.
+		cpy := make([]*types.Log, len(coalescedLogs))
+		for i, l := range coalescedLogs {
+			cpy[i] = new(types.Log)
+			*cpy[i] = *l
+		}
+		go mux.Post(core.PendingLogsEvent{Logs: cpy})
+	}
+}
+
+// simulateBundle executes a bundle on a copy of the current state, returning the
+// profit it would generate for the coinbase, or an error if any of the contained
+// transactions is invalid or fails.
+func (env *Work) simulateBundle(bundle *core.Bundle, bc *core.BlockChain, coinbase common.Address) (*bundleSim, error) {
+	var (
+		statedb = env.state.Copy()
+		header  = types.CopyHeader(env.header)
+		gp      = *env.gasPool
+		before  = statedb.GetBalance(coinbase)
+		senders = make(map[common.Address]struct{})
+	)
+	for i, tx := range bundle.Txs {
+		from, _ := types.Sender(env.signer, tx) // already validated by the pool
+		if tx.Protected() && !env.config.IsEIP155(header.Number) {
+			return nil, fmt.Errorf("transaction %d: replay protection not active", i)
+		}
+		senders[from] = struct{}{}
+
+		statedb.Prepare(tx.Hash(), common.Hash{}, env.tcount+i)
+		receipt, _, err := core.ApplyTransaction(env.config, bc, &coinbase, &gp, statedb, header, tx, &header.GasUsed, vm.Config{})
+		if err != nil {
+			return nil, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		if env.config.IsByzantium(header.Number) && receipt.Status == types.ReceiptStatusFailed {
+			return nil, fmt.Errorf("transaction %d: execution reverted", i)
+		}
+	}
+	return &bundleSim{
+		bundle:  bundle,
+		senders: senders,
+		profit:  new(big.Int).Sub(statedb.GetBalance(coinbase), before),
+	}, nil
+}
+
+// commitBundle applies all the transactions of a bundle to the current state. If
+// any of them fails, all the changes done by the bundle are rolled back.
+//
+// Note, state snapshots don't survive transaction boundaries (the journal is
+// cleared when a transaction is finalised), so the rollback restores a copy of
+// the state taken before the bundle instead.
+func (env *Work) commitBundle(bundle *core.Bundle, bc *core.BlockChain, coinbase common.Address) ([]*types.Log, error) {
+	var (
+		statedb = env.state.Copy()
+		gas     = *env.gasPool
+		gasUsed = env.header.GasUsed
+		txs     = len(env.txs)
+		tcount  = env.tcount
+		logs    []*types.Log
+	)
+	for i, tx := range bundle.Txs {
+		env.state.Prepare(tx.Hash(), common.Hash{}, env.tcount)
+
+		err, txLogs := env.commitTransaction(tx, bc, coinbase, env.gasPool)
+		if err == nil && env.config.IsByzantium(env.header.Number) && env.receipts[len(env.receipts)-1].Status == types.ReceiptStatusFailed {
+			err = errors.New("execution reverted")
+		}
+		if err != nil {
+			env.state = statedb
+			*env.gasPool = gas
+			env.header.GasUsed = gasUsed
+			env.txs, env.receipts = env.txs[:txs], env.receipts[:txs]
+			env.tcount = tcount
+
+			return nil, fmt.Errorf("transaction %d: %v", i, err)
+		}
+		logs = append(logs, txLogs...)
+		env.tcount++
+	}
+	return logs, nil
+}
@@ -0,0 +1,350 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package miner
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/accounts"
+	"github.com/AdelineCoin/go-adln/common"
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
+	"github.com/AdelineCoin/go-adln/core"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/core/vm"
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/ethdb"
+	"github.com/AdelineCoin/go-adln/event"
+	"github.com/AdelineCoin/go-adln/params"
+)
+
+var (
+	// Test accounts funded in the genesis block of the test chain
+	testKeys     = make([]*ecdsa.PrivateKey, 4)
+	testAccounts = make([]common.Address, 4)
+	testFunds    = big.NewInt(1000000000000000000)
+
+	// testLock is a contract which reverts if its first storage slot is already
+	// set, and sets it otherwise: only the first call to it ever succeeds.
+	testLock     = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	testLockCode = common.FromHex("0x60005415600b57600080fd5b600160005500")
+)
+
+func init() {
+	for i := range testKeys {
+		testKeys[i], _ = crypto.GenerateKey()
+		testAccounts[i] = crypto.PubkeyToAddress(testKeys[i].PublicKey)
+	}
+}
+
+// testWorkerBackend implements Backend on top of an in-memory test chain.
+type testWorkerBackend struct {
+	db         ethdb.Database
+	chain      *core.BlockChain
+	txPool     *core.TxPool
+	bundlePool *core.BundlePool
+}
+
+func newTestWorkerBackend(t *testing.T, config *params.ChainConfig) *testWorkerBackend {
+	alloc := core.GenesisAlloc{testLock: {Code: testLockCode, Balance: new(big.Int)}}
+	for _, addr := range testAccounts {
+		alloc[addr] = core.GenesisAccount{Balance: testFunds}
+	}
+	db, _ := ethdb.NewMemDatabase()
+	(&core.Genesis{Config: config, Alloc: alloc}).MustCommit(db)
+
+	chain, err := core.NewBlockChain(db, nil, config, ethash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create test chain: %v", err)
+	}
+	pool := core.DefaultTxPoolConfig
+	pool.Journal = ""
+
+	return &testWorkerBackend{
+		db:         db,
+		chain:      chain,
+		txPool:     core.NewTxPool(pool, config, chain),
+		bundlePool: core.NewBundlePool(config, chain),
+	}
+}
+
+func (b *testWorkerBackend) AccountManager() *accounts.Manager { return nil }
+func (b *testWorkerBackend) BlockChain() *core.BlockChain      { return b.chain }
+func (b *testWorkerBackend) TxPool() *core.TxPool              { return b.txPool }
+func (b *testWorkerBackend) BundlePool() *core.BundlePool      { return b.bundlePool }
+func (b *testWorkerBackend) ChainDb() ethdb.Database           { return b.db }
+
+func (b *testWorkerBackend) close() {
+	b.bundlePool.Stop()
+	b.txPool.Stop()
+	b.chain.Stop()
+}
+
+// testAgent is a mining agent collecting all the work pushed to it.
+type testAgent struct {
+	work chan *Work
+}
+
+func (a *testAgent) Work() chan<- *Work         { return a.work }
+func (a *testAgent) SetReturnCh(chan<- *Result) {}
+func (a *testAgent) Start()                     {}
+func (a *testAgent) Stop()                      {}
+func (a *testAgent) GetHashRate() int64         { return 0 }
+
+// signedTx creates a transaction sending value to the given account with a gas
+// price, signed by one of the test accounts.
+func signedTx(key *ecdsa.PrivateKey, nonce uint64, to common.Address, gas uint64, price int64) *types.Transaction {
+	tx, _ := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(1), gas, big.NewInt(price), nil), types.HomesteadSigner{}, key)
+	return tx
+}
+
+// newTestWork creates a worker on top of the test backend, returning it with the
+// mining environment of the next block.
+func newTestWork(t *testing.T) (*worker, *testWorkerBackend, *Work) {
+	backend := newTestWorkerBackend(t, params.TestChainConfig)
+	w := newWorker(params.TestChainConfig, ethash.NewFaker(), testAccounts[0], backend, new(event.TypeMux), 0)
+
+	w.currentMu.Lock()
+	defer w.currentMu.Unlock()
+	return w, backend, w.current
+}
+
+// Tests that bundles are committed by the profit they generate for the miner,
+// each of them contiguously and in order.
+func TestCommitBundlesProfitOrdering(t *testing.T) {
+	w, backend, work := newTestWork(t)
+	defer backend.close()
+
+	var (
+		cheap  = core.NewBundle(types.Transactions{signedTx(testKeys[1], 0, testAccounts[0], 21000, 1), signedTx(testKeys[1], 1, testAccounts[0], 21000, 1)}, 1)
+		pricey = core.NewBundle(types.Transactions{signedTx(testKeys[2], 0, testAccounts[0], 21000, 5)}, 1)
+		medium = core.NewBundle(types.Transactions{signedTx(testKeys[3], 0, testAccounts[0], 21000, 2), signedTx(testKeys[3], 1, testAccounts[0], 21000, 2)}, 1)
+	)
+	work.commitBundles(w.mux, []*core.Bundle{cheap, pricey, medium}, w.chain, testAccounts[0], make(map[common.Hash]*bundleSim))
+
+	var want types.Transactions
+	want = append(want, pricey.Txs...)
+	want = append(want, medium.Txs...)
+	want = append(want, cheap.Txs...)
+	if len(work.txs) != len(want) {
+		t.Fatalf("committed transaction count mismatch: have %d, want %d", len(work.txs), len(want))
+	}
+	for i, tx := range work.txs {
+		if tx.Hash() != want[i].Hash() {
+			t.Errorf("transaction %d: hash mismatch: have %x, want %x", i, tx.Hash(), want[i].Hash())
+		}
+	}
+	if work.tcount != len(want) {
+		t.Errorf("transaction counter mismatch: have %d, want %d", work.tcount, len(want))
+	}
+}
+
+// Tests that cached bundle simulations are reused instead of simulating the
+// bundles again.
+func TestCommitBundlesCached(t *testing.T) {
+	w, backend, work := newTestWork(t)
+	defer backend.close()
+
+	var (
+		cheap  = core.NewBundle(types.Transactions{signedTx(testKeys[1], 0, testAccounts[0], 21000, 1)}, 1)
+		pricey = core.NewBundle(types.Transactions{signedTx(testKeys[2], 0, testAccounts[0], 21000, 5)}, 1)
+	)
+	// Mark the valid, more profitable bundle as failing in the cache
+	cache := map[common.Hash]*bundleSim{pricey.Hash(): nil}
+	work.commitBundles(w.mux, []*core.Bundle{cheap, pricey}, w.chain, testAccounts[0], cache)
+
+	if len(work.txs) != 1 || work.txs[0].Hash() != cheap.Txs[0].Hash() {
+		t.Fatalf("committed transactions mismatch: have %d, want only the uncached bundle", len(work.txs))
+	}
+	if sim, ok := cache[cheap.Hash()]; !ok || sim == nil {
+		t.Fatalf("simulation of new bundle not cached")
+	}
+	if sim := cache[pricey.Hash()]; sim != nil {
+		t.Errorf("cached failure overwritten")
+	}
+}
+
+// Tests that bundles sharing a sender with a more profitable bundle are skipped.
+func TestCommitBundlesConflicts(t *testing.T) {
+	w, backend, work := newTestWork(t)
+	defer backend.close()
+
+	var (
+		winner   = core.NewBundle(types.Transactions{signedTx(testKeys[1], 0, testAccounts[0], 21000, 5), signedTx(testKeys[2], 0, testAccounts[0], 21000, 5)}, 1)
+		conflict = core.NewBundle(types.Transactions{signedTx(testKeys[3], 0, testAccounts[0], 21000, 2), signedTx(testKeys[2], 0, testAccounts[0], 21000, 2)}, 1)
+	)
+	work.commitBundles(w.mux, []*core.Bundle{conflict, winner}, w.chain, testAccounts[0], make(map[common.Hash]*bundleSim))
+
+	if len(work.txs) != len(winner.Txs) {
+		t.Fatalf("committed transaction count mismatch: have %d, want %d", len(work.txs), len(winner.Txs))
+	}
+	for i, tx := range work.txs {
+		if tx.Hash() != winner.Txs[i].Hash() {
+			t.Errorf("transaction %d: hash mismatch: have %x, want %x", i, tx.Hash(), winner.Txs[i].Hash())
+		}
+	}
+	// The conflicting bundle must not leave its non-conflicting transaction behind
+	if nonce := work.state.GetNonce(testAccounts[3]); nonce != 0 {
+		t.Errorf("skipped bundle sender nonce mismatch: have %d, want %d", nonce, 0)
+	}
+}
+
+// Tests that a bundle whose later transaction fails on the pending state, after
+// the state was modified by a more profitable bundle, is rolled back entirely.
+func TestCommitBundlesRollback(t *testing.T) {
+	w, backend, work := newTestWork(t)
+	defer backend.close()
+
+	var (
+		winner = core.NewBundle(types.Transactions{signedTx(testKeys[1], 0, testLock, 100000, 5)}, 1)
+		loser  = core.NewBundle(types.Transactions{signedTx(testKeys[2], 0, testAccounts[3], 21000, 2), signedTx(testKeys[3], 0, testLock, 100000, 2)}, 1)
+	)
+	var (
+		gas    = work.gasPool.Gas()
+		before = work.state.GetBalance(testAccounts[3])
+	)
+	work.commitBundles(w.mux, []*core.Bundle{loser, winner}, w.chain, testAccounts[0], make(map[common.Hash]*bundleSim))
+
+	if len(work.txs) != 1 || work.txs[0].Hash() != winner.Txs[0].Hash() {
+		t.Fatalf("committed transactions mismatch: have %d, want only the winner", len(work.txs))
+	}
+	if len(work.receipts) != 1 || work.tcount != 1 {
+		t.Errorf("receipts/counter not rolled back: receipts %d, counter %d", len(work.receipts), work.tcount)
+	}
+	if used := gas - work.gasPool.Gas(); used != work.header.GasUsed || used != work.receipts[0].GasUsed {
+		t.Errorf("gas accounting not rolled back: pool used %d, header used %d, receipt used %d", used, work.header.GasUsed, work.receipts[0].GasUsed)
+	}
+	if nonce := work.state.GetNonce(testAccounts[2]); nonce != 0 {
+		t.Errorf("rolled back sender nonce mismatch: have %d, want %d", nonce, 0)
+	}
+	if balance := work.state.GetBalance(testAccounts[3]); balance.Cmp(before) != 0 {
+		t.Errorf("rolled back transfer recipient balance mismatch: have %v, want %v", balance, before)
+	}
+}
+
+// Tests that the recommit interval backs off if sealing blocks takes long, and
+// recovers towards the configured minimum once blocks are sealed quickly.
+func TestRecommitBackoff(t *testing.T) {
+	minimum := 3 * time.Second
+
+	// Quickly sealed blocks should keep the interval at the minimum
+	if next := recalcRecommit(minimum, minimum, 10*time.Millisecond); next != minimum {
+		t.Errorf("quick seal interval mismatch: have %v, want %v", next, minimum)
+	}
+	// Slowly sealed blocks should gradually back off, up to the maximum
+	prev := minimum
+	for i := 0; i < 10; i++ {
+		next := recalcRecommit(minimum, prev, 2*time.Minute)
+		if next <= prev {
+			t.Fatalf("iteration %d: interval not backed off: have %v, prev %v", i, next, prev)
+		}
+		prev = next
+	}
+	for i := 0; i < 1000; i++ {
+		prev = recalcRecommit(minimum, prev, time.Hour)
+	}
+	if prev != maxRecommitInterval {
+		t.Errorf("interval not capped: have %v, want %v", prev, maxRecommitInterval)
+	}
+	// Quickly sealed blocks should recover the interval towards the minimum
+	for i := 0; i < 1000; i++ {
+		prev = recalcRecommit(minimum, prev, 0)
+	}
+	if prev != minimum {
+		t.Errorf("interval not recovered: have %v, want %v", prev, minimum)
+	}
+}
+
+// Tests that recommit interval updates never block, even if nobody is picking
+// them up anymore, and that only the latest update is kept.
+func TestRecommitUpdateNonBlocking(t *testing.T) {
+	w := &worker{recommitCh: make(chan time.Duration, 1)}
+
+	done := make(chan struct{})
+	go func() {
+		w.setRecommitInterval(2 * time.Second)
+		w.setRecommitInterval(5 * time.Second)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("recommit interval update blocked")
+	}
+	if interval := <-w.recommitCh; interval != 5*time.Second {
+		t.Errorf("wrong interval delivered: have %v, want %v", interval, 5*time.Second)
+	}
+}
+
+// Tests that recommit intervals below the allowed minimum are raised, but that
+// the periodic recommits can be disabled.
+func TestRecommitSanitize(t *testing.T) {
+	tests := []struct {
+		interval, want time.Duration
+	}{
+		{0, 0},
+		{time.Millisecond, minRecommitInterval},
+		{minRecommitInterval, minRecommitInterval},
+		{time.Minute, time.Minute},
+	}
+	for i, tt := range tests {
+		if have := sanitizeRecommit(tt.interval); have != tt.want {
+			t.Errorf("test %d: interval mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that while mining, the worker periodically rebuilds the sealing block from
+// the latest pool contents and pushes it to the agents, without waiting for a new
+// chain head.
+func TestRecommitPushesNewWork(t *testing.T) {
+	backend := newTestWorkerBackend(t, params.TestChainConfig)
+	defer backend.close()
+
+	w := newWorker(params.TestChainConfig, ethash.NewFaker(), testAccounts[0], backend, new(event.TypeMux), minRecommitInterval)
+	defer w.stop()
+
+	agent := &testAgent{work: make(chan *Work, 16)}
+	w.register(agent)
+	w.start()
+
+	// Arriving transactions only mark the template stale while mining, the new
+	// template must be built and pushed by the recommit timer
+	tx := signedTx(testKeys[1], 0, testAccounts[2], 21000, 1)
+	if err := backend.txPool.AddRemote(tx); err != nil {
+		t.Fatalf("failed to add transaction: %v", err)
+	}
+	timeout := time.After(3 * minRecommitInterval)
+	for {
+		select {
+		case work := <-agent.work:
+			if work.Block.NumberU64() != 1 {
+				t.Fatalf("work built on wrong parent: have block %d, want %d", work.Block.NumberU64(), 1)
+			}
+			if txs := work.Block.Transactions(); len(txs) == 1 && txs[0].Hash() == tx.Hash() {
+				if head := backend.chain.CurrentBlock().NumberU64(); head != 0 {
+					t.Fatalf("chain head moved: have %d, want %d", head, 0)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("recommitted work not pushed")
+		}
+	}
+}
@@ -70,6 +70,11 @@ type NodeConfig struct {
 	// It has the form "nodename:secret@host:port"
 	EthereumNetStats string
 
+	// EthashDagRoots are the trusted Merkle roots of the ethash mining DAGs, indexed
+	// by epoch. If set, block seals are verified via proofs served by the remote
+	// peers instead of generating the memory hungry verification caches.
+	EthashDagRoots *Hashes
+
 	// WhisperEnabled specifies whether the node should run the Whisper protocol.
 	WhisperEnabled bool
 }
@@ -149,6 +154,10 @@ func NewNode(datadir string, config *NodeConfig) (stack *Node, _ error) {
 		ethConf.SyncMode = downloader.LightSync
 		ethConf.NetworkId = uint64(config.EthereumNetworkID)
 		ethConf.DatabaseCache = config.EthereumDatabaseCache
+		if config.EthashDagRoots != nil {
+			ethConf.Ethash.SealProofs = true
+			ethConf.Ethash.DagRoots = config.EthashDagRoots.hashes
+		}
 		if err := rawStack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
 			return les.New(ctx, &ethConf)
 		}); err != nil {
@@ -75,6 +75,15 @@ func (api *PrivateAdminAPI) RemovePeer(url string) (bool, error) {
 	return true, nil
 }
 
+// RotateNodeKey replaces the node key with a freshly generated one, giving the
+// node a new identity, and returns the new enode URL.
+func (api *PrivateAdminAPI) RotateNodeKey() (string, error) {
+	if err := api.node.RotateNodeKey(); err != nil {
+		return "", err
+	}
+	return api.node.Server().Self().String(), nil
+}
+
 // PeerEvents creates an RPC subscription which receives peer events from the
 // node's p2p.Server
 func (api *PrivateAdminAPI) PeerEvents(ctx context.Context) (*rpc.Subscription, error) {
@@ -24,6 +24,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/AdelineCoin/go-adln/accounts"
 	"github.com/AdelineCoin/go-adln/accounts/keystore"
@@ -33,6 +34,7 @@ import (
 	"github.com/AdelineCoin/go-adln/log"
 	"github.com/AdelineCoin/go-adln/p2p"
 	"github.com/AdelineCoin/go-adln/p2p/discover"
+	"github.com/AdelineCoin/go-adln/rpc"
 )
 
 const (
@@ -91,6 +93,10 @@ type Config struct {
 	// relative), then that specific path is enforced. An empty path disables IPC.
 	IPCPath string `toml:",omitempty"`
 
+	// IPCPolicy is the access policy (method filters, rate, concurrency and result
+	// size limits) enforced on the IPC endpoint.
+	IPCPolicy *rpc.Policy `toml:",omitempty"`
+
 	// HTTPHost is the host interface on which to start the HTTP RPC server. If this
 	// field is empty, no HTTP API endpoint will be started.
 	HTTPHost string `toml:",omitempty"`
@@ -119,6 +125,20 @@ type Config struct {
 	// exposed.
 	HTTPModules []string `toml:",omitempty"`
 
+	// HTTPJWTSecret is the path to a file holding a hex encoded 32 byte secret.
+	// If set, every HTTP RPC request must carry an HS256 bearer token signed with
+	// it and issued within the last minute.
+	HTTPJWTSecret string `toml:",omitempty"`
+
+	// HTTPJWTModules optionally restricts the API modules reachable over HTTP by
+	// a token, keyed by the subject (sub) claim of the token.
+	HTTPJWTModules map[string][]string `toml:",omitempty"`
+
+	// HTTPPolicy is the access policy (method filters, rate, concurrency and result
+	// size limits) enforced on the HTTP RPC endpoint. Its rate, concurrency and
+	// size limits also apply to the plain HTTP handlers of services (e.g. GraphQL).
+	HTTPPolicy *rpc.Policy `toml:",omitempty"`
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string `toml:",omitempty"`
@@ -145,6 +165,38 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// WSJWTSecret is the path to a file holding a hex encoded 32 byte secret. If
+	// set, every websocket upgrade request must carry an HS256 bearer token signed
+	// with it and issued within the last minute.
+	WSJWTSecret string `toml:",omitempty"`
+
+	// WSJWTModules optionally restricts the API modules reachable over websocket
+	// by a token, keyed by the subject (sub) claim of the token.
+	WSJWTModules map[string][]string `toml:",omitempty"`
+
+	// WSPolicy is the access policy (method filters, rate, concurrency and result
+	// size limits) enforced on the websocket RPC endpoint.
+	WSPolicy *rpc.Policy `toml:",omitempty"`
+
+	// RPCSlowCallThreshold is the duration above which RPC calls served over IPC,
+	// HTTP or websocket are logged together with their parameters. Parameters of
+	// the personal and shh namespaces, which carry passwords and keys, are only
+	// logged by size. Zero disables slow call logging.
+	RPCSlowCallThreshold time.Duration `toml:",omitempty"`
+
+	// RPCBatchLimit is the maximum number of requests accepted in a single batch
+	// over HTTP or websocket. Zero, the default, disables the limit.
+	RPCBatchLimit int `toml:",omitempty"`
+
+	// RPCResponseLimit is the maximum size in bytes of a single (batch) response
+	// sent over HTTP or websocket. Responses exceeding it once partly sent are cut
+	// short. Zero, the default, disables the limit.
+	RPCResponseLimit int `toml:",omitempty"`
+
+	// Health configures the checks behind the /health and /ready endpoints of
+	// the HTTP RPC server.
+	Health HealthConfig
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 }
@@ -209,6 +261,19 @@ func DefaultHTTPEndpoint() string {
 	return config.HTTPEndpoint()
 }
 
+// jwtConfig assembles the authentication settings of an RPC endpoint, returning
+// nil if no secret file was configured.
+func jwtConfig(secretFile string, modules map[string][]string) (*rpc.JWTConfig, error) {
+	if secretFile == "" {
+		return nil, nil
+	}
+	secret, err := rpc.LoadJWTSecret(secretFile)
+	if err != nil {
+		return nil, err
+	}
+	return &rpc.JWTConfig{Secret: secret, Modules: modules}, nil
+}
+
 // WSEndpoint resolves an websocket endpoint based on the configured host interface
 // and port parameters.
 func (c *Config) WSEndpoint() string {
@@ -318,18 +383,49 @@ func (c *Config) NodeKey() *ecdsa.PrivateKey {
 	if err != nil {
 		log.Crit(fmt.Sprintf("Failed to generate node key: %v", err))
 	}
-	instanceDir := filepath.Join(c.DataDir, c.name())
-	if err := os.MkdirAll(instanceDir, 0700); err != nil {
-		log.Error(fmt.Sprintf("Failed to persist node key: %v", err))
-		return key
-	}
-	keyfile = filepath.Join(instanceDir, datadirPrivateKey)
-	if err := crypto.SaveECDSA(keyfile, key); err != nil {
+	if err := c.saveNodeKey(key); err != nil {
 		log.Error(fmt.Sprintf("Failed to persist node key: %v", err))
 	}
 	return key
 }
 
+// rotateNodeKey generates a new node key, replacing the one persisted in the
+// instance directory. Ephemeral nodes get a new key without persisting it, while
+// specifically configured keys can't be rotated.
+func (c *Config) rotateNodeKey() (*ecdsa.PrivateKey, error) {
+	if c.P2P.PrivateKey != nil {
+		return nil, ErrNodeKeyConfigured
+	}
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	if c.DataDir == "" {
+		return key, nil
+	}
+	if err := c.saveNodeKey(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// restoreNodeKey persists a node key replaced by rotateNodeKey again.
+func (c *Config) restoreNodeKey(key *ecdsa.PrivateKey) error {
+	if c.DataDir == "" {
+		return nil
+	}
+	return c.saveNodeKey(key)
+}
+
+// saveNodeKey persists the node key in the instance directory.
+func (c *Config) saveNodeKey(key *ecdsa.PrivateKey) error {
+	instanceDir := filepath.Join(c.DataDir, c.name())
+	if err := os.MkdirAll(instanceDir, 0700); err != nil {
+		return err
+	}
+	return crypto.SaveECDSA(filepath.Join(instanceDir, datadirPrivateKey), key)
+}
+
 // StaticNodes returns a list of node enode URLs configured as static nodes.
 func (c *Config) StaticNodes() []*discover.Node {
 	return c.parsePersistentNodes(c.resolvePath(datadirStaticNodes))
@@ -28,6 +28,9 @@ var (
 	ErrNodeStopped    = errors.New("node not started")
 	ErrNodeRunning    = errors.New("node already running")
 	ErrServiceUnknown = errors.New("unknown service")
+	ErrHTTPPathUsed   = errors.New("HTTP path already registered")
+
+	ErrNodeKeyConfigured = errors.New("node key configured explicitly, can't rotate it")
 
 	datadirInUseErrnos = map[uint]bool{11: true, 32: true, 35: true}
 )
@@ -0,0 +1,161 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/metrics"
+)
+
+const (
+	healthPath = "/health" // URL path of the liveness endpoint
+	readyPath  = "/ready"  // URL path of the readiness endpoint
+)
+
+// HealthConfig configures the checks run by the readiness endpoint. Checks with
+// a zero threshold are disabled.
+type HealthConfig struct {
+	MinPeers   int           `toml:",omitempty"` // Minimum number of connected peers
+	MaxHeadAge time.Duration `toml:",omitempty"` // Maximum age of the chain head block
+	NotSyncing bool          `toml:",omitempty"` // Whether chain synchronisation must be complete
+}
+
+// HealthCheck is a named check contributing to the node's health status.
+type HealthCheck struct {
+	Name  string              // Name of the check reported in the status details
+	Ready bool                // Whether the check only gates readiness, not liveness
+	Check metrics.Healthcheck // Check updating the status when run
+}
+
+// HealthService is an optional interface a Service may implement to contribute
+// checks to the node's /health and /ready endpoints.
+type HealthService interface {
+	// HealthChecks retrieves the checks of the service, honouring the thresholds
+	// of the given configuration.
+	HealthChecks(config *HealthConfig) []HealthCheck
+}
+
+// healthStatus is the JSON report served by the health endpoints.
+type healthStatus struct {
+	Healthy bool                   `json:"healthy"`
+	Checks  map[string]checkStatus `json:"checks"`
+}
+
+// checkStatus is the outcome of a single health check.
+type checkStatus struct {
+	Healthy bool   `json:"healthy"`
+	Error   string `json:"error,omitempty"`
+}
+
+// healthHandler serves the liveness and readiness endpoints. Liveness only runs
+// the checks indicating the node is functional, whereas readiness additionally
+// runs those indicating it is fit to serve requests.
+type healthHandler struct {
+	checks []HealthCheck
+	lock   sync.Mutex // Serialises check runs, healthchecks aren't thread safe
+}
+
+// newHealthHandler creates the health endpoints of a node, combining the node's
+// own checks with those of the services. The peers callback reports the number
+// of connected peers.
+func newHealthHandler(config *HealthConfig, peers func() int, services []HealthService) *healthHandler {
+	var checks []HealthCheck
+	if config.MinPeers > 0 {
+		checks = append(checks, HealthCheck{
+			Name:  "peers",
+			Ready: true,
+			Check: metrics.NewHealthcheckForced(func(h metrics.Healthcheck) {
+				if count := peers(); count < config.MinPeers {
+					h.Unhealthy(fmt.Errorf("too few peers: have %d, want %d", count, config.MinPeers))
+					return
+				}
+				h.Healthy()
+			}),
+		})
+	}
+	for _, service := range services {
+		checks = append(checks, service.HealthChecks(config)...)
+	}
+	sort.SliceStable(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	return &healthHandler{checks: checks}
+}
+
+// ServeHTTP runs the checks of the requested endpoint and reports their status,
+// failing with 503 Service Unavailable if any of them is unhealthy.
+func (h *healthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var ready bool
+	switch r.URL.Path {
+	case healthPath:
+	case readyPath:
+		ready = true
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	status := h.run(ready)
+
+	code := http.StatusOK
+	if !status.Healthy {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if r.Method == http.MethodGet {
+		json.NewEncoder(w).Encode(status)
+	}
+}
+
+// run executes the liveness checks, and the readiness ones too if requested.
+func (h *healthHandler) run(ready bool) *healthStatus {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	status := &healthStatus{Healthy: true, Checks: make(map[string]checkStatus)}
+	for _, check := range h.checks {
+		if check.Ready && !ready {
+			continue
+		}
+		check.Check.Check()
+		if err := check.Check.Error(); err != nil {
+			status.Healthy = false
+			status.Checks[check.Name] = checkStatus{Error: err.Error()}
+			continue
+		}
+		status.Checks[check.Name] = checkStatus{Healthy: true}
+	}
+	return status
+}
+
+// withHealth mounts the health endpoints in front of handler, bypassing any of
+// its filters so that orchestrator probes need no credentials or host names.
+func withHealth(health http.Handler, handler http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(healthPath, health)
+	mux.Handle(readyPath, health)
+	mux.Handle("/", handler)
+	return mux
+}
@@ -0,0 +1,110 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package node
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/metrics"
+	"github.com/AdelineCoin/go-adln/rpc"
+)
+
+// testHealthService is a service contributing a liveness and a readiness check
+// with configurable outcomes.
+type testHealthService struct {
+	alive, ready error
+}
+
+func (s *testHealthService) HealthChecks(config *HealthConfig) []HealthCheck {
+	return []HealthCheck{
+		{Name: "alive", Check: metrics.NewHealthcheckForced(func(h metrics.Healthcheck) { h.Unhealthy(s.alive) })},
+		{Name: "ready", Ready: true, Check: metrics.NewHealthcheckForced(func(h metrics.Healthcheck) { h.Unhealthy(s.ready) })},
+	}
+}
+
+// probe requests a health endpoint and decodes the reported status.
+func probe(t *testing.T, handler http.Handler, path string) (int, *healthStatus) {
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil))
+
+	status := new(healthStatus)
+	if err := json.Unmarshal(rec.Body.Bytes(), status); err != nil {
+		t.Fatalf("%s: failed to decode status %q: %v", path, rec.Body.String(), err)
+	}
+	return rec.Code, status
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	var (
+		peers   = 1
+		service = new(testHealthService)
+		handler = newHealthHandler(&HealthConfig{MinPeers: 2}, func() int { return peers }, []HealthService{service})
+	)
+	// Too few peers and a failing readiness check must only fail readiness
+	service.ready = errors.New("not ready")
+
+	code, status := probe(t, handler, healthPath)
+	if code != http.StatusOK || !status.Healthy || len(status.Checks) != 1 || !status.Checks["alive"].Healthy {
+		t.Fatalf("liveness mismatch: code %d, status %+v", code, status)
+	}
+	code, status = probe(t, handler, readyPath)
+	if code != http.StatusServiceUnavailable || status.Healthy || len(status.Checks) != 3 {
+		t.Fatalf("readiness mismatch: code %d, status %+v", code, status)
+	}
+	if check := status.Checks["peers"]; check.Healthy || check.Error == "" {
+		t.Errorf("peer check mismatch: %+v", check)
+	}
+	if check := status.Checks["ready"]; check.Healthy || check.Error != "not ready" {
+		t.Errorf("service check mismatch: %+v", check)
+	}
+	// Once all checks pass, the node must become ready
+	peers, service.ready = 2, nil
+
+	if code, status = probe(t, handler, readyPath); code != http.StatusOK || !status.Healthy {
+		t.Fatalf("readiness mismatch: code %d, status %+v", code, status)
+	}
+	// A failing liveness check must fail both endpoints
+	service.alive = errors.New("broken")
+
+	if code, _ = probe(t, handler, healthPath); code != http.StatusServiceUnavailable {
+		t.Errorf("liveness code mismatch: have %d, want %d", code, http.StatusServiceUnavailable)
+	}
+	if code, _ = probe(t, handler, readyPath); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness code mismatch: have %d, want %d", code, http.StatusServiceUnavailable)
+	}
+}
+
+// Tests that the health endpoints are reachable even when the RPC server only
+// accepts requests for specific virtual hosts.
+func TestHealthBypassesVirtualHosts(t *testing.T) {
+	health := newHealthHandler(&HealthConfig{}, func() int { return 0 }, nil)
+	server := rpc.NewHTTPServer(nil, []string{"localhost"}, nil, rpc.NewServer())
+	handler := withHealth(health, server.Handler)
+
+	if code, status := probe(t, handler, healthPath); code != http.StatusOK || !status.Healthy {
+		t.Fatalf("health endpoint unreachable: code %d, status %+v", code, status)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "http://example.com/", nil))
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("RPC virtual host filter bypassed: code %d", rec.Code)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -58,10 +59,12 @@ type Node struct {
 	ipcListener net.Listener // IPC RPC listener socket to serve API requests
 	ipcHandler  *rpc.Server  // IPC RPC request handler to process the API requests
 
-	httpEndpoint  string       // HTTP endpoint (interface + port) to listen at (empty = HTTP disabled)
-	httpWhitelist []string     // HTTP RPC modules to allow through this endpoint
-	httpListener  net.Listener // HTTP RPC listener socket to server API requests
-	httpHandler   *rpc.Server  // HTTP RPC request handler to process the API requests
+	httpEndpoint  string                  // HTTP endpoint (interface + port) to listen at (empty = HTTP disabled)
+	httpWhitelist []string                // HTTP RPC modules to allow through this endpoint
+	httpListener  net.Listener            // HTTP RPC listener socket to server API requests
+	httpHandler   *rpc.Server             // HTTP RPC request handler to process the API requests
+	httpServices  map[string]http.Handler // Additional HTTP handlers provided by the services
+	httpHealth    *healthHandler          // Health and readiness endpoints of the HTTP server
 
 	wsEndpoint string       // Websocket endpoint (interface + port) to listen at (empty = websocket disabled)
 	wsListener net.Listener // Websocket RPC listener socket to server API requests
@@ -255,6 +258,29 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 	for _, service := range services {
 		apis = append(apis, service.APIs()...)
 	}
+	// Gather all the plain HTTP handlers to mount next to the RPC API
+	handlers := make(map[string]http.Handler)
+	for _, service := range services {
+		if service, ok := service.(HTTPService); ok {
+			for path, handler := range service.HTTPHandlers() {
+				if _, ok := handlers[path]; ok || path == "/" || path == healthPath || path == readyPath {
+					return fmt.Errorf("%v: %s", ErrHTTPPathUsed, path)
+				}
+				handlers[path] = handler
+			}
+		}
+	}
+	n.httpServices = handlers
+
+	// Gather the health checks of the services
+	var health []HealthService
+	for _, service := range services {
+		if service, ok := service.(HealthService); ok {
+			health = append(health, service)
+		}
+	}
+	n.httpHealth = newHealthHandler(&n.config.Health, n.peerCount, health)
+
 	// Start the various API endpoints, terminating all in case of errors
 	if err := n.startInProc(apis); err != nil {
 		return err
@@ -309,6 +335,8 @@ func (n *Node) startIPC(apis []rpc.API) error {
 	}
 	// Register all the APIs exposed by the services
 	handler := rpc.NewServer()
+	handler.SetPolicy(n.config.IPCPolicy)
+	handler.SetSlowCallThreshold(n.config.RPCSlowCallThreshold)
 	for _, api := range apis {
 		if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
 			return err
@@ -377,6 +405,9 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	}
 	// Register all the APIs exposed by the services
 	handler := rpc.NewServer()
+	handler.SetPolicy(n.config.HTTPPolicy)
+	handler.SetBatchLimits(n.config.RPCBatchLimit, n.config.RPCResponseLimit)
+	handler.SetSlowCallThreshold(n.config.RPCSlowCallThreshold)
 	for _, api := range apis {
 		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -385,16 +416,29 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 			n.log.Debug("HTTP registered", "service", api.Service, "namespace", api.Namespace)
 		}
 	}
+	jwt, err := jwtConfig(n.config.HTTPJWTSecret, n.config.HTTPJWTModules)
+	if err != nil {
+		return err
+	}
+	// Mount the RPC API and any service provided handlers onto the same endpoint,
+	// the latter limited by the same policy as the RPC API
+	mux := http.NewServeMux()
+	mux.Handle("/", handler)
+	for path, service := range n.httpServices {
+		mux.Handle(path, handler.LimitHandler(service))
+		n.log.Debug("HTTP handler registered", "path", path)
+	}
 	// All APIs registered, start the HTTP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
 		return err
 	}
-	go rpc.NewHTTPServer(cors, vhosts, handler).Serve(listener)
-	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","))
+	server := rpc.NewHTTPServer(cors, vhosts, jwt, mux)
+	if n.httpHealth != nil {
+		server.Handler = withHealth(n.httpHealth, server.Handler)
+	}
+	go server.Serve(listener)
+	n.log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint), "cors", strings.Join(cors, ","), "vhosts", strings.Join(vhosts, ","), "auth", jwt != nil)
 	// All listeners booted successfully
 	n.httpEndpoint = endpoint
 	n.httpListener = listener
@@ -430,6 +474,9 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	}
 	// Register all the APIs exposed by the services
 	handler := rpc.NewServer()
+	handler.SetPolicy(n.config.WSPolicy)
+	handler.SetBatchLimits(n.config.RPCBatchLimit, n.config.RPCResponseLimit)
+	handler.SetSlowCallThreshold(n.config.RPCSlowCallThreshold)
 	for _, api := range apis {
 		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -438,16 +485,17 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 			n.log.Debug("WebSocket registered", "service", api.Service, "namespace", api.Namespace)
 		}
 	}
+	jwt, err := jwtConfig(n.config.WSJWTSecret, n.config.WSJWTModules)
+	if err != nil {
+		return err
+	}
 	// All APIs registered, start the HTTP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
 		return err
 	}
-	go rpc.NewWSServer(wsOrigins, handler).Serve(listener)
-	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()))
+	go rpc.NewWSServer(wsOrigins, jwt, handler).Serve(listener)
+	n.log.Info("WebSocket endpoint opened", "url", fmt.Sprintf("ws://%s", listener.Addr()), "auth", jwt != nil)
 
 	// All listeners booted successfully
 	n.wsEndpoint = endpoint
@@ -487,6 +535,8 @@ func (n *Node) Stop() error {
 	n.stopHTTP()
 	n.stopIPC()
 	n.rpcAPIs = nil
+	n.httpServices = nil
+	n.httpHealth = nil
 	failure := &StopError{
 		Services: make(map[reflect.Type]error),
 	}
@@ -551,6 +601,32 @@ func (n *Node) Restart() error {
 	return nil
 }
 
+// RotateNodeKey replaces the node key with a freshly generated one, giving the
+// node a new identity on the p2p network. The new key is persisted in the data
+// directory, and the running P2P server switches to it, disconnecting all peers.
+// If the server can't be restarted with the new key, the previous one is kept.
+func (n *Node) RotateNodeKey() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	if n.server == nil {
+		return ErrNodeStopped
+	}
+	key, err := n.config.rotateNodeKey()
+	if err != nil {
+		return err
+	}
+	if err := n.server.SetPrivateKey(key); err != nil {
+		// The server fell back to the previous key, persist it again too
+		if err := n.config.restoreNodeKey(n.serverConfig.PrivateKey); err != nil {
+			n.log.Error("Failed to restore node key", "err", err)
+		}
+		return err
+	}
+	n.serverConfig.PrivateKey = key
+	return nil
+}
+
 // Attach creates an RPC client attached to an in-process API handler.
 func (n *Node) Attach() (*rpc.Client, error) {
 	n.lock.RLock()
@@ -573,6 +649,15 @@ func (n *Node) RPCHandler() (*rpc.Server, error) {
 	return n.inprocHandler, nil
 }
 
+// peerCount returns the number of peers connected to the running P2P network
+// layer, or zero if it is not running.
+func (n *Node) peerCount() int {
+	if server := n.Server(); server != nil {
+		return server.PeerCount()
+	}
+	return 0
+}
+
 // Server retrieves the currently running P2P network layer. This method is meant
 // only to inspect fields of the currently running server, life cycle management
 // should be left to this Node entity.
@@ -26,6 +26,7 @@ import (
 
 	"github.com/AdelineCoin/go-adln/crypto"
 	"github.com/AdelineCoin/go-adln/p2p"
+	"github.com/AdelineCoin/go-adln/p2p/discover"
 	"github.com/AdelineCoin/go-adln/rpc"
 )
 
@@ -103,6 +104,93 @@ func TestNodeUsedDataDir(t *testing.T) {
 	}
 }
 
+// Tests that rotating the node key changes the identity of the running node and
+// persists the new key in the data directory.
+func TestNodeKeyRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	stack, err := New(&Config{DataDir: dir, P2P: p2p.Config{ListenAddr: "127.0.0.1:0", MaxPeers: 10}})
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	if err := stack.RotateNodeKey(); err != ErrNodeStopped {
+		t.Fatalf("rotation failure mismatch: have %v, want %v", err, ErrNodeStopped)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	defer stack.Stop()
+
+	old := stack.Server().Self().ID
+	if err := stack.RotateNodeKey(); err != nil {
+		t.Fatalf("failed to rotate node key: %v", err)
+	}
+	key, err := crypto.LoadECDSA(stack.ResolvePath(datadirPrivateKey))
+	if err != nil {
+		t.Fatalf("failed to load persisted node key: %v", err)
+	}
+	id := stack.Server().Self().ID
+	if id == old {
+		t.Fatalf("node identity unchanged after rotation")
+	}
+	if want := discover.PubkeyID(&key.PublicKey); id != want {
+		t.Fatalf("node identity mismatch: have %v, want %v", id, want)
+	}
+
+	// Specifically configured keys can't be rotated
+	fixed, err := New(testNodeConfig())
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	if err := fixed.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	defer fixed.Stop()
+
+	if err := fixed.RotateNodeKey(); err != ErrNodeKeyConfigured {
+		t.Fatalf("rotation failure mismatch: have %v, want %v", err, ErrNodeKeyConfigured)
+	}
+}
+
+// Tests that a node key rotation failing to restart the P2P server keeps the
+// previous key, both in the server and in the data directory.
+func TestNodeKeyRotationFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary data directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	stack, err := New(&Config{DataDir: dir, P2P: p2p.Config{ListenAddr: "127.0.0.1:0", MaxPeers: 10}})
+	if err != nil {
+		t.Fatalf("failed to create protocol stack: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start node: %v", err)
+	}
+	defer stack.Stop()
+
+	old := stack.Server().PrivateKey
+	stack.Server().DNSDiscovery = []string{"invalid"}
+	if err := stack.RotateNodeKey(); err == nil {
+		t.Fatalf("rotation succeeded with broken configuration")
+	}
+	if stack.Server().PrivateKey != old {
+		t.Errorf("server key not restored")
+	}
+	key, err := crypto.LoadECDSA(stack.ResolvePath(datadirPrivateKey))
+	if err != nil {
+		t.Fatalf("failed to load persisted node key: %v", err)
+	}
+	if key.D.Cmp(old.D) != 0 {
+		t.Errorf("persisted node key not restored")
+	}
+}
+
 // Tests whether services can be registered and duplicates caught.
 func TestServiceRegistry(t *testing.T) {
 	stack, err := New(testNodeConfig())
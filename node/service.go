@@ -17,6 +17,7 @@
 package node
 
 import (
+	"net/http"
 	"reflect"
 
 	"github.com/AdelineCoin/go-adln/accounts"
@@ -96,3 +97,10 @@ type Service interface {
 	// are all terminated.
 	Stop() error
 }
+
+// HTTPService is an optional interface a Service may implement to expose plain
+// HTTP handlers on the node's HTTP RPC endpoint, next to the JSON-RPC API.
+type HTTPService interface {
+	// HTTPHandlers retrieves the handlers to mount, keyed by their URL path.
+	HTTPHandlers() map[string]http.Handler
+}
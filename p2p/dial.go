@@ -82,6 +82,7 @@ type dialstate struct {
 
 	start     time.Time        // time when the dialer was first used
 	bootnodes []*discover.Node // default dials when there are no peers
+	sources   []nodeSource     // additional sources of dynamic dial candidates
 }
 
 type discoverTable interface {
@@ -92,6 +93,12 @@ type discoverTable interface {
 	ReadRandomNodes([]*discover.Node) int
 }
 
+// nodeSource provides random dial candidates in addition to the nodes
+// of the discovery table, e.g. from DNS node lists.
+type nodeSource interface {
+	ReadRandomNodes([]*discover.Node) int
+}
+
 // the dial history remembers recent dials.
 type dialHistory []pastDial
 
@@ -226,6 +233,20 @@ func (s *dialstate) newTasks(nRunning int, peers map[discover.NodeID]*Peer, now
 			}
 		}
 	}
+	// Use random nodes from the additional node sources for half of
+	// the remaining dynamic dials.
+	for _, src := range s.sources {
+		candidates := (needDynDials + 1) / 2
+		if candidates == 0 {
+			break
+		}
+		n := src.ReadRandomNodes(s.randomNodes)
+		for i := 0; i < candidates && i < n; i++ {
+			if addDial(dynDialedConn, s.randomNodes[i]) {
+				needDynDials--
+			}
+		}
+	}
 	// Create dynamic dials from random lookup results, removing tried
 	// items from the result buffer.
 	i := 0
@@ -364,7 +385,13 @@ func (t *discoverTask) Do(srv *Server) {
 	// event loop spins too fast.
 	next := srv.lastLookup.Add(lookupInterval)
 	if now := time.Now(); now.Before(next) {
-		time.Sleep(next.Sub(now))
+		timer := time.NewTimer(next.Sub(now))
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-srv.quit:
+			return
+		}
 	}
 	srv.lastLookup = time.Now()
 	var target discover.NodeID
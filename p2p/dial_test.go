@@ -402,6 +402,52 @@ func TestDialStateDynDialFromTable(t *testing.T) {
 	})
 }
 
+// This test checks that dynamic dials are launched from additional node
+// sources, such as DNS node lists.
+func TestDialStateDynDialFromSource(t *testing.T) {
+	// This source always returns the same random nodes
+	// in the order given below.
+	source := fakeTable{
+		{ID: uintID(1)},
+		{ID: uintID(2)},
+		{ID: uintID(3)},
+		{ID: uintID(4)},
+		{ID: uintID(5)},
+		{ID: uintID(6)},
+		{ID: uintID(7)},
+		{ID: uintID(8)},
+	}
+	state := newDialState(nil, nil, fakeTable{}, 10, nil)
+	state.sources = []nodeSource{source}
+
+	runDialTest(t, dialtest{
+		init: state,
+		rounds: []round{
+			// Half of the dynamic dials are launched from the source,
+			// a lookup is started for the rest.
+			{
+				new: []task{
+					&dialTask{flags: dynDialedConn, dest: &discover.Node{ID: uintID(1)}},
+					&dialTask{flags: dynDialedConn, dest: &discover.Node{ID: uintID(2)}},
+					&dialTask{flags: dynDialedConn, dest: &discover.Node{ID: uintID(3)}},
+					&dialTask{flags: dynDialedConn, dest: &discover.Node{ID: uintID(4)}},
+					&dialTask{flags: dynDialedConn, dest: &discover.Node{ID: uintID(5)}},
+					&discoverTask{},
+				},
+			},
+			// Nodes that are already being dialed are not dialed again.
+			{
+				done: []task{
+					&discoverTask{},
+				},
+				new: []task{
+					&discoverTask{},
+				},
+			},
+		},
+	})
+}
+
 // This test checks that candidates that do not match the netrestrict list are not dialed.
 func TestDialStateNetRestrict(t *testing.T) {
 	// This table always returns the same random nodes
@@ -29,6 +29,7 @@ import (
 
 	"github.com/AdelineCoin/go-adln/crypto"
 	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
 	"github.com/AdelineCoin/go-adln/rlp"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/errors"
@@ -61,6 +62,9 @@ var (
 	nodeDBDiscoverPing      = nodeDBDiscoverRoot + ":lastping"
 	nodeDBDiscoverPong      = nodeDBDiscoverRoot + ":lastpong"
 	nodeDBDiscoverFindFails = nodeDBDiscoverRoot + ":findfail"
+
+	nodeDBLocalPrefix = []byte("local:") // Identifier to prefix local node entries with
+	nodeDBLocalRecord = ":enr"
 )
 
 // newNodeDB creates a new node database for storing and retrieving infos about
@@ -175,6 +179,37 @@ func (db *nodeDB) storeInt64(key []byte, n int64) error {
 	return db.lvl.Put(key, blob, nil)
 }
 
+// makeLocalKey generates the leveldb key-blob of a field of the local node.
+// These are kept apart from the entries of discovered nodes so that expiring
+// nodes never touches them.
+func makeLocalKey(id NodeID, field string) []byte {
+	return append(nodeDBLocalPrefix, append(id[:], field...)...)
+}
+
+// localRecord retrieves the last signed record of the local node with the given
+// id, or nil if none was stored.
+func (db *nodeDB) localRecord(id NodeID) *enr.Record {
+	blob, err := db.lvl.Get(makeLocalKey(id, nodeDBLocalRecord), nil)
+	if err != nil {
+		return nil
+	}
+	record := new(enr.Record)
+	if err := rlp.DecodeBytes(blob, record); err != nil {
+		log.Warn("Failed to decode local node record", "err", err)
+		return nil
+	}
+	return record
+}
+
+// storeLocalRecord updates the signed record of the local node with the given id.
+func (db *nodeDB) storeLocalRecord(id NodeID, record *enr.Record) error {
+	blob, err := rlp.EncodeToBytes(record)
+	if err != nil {
+		return err
+	}
+	return db.lvl.Put(makeLocalKey(id, nodeDBLocalRecord), blob, nil)
+}
+
 // node retrieves a node with a given id from the database.
 func (db *nodeDB) node(id NodeID) *Node {
 	blob, err := db.lvl.Get(makeKey(id, nodeDBDiscoverRoot), nil)
@@ -0,0 +1,213 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"net"
+	"sync"
+
+	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
+	"github.com/AdelineCoin/go-adln/rlp"
+)
+
+// LocalNode maintains the signed node record (EIP-778) of the local node.
+//
+// Entries may be updated at any time. The record is re-signed lazily with an
+// incremented sequence number on the first access after any entry changed. Once
+// attached to a node database by the discovery protocol, the latest record is
+// also persisted, so that the sequence number keeps increasing across restarts.
+type LocalNode struct {
+	id  NodeID
+	key *ecdsa.PrivateKey
+
+	lock    sync.Mutex
+	db      *nodeDB              // Database to persist the record in, nil if not attached
+	entries map[string]enr.Entry // Entries of the record, indexed by key
+	seq     uint64               // Sequence number of the last signed record
+	record  *enr.Record          // Last signed record, nil if the entries changed since
+}
+
+// NewLocalNode creates the record maintainer of the local node identified by the
+// given private key.
+func NewLocalNode(key *ecdsa.PrivateKey) *LocalNode {
+	return &LocalNode{
+		id:      PubkeyID(&key.PublicKey),
+		key:     key,
+		entries: make(map[string]enr.Entry),
+	}
+}
+
+// ID returns the identifier of the local node.
+func (ln *LocalNode) ID() NodeID {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	return ln.id
+}
+
+// SetPrivateKey switches the local node to the identity of the given key. The
+// entries are kept and the record is re-signed by the new key on next access.
+// The node is detached from its database: the sequence number restarts from
+// zero until the discovery protocol attaches it again, resuming from the last
+// record persisted for the new identity.
+func (ln *LocalNode) SetPrivateKey(key *ecdsa.PrivateKey) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	ln.id = PubkeyID(&key.PublicKey)
+	ln.key = key
+	ln.db = nil
+	ln.seq = 0
+	ln.record = nil
+}
+
+// Set adds or updates an entry of the record, invalidating the signature if the
+// value changed.
+func (ln *LocalNode) Set(e enr.Entry) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	ln.set(e)
+}
+
+// Delete removes the entry with the given key from the record.
+func (ln *LocalNode) Delete(key string) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	ln.delete(key)
+}
+
+// SetIP updates the IP address announced in the record. Unspecified addresses
+// are not announced.
+func (ln *LocalNode) SetIP(ip net.IP) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	switch {
+	case ip == nil || ip.IsUnspecified():
+		ln.delete(enr.IP4{}.ENRKey())
+		ln.delete(enr.IP6{}.ENRKey())
+	case ip.To4() != nil:
+		ln.set(enr.IP4(ip.To4()))
+		ln.delete(enr.IP6{}.ENRKey())
+	default:
+		ln.set(enr.IP6(ip.To16()))
+		ln.delete(enr.IP4{}.ENRKey())
+	}
+}
+
+// IP returns the IP address announced in the record, or nil if there is none.
+func (ln *LocalNode) IP() net.IP {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	if ip, ok := ln.entries[enr.IP4{}.ENRKey()].(enr.IP4); ok {
+		return net.IP(ip)
+	}
+	if ip, ok := ln.entries[enr.IP6{}.ENRKey()].(enr.IP6); ok {
+		return net.IP(ip)
+	}
+	return nil
+}
+
+// Seq returns the sequence number of the current record.
+func (ln *LocalNode) Seq() uint64 {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	ln.sign()
+	return ln.seq
+}
+
+// Record returns the current signed record of the local node. The returned
+// record must not be modified by the caller.
+func (ln *LocalNode) Record() *enr.Record {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	ln.sign()
+	return ln.record
+}
+
+// attach makes the local node persist its record in the given database. The
+// sequence number resumes from the last record stored for this node, so that
+// the next record supersedes any published before the restart.
+func (ln *LocalNode) attach(db *nodeDB) {
+	ln.lock.Lock()
+	defer ln.lock.Unlock()
+
+	ln.db = db
+	if stored := db.localRecord(ln.id); stored != nil && stored.Seq() > ln.seq {
+		ln.seq = stored.Seq()
+	}
+	ln.record = nil
+}
+
+// set updates an entry, invalidating the record if its value changed. The lock
+// must be held by the caller.
+func (ln *LocalNode) set(e enr.Entry) {
+	if old, ok := ln.entries[e.ENRKey()]; ok && sameEntry(old, e) {
+		return
+	}
+	ln.entries[e.ENRKey()] = e
+	ln.record = nil
+}
+
+// delete removes an entry, invalidating the record if it existed. The lock must
+// be held by the caller.
+func (ln *LocalNode) delete(key string) {
+	if _, ok := ln.entries[key]; ok {
+		delete(ln.entries, key)
+		ln.record = nil
+	}
+}
+
+// sign creates and persists a new record if the entries changed since the last
+// signing. The lock must be held by the caller.
+func (ln *LocalNode) sign() {
+	if ln.record != nil {
+		return
+	}
+	record := new(enr.Record)
+	for _, e := range ln.entries {
+		record.Set(e)
+	}
+	record.SetSeq(ln.seq)
+	if err := record.Sign(ln.key); err != nil {
+		log.Error("Failed to sign local node record", "err", err)
+		return
+	}
+	ln.seq, ln.record = record.Seq(), record
+
+	if ln.db != nil {
+		if err := ln.db.storeLocalRecord(ln.id, record); err != nil {
+			log.Warn("Failed to store local node record", "err", err)
+		}
+	}
+	log.Debug("Updated local node record", "seq", ln.seq)
+}
+
+// sameEntry reports whether two entries of the same key have equal values.
+func sameEntry(a, b enr.Entry) bool {
+	blobA, errA := rlp.EncodeToBytes(a)
+	blobB, errB := rlp.EncodeToBytes(b)
+	return errA == nil && errB == nil && bytes.Equal(blobA, blobB)
+}
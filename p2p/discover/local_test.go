@@ -0,0 +1,97 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/p2p/enr"
+)
+
+// Tests that the local record is only re-signed when its entries change.
+func TestLocalNodeSeq(t *testing.T) {
+	ln := NewLocalNode(newkey())
+	ln.Set(enr.TCP(30303))
+
+	if seq := ln.Seq(); seq != 1 {
+		t.Fatalf("initial seq mismatch: have %d, want 1", seq)
+	}
+	ln.Set(enr.TCP(30303))
+	if seq := ln.Seq(); seq != 1 {
+		t.Fatalf("seq bumped without change: have %d, want 1", seq)
+	}
+	ln.Set(enr.TCP(30304))
+	ln.SetIP(net.ParseIP("1.2.3.4"))
+	if seq := ln.Seq(); seq != 2 {
+		t.Fatalf("seq mismatch after update: have %d, want 2", seq)
+	}
+	var ip enr.IP4
+	if err := ln.Record().Load(&ip); err != nil || !net.IP(ip).Equal(net.ParseIP("1.2.3.4")) {
+		t.Fatalf("IPv4 address mismatch: have %v, err %v", net.IP(ip), err)
+	}
+	// Switching to IPv6 must drop the IPv4 entry
+	ln.SetIP(net.ParseIP("2001:db8::1"))
+	if err := ln.Record().Load(&ip); !enr.IsNotFound(err) {
+		t.Fatalf("stale IPv4 address retained: %v", err)
+	}
+	if seq := ln.Seq(); seq != 3 {
+		t.Fatalf("seq mismatch after IP change: have %d, want 3", seq)
+	}
+}
+
+// Tests that the sequence number keeps increasing across restarts.
+func TestLocalNodePersistence(t *testing.T) {
+	root, err := ioutil.TempDir("", "nodedb-")
+	if err != nil {
+		t.Fatalf("failed to create temporary data folder: %v", err)
+	}
+	defer os.RemoveAll(root)
+
+	key := newkey()
+	for i := uint64(1); i <= 3; i++ {
+		db, err := newNodeDB(root, Version, PubkeyID(&key.PublicKey))
+		if err != nil {
+			t.Fatalf("failed to open node database: %v", err)
+		}
+		ln := NewLocalNode(key)
+		ln.attach(db)
+		ln.Set(enr.UDP(30303))
+
+		if seq := ln.Seq(); seq != i {
+			t.Errorf("run %d: seq mismatch: have %d, want %d", i, seq, i)
+		}
+		if stored := db.localRecord(ln.ID()); stored == nil || stored.Seq() != i {
+			t.Errorf("run %d: stored record mismatch: %v", i, stored)
+		}
+		db.close()
+	}
+	// Records of other keys must start afresh
+	db, err := newNodeDB(root, Version, PubkeyID(&key.PublicKey))
+	if err != nil {
+		t.Fatalf("failed to open node database: %v", err)
+	}
+	defer db.close()
+
+	ln := NewLocalNode(newkey())
+	ln.attach(db)
+	if seq := ln.Seq(); seq != 1 {
+		t.Errorf("rotated key seq mismatch: have %d, want 1", seq)
+	}
+}
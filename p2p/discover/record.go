@@ -0,0 +1,88 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"crypto/ecdsa"
+	"encoding/base64"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/AdelineCoin/go-adln/p2p/enr"
+	"github.com/AdelineCoin/go-adln/rlp"
+)
+
+// recordPrefix is the prefix of the textual representation of node records.
+const recordPrefix = "enr:"
+
+var (
+	errMissingRecordPrefix = errors.New("missing 'enr:' prefix")
+	errInvalidRecordPubkey = errors.New("record has no valid secp256k1 public key")
+)
+
+// ParseRecord decodes a signed node record from its textual representation,
+// which is the URL-safe base64 encoding of the record's RLP, prefixed by "enr:".
+// The signature of the record is verified.
+func ParseRecord(text string) (*enr.Record, error) {
+	if !strings.HasPrefix(text, recordPrefix) {
+		return nil, errMissingRecordPrefix
+	}
+	blob, err := base64.RawURLEncoding.DecodeString(text[len(recordPrefix):])
+	if err != nil {
+		return nil, err
+	}
+	r := new(enr.Record)
+	if err := rlp.DecodeBytes(blob, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// RecordString returns the textual representation of a signed node record.
+func RecordString(r *enr.Record) (string, error) {
+	blob, err := rlp.EncodeToBytes(r)
+	if err != nil {
+		return "", err
+	}
+	return recordPrefix + base64.RawURLEncoding.EncodeToString(blob), nil
+}
+
+// NodeFromRecord creates a node from the identity and endpoint contained in a
+// signed node record. The node is incomplete if the record has no IP address.
+func NodeFromRecord(r *enr.Record) (*Node, error) {
+	var pubkey enr.Secp256k1
+	if err := r.Load(&pubkey); err != nil {
+		return nil, errInvalidRecordPubkey
+	}
+	var (
+		ip4 enr.IP4
+		ip6 enr.IP6
+		ip  net.IP
+		tcp enr.TCP
+		udp enr.UDP
+	)
+	if r.Load(&ip4) == nil {
+		ip = net.IP(ip4)
+	} else if r.Load(&ip6) == nil {
+		ip = net.IP(ip6)
+	}
+	r.Load(&tcp)
+	r.Load(&udp)
+	key := ecdsa.PublicKey(pubkey)
+	return NewNode(PubkeyID(&key), ip, uint16(udp), uint16(tcp)), nil
+}
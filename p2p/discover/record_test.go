@@ -0,0 +1,69 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package discover
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/p2p/enr"
+)
+
+// Tests that records survive a round trip through their textual representation
+// and that the endpoint of the node is extracted correctly.
+func TestRecordText(t *testing.T) {
+	key := newkey()
+
+	var r enr.Record
+	r.Set(enr.IP4(net.IP{10, 0, 0, 1}))
+	r.Set(enr.TCP(30303))
+	r.Set(enr.UDP(30304))
+	if err := r.Sign(key); err != nil {
+		t.Fatalf("failed to sign record: %v", err)
+	}
+	text, err := RecordString(&r)
+	if err != nil {
+		t.Fatalf("failed to encode record: %v", err)
+	}
+	parsed, err := ParseRecord(text)
+	if err != nil {
+		t.Fatalf("failed to parse record %q: %v", text, err)
+	}
+	if parsed.Seq() != r.Seq() {
+		t.Errorf("seq mismatch: have %d, want %d", parsed.Seq(), r.Seq())
+	}
+	n, err := NodeFromRecord(parsed)
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	want := NewNode(PubkeyID(&key.PublicKey), net.IP{10, 0, 0, 1}, 30304, 30303)
+	if n.String() != want.String() {
+		t.Errorf("node mismatch:\nhave %v\nwant %v", n, want)
+	}
+	// Records without the prefix or with tampered content must be rejected
+	if _, err := ParseRecord(strings.TrimPrefix(text, "enr:")); err != errMissingRecordPrefix {
+		t.Errorf("missing prefix accepted: %v", err)
+	}
+	tampered := text[:len(text)-2] + "AA"
+	if tampered == text {
+		tampered = text[:len(text)-2] + "BB"
+	}
+	if _, err := ParseRecord(tampered); err == nil {
+		t.Errorf("tampered record accepted")
+	}
+}
@@ -36,6 +36,7 @@ import (
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/crypto"
 	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
 	"github.com/AdelineCoin/go-adln/p2p/netutil"
 )
 
@@ -84,8 +85,10 @@ type Table struct {
 
 	nodeAddedHook func(*Node) // for testing
 
-	net  transport
-	self *Node // metadata of the local node
+	net    transport
+	selfMu sync.RWMutex
+	self   *Node      // metadata of the local node, guarded by selfMu
+	local  *LocalNode // signed record of the local node, set by the transport
 }
 
 type bondproc struct {
@@ -101,6 +104,7 @@ type transport interface {
 	ping(NodeID, *net.UDPAddr) error
 	waitping(NodeID) error
 	findnode(toid NodeID, addr *net.UDPAddr, target NodeID) ([]*Node, error)
+	requestENR(toid NodeID, addr *net.UDPAddr) (*enr.Record, error)
 	close()
 }
 
@@ -164,9 +168,26 @@ func (tab *Table) seedRand() {
 // Self returns the local node.
 // The returned node should not be modified by the caller.
 func (tab *Table) Self() *Node {
+	tab.selfMu.RLock()
+	defer tab.selfMu.RUnlock()
 	return tab.self
 }
 
+// SetIP changes the IP address announced by the local node, e.g. after the
+// external address of a NAT device changed. The endpoint sent in pings, Self
+// and the signed node record are all updated.
+func (tab *Table) SetIP(ip net.IP) {
+	tab.selfMu.Lock()
+	self := *tab.self
+	self.IP = ip
+	tab.self = &self
+	tab.selfMu.Unlock()
+
+	if tab.local != nil {
+		tab.local.SetIP(ip)
+	}
+}
+
 // ReadRandomNodes fills the given slice with random nodes from the
 // table. It will not write the same node more than once. The nodes in
 // the slice are copies and can be modified by the caller.
@@ -270,6 +291,12 @@ func (tab *Table) Resolve(targetID NodeID) *Node {
 	return nil
 }
 
+// RequestENR retrieves the signed node record of the given node (EIP-868). The
+// node only answers if it has bonded with the local node before.
+func (tab *Table) RequestENR(n *Node) (*enr.Record, error) {
+	return tab.net.requestENR(n.ID, n.addr())
+}
+
 // Lookup performs a network search for nodes close
 // to the given target. It approaches the target by querying
 // nodes that are closer to it on each iteration.
@@ -290,7 +317,7 @@ func (tab *Table) lookup(targetID NodeID, refreshIfEmpty bool) []*Node {
 	)
 	// don't query further if we hit ourself.
 	// unlikely to happen often in practice.
-	asked[tab.self.ID] = true
+	asked[tab.Self().ID] = true
 
 	for {
 		tab.mutex.Lock()
@@ -432,7 +459,7 @@ func (tab *Table) doRefresh(done chan struct{}) {
 	tab.loadSeedNodes(true)
 
 	// Run self lookup to discover new neighbor nodes.
-	tab.lookup(tab.self.ID, false)
+	tab.lookup(tab.Self().ID, false)
 
 	// The Kademlia paper specifies that the bucket refresh should
 	// perform a lookup in the least recently used bucket. We cannot
@@ -588,7 +615,7 @@ func (tab *Table) bondall(nodes []*Node) (result []*Node) {
 // If pinged is true, the remote node has just pinged us and one half
 // of the process can be skipped.
 func (tab *Table) bond(pinged bool, id NodeID, addr *net.UDPAddr, tcpPort uint16) (*Node, error) {
-	if id == tab.self.ID {
+	if id == tab.Self().ID {
 		return nil, errors.New("is self")
 	}
 	if pinged && !tab.isInitDone() {
@@ -669,7 +696,7 @@ func (tab *Table) ping(id NodeID, addr *net.UDPAddr) error {
 
 // bucket returns the bucket for the given node ID hash.
 func (tab *Table) bucket(sha common.Hash) *bucket {
-	d := logdist(tab.self.sha, sha)
+	d := logdist(tab.Self().sha, sha)
 	if d <= bucketMinDistance {
 		return tab.buckets[0]
 	}
@@ -700,7 +727,7 @@ func (tab *Table) stuff(nodes []*Node) {
 	defer tab.mutex.Unlock()
 
 	for _, n := range nodes {
-		if n.ID == tab.self.ID {
+		if n.ID == tab.Self().ID {
 			continue // don't add self
 		}
 		b := tab.bucket(n.sha)
@@ -27,6 +27,7 @@ import (
 
 	"github.com/AdelineCoin/go-adln/crypto"
 	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
 	"github.com/AdelineCoin/go-adln/p2p/nat"
 	"github.com/AdelineCoin/go-adln/p2p/netutil"
 	"github.com/AdelineCoin/go-adln/rlp"
@@ -44,6 +45,7 @@ var (
 	errTimeout          = errors.New("RPC timeout")
 	errClockWarp        = errors.New("reply deadline too far in the future")
 	errClosed           = errors.New("socket closed")
+	errRecordMismatch   = errors.New("node record identity mismatch")
 )
 
 // Timeouts
@@ -63,6 +65,8 @@ const (
 	pongPacket
 	findnodePacket
 	neighborsPacket
+	enrRequestPacket
+	enrResponsePacket
 )
 
 // RPC request structures
@@ -71,7 +75,8 @@ type (
 		Version    uint
 		From, To   rpcEndpoint
 		Expiration uint64
-		// Ignore additional fields (for forward compatibility).
+		// Ignore additional fields (for forward compatibility). The first one
+		// holds the sequence number of the sender's node record (EIP-868).
 		Rest []rlp.RawValue `rlp:"tail"`
 	}
 
@@ -84,7 +89,8 @@ type (
 
 		ReplyTok   []byte // This contains the hash of the ping packet.
 		Expiration uint64 // Absolute timestamp at which the packet becomes invalid.
-		// Ignore additional fields (for forward compatibility).
+		// Ignore additional fields (for forward compatibility). The first one
+		// holds the sequence number of the sender's node record (EIP-868).
 		Rest []rlp.RawValue `rlp:"tail"`
 	}
 
@@ -104,6 +110,21 @@ type (
 		Rest []rlp.RawValue `rlp:"tail"`
 	}
 
+	// enrRequest is a query for the sender's node record (EIP-868).
+	enrRequest struct {
+		Expiration uint64
+		// Ignore additional fields (for forward compatibility).
+		Rest []rlp.RawValue `rlp:"tail"`
+	}
+
+	// reply to enrRequest
+	enrResponse struct {
+		ReplyTok []byte // This contains the hash of the enrRequest packet.
+		Record   enr.Record
+		// Ignore additional fields (for forward compatibility).
+		Rest []rlp.RawValue `rlp:"tail"`
+	}
+
 	rpcNode struct {
 		IP  net.IP // len 4 for IPv4 or 16 for IPv6
 		UDP uint16 // for discovery protocol
@@ -126,6 +147,13 @@ func makeEndpoint(addr *net.UDPAddr, tcpPort uint16) rpcEndpoint {
 	return rpcEndpoint{IP: ip, UDP: uint16(addr.Port), TCP: tcpPort}
 }
 
+// seqField encodes the sequence number of the local node record as the trailing
+// field of a ping or pong. Receivers predating EIP-868 ignore it.
+func seqField(seq uint64) []rlp.RawValue {
+	blob, _ := rlp.EncodeToBytes(seq)
+	return []rlp.RawValue{blob}
+}
+
 func (t *udp) nodeFromRPC(sender *net.UDPAddr, rn rpcNode) (*Node, error) {
 	if rn.UDP <= 1024 {
 		return nil, errors.New("low port")
@@ -162,7 +190,6 @@ type udp struct {
 	conn        conn
 	netrestrict *netutil.Netlist
 	priv        *ecdsa.PrivateKey
-	ourEndpoint rpcEndpoint
 
 	addpending chan *pending
 	gotreply   chan reply
@@ -222,6 +249,7 @@ type Config struct {
 	PrivateKey *ecdsa.PrivateKey
 
 	// These settings are optional:
+	LocalNode    *LocalNode        // local node record, created from PrivateKey if nil
 	AnnounceAddr *net.UDPAddr      // local address announced in the DHT
 	NodeDBPath   string            // if set, the node database is stored at this filesystem location
 	NetRestrict  *netutil.Netlist  // network whitelist
@@ -235,11 +263,15 @@ func ListenUDP(c conn, cfg Config) (*Table, error) {
 	if err != nil {
 		return nil, err
 	}
-	log.Info("UDP listener up", "self", tab.self)
+	log.Info("UDP listener up", "self", tab.Self())
 	return tab, nil
 }
 
 func newUDP(c conn, cfg Config) (*Table, *udp, error) {
+	local := cfg.LocalNode
+	if local == nil {
+		local = NewLocalNode(cfg.PrivateKey)
+	}
 	udp := &udp{
 		conn:        c,
 		priv:        cfg.PrivateKey,
@@ -252,19 +284,31 @@ func newUDP(c conn, cfg Config) (*Table, *udp, error) {
 	if cfg.AnnounceAddr != nil {
 		realaddr = cfg.AnnounceAddr
 	}
-	// TODO: separate TCP port
-	udp.ourEndpoint = makeEndpoint(realaddr, uint16(realaddr.Port))
 	tab, err := newTable(udp, PubkeyID(&cfg.PrivateKey.PublicKey), realaddr, cfg.NodeDBPath, cfg.Bootnodes)
 	if err != nil {
 		return nil, nil, err
 	}
+	tab.local = local
 	udp.Table = tab
 
+	// Announce the discovery endpoint in the local node record and persist it
+	// alongside the known nodes.
+	local.attach(tab.db)
+	local.SetIP(realaddr.IP)
+	local.Set(enr.UDP(realaddr.Port))
+
 	go udp.loop()
 	go udp.readLoop(cfg.Unhandled)
 	return udp.Table, udp, nil
 }
 
+// ourEndpoint returns the endpoint of the local node announced in pings.
+func (t *udp) ourEndpoint() rpcEndpoint {
+	self := t.Self()
+	// TODO: separate TCP port
+	return makeEndpoint(&net.UDPAddr{IP: self.IP, Port: int(self.UDP)}, self.TCP)
+}
+
 func (t *udp) close() {
 	close(t.closing)
 	t.conn.Close()
@@ -275,9 +319,10 @@ func (t *udp) close() {
 func (t *udp) ping(toid NodeID, toaddr *net.UDPAddr) error {
 	req := &ping{
 		Version:    Version,
-		From:       t.ourEndpoint,
+		From:       t.ourEndpoint(),
 		To:         makeEndpoint(toaddr, 0), // TODO: maybe use known TCP port from DB
 		Expiration: uint64(time.Now().Add(expiration).Unix()),
+		Rest:       seqField(t.local.Seq()),
 	}
 	packet, hash, err := encodePacket(t.priv, pingPacket, req)
 	if err != nil {
@@ -320,6 +365,40 @@ func (t *udp) findnode(toid NodeID, toaddr *net.UDPAddr, target NodeID) ([]*Node
 	return nodes, err
 }
 
+// requestENR sends an ENR request to the given node and waits for its signed
+// node record. The node only answers if it has bonded with us.
+func (t *udp) requestENR(toid NodeID, toaddr *net.UDPAddr) (*enr.Record, error) {
+	req := &enrRequest{
+		Expiration: uint64(time.Now().Add(expiration).Unix()),
+	}
+	packet, hash, err := encodePacket(t.priv, enrRequestPacket, req)
+	if err != nil {
+		return nil, err
+	}
+	var record *enr.Record
+	errc := t.pending(toid, enrResponsePacket, func(r interface{}) bool {
+		reply := r.(*enrResponse)
+		if !bytes.Equal(reply.ReplyTok, hash) {
+			return false
+		}
+		record = &reply.Record
+		return true
+	})
+	t.write(toaddr, req.name(), packet)
+	if err := <-errc; err != nil {
+		return nil, err
+	}
+	// Ensure the record was signed by the node we asked
+	var pubkey enr.Secp256k1
+	if err := record.Load(&pubkey); err != nil {
+		return nil, err
+	}
+	if PubkeyID((*ecdsa.PublicKey)(&pubkey)) != toid {
+		return nil, errRecordMismatch
+	}
+	return record, nil
+}
+
 // pending adds a reply callback to the pending reply queue.
 // see the documentation of type pending for a detailed explanation.
 func (t *udp) pending(id NodeID, ptype byte, callback func(interface{}) bool) <-chan error {
@@ -571,6 +650,10 @@ func decodePacket(buf []byte) (packet, NodeID, []byte, error) {
 		req = new(findnode)
 	case neighborsPacket:
 		req = new(neighbors)
+	case enrRequestPacket:
+		req = new(enrRequest)
+	case enrResponsePacket:
+		req = new(enrResponse)
 	default:
 		return nil, fromID, hash, fmt.Errorf("unknown type: %d", ptype)
 	}
@@ -587,6 +670,7 @@ func (req *ping) handle(t *udp, from *net.UDPAddr, fromID NodeID, mac []byte) er
 		To:         makeEndpoint(from, req.From.TCP),
 		ReplyTok:   mac,
 		Expiration: uint64(time.Now().Add(expiration).Unix()),
+		Rest:       seqField(t.local.Seq()),
 	})
 	if !t.handleReply(fromID, pingPacket, req) {
 		// Note: we're ignoring the provided IP address right now
@@ -662,6 +746,37 @@ func (req *neighbors) handle(t *udp, from *net.UDPAddr, fromID NodeID, mac []byt
 
 func (req *neighbors) name() string { return "NEIGHBORS/v4" }
 
+func (req *enrRequest) handle(t *udp, from *net.UDPAddr, fromID NodeID, mac []byte) error {
+	if expired(req.Expiration) {
+		return errExpired
+	}
+	if !t.db.hasBond(fromID) {
+		// Only bonded nodes may request the record, for the same reason as
+		// with findnode: the response is bigger than the request.
+		return errUnknownNode
+	}
+	record := t.local.Record()
+	if record == nil {
+		return errors.New("local node record unavailable")
+	}
+	t.send(from, enrResponsePacket, &enrResponse{
+		ReplyTok: mac,
+		Record:   *record,
+	})
+	return nil
+}
+
+func (req *enrRequest) name() string { return "ENRREQUEST/v4" }
+
+func (req *enrResponse) handle(t *udp, from *net.UDPAddr, fromID NodeID, mac []byte) error {
+	if !t.handleReply(fromID, enrResponsePacket, req) {
+		return errUnsolicitedReply
+	}
+	return nil
+}
+
+func (req *enrResponse) name() string { return "ENRRESPONSE/v4" }
+
 func expired(ts uint64) bool {
 	return time.Unix(int64(ts), 0).Before(time.Now())
 }
@@ -36,6 +36,7 @@ import (
 	"github.com/davecgh/go-spew/spew"
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
 	"github.com/AdelineCoin/go-adln/rlp"
 )
 
@@ -345,12 +346,15 @@ func TestUDP_successfulPing(t *testing.T) {
 		if !reflect.DeepEqual(p.To, wantTo) {
 			t.Errorf("got pong.To %v, want %v", p.To, wantTo)
 		}
+		if !reflect.DeepEqual(p.Rest, seqField(test.udp.local.Seq())) {
+			t.Errorf("got pong record seq %x, want %d", p.Rest, test.udp.local.Seq())
+		}
 	})
 
 	// remote is unknown, the table pings back.
 	hash, _ := test.waitPacketOut(func(p *ping) error {
-		if !reflect.DeepEqual(p.From, test.udp.ourEndpoint) {
-			t.Errorf("got ping.From %v, want %v", p.From, test.udp.ourEndpoint)
+		if !reflect.DeepEqual(p.From, test.udp.ourEndpoint()) {
+			t.Errorf("got ping.From %v, want %v", p.From, test.udp.ourEndpoint())
 		}
 		wantTo := rpcEndpoint{
 			// The mirrored UDP address is the UDP packet sender.
@@ -386,6 +390,102 @@ func TestUDP_successfulPing(t *testing.T) {
 	}
 }
 
+func TestUDP_enrRequest(t *testing.T) {
+	test := newUDPTest(t)
+	defer test.table.Close()
+
+	// Unbonded nodes must not be able to request the record.
+	test.packetIn(errUnknownNode, enrRequestPacket, &enrRequest{Expiration: futureExp})
+
+	remoteID := PubkeyID(&test.remotekey.PublicKey)
+	test.table.db.updateBondTime(remoteID, time.Now())
+	test.packetIn(nil, enrRequestPacket, &enrRequest{Expiration: futureExp})
+
+	test.waitPacketOut(func(p *enrResponse) {
+		reqhash := test.sent[len(test.sent)-1][:macSize]
+		if !bytes.Equal(p.ReplyTok, reqhash) {
+			t.Errorf("got enrResponse.ReplyTok %x, want %x", p.ReplyTok, reqhash)
+		}
+		if seq := test.udp.local.Seq(); p.Record.Seq() != seq {
+			t.Errorf("got record seq %d, want %d", p.Record.Seq(), seq)
+		}
+		var port enr.UDP
+		if err := p.Record.Load(&port); err != nil {
+			t.Errorf("record has no UDP port: %v", err)
+		} else if want := test.pipe.LocalAddr().(*net.UDPAddr).Port; int(port) != want {
+			t.Errorf("got record UDP port %d, want %d", port, want)
+		}
+	})
+}
+
+func TestUDP_setIP(t *testing.T) {
+	test := newUDPTest(t)
+	defer test.table.Close()
+
+	seq := test.udp.local.Seq()
+	extip := net.IP{33, 44, 55, 66}
+	test.table.SetIP(extip)
+
+	if self := test.table.Self(); !self.IP.Equal(extip) {
+		t.Errorf("got Self IP %v, want %v", self.IP, extip)
+	}
+	var ip enr.IP4
+	if err := test.udp.local.Record().Load(&ip); err != nil || !net.IP(ip).Equal(extip) {
+		t.Errorf("got record IP %v (err %v), want %v", net.IP(ip), err, extip)
+	}
+	if newseq := test.udp.local.Seq(); newseq <= seq {
+		t.Errorf("record seq not increased: got %d, had %d", newseq, seq)
+	}
+
+	// Pings announce the new address.
+	go test.udp.ping(NodeID{1, 2, 3, 4}, &net.UDPAddr{IP: net.ParseIP("1.2.3.4"), Port: 2222})
+	test.waitPacketOut(func(p *ping) {
+		if !p.From.IP.Equal(extip) {
+			t.Errorf("got ping.From IP %v, want %v", p.From.IP, extip)
+		}
+	})
+}
+
+func TestUDP_requestENR(t *testing.T) {
+	test := newUDPTest(t)
+	defer test.table.Close()
+
+	remoteID := PubkeyID(&test.remotekey.PublicKey)
+	for _, signer := range []*ecdsa.PrivateKey{test.remotekey, newkey()} {
+		var record enr.Record
+		record.Set(enr.TCP(30303))
+		if err := record.Sign(signer); err != nil {
+			t.Fatalf("can't sign record: %v", err)
+		}
+		type result struct {
+			record *enr.Record
+			err    error
+		}
+		done := make(chan result, 1)
+		go func() {
+			record, err := test.udp.requestENR(remoteID, test.remoteaddr)
+			done <- result{record, err}
+		}()
+		hash, _ := test.waitPacketOut(func(p *enrRequest) {})
+		test.packetIn(nil, enrResponsePacket, &enrResponse{ReplyTok: hash, Record: record})
+
+		res := <-done
+		if signer != test.remotekey {
+			if res.err != errRecordMismatch {
+				t.Errorf("foreign record accepted: err %v", res.err)
+			}
+			continue
+		}
+		if res.err != nil {
+			t.Fatalf("request failed: %v", res.err)
+		}
+		var port enr.TCP
+		if err := res.record.Load(&port); err != nil || port != 30303 {
+			t.Errorf("got record TCP port %d (err %v), want 30303", port, err)
+		}
+	}
+}
+
 var testPackets = []struct {
 	input      string
 	wantPacket interface{}
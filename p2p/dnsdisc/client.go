@@ -0,0 +1,364 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package dnsdisc implements node discovery via DNS (EIP-1459).
+//
+// Node lists are published as a merkle tree of signed node records, stored in
+// DNS TXT records. The root of the tree is signed by the list operator, whose
+// public key is part of the enrtree:// URL of the list.
+package dnsdisc
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/crypto/sha3"
+	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/p2p/discover"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	defaultTimeout         = 5 * time.Second  // Timeout of a single DNS lookup
+	defaultRecheckInterval = 30 * time.Minute // Time between checks for tree updates
+	defaultCacheLimit      = 1000             // Number of tree entries kept in memory
+	syncRetryInterval      = time.Minute      // Time until a failed sync is retried
+)
+
+// Config holds configuration options for the client.
+type Config struct {
+	Timeout         time.Duration // timeout used for DNS lookups (default 5s)
+	RecheckInterval time.Duration // time between tree root update checks (default 30min)
+	CacheLimit      int           // maximum number of cached tree entries (default 1000)
+	Resolver        Resolver      // the DNS resolver to use (defaults to system DNS)
+	Logger          log.Logger    // destination of client log messages (defaults to root logger)
+}
+
+// Resolver is a DNS resolver that can query TXT records.
+type Resolver interface {
+	LookupTXT(ctx context.Context, domain string) ([]string, error)
+}
+
+func (cfg Config) withDefaults() Config {
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.RecheckInterval == 0 {
+		cfg.RecheckInterval = defaultRecheckInterval
+	}
+	if cfg.CacheLimit == 0 {
+		cfg.CacheLimit = defaultCacheLimit
+	}
+	if cfg.Resolver == nil {
+		cfg.Resolver = new(net.Resolver)
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = log.Root()
+	}
+	return cfg
+}
+
+// Client discovers nodes by querying DNS servers. The node lists it was created
+// with are synced in the background and their nodes are provided as dial
+// candidates through ReadRandomNodes.
+type Client struct {
+	cfg     Config
+	entries *lru.Cache   // Tree entries by subdomain hash
+	lists   []*linkEntry // Node lists to sync
+	ctx     context.Context
+	cancel  context.CancelFunc
+	closed  chan struct{}
+
+	lock  sync.Mutex
+	trees map[string]*Tree // Last synced tree of each list, by URL
+	nodes []*discover.Node // Dialable nodes of all synced trees
+}
+
+// NewClient creates a client which syncs the node lists at the given enrtree://
+// URLs, as well as any lists linked from them. Close must be called to stop the
+// background sync.
+func NewClient(cfg Config, urls ...string) (*Client, error) {
+	cfg = cfg.withDefaults()
+	cache, err := lru.New(cfg.CacheLimit)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		cfg:     cfg,
+		entries: cache,
+		closed:  make(chan struct{}),
+		trees:   make(map[string]*Tree),
+	}
+	for _, url := range urls {
+		le, err := parseLink(url)
+		if err != nil {
+			return nil, err
+		}
+		c.lists = append(c.lists, le)
+	}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	go c.loop()
+	return c, nil
+}
+
+// Close stops the background sync and waits for it to terminate.
+func (c *Client) Close() {
+	c.cancel()
+	<-c.closed
+}
+
+// SyncTree downloads the entire node tree at the given URL. This doesn't add the
+// tree for later reuse, but it does use the client's entry cache.
+func (c *Client) SyncTree(url string) (*Tree, error) {
+	le, err := parseLink(url)
+	if err != nil {
+		return nil, err
+	}
+	return c.syncTree(c.ctx, le)
+}
+
+// ReadRandomNodes fills the given slice with random nodes of the synced lists.
+// It returns the number of nodes written.
+func (c *Client) ReadRandomNodes(buf []*discover.Node) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	n := 0
+	for _, i := range rand.Perm(len(c.nodes)) {
+		if n == len(buf) {
+			break
+		}
+		buf[n] = c.nodes[i]
+		n++
+	}
+	return n
+}
+
+// loop syncs all node lists, periodically checking them for updates.
+func (c *Client) loop() {
+	defer close(c.closed)
+	if len(c.lists) == 0 {
+		<-c.ctx.Done()
+		return
+	}
+	for {
+		wait := c.cfg.RecheckInterval
+		if !c.syncAll() && wait > syncRetryInterval {
+			wait = syncRetryInterval
+		}
+		select {
+		case <-time.After(wait):
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+// syncAll syncs the configured lists and all lists linked from them, replacing
+// the set of known nodes. Lists that fail to sync keep their previous contents.
+// The return value reports whether all lists were synced successfully.
+func (c *Client) syncAll() bool {
+	var (
+		ok      = true
+		queue   = append([]*linkEntry(nil), c.lists...)
+		visited = make(map[string]bool)
+		trees   = make(map[string]*Tree)
+	)
+	for len(queue) > 0 {
+		le := queue[0]
+		queue = queue[1:]
+		url := le.String()
+		if visited[url] {
+			continue
+		}
+		visited[url] = true
+
+		t, err := c.updateTree(le)
+		if err != nil {
+			c.cfg.Logger.Debug("Failed to sync DNS node list", "tree", le.domain, "err", err)
+			ok = false
+			if t = c.tree(url); t == nil {
+				continue
+			}
+		}
+		trees[url] = t
+		for _, link := range t.Links() {
+			next, err := parseLink(link)
+			if err != nil {
+				continue
+			}
+			queue = append(queue, next)
+		}
+	}
+	// Collect the dialable nodes of all trees
+	var (
+		nodes []*discover.Node
+		seen  = make(map[discover.NodeID]bool)
+	)
+	for _, t := range trees {
+		for _, r := range t.Records() {
+			n, err := discover.NodeFromRecord(r)
+			if err != nil || n.Incomplete() || n.TCP == 0 || seen[n.ID] {
+				continue
+			}
+			seen[n.ID] = true
+			nodes = append(nodes, n)
+		}
+	}
+	c.lock.Lock()
+	c.trees, c.nodes = trees, nodes
+	c.lock.Unlock()
+
+	c.cfg.Logger.Debug("Synced DNS node lists", "trees", len(trees), "nodes", len(nodes))
+	return ok
+}
+
+// tree returns the last synced tree at the given URL.
+func (c *Client) tree(url string) *Tree {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.trees[url]
+}
+
+// updateTree checks the root of a list for changes, syncing the list again only
+// if it was updated since the last sync.
+func (c *Client) updateTree(le *linkEntry) (*Tree, error) {
+	root, err := c.resolveRoot(c.ctx, le)
+	if err != nil {
+		return nil, err
+	}
+	if t := c.tree(le.String()); t != nil && t.root.eroot == root.eroot && t.root.lroot == root.lroot {
+		return t, nil
+	}
+	return c.syncTreeFrom(c.ctx, le, root)
+}
+
+// syncTree downloads the tree of a list.
+func (c *Client) syncTree(ctx context.Context, le *linkEntry) (*Tree, error) {
+	root, err := c.resolveRoot(ctx, le)
+	if err != nil {
+		return nil, err
+	}
+	return c.syncTreeFrom(ctx, le, root)
+}
+
+// syncTreeFrom downloads all entries below the given root of a list.
+func (c *Client) syncTreeFrom(ctx context.Context, le *linkEntry, root *rootEntry) (*Tree, error) {
+	t := &Tree{root: root, entries: make(map[string]entry)}
+	if err := c.syncSubtree(ctx, le.domain, root.eroot, false, t.entries); err != nil {
+		return nil, err
+	}
+	if err := c.syncSubtree(ctx, le.domain, root.lroot, true, t.entries); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// syncSubtree downloads the subtree at the given hash into entries. Depending on
+// the subtree type, the leaves must be either links or node records.
+func (c *Client) syncSubtree(ctx context.Context, domain, hash string, links bool, entries map[string]entry) error {
+	e, err := c.resolveEntry(ctx, domain, hash)
+	if err != nil {
+		return err
+	}
+	entries[hash] = e
+
+	switch e := e.(type) {
+	case *branchEntry:
+		for _, child := range e.children {
+			if err := c.syncSubtree(ctx, domain, child, links, entries); err != nil {
+				return err
+			}
+		}
+	case *enrEntry:
+		if links {
+			return nameError{hash + "." + domain, errENRInLinkTree}
+		}
+	case *linkEntry:
+		if !links {
+			return nameError{hash + "." + domain, errLinkInENRTree}
+		}
+	}
+	return nil
+}
+
+// resolveRoot retrieves the root entry of a list and verifies its signature.
+func (c *Client) resolveRoot(ctx context.Context, le *linkEntry) (*rootEntry, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	txts, err := c.cfg.Resolver.LookupTXT(ctx, le.domain)
+	if err != nil {
+		return nil, nameError{le.domain, err}
+	}
+	for _, txt := range txts {
+		if strings.HasPrefix(txt, rootPrefix) {
+			root, err := parseRoot(txt)
+			if err != nil {
+				return nil, nameError{le.domain, err}
+			}
+			if !root.verifySignature(le.pubkey) {
+				return nil, nameError{le.domain, entryError{"root", errInvalidSig}}
+			}
+			return root, nil
+		}
+	}
+	return nil, nameError{le.domain, errNoRoot}
+}
+
+// resolveEntry retrieves the tree entry at the given hash, using the cache if
+// possible. The content of the entry is checked against the hash.
+func (c *Client) resolveEntry(ctx context.Context, domain, hash string) (entry, error) {
+	if e, ok := c.entries.Get(hash); ok {
+		return e.(entry), nil
+	}
+	wantHash, err := b32format.DecodeString(hash)
+	if err != nil {
+		return nil, nameError{hash + "." + domain, errInvalidChild}
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.cfg.Timeout)
+	defer cancel()
+
+	name := hash + "." + domain
+	txts, err := c.cfg.Resolver.LookupTXT(ctx, name)
+	if err != nil {
+		return nil, nameError{name, err}
+	}
+	for _, txt := range txts {
+		// The TXT record may contain unrelated entries, skip those.
+		e, err := parseEntry(txt)
+		if err == errUnknownEntry {
+			continue
+		}
+		h := sha3.NewKeccak256()
+		h.Write([]byte(txt))
+		if !bytes.HasPrefix(h.Sum(nil), wantHash) {
+			err = errHashMismatch
+		}
+		if err != nil {
+			return nil, nameError{name, err}
+		}
+		c.entries.Add(hash, e)
+		return e, nil
+	}
+	return nil, nameError{name, errNoEntry}
+}
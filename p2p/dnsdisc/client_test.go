@@ -0,0 +1,186 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package dnsdisc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/p2p/discover"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
+)
+
+// Tests that a signed tree can be downloaded in full.
+func TestClientSyncTree(t *testing.T) {
+	records := testRecords(testKeys(20))
+	links := []string{newLinkString(testKey(1), "other.example.org")}
+	signer := testKey(signingKeySeed)
+	tree, url := makeTestTree(t, "n", signer, records, links)
+
+	c := newTestClient(t, newMapResolver(tree.ToTXT("n")))
+	defer c.Close()
+
+	synced, err := c.SyncTree(url)
+	if err != nil {
+		t.Fatalf("sync error: %v", err)
+	}
+	if !sameRecords(synced.Records(), records) {
+		t.Errorf("wrong records in synced tree")
+	}
+	if !reflect.DeepEqual(synced.Links(), links) {
+		t.Errorf("wrong links %v, want %v", synced.Links(), links)
+	}
+	if synced.Seq() != tree.Seq() || synced.Signature() != tree.Signature() {
+		t.Errorf("wrong root: seq %d, sig %s", synced.Seq(), synced.Signature())
+	}
+}
+
+// Tests that trees signed by another key than the one in the URL are rejected.
+func TestClientSyncTreeBadSignature(t *testing.T) {
+	tree, _ := makeTestTree(t, "n", testKey(signingKeySeed), testRecords(testKeys(3)), nil)
+
+	c := newTestClient(t, newMapResolver(tree.ToTXT("n")))
+	defer c.Close()
+
+	_, err := c.SyncTree(newLinkString(testKey(1), "n"))
+	want := nameError{"n", entryError{"root", errInvalidSig}}
+	if err != want {
+		t.Errorf("wrong error %q, want %q", err, want)
+	}
+}
+
+// Tests that tree entries not matching their hash are rejected.
+func TestClientSyncTreeBadEntry(t *testing.T) {
+	records := testRecords(testKeys(3))
+	tree, url := makeTestTree(t, "n", testKey(signingKeySeed), records, nil)
+
+	// Replace one of the node records with a record of another node
+	other, _ := discover.RecordString(testRecords(testKeys(4))[3])
+	txt := tree.ToTXT("n")
+	for name, value := range txt {
+		if value[:len(enrPrefix)] == enrPrefix {
+			txt[name] = other
+			break
+		}
+	}
+	c := newTestClient(t, newMapResolver(txt))
+	defer c.Close()
+
+	if _, err := c.SyncTree(url); err == nil {
+		t.Fatal("tampered tree accepted")
+	} else if nerr, ok := err.(nameError); !ok || nerr.err != errHashMismatch {
+		t.Errorf("wrong error %q, want hash mismatch", err)
+	}
+}
+
+// Tests that the nodes of configured and linked trees are provided as dial
+// candidates once synced.
+func TestClientReadRandomNodes(t *testing.T) {
+	var (
+		keys      = testKeys(10)
+		records   = testRecords(keys)
+		linkedKey = testKey(2)
+		signer    = testKey(signingKeySeed)
+	)
+	linked, linkURL := makeTestTree(t, "linked.example.org", linkedKey, records[5:], nil)
+	tree, url := makeTestTree(t, "n", signer, records[:5], []string{linkURL})
+
+	txt := tree.ToTXT("n")
+	for name, value := range linked.ToTXT("linked.example.org") {
+		txt[name] = value
+	}
+	resolver := newMapResolver(txt)
+	c, err := NewClient(Config{Resolver: resolver}, url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	buf := make([]*discover.Node, 20)
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		n := c.ReadRandomNodes(buf)
+		if n == len(keys) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for nodes, have %d, want %d", n, len(keys))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	seen := make(map[discover.NodeID]bool)
+	for _, n := range buf[:len(keys)] {
+		seen[n.ID] = true
+		if n.TCP != 30303 || n.IP == nil {
+			t.Errorf("node %v has incomplete endpoint", n)
+		}
+	}
+	for _, key := range keys {
+		if !seen[discover.PubkeyID(&key.PublicKey)] {
+			t.Errorf("node %x missing", discover.PubkeyID(&key.PublicKey).Bytes()[:8])
+		}
+	}
+	// Reading into a smaller buffer must not overflow it
+	if n := c.ReadRandomNodes(buf[:3]); n != 3 {
+		t.Errorf("wrong node count %d, want 3", n)
+	}
+}
+
+func makeTestTree(t *testing.T, domain string, key *ecdsa.PrivateKey, records []*enr.Record, links []string) (*Tree, string) {
+	tree, err := MakeTree(1, records, links)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url, err := tree.Sign(key, domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree, url
+}
+
+func newTestClient(t *testing.T, r Resolver) *Client {
+	c, err := NewClient(Config{Resolver: r})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// mapResolver is a DNS resolver stub serving TXT records from a map.
+type mapResolver struct {
+	mu      sync.Mutex
+	records map[string]string
+}
+
+func newMapResolver(records map[string]string) *mapResolver {
+	return &mapResolver{records: records}
+}
+
+func (mr *mapResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	if record, ok := mr.records[name]; ok {
+		return []string{record}, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: name}
+}
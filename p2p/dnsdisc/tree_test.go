@@ -0,0 +1,261 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package dnsdisc
+
+import (
+	"crypto/ecdsa"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/crypto"
+	"github.com/AdelineCoin/go-adln/p2p/discover"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
+)
+
+func TestParseRoot(t *testing.T) {
+	tests := []struct {
+		input string
+		e     *rootEntry
+		err   error
+	}{
+		{
+			input: "enrtree-root:v1 e=TO4Q75OQ2N7DX4EOOR7X66A6OM seq=3 sig=N-YY6UB9xD0hFx1Gmnt7v0RfSxch5tKyry2SRDoLx7B4GfPXagwLxQqyf7gAMvApFn_ORwZQekMWa_pXrcGCtw",
+			err:   entryError{"root", errSyntax},
+		},
+		{
+			input: "enrtree-root:v1 e=TO4Q75OQ2N7DX4EOOR7X66A6OM l=TO4Q75OQ2N7DX4EOOR7X66A6OM seq=3 sig=N-YY6UB9xD0hFx1Gmnt7v0RfSxch5tKyry2SRDoLx7B4GfPXagwLxQqyf7gAMvApFn_ORwZQekMWa_pXrcGCtw",
+			err:   entryError{"root", errInvalidSig},
+		},
+	}
+	for i, test := range tests {
+		e, err := parseRoot(test.input)
+		if !reflect.DeepEqual(e, test.e) {
+			t.Errorf("test %d: wrong entry %+v, want %+v", i, e, test.e)
+		}
+		if err != test.err {
+			t.Errorf("test %d: wrong error %q, want %q", i, err, test.err)
+		}
+	}
+	// Signed roots must survive a round trip
+	root := &rootEntry{eroot: "QFT4PBCRX4XQCV3VUYJ6BTCEPU", lroot: "JGUFMSAGI7KZYB3P7IZW4S5Y3A", seq: 3}
+	key := testKey(signingKeySeed)
+	sig, err := crypto.Sign(root.sigHash(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.sig = sig
+	parsed, err := parseRoot(root.String())
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", root.String(), err)
+	}
+	if !reflect.DeepEqual(parsed, root) {
+		t.Errorf("wrong entry %+v, want %+v", parsed, root)
+	}
+	if !parsed.verifySignature(&key.PublicKey) {
+		t.Errorf("signature of parsed root invalid")
+	}
+}
+
+func TestParseEntry(t *testing.T) {
+	testkey := testKey(signingKeySeed)
+	tests := []struct {
+		input string
+		e     entry
+		err   error
+	}{
+		// Subtrees:
+		{
+			input: "enrtree-branch:1,2",
+			err:   entryError{"branch", errInvalidChild},
+		},
+		{
+			input: "enrtree-branch:AAAAAAAAAAAAAAAA",
+			err:   entryError{"branch", errInvalidChild},
+		},
+		{
+			input: "enrtree-branch:",
+			e:     &branchEntry{},
+		},
+		{
+			input: "enrtree-branch:AAAAAAAAAAAAAAAAAAAAAAAAAA",
+			e:     &branchEntry{[]string{"AAAAAAAAAAAAAAAAAAAAAAAAAA"}},
+		},
+		{
+			input: "enrtree-branch:AAAAAAAAAAAAAAAAAAAAAAAAAA,BBBBBBBBBBBBBBBBBBBBBBBBBB",
+			e:     &branchEntry{[]string{"AAAAAAAAAAAAAAAAAAAAAAAAAA", "BBBBBBBBBBBBBBBBBBBBBBBBBB"}},
+		},
+		// Links
+		{
+			input: "enrtree://" + b32format.EncodeToString(crypto.CompressPubkey(&testkey.PublicKey)) + "@nodes.example.org",
+			e:     &linkEntry{"nodes.example.org", &testkey.PublicKey},
+		},
+		{
+			input: "enrtree://nodes.example.org",
+			err:   entryError{"link", errNoPubkey},
+		},
+		{
+			input: "enrtree://AP62DT7WOTEQZGQZOU474PP3KMEGVTTE7A7NPRXKX3DUD57@nodes.example.org",
+			err:   entryError{"link", errBadPubkey},
+		},
+		{
+			input: "enrtree://AP62DT7WONEQZGQZOU474PP3KMEGVTTE7A7NPRXKX3DUD57TQHGIA@nodes.example.org",
+			err:   entryError{"link", errBadPubkey},
+		},
+		// ENRs
+		{
+			input: "enr:-invalid-record",
+			err:   entryError{"enr", errInvalidENR},
+		},
+		// Invalid:
+		{input: "", err: errUnknownEntry},
+		{input: "foo", err: errUnknownEntry},
+		{input: "enrtree", err: errUnknownEntry},
+		{input: "enrtree-x=", err: errUnknownEntry},
+	}
+	for i, test := range tests {
+		e, err := parseEntry(test.input)
+		if !reflect.DeepEqual(e, test.e) {
+			t.Errorf("test %d: wrong entry %+v, want %+v", i, e, test.e)
+		}
+		if err != test.err {
+			t.Errorf("test %d: wrong error %q, want %q", i, err, test.err)
+		}
+	}
+}
+
+// Tests that a tree survives a round trip through its TXT records, including
+// trees large enough to require nested branches.
+func TestMakeTree(t *testing.T) {
+	keys := testKeys(50)
+	records := testRecords(keys)
+	links := []string{
+		newLinkString(testKey(1), "a.example.org"),
+		newLinkString(testKey(2), "b.example.org"),
+	}
+	tree, err := MakeTree(9, records, links)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := testKey(signingKeySeed)
+	url, err := tree.Sign(signer, "n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := newLinkString(signer, "n"); url != want {
+		t.Errorf("wrong URL %q, want %q", url, want)
+	}
+	txt := tree.ToTXT("n")
+	if len(txt) < len(records)+len(links)+1 {
+		t.Fatalf("too few TXT records: %d", len(txt))
+	}
+	for name, value := range txt {
+		if name == "n" {
+			root, err := parseRoot(value)
+			if err != nil {
+				t.Fatalf("invalid root: %v", err)
+			}
+			if root.seq != 9 || !root.verifySignature(&signer.PublicKey) {
+				t.Errorf("invalid root %s", value)
+			}
+			continue
+		}
+		if len(value) > maxTXTLength && strings.HasPrefix(value, branchPrefix) {
+			t.Errorf("branch at %s too long: %d bytes", name, len(value))
+		}
+		e, err := parseEntry(value)
+		if err != nil {
+			t.Fatalf("invalid entry at %s: %v", name, err)
+		}
+		if hash := subdomain(e) + ".n"; hash != name {
+			t.Errorf("entry at %s has hash %s", name, hash)
+		}
+	}
+	if !reflect.DeepEqual(tree.Links(), links) {
+		t.Errorf("wrong links %v, want %v", tree.Links(), links)
+	}
+	if have := tree.Records(); !sameRecords(have, records) {
+		t.Errorf("wrong records")
+	}
+	// The signature must only be accepted for the signing key
+	if err := tree.SetSignature(&testKey(1).PublicKey, tree.Signature()); err != errInvalidSig {
+		t.Errorf("signature accepted for wrong key: %v", err)
+	}
+	if err := tree.SetSignature(&signer.PublicKey, tree.Signature()); err != nil {
+		t.Errorf("signature rejected: %v", err)
+	}
+}
+
+const signingKeySeed = 0x111111
+
+func testKey(seed int64) *ecdsa.PrivateKey {
+	k := make([]byte, 32)
+	for i := 0; i < 8; i++ {
+		k[31-i] = byte(seed >> uint(8*i))
+	}
+	key, err := crypto.ToECDSA(k)
+	if err != nil {
+		panic(err)
+	}
+	return key
+}
+
+func testKeys(n int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, n)
+	for i := range keys {
+		keys[i] = testKey(int64(1000 + i))
+	}
+	return keys
+}
+
+func testRecords(keys []*ecdsa.PrivateKey) []*enr.Record {
+	records := make([]*enr.Record, len(keys))
+	for i, key := range keys {
+		r := new(enr.Record)
+		r.Set(enr.IP4(net.IP{10, 0, byte(i >> 8), byte(i)}))
+		r.Set(enr.TCP(30303))
+		r.Set(enr.UDP(30303))
+		if err := r.Sign(key); err != nil {
+			panic(err)
+		}
+		records[i] = r
+	}
+	return records
+}
+
+func newLinkString(key *ecdsa.PrivateKey, domain string) string {
+	return (&linkEntry{domain, &key.PublicKey}).String()
+}
+
+func sameRecords(a, b []*enr.Record) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	ids := make(map[string]bool)
+	for _, r := range a {
+		text, _ := discover.RecordString(r)
+		ids[text] = true
+	}
+	for _, r := range b {
+		text, _ := discover.RecordString(r)
+		if !ids[text] {
+			return false
+		}
+	}
+	return true
+}
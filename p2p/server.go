@@ -31,6 +31,8 @@ import (
 	"github.com/AdelineCoin/go-adln/log"
 	"github.com/AdelineCoin/go-adln/p2p/discover"
 	"github.com/AdelineCoin/go-adln/p2p/discv5"
+	"github.com/AdelineCoin/go-adln/p2p/dnsdisc"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
 	"github.com/AdelineCoin/go-adln/p2p/nat"
 	"github.com/AdelineCoin/go-adln/p2p/netutil"
 )
@@ -49,6 +51,9 @@ const (
 
 	// Maximum amount of time allowed for writing a complete message.
 	frameWriteTimeout = 20 * time.Second
+
+	// Interval at which the external IP address is re-queried from the NAT device.
+	natRefreshInterval = 5 * time.Minute
 )
 
 var errServerStopped = errors.New("server stopped")
@@ -93,6 +98,11 @@ type Config struct {
 	// protocol.
 	BootstrapNodesV5 []*discv5.Node `toml:",omitempty"`
 
+	// DNSDiscovery contains enrtree:// URLs of DNS node lists (EIP-1459).
+	// Nodes in these lists are used as dial candidates in addition to
+	// the nodes found by the discovery protocol.
+	DNSDiscovery []string `toml:",omitempty"`
+
 	// Static nodes are used as pre-configured connections which are always
 	// maintained and re-connected on disconnects.
 	StaticNodes []*discover.Node
@@ -157,6 +167,8 @@ type Server struct {
 	running bool
 
 	ntab         discoverTable
+	dnsdisc      *dnsdisc.Client
+	localnode    *discover.LocalNode
 	listener     net.Listener
 	ourHandshake *protoHandshake
 	lastLookup   time.Time
@@ -173,6 +185,7 @@ type Server struct {
 	addpeer       chan *conn
 	delpeer       chan peerDrop
 	loopWG        sync.WaitGroup // loop, listenLoop
+	taskWG        sync.WaitGroup // dial tasks, which may outlive loop
 	peerFeed      event.Feed
 	log           log.Logger
 }
@@ -324,11 +337,16 @@ func (srv *Server) makeSelf(listener net.Listener, ntab discoverTable) *discover
 		if listener == nil {
 			return &discover.Node{IP: net.ParseIP("0.0.0.0"), ID: discover.PubkeyID(&srv.PrivateKey.PublicKey)}
 		}
-		// Otherwise inject the listener address too
+		// Otherwise inject the listener address too, preferring the external
+		// IP announced in the node record.
 		addr := listener.Addr().(*net.TCPAddr)
+		ip := addr.IP
+		if recIP := srv.localnode.IP(); recIP != nil {
+			ip = recIP
+		}
 		return &discover.Node{
 			ID:  discover.PubkeyID(&srv.PrivateKey.PublicKey),
-			IP:  addr.IP,
+			IP:  ip,
 			TCP: uint16(addr.Port),
 		}
 	}
@@ -336,6 +354,18 @@ func (srv *Server) makeSelf(listener net.Listener, ntab discoverTable) *discover
 	return ntab.Self()
 }
 
+// LocalNode returns the maintainer of the local node record, or nil if the server
+// is not running.
+func (srv *Server) LocalNode() *discover.LocalNode {
+	srv.lock.Lock()
+	defer srv.lock.Unlock()
+
+	if !srv.running {
+		return nil
+	}
+	return srv.localnode
+}
+
 // Stop terminates the server and all active peer connections.
 // It blocks until all active connections have been closed.
 func (srv *Server) Stop() {
@@ -353,6 +383,47 @@ func (srv *Server) Stop() {
 	srv.loopWG.Wait()
 }
 
+// SetPrivateKey replaces the node key, giving the local node a new identity.
+// A running server is restarted under the new identity: all peers are
+// disconnected and the node record, keeping the entries set by protocols, is
+// re-signed by the new key. If the restart fails, the previous key is restored
+// and the server restarted with it, the error being returned either way.
+func (srv *Server) SetPrivateKey(key *ecdsa.PrivateKey) error {
+	if key == nil {
+		return errors.New("node key must be non-nil")
+	}
+	srv.lock.Lock()
+	running := srv.running
+	srv.lock.Unlock()
+
+	if running {
+		srv.Stop()
+	}
+	srv.taskWG.Wait()
+
+	srv.lock.Lock()
+	prev := srv.PrivateKey
+	srv.PrivateKey = key
+	srv.lock.Unlock()
+
+	if !running {
+		return nil
+	}
+	err := srv.Start()
+	if err == nil {
+		return nil
+	}
+	// Restarting failed, fall back to the previous identity
+	srv.lock.Lock()
+	srv.PrivateKey = prev
+	srv.lock.Unlock()
+
+	if rerr := srv.Start(); rerr != nil {
+		srv.log.Error("Failed to restart P2P networking", "err", rerr)
+	}
+	return err
+}
+
 // sharedUDPConn implements a shared connection. Write sends messages to the underlying connection while read returns
 // messages that were found unprocessable and sent to the unhandled channel by the primary listener.
 type sharedUDPConn struct {
@@ -379,15 +450,24 @@ func (s *sharedUDPConn) Close() error {
 	return nil
 }
 
-// Start starts running the server.
-// Servers can not be re-used after stopping.
+// Start starts running the server. A stopped server may be started again, it
+// then keeps its node record. If starting fails, everything acquired so far is
+// released and the server is left stopped.
 func (srv *Server) Start() (err error) {
 	srv.lock.Lock()
 	defer srv.lock.Unlock()
 	if srv.running {
 		return errors.New("server already running")
 	}
+	// Dial tasks of a previous run terminate soon after stopping, wait for them
+	// before resetting the fields they use.
+	srv.taskWG.Wait()
 	srv.running = true
+	defer func() {
+		if err != nil {
+			srv.abortStart()
+		}
+	}()
 	srv.log = srv.Config.Logger
 	if srv.log == nil {
 		srv.log = log.New()
@@ -413,6 +493,19 @@ func (srv *Server) Start() (err error) {
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
 
+	// node record, kept across restarts so that protocols can keep updating
+	// their entries
+	if srv.localnode == nil {
+		srv.localnode = discover.NewLocalNode(srv.PrivateKey)
+		for _, p := range srv.Protocols {
+			for _, attr := range p.Attributes {
+				srv.localnode.Set(attr)
+			}
+		}
+	} else if srv.localnode.ID() != discover.PubkeyID(&srv.PrivateKey.PublicKey) {
+		srv.localnode.SetPrivateKey(srv.PrivateKey)
+	}
+
 	var (
 		conn      *net.UDPConn
 		sconn     *sharedUDPConn
@@ -434,7 +527,7 @@ func (srv *Server) Start() (err error) {
 			if !realaddr.IP.IsLoopback() {
 				go nat.Map(srv.NAT, srv.quit, "udp", realaddr.Port, realaddr.Port, "ethereum discovery")
 			}
-			// TODO: react to external IP changes over time.
+			// Changes of the external IP are tracked after startup.
 			if ext, err := srv.NAT.ExternalIP(); err == nil {
 				realaddr = &net.UDPAddr{IP: ext, Port: realaddr.Port}
 			}
@@ -446,10 +539,12 @@ func (srv *Server) Start() (err error) {
 		sconn = &sharedUDPConn{conn, unhandled}
 	}
 
-	// node table
+	// node table. Discovery of a previous run was shut down on stop, forget it.
+	srv.ntab, srv.DiscV5, srv.dnsdisc = nil, nil, nil
 	if !srv.NoDiscovery {
 		cfg := discover.Config{
 			PrivateKey:   srv.PrivateKey,
+			LocalNode:    srv.localnode,
 			AnnounceAddr: realaddr,
 			NodeDBPath:   srv.NodeDatabase,
 			NetRestrict:  srv.NetRestrict,
@@ -458,6 +553,7 @@ func (srv *Server) Start() (err error) {
 		}
 		ntab, err := discover.ListenUDP(conn, cfg)
 		if err != nil {
+			conn.Close()
 			return err
 		}
 		srv.ntab = ntab
@@ -474,16 +570,31 @@ func (srv *Server) Start() (err error) {
 			ntab, err = discv5.ListenUDP(srv.PrivateKey, conn, realaddr, "", srv.NetRestrict) //srv.NodeDatabase)
 		}
 		if err != nil {
+			if srv.ntab == nil {
+				conn.Close()
+			}
 			return err
 		}
+		srv.DiscV5 = ntab
 		if err := ntab.SetFallbackNodes(srv.BootstrapNodesV5); err != nil {
 			return err
 		}
-		srv.DiscV5 = ntab
+	}
+
+	// DNS node lists
+	if len(srv.DNSDiscovery) > 0 {
+		client, err := dnsdisc.NewClient(dnsdisc.Config{Logger: srv.log}, srv.DNSDiscovery...)
+		if err != nil {
+			return err
+		}
+		srv.dnsdisc = client
 	}
 
 	dynPeers := srv.maxDialedConns()
 	dialer := newDialState(srv.StaticNodes, srv.BootstrapNodes, srv.ntab, dynPeers, srv.NetRestrict)
+	if srv.dnsdisc != nil {
+		dialer.sources = append(dialer.sources, srv.dnsdisc)
+	}
 
 	// handshake
 	srv.ourHandshake = &protoHandshake{Version: baseProtocolVersion, Name: srv.Name, ID: discover.PubkeyID(&srv.PrivateKey.PublicKey)}
@@ -499,6 +610,10 @@ func (srv *Server) Start() (err error) {
 	if srv.NoDial && srv.ListenAddr == "" {
 		srv.log.Warn("P2P server will be useless, neither dialing nor listening")
 	}
+	if srv.NAT != nil {
+		srv.loopWG.Add(1)
+		go srv.trackExternalIP()
+	}
 
 	srv.loopWG.Add(1)
 	go srv.run(dialer)
@@ -506,6 +621,35 @@ func (srv *Server) Start() (err error) {
 	return nil
 }
 
+// abortStart releases the resources acquired by a failed Start, which may have
+// stopped anywhere before launching the main loop.
+func (srv *Server) abortStart() {
+	srv.running = false
+	if srv.quit != nil {
+		select {
+		case <-srv.quit:
+		default:
+			close(srv.quit)
+		}
+	}
+	if srv.listener != nil {
+		srv.listener.Close()
+	}
+	if srv.ntab != nil {
+		srv.ntab.Close()
+		srv.ntab = nil
+	}
+	if srv.DiscV5 != nil {
+		srv.DiscV5.Close()
+		srv.DiscV5 = nil
+	}
+	if srv.dnsdisc != nil {
+		srv.dnsdisc.Close()
+		srv.dnsdisc = nil
+	}
+	srv.loopWG.Wait()
+}
+
 func (srv *Server) startListening() error {
 	// Launch the TCP listener.
 	listener, err := net.Listen("tcp", srv.ListenAddr)
@@ -517,6 +661,15 @@ func (srv *Server) startListening() error {
 	srv.listener = listener
 	srv.loopWG.Add(1)
 	go srv.listenLoop()
+
+	// Announce the listener in the node record. Without discovery, nothing else
+	// announces the IP address.
+	srv.localnode.Set(enr.TCP(laddr.Port))
+	if srv.ntab == nil {
+		srv.localnode.SetIP(laddr.IP)
+	}
+	srv.log.Info("RLPx listener up", "self", srv.makeSelf(listener, srv.ntab))
+
 	// Map the TCP listening port if NAT is configured.
 	if !laddr.IP.IsLoopback() && srv.NAT != nil {
 		srv.loopWG.Add(1)
@@ -528,6 +681,47 @@ func (srv *Server) startListening() error {
 	return nil
 }
 
+// trackExternalIP keeps the IP address announced by the local node in sync with
+// the external address of the NAT device, which may change over time.
+func (srv *Server) trackExternalIP() {
+	defer srv.loopWG.Done()
+
+	var (
+		last  net.IP
+		timer = time.NewTimer(0)
+	)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if ip, err := srv.NAT.ExternalIP(); err != nil {
+				srv.log.Debug("Couldn't query external IP", "nat", srv.NAT, "err", err)
+			} else if !ip.Equal(last) {
+				if last != nil {
+					srv.log.Info("External IP changed", "old", last, "new", ip)
+				}
+				srv.setExternalIP(ip)
+				last = ip
+			}
+			timer.Reset(natRefreshInterval)
+
+		case <-srv.quit:
+			return
+		}
+	}
+}
+
+// setExternalIP announces a new external IP address. With discovery enabled, the
+// table updates the endpoint in its pings and Self along with the node record.
+func (srv *Server) setExternalIP(ip net.IP) {
+	if tab, ok := srv.ntab.(*discover.Table); ok {
+		tab.SetIP(ip)
+	} else {
+		srv.localnode.SetIP(ip)
+	}
+}
+
 type dialer interface {
 	newTasks(running int, peers map[discover.NodeID]*Peer, now time.Time) []task
 	taskDone(task, time.Time)
@@ -567,7 +761,8 @@ func (srv *Server) run(dialstate dialer) {
 		for ; len(runningTasks) < maxActiveDialTasks && i < len(ts); i++ {
 			t := ts[i]
 			srv.log.Trace("New dial task", "task", t)
-			go func() { t.Do(srv); taskdone <- t }()
+			srv.taskWG.Add(1)
+			go func() { t.Do(srv); taskdone <- t; srv.taskWG.Done() }()
 			runningTasks = append(runningTasks, t)
 		}
 		return ts[i:]
@@ -677,6 +872,9 @@ running:
 	if srv.DiscV5 != nil {
 		srv.DiscV5.Close()
 	}
+	if srv.dnsdisc != nil {
+		srv.dnsdisc.Close()
+	}
 	// Disconnect all peers.
 	for _, p := range peers {
 		p.Disconnect(DiscQuitting)
@@ -739,7 +937,6 @@ type tempError interface {
 // inbound connections.
 func (srv *Server) listenLoop() {
 	defer srv.loopWG.Done()
-	srv.log.Info("RLPx listener up", "self", srv.makeSelf(srv.listener, srv.ntab))
 
 	tokens := defaultMaxPendingPeers
 	if srv.MaxPendingPeers > 0 {
@@ -809,14 +1006,14 @@ func (srv *Server) SetupConn(fd net.Conn, flags connFlag, dialDest *discover.Nod
 func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *discover.Node) error {
 	// Prevent leftover pending conns from entering the handshake.
 	srv.lock.Lock()
-	running := srv.running
+	running, key := srv.running, srv.PrivateKey
 	srv.lock.Unlock()
 	if !running {
 		return errServerStopped
 	}
 	// Run the encryption handshake.
 	var err error
-	if c.id, err = c.doEncHandshake(srv.PrivateKey, dialDest); err != nil {
+	if c.id, err = c.doEncHandshake(key, dialDest); err != nil {
 		srv.log.Trace("Failed RLPx handshake", "addr", c.fd.RemoteAddr(), "conn", c.flags, "err", err)
 		return err
 	}
@@ -910,6 +1107,7 @@ type NodeInfo struct {
 	ID    string `json:"id"`    // Unique node identifier (also the encryption key)
 	Name  string `json:"name"`  // Name of the node, including client type, version, OS, custom data
 	Enode string `json:"enode"` // Enode URL for adding this peer from remote peers
+	ENR   string `json:"enr"`   // Signed node record (EIP-778) in its text form
 	IP    string `json:"ip"`    // IP address of the node
 	Ports struct {
 		Discovery int `json:"discovery"` // UDP listening port for discovery protocol
@@ -923,18 +1121,31 @@ type NodeInfo struct {
 func (srv *Server) NodeInfo() *NodeInfo {
 	node := srv.Self()
 
+	// The listener address is updated whenever the server (re)starts
+	srv.lock.Lock()
+	listenAddr := srv.ListenAddr
+	srv.lock.Unlock()
+
 	// Gather and assemble the generic node infos
 	info := &NodeInfo{
 		Name:       srv.Name,
 		Enode:      node.String(),
 		ID:         node.ID.String(),
 		IP:         node.IP.String(),
-		ListenAddr: srv.ListenAddr,
+		ListenAddr: listenAddr,
 		Protocols:  make(map[string]interface{}),
 	}
 	info.Ports.Discovery = int(node.UDP)
 	info.Ports.Listener = int(node.TCP)
 
+	if local := srv.LocalNode(); local != nil {
+		if record := local.Record(); record != nil {
+			if text, err := discover.RecordString(record); err == nil {
+				info.ENR = text
+			}
+		}
+	}
+
 	// Gather all the running protocol infos (only once per protocol type)
 	for _, proto := range srv.Protocols {
 		if _, ok := info.Protocols[proto.Name]; !ok {
@@ -29,6 +29,7 @@ import (
 	"github.com/AdelineCoin/go-adln/crypto/sha3"
 	"github.com/AdelineCoin/go-adln/log"
 	"github.com/AdelineCoin/go-adln/p2p/discover"
+	"github.com/AdelineCoin/go-adln/p2p/enr"
 )
 
 func init() {
@@ -366,6 +367,186 @@ func TestServerAtCap(t *testing.T) {
 
 }
 
+// Tests that the server publishes a node record announcing its listener, the
+// external IP of the NAT device and the attributes of its protocols.
+func TestServerNodeRecord(t *testing.T) {
+	srv := &Server{
+		Config: Config{
+			PrivateKey:  newkey(),
+			MaxPeers:    10,
+			NoDial:      true,
+			NoDiscovery: true,
+			ListenAddr:  "127.0.0.1:0",
+			NAT:         &testNAT{ip: net.IP{1, 2, 3, 4}},
+			Protocols: []Protocol{{
+				Name:       "test",
+				Attributes: []enr.Entry{enr.WithEntry("test", uint(7))},
+			}},
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	defer srv.Stop()
+
+	// The external IP is queried asynchronously, wait for it to be announced
+	var record *enr.Record
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		var err error
+		if record, err = discover.ParseRecord(srv.NodeInfo().ENR); err != nil {
+			t.Fatalf("invalid record: %v", err)
+		}
+		var ip enr.IP4
+		if err := record.Load(&ip); err == nil && net.IP(ip).Equal(net.IP{1, 2, 3, 4}) {
+			break
+		}
+		if time.Since(start) > time.Second {
+			t.Fatalf("external IP not announced: %v", net.IP(ip))
+		}
+	}
+	var (
+		tcp  enr.TCP
+		attr uint
+	)
+	if err := record.Load(&tcp); err != nil || int(tcp) != srv.listener.Addr().(*net.TCPAddr).Port {
+		t.Errorf("TCP port mismatch: have %d, err %v", tcp, err)
+	}
+	if err := record.Load(enr.WithEntry("test", &attr)); err != nil || attr != 7 {
+		t.Errorf("protocol attribute mismatch: have %d, err %v", attr, err)
+	}
+	if self := srv.Self(); !self.IP.Equal(net.IP{1, 2, 3, 4}) {
+		t.Errorf("external IP not in Self: have %v", self.IP)
+	}
+}
+
+func TestServerSetPrivateKey(t *testing.T) {
+	srv := &Server{
+		Config: Config{
+			PrivateKey: newkey(),
+			MaxPeers:   10,
+			NoDial:     true,
+			ListenAddr: "127.0.0.1:0",
+			Protocols: []Protocol{{
+				Name:       "test",
+				Attributes: []enr.Entry{enr.WithEntry("test", uint(7))},
+			}},
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	defer srv.Stop()
+
+	srv.LocalNode().Set(enr.WithEntry("test", uint(8)))
+	key := newkey()
+	if err := srv.SetPrivateKey(key); err != nil {
+		t.Fatalf("could not rotate node key: %v", err)
+	}
+
+	id := discover.PubkeyID(&key.PublicKey)
+	if self := srv.Self(); self.ID != id {
+		t.Errorf("Self ID mismatch: have %v, want %v", self.ID, id)
+	}
+	record, err := discover.ParseRecord(srv.NodeInfo().ENR)
+	if err != nil {
+		t.Fatalf("invalid record: %v", err)
+	}
+	var (
+		pubkey enr.Secp256k1
+		attr   uint
+	)
+	if err := record.Load(&pubkey); err != nil || discover.PubkeyID((*ecdsa.PublicKey)(&pubkey)) != id {
+		t.Errorf("record not signed by the new key (err %v)", err)
+	}
+	if err := record.Load(enr.WithEntry("test", &attr)); err != nil || attr != 8 {
+		t.Errorf("protocol attribute not kept: have %d, err %v", attr, err)
+	}
+}
+
+// Tests that the node identity can be queried while the node key is replaced.
+func TestServerSetPrivateKeyConcurrentReads(t *testing.T) {
+	srv := &Server{
+		Config: Config{
+			PrivateKey: newkey(),
+			MaxPeers:   10,
+			NoDial:     true,
+			ListenAddr: "127.0.0.1:0",
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	defer srv.Stop()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			if err := srv.SetPrivateKey(newkey()); err != nil {
+				t.Errorf("could not rotate node key: %v", err)
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+			srv.NodeInfo()
+		}
+	}
+}
+
+// Tests that a failed restart under a new key falls back to the previous key and
+// releases everything it acquired, so that the server can be started again.
+func TestServerSetPrivateKeyFailure(t *testing.T) {
+	key := newkey()
+	srv := &Server{
+		Config: Config{
+			PrivateKey: key,
+			MaxPeers:   10,
+			NoDial:     true,
+			ListenAddr: "127.0.0.1:0",
+		},
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	defer srv.Stop()
+
+	// Break the configuration so that no restart succeeds
+	srv.DNSDiscovery = []string{"invalid"}
+	if err := srv.SetPrivateKey(newkey()); err == nil {
+		t.Fatalf("rotation succeeded with broken configuration")
+	}
+	if srv.PrivateKey != key {
+		t.Errorf("previous key not restored")
+	}
+	if srv.LocalNode() != nil {
+		t.Errorf("server running after failed restart")
+	}
+	// Fix the configuration and ensure the server restarts on the same ports
+	srv.DNSDiscovery = nil
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not restart server: %v", err)
+	}
+	if self := srv.Self(); self.ID != discover.PubkeyID(&key.PublicKey) {
+		t.Errorf("Self ID mismatch: have %v, want %v", self.ID, discover.PubkeyID(&key.PublicKey))
+	}
+}
+
+// testNAT is a NAT device reporting a fixed external IP.
+type testNAT struct {
+	ip net.IP
+}
+
+func (n *testNAT) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) error {
+	return nil
+}
+func (n *testNAT) DeleteMapping(protocol string, extport, intport int) error { return nil }
+func (n *testNAT) ExternalIP() (net.IP, error)                               { return n.ip, nil }
+func (n *testNAT) String() string                                            { return "test" }
+
 func TestServerSetupConn(t *testing.T) {
 	id := randomID()
 	srvkey := newkey()
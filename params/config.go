@@ -85,16 +85,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -118,12 +118,38 @@ type ChainConfig struct {
 	EIP155Block *big.Int `json:"eip155Block,omitempty"` // EIP155 HF block
 	EIP158Block *big.Int `json:"eip158Block,omitempty"` // EIP158 HF block
 
-	ByzantiumBlock      *big.Int `json:"byzantiumBlock,omitempty"`      // Byzantium switch block (nil = no fork, 0 = already on byzantium)
+	ByzantiumBlock *big.Int `json:"byzantiumBlock,omitempty"` // Byzantium switch block (nil = no fork, 0 = already on byzantium)
+
+	// Constantinople implements the SHL, SHR and SAR bitwise shifts
+	// (https://eips.ethereum.org/EIPS/eip-145)
 	ConstantinopleBlock *big.Int `json:"constantinopleBlock,omitempty"` // Constantinople switch block (nil = no fork, 0 = already activated)
 
+	// EIP1014 implements CREATE2 (https://eips.ethereum.org/EIPS/eip-1014)
+	// with EXTCODEHASH (https://eips.ethereum.org/EIPS/eip-1052)
+	EIP1014Block *big.Int `json:"eip1014Block,omitempty"` // EIP1014 HF block (nil = no fork)
+
+	// Istanbul implements CHAINID (https://eips.ethereum.org/EIPS/eip-1344),
+	// SELFBALANCE with the trie size dependent repricing (https://eips.ethereum.org/EIPS/eip-1884),
+	// net gas metering for SSTORE (https://eips.ethereum.org/EIPS/eip-2200)
+	// and the BLAKE2b F precompile (https://eips.ethereum.org/EIPS/eip-152)
+	IstanbulBlock *big.Int `json:"istanbulBlock,omitempty"` // Istanbul switch block (nil = no fork, 0 = already on istanbul)
+
+	// AdlnPrecompile activates the chain specific precompiled contracts
+	// (ed25519 signature verification and SHA3-512 hashing)
+	AdlnPrecompileBlock *big.Int `json:"adlnPrecompileBlock,omitempty"` // ADLN precompile switch block (nil = no fork)
+
+	// EIP2718 implements the typed transaction envelope (https://eips.ethereum.org/EIPS/eip-2718)
+	// with EIP2930 access list transactions (https://eips.ethereum.org/EIPS/eip-2930)
+	EIP2718Block *big.Int `json:"eip2718Block,omitempty"` // EIP2718 HF block (nil = no fork)
+
+	// EIP1559 implements the base fee market (https://eips.ethereum.org/EIPS/eip-1559)
+	EIP1559Block *big.Int        `json:"eip1559Block,omitempty"` // EIP1559 HF block (nil = no fork)
+	FeeCollector *common.Address `json:"feeCollector,omitempty"` // Treasury receiving the base fees (nil = burn)
+
 	// Various consensus engines
-	Ethash *EthashConfig `json:"ethash,omitempty"`
-	Clique *CliqueConfig `json:"clique,omitempty"`
+	Ethash    *EthashConfig    `json:"ethash,omitempty"`
+	Clique    *CliqueConfig    `json:"clique,omitempty"`
+	Authority *AuthorityConfig `json:"authority,omitempty"`
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -145,6 +171,19 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// AuthorityConfig is the consensus engine configs for proof-of-authority based
+// sealing with the validator set maintained by a system contract.
+type AuthorityConfig struct {
+	Period   uint64         `json:"period"`   // Number of seconds between blocks to enforce
+	Epoch    uint64         `json:"epoch"`    // Epoch length after which to refresh the validator set
+	Contract common.Address `json:"contract"` // System contract maintaining the validator set
+}
+
+// String implements the stringer interface, returning the consensus engine details.
+func (c *AuthorityConfig) String() string {
+	return "authority"
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 
@@ -179,10 +218,40 @@ func (c *ChainConfig) IsByzantium(num *big.Int) bool {
 	return isForked(c.ByzantiumBlock, num)
 }
 
+// IsConstantinople returns whether num is either equal to the Constantinople
+// fork block or greater, activating the EIP-145 bitwise shifts.
 func (c *ChainConfig) IsConstantinople(num *big.Int) bool {
 	return isForked(c.ConstantinopleBlock, num)
 }
 
+// IsEIP1014 returns whether num is either equal to the EIP1014 fork block or
+// greater, activating the CREATE2 and EXTCODEHASH opcodes.
+func (c *ChainConfig) IsEIP1014(num *big.Int) bool {
+	return isForked(c.EIP1014Block, num)
+}
+
+// IsIstanbul returns whether num is either equal to the Istanbul fork block or
+// greater, activating EIP-152, EIP-1344, EIP-1884 and EIP-2200 together.
+func (c *ChainConfig) IsIstanbul(num *big.Int) bool {
+	return isForked(c.IstanbulBlock, num)
+}
+
+// IsAdlnPrecompile returns whether num is either equal to the ADLN precompile
+// fork block or greater.
+func (c *ChainConfig) IsAdlnPrecompile(num *big.Int) bool {
+	return isForked(c.AdlnPrecompileBlock, num)
+}
+
+// IsEIP2718 returns whether num is either equal to the EIP2718 fork block or greater.
+func (c *ChainConfig) IsEIP2718(num *big.Int) bool {
+	return isForked(c.EIP2718Block, num)
+}
+
+// IsEIP1559 returns whether num is either equal to the EIP1559 fork block or greater.
+func (c *ChainConfig) IsEIP1559(num *big.Int) bool {
+	return isForked(c.EIP1559Block, num)
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -191,6 +260,10 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 		return GasTableHomestead
 	}
 	switch {
+	case c.IsIstanbul(num):
+		return GasTableIstanbul
+	case c.IsEIP1014(num):
+		return GasTableEIP1014
 	case c.IsEIP158(num):
 		return GasTableEIP158
 	case c.IsEIP150(num):
@@ -200,6 +273,42 @@ func (c *ChainConfig) GasTable(num *big.Int) GasTable {
 	}
 }
 
+// CheckConfigForkOrder checks that the forks are enabled in the order they were
+// introduced in. The switches are not independent: each instruction set and gas
+// schedule builds on the previous one, so skipping or reordering forks would
+// silently activate rules the configuration claims to be disabled. The DAO and
+// ADLN precompile forks are self contained and may be scheduled at any block.
+func (c *ChainConfig) CheckConfigForkOrder() error {
+	type fork struct {
+		name  string
+		block *big.Int
+	}
+	var last fork
+	for _, cur := range []fork{
+		{"homesteadBlock", c.HomesteadBlock},
+		{"eip150Block", c.EIP150Block},
+		{"eip155Block", c.EIP155Block},
+		{"eip158Block", c.EIP158Block},
+		{"byzantiumBlock", c.ByzantiumBlock},
+		{"constantinopleBlock", c.ConstantinopleBlock},
+		{"eip1014Block", c.EIP1014Block},
+		{"istanbulBlock", c.IstanbulBlock},
+		{"eip2718Block", c.EIP2718Block},
+		{"eip1559Block", c.EIP1559Block},
+	} {
+		if last.name != "" {
+			switch {
+			case last.block == nil && cur.block != nil:
+				return fmt.Errorf("unsupported fork ordering: %v not enabled, but %v enabled at %v", last.name, cur.name, cur.block)
+			case last.block != nil && cur.block != nil && last.block.Cmp(cur.block) > 0:
+				return fmt.Errorf("unsupported fork ordering: %v enabled at %v, but %v enabled at %v", last.name, last.block, cur.name, cur.block)
+			}
+		}
+		last = cur
+	}
+	return nil
+}
+
 // CheckCompatible checks whether scheduled fork transitions have been imported
 // with a mismatching chain configuration.
 func (c *ChainConfig) CheckCompatible(newcfg *ChainConfig, height uint64) *ConfigCompatError {
@@ -246,6 +355,21 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.ConstantinopleBlock, newcfg.ConstantinopleBlock, head) {
 		return newCompatError("Constantinople fork block", c.ConstantinopleBlock, newcfg.ConstantinopleBlock)
 	}
+	if isForkIncompatible(c.EIP1014Block, newcfg.EIP1014Block, head) {
+		return newCompatError("EIP1014 fork block", c.EIP1014Block, newcfg.EIP1014Block)
+	}
+	if isForkIncompatible(c.IstanbulBlock, newcfg.IstanbulBlock, head) {
+		return newCompatError("Istanbul fork block", c.IstanbulBlock, newcfg.IstanbulBlock)
+	}
+	if isForkIncompatible(c.AdlnPrecompileBlock, newcfg.AdlnPrecompileBlock, head) {
+		return newCompatError("ADLN precompile fork block", c.AdlnPrecompileBlock, newcfg.AdlnPrecompileBlock)
+	}
+	if isForkIncompatible(c.EIP2718Block, newcfg.EIP2718Block, head) {
+		return newCompatError("EIP2718 fork block", c.EIP2718Block, newcfg.EIP2718Block)
+	}
+	if isForkIncompatible(c.EIP1559Block, newcfg.EIP1559Block, head) {
+		return newCompatError("EIP1559 fork block", c.EIP1559Block, newcfg.EIP1559Block)
+	}
 	return nil
 }
 
@@ -312,7 +436,9 @@ func (err *ConfigCompatError) Error() string {
 type Rules struct {
 	ChainId                                   *big.Int
 	IsHomestead, IsEIP150, IsEIP155, IsEIP158 bool
-	IsByzantium                               bool
+	IsByzantium, IsConstantinople             bool
+	IsEIP1014, IsIstanbul                     bool
+	IsEIP2718                                 bool
 }
 
 func (c *ChainConfig) Rules(num *big.Int) Rules {
@@ -320,5 +446,5 @@ func (c *ChainConfig) Rules(num *big.Int) Rules {
 	if chainId == nil {
 		chainId = new(big.Int)
 	}
-	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num)}
+	return Rules{ChainId: new(big.Int).Set(chainId), IsHomestead: c.IsHomestead(num), IsEIP150: c.IsEIP150(num), IsEIP155: c.IsEIP155(num), IsEIP158: c.IsEIP158(num), IsByzantium: c.IsByzantium(num), IsConstantinople: c.IsConstantinople(num), IsEIP1014: c.IsEIP1014(num), IsIstanbul: c.IsIstanbul(num), IsEIP2718: c.IsEIP2718(num)}
 }
@@ -79,3 +79,36 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestCheckConfigForkOrder(t *testing.T) {
+	tests := []struct {
+		config  *ChainConfig
+		wantErr string
+	}{
+		{config: AllEthashProtocolChanges},
+		{config: MainnetChainConfig},
+		{config: &ChainConfig{}},
+		{config: &ChainConfig{HomesteadBlock: big.NewInt(0), DAOForkBlock: big.NewInt(5), AdlnPrecompileBlock: big.NewInt(1)}},
+		{
+			config:  &ChainConfig{HomesteadBlock: big.NewInt(0), EIP150Block: big.NewInt(0), EIP155Block: big.NewInt(0), EIP158Block: big.NewInt(0), ByzantiumBlock: big.NewInt(0), EIP1014Block: big.NewInt(10)},
+			wantErr: "unsupported fork ordering: constantinopleBlock not enabled, but eip1014Block enabled at 10",
+		},
+		{
+			config:  &ChainConfig{HomesteadBlock: big.NewInt(0), EIP150Block: big.NewInt(0), EIP155Block: big.NewInt(10), EIP158Block: big.NewInt(5)},
+			wantErr: "unsupported fork ordering: eip155Block enabled at 10, but eip158Block enabled at 5",
+		},
+		{
+			config:  &ChainConfig{EIP1559Block: big.NewInt(0)},
+			wantErr: "unsupported fork ordering: eip2718Block not enabled, but eip1559Block enabled at 0",
+		},
+	}
+	for i, test := range tests {
+		err := test.config.CheckConfigForkOrder()
+		switch {
+		case test.wantErr == "" && err != nil:
+			t.Errorf("test %d: unexpected error: %v", i, err)
+		case test.wantErr != "" && (err == nil || err.Error() != test.wantErr):
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, test.wantErr)
+		}
+	}
+}
@@ -57,10 +57,21 @@ const (
 	TierStepGas      uint64 = 0     // Once per operation, for a selection of them.
 	LogTopicGas      uint64 = 375   // Multiplied by the * of the LOG*, per LOG transaction. e.g. LOG0 incurs 0 * c_txLogTopicGas, LOG4 incurs 4 * c_txLogTopicGas.
 	CreateGas        uint64 = 32000 // Once per CREATE operation & contract-creation transaction.
+	Create2Gas       uint64 = 32000 // Once per CREATE2 operation
 	SuicideRefundGas uint64 = 24000 // Refunded following a suicide operation.
 	MemoryGas        uint64 = 3     // Times the address of the (highest referenced byte in memory + 1). NOTE: referencing happens on read, write and in instructions such as RETURN and CALL.
 	TxDataNonZeroGas uint64 = 68    // Per byte of data attached to a transaction that is not equal to zero. NOTE: Not payable on data of calls between transactions.
 
+	SstoreSentryGasEIP2200            uint64 = 2300  // Minimum gas required to be present for an SSTORE call, not consumed
+	SloadGasEIP2200                   uint64 = 800   // Cost of SLOAD after EIP 2200 (part of Istanbul)
+	SstoreSetGasEIP2200               uint64 = 20000 // Once per SSTORE operation from clean zero to non-zero
+	SstoreResetGasEIP2200             uint64 = 5000  // Once per SSTORE operation from clean non-zero to something else
+	SstoreClearsScheduleRefundEIP2200 uint64 = 15000 // Once per SSTORE operation for clearing an originally existing storage slot
+
+	ColdAccountAccessCostEIP2929 uint64 = 2600 // Cost of the first access of an account in a transaction (EIP 2929)
+	ColdSloadCostEIP2929         uint64 = 2100 // Cost of the first access of a storage slot in a transaction (EIP 2929)
+	WarmStorageReadCostEIP2929   uint64 = 100  // Cost of accessing an account or storage slot already accessed (EIP 2929)
+
 	MaxCodeSize = 24576 // Maximum bytecode to permit for a contract
 
 	// Precompiled contract gas prices
@@ -77,6 +88,18 @@ const (
 	Bn256ScalarMulGas       uint64 = 40000  // Gas needed for an elliptic curve scalar multiplication
 	Bn256PairingBaseGas     uint64 = 100000 // Base price for an elliptic curve pairing check
 	Bn256PairingPerPointGas uint64 = 80000  // Per-point price for an elliptic curve pairing check
+	Blake2FRoundGas         uint64 = 1      // Per-round price for a BLAKE2b compression
+	Ed25519VerifyBaseGas    uint64 = 2000   // Base price for an ed25519 signature verification
+	Ed25519VerifyPerWordGas uint64 = 12     // Per-word price of the message of an ed25519 signature verification
+	Sha3512BaseGas          uint64 = 60     // Base price for a SHA3-512 operation
+	Sha3512PerWordGas       uint64 = 12     // Per-word price for a SHA3-512 operation
+
+	BaseFeeChangeDenominator uint64 = 8          // Bounds the amount the base fee can change between blocks.
+	ElasticityMultiplier     uint64 = 2          // Ratio of the gas limit to the gas target of EIP1559 blocks.
+	InitialBaseFee           uint64 = 1000000000 // Base fee of the EIP1559 fork block.
+
+	TxAccessListAddressGas    uint64 = 2400 // Per address specified in an EIP2930 access list
+	TxAccessListStorageKeyGas uint64 = 1900 // Per storage key specified in an EIP2930 access list
 )
 
 var (
@@ -64,12 +64,17 @@ type Decoder interface {
 // error if there are too few or too many elements.
 //
 // The decoding of struct fields honours certain struct tags, "tail",
-// "nil" and "-".
+// "optional", "nil" and "-".
 //
 // The "-" tag ignores fields.
 //
 // For an explanation of "tail", see the example.
 //
+// The "optional" tag allows the trailing elements of the input list to be
+// missing, leaving the corresponding fields zero. All fields following an
+// optional one must be optional too. When encoding, trailing optional fields
+// are omitted if they are zero.
+//
 // The "nil" tag applies to pointer-typed fields and changes the decoding
 // rules for the field such that input values of size zero decode as a nil
 // pointer. This tag can be useful when decoding recursive types.
@@ -422,10 +427,18 @@ func makeStructDecoder(typ reflect.Type) (decoder, error) {
 		if _, err := s.List(); err != nil {
 			return wrapStreamError(err, typ)
 		}
-		for _, f := range fields {
+		for i, f := range fields {
 			err := f.info.decoder(s, val.Field(f.index))
 			if err == EOL {
-				return &decodeError{msg: "too few elements", typ: typ}
+				if !f.optional {
+					return &decodeError{msg: "too few elements", typ: typ}
+				}
+				// The remaining optional fields are missing, zero them
+				for _, f := range fields[i:] {
+					fv := val.Field(f.index)
+					fv.Set(reflect.Zero(fv.Type()))
+				}
+				break
 			} else if err != nil {
 				return addErrorContext(err, "."+typ.Field(f.index).Name)
 			}
@@ -0,0 +1,198 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// jwtSecretLength is the required length of the HS256 shared secret.
+	jwtSecretLength = 32
+
+	// jwtExpiryTimeout is the maximum allowed distance between the issuance
+	// time of a token and the local clock.
+	jwtExpiryTimeout = 60 * time.Second
+)
+
+var (
+	errMissingToken  = errors.New("missing token")
+	errStaleToken    = errors.New("stale token")
+	errFutureToken   = errors.New("future token")
+	errMissingIssued = errors.New("missing issued-at")
+)
+
+// JWTConfig is the authentication configuration of an HTTP or WebSocket RPC
+// endpoint. Requests must carry an HS256 signed bearer token with a recent
+// issued-at (iat) claim.
+type JWTConfig struct {
+	// Secret is the shared key used to sign and verify the tokens.
+	Secret []byte
+
+	// Modules optionally restricts the API modules a token may call, keyed by
+	// the subject (sub) claim of the token. Tokens whose subject is missing from
+	// the map may call every module exposed by the endpoint.
+	Modules map[string][]string
+}
+
+// LoadJWTSecret reads a hex encoded 32 byte HS256 secret from the given file.
+func LoadJWTSecret(path string) ([]byte, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(blob)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT secret in %s: %v", path, err)
+	}
+	if len(secret) != jwtSecretLength {
+		return nil, fmt.Errorf("invalid JWT secret length in %s: have %d, want %d", path, len(secret), jwtSecretLength)
+	}
+	return secret, nil
+}
+
+// modulesKey is the context key under which the API modules allowed for an
+// authenticated request are stored.
+type modulesKey struct{}
+
+// subjectKey is the context key under which the subject of the token of an
+// authenticated request is stored.
+type subjectKey struct{}
+
+// jwtHandler is an http.Handler which rejects requests not carrying a valid
+// bearer token before passing them on to the wrapped handler.
+type jwtHandler struct {
+	secret  []byte
+	modules map[string]map[string]struct{}
+	next    http.Handler
+}
+
+// newJWTHandler wraps next with a JWT authentication layer. If no config is
+// specified, authentication is disabled and next is returned as is.
+func newJWTHandler(config *JWTConfig, next http.Handler) http.Handler {
+	if config == nil {
+		return next
+	}
+	modules := make(map[string]map[string]struct{})
+	for subject, allowed := range config.Modules {
+		modules[subject] = make(map[string]struct{})
+		for _, module := range allowed {
+			modules[subject][module] = struct{}{}
+		}
+	}
+	return &jwtHandler{secret: config.Secret, modules: modules, next: next}
+}
+
+// ServeHTTP authenticates the request and serves it if the token is valid.
+func (h *jwtHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	claims, err := h.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	ctx := context.WithValue(r.Context(), subjectKey{}, claims.Subject)
+	if allowed, ok := h.modules[claims.Subject]; ok {
+		ctx = context.WithValue(ctx, modulesKey{}, allowed)
+	}
+	h.next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// authenticate extracts the bearer token from the request headers, checks its
+// signature and ensures it was issued close enough to the local time.
+func (h *jwtHandler) authenticate(r *http.Request) (*jwt.StandardClaims, error) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		return nil, errMissingToken
+	}
+	var (
+		claims jwt.StandardClaims
+		parser = jwt.Parser{ValidMethods: []string{jwt.SigningMethodHS256.Alg()}, SkipClaimsValidation: true}
+	)
+	_, err := parser.ParseWithClaims(strings.TrimPrefix(auth, "Bearer "), &claims, func(*jwt.Token) (interface{}, error) {
+		return h.secret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// The stock claim validation has no notion of clock skew, check the
+	// issuance time against the allowed window manually.
+	if claims.IssuedAt == 0 {
+		return nil, errMissingIssued
+	}
+	issued := time.Unix(claims.IssuedAt, 0)
+	switch {
+	case time.Since(issued) > jwtExpiryTimeout:
+		return nil, errStaleToken
+	case time.Until(issued) > jwtExpiryTimeout:
+		return nil, errFutureToken
+	}
+	if claims.ExpiresAt != 0 && !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, errStaleToken
+	}
+	return &claims, nil
+}
+
+// moduleFilterCodec is a ServerCodec rejecting calls to API modules outside of
+// the allowed set of an authenticated token.
+type moduleFilterCodec struct {
+	ServerCodec
+	allowed map[string]struct{}
+}
+
+// newModuleFilterCodec wraps codec with a module allowlist if the context of
+// the originating HTTP request carries one.
+func newModuleFilterCodec(ctx context.Context, codec ServerCodec) ServerCodec {
+	allowed, ok := ctx.Value(modulesKey{}).(map[string]struct{})
+	if !ok {
+		return codec
+	}
+	return &moduleFilterCodec{ServerCodec: codec, allowed: allowed}
+}
+
+// setResponseLimit forwards the response size limit to the wrapped codec.
+func (c *moduleFilterCodec) setResponseLimit(limit int) {
+	if lc, ok := c.ServerCodec.(limitedCodec); ok {
+		lc.setResponseLimit(limit)
+	}
+}
+
+// ReadRequestHeaders reads the next batch of requests from the wrapped codec,
+// marking calls to disallowed modules as not found.
+func (c *moduleFilterCodec) ReadRequestHeaders() ([]rpcRequest, bool, Error) {
+	reqs, batch, err := c.ServerCodec.ReadRequestHeaders()
+	if err != nil {
+		return reqs, batch, err
+	}
+	for i, r := range reqs {
+		if r.err != nil {
+			continue
+		}
+		if _, ok := c.allowed[r.service]; !ok {
+			reqs[i].err = &methodNotFoundError{r.service, r.method}
+		}
+	}
+	return reqs, batch, nil
+}
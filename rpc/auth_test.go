@@ -0,0 +1,160 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var testJWTSecret = bytes.Repeat([]byte{0x42}, jwtSecretLength)
+
+// signTestToken creates a token with the given issuance time and subject.
+func signTestToken(t *testing.T, method jwt.SigningMethod, secret []byte, issued time.Time, subject string) string {
+	claims := jwt.StandardClaims{IssuedAt: issued.Unix(), Subject: subject}
+	token, err := jwt.NewWithClaims(method, claims).SignedString(secret)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return token
+}
+
+// callAuthenticated issues an HTTP RPC call against handler with the given
+// bearer token, returning the status code and the decoded response.
+func callAuthenticated(t *testing.T, handler http.Handler, token, method string) (int, map[string]interface{}) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":[]}`
+	req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(body))
+	req.Header.Set("content-type", contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		return rec.Code, nil
+	}
+	var resp map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code, resp
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	handler := NewHTTPServer(nil, []string{"*"}, &JWTConfig{Secret: testJWTSecret}, server).Handler
+
+	now := time.Now()
+	tests := []struct {
+		name  string
+		token string
+		code  int
+	}{
+		{"missing", "", http.StatusUnauthorized},
+		{"garbage", "not.a.token", http.StatusUnauthorized},
+		{"valid", signTestToken(t, jwt.SigningMethodHS256, testJWTSecret, now, ""), http.StatusOK},
+		{"slight skew", signTestToken(t, jwt.SigningMethodHS256, testJWTSecret, now.Add(30*time.Second), ""), http.StatusOK},
+		{"stale", signTestToken(t, jwt.SigningMethodHS256, testJWTSecret, now.Add(-2*time.Minute), ""), http.StatusUnauthorized},
+		{"future", signTestToken(t, jwt.SigningMethodHS256, testJWTSecret, now.Add(2*time.Minute), ""), http.StatusUnauthorized},
+		{"no iat", signTestToken(t, jwt.SigningMethodHS256, testJWTSecret, time.Unix(0, 0), ""), http.StatusUnauthorized},
+		{"wrong secret", signTestToken(t, jwt.SigningMethodHS256, []byte("other secret"), now, ""), http.StatusUnauthorized},
+		{"wrong method", signTestToken(t, jwt.SigningMethodHS512, testJWTSecret, now, ""), http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		code, resp := callAuthenticated(t, handler, tt.token, "service_rets")
+		if code != tt.code {
+			t.Errorf("%s: status code mismatch: have %d, want %d", tt.name, code, tt.code)
+			continue
+		}
+		if code == http.StatusOK && resp["error"] != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, resp["error"])
+		}
+	}
+}
+
+func TestJWTModuleAllowlist(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	config := &JWTConfig{
+		Secret:  testJWTSecret,
+		Modules: map[string][]string{"restricted": {"rpc"}},
+	}
+	handler := NewHTTPServer(nil, []string{"*"}, config, server).Handler
+
+	// Tokens without an allowlist may call every exposed module
+	token := signTestToken(t, jwt.SigningMethodHS256, testJWTSecret, time.Now(), "admin")
+	if _, resp := callAuthenticated(t, handler, token, "service_rets"); resp["error"] != nil {
+		t.Fatalf("unrestricted call failed: %v", resp["error"])
+	}
+	// Restricted tokens may only call the allowed modules
+	token = signTestToken(t, jwt.SigningMethodHS256, testJWTSecret, time.Now(), "restricted")
+	if _, resp := callAuthenticated(t, handler, token, "rpc_modules"); resp["error"] != nil {
+		t.Fatalf("allowed call failed: %v", resp["error"])
+	}
+	_, resp := callAuthenticated(t, handler, token, "service_rets")
+	if resp["error"] == nil {
+		t.Fatalf("disallowed call succeeded: %v", resp["result"])
+	}
+	if code := resp["error"].(map[string]interface{})["code"].(float64); code != -32601 {
+		t.Fatalf("error code mismatch: have %v, want %d", code, -32601)
+	}
+}
+
+func TestLoadJWTSecret(t *testing.T) {
+	file, err := ioutil.TempFile("", "jwtsecret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+
+	tests := []struct {
+		content string
+		fail    bool
+	}{
+		{"0x" + strings.Repeat("42", jwtSecretLength), false},
+		{strings.Repeat("42", jwtSecretLength) + "\n", false},
+		{strings.Repeat("42", jwtSecretLength-1), true},
+		{strings.Repeat("zz", jwtSecretLength), true},
+	}
+	for i, tt := range tests {
+		if err := ioutil.WriteFile(file.Name(), []byte(tt.content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		secret, err := LoadJWTSecret(file.Name())
+		if tt.fail {
+			if err == nil {
+				t.Errorf("test %d: expected failure, got secret %x", i, secret)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test %d: failed to load secret: %v", i, err)
+		} else if !bytes.Equal(secret, testJWTSecret) {
+			t.Errorf("test %d: secret mismatch: have %x, want %x", i, secret, testJWTSecret)
+		}
+	}
+}
@@ -146,12 +146,13 @@ func (t *httpReadWriteNopCloser) Close() error {
 	return nil
 }
 
-// NewHTTPServer creates a new HTTP RPC server around an API provider.
+// NewHTTPServer creates a new HTTP RPC server around an API provider. If a JWT
+// configuration is given, requests must carry a valid bearer token.
 //
 // Deprecated: Server implements http.Handler
-func NewHTTPServer(cors []string, vhosts []string, srv *Server) *http.Server {
-	// Wrap the CORS-handler within a host-handler
-	handler := newCorsHandler(srv, cors)
+func NewHTTPServer(cors []string, vhosts []string, jwt *JWTConfig, srv http.Handler) *http.Server {
+	// Wrap the authenticated handler within a CORS-handler within a host-handler
+	handler := newCorsHandler(newJWTHandler(jwt, srv), cors)
 	handler = newVHostHandler(vhosts, handler)
 	return &http.Server{Handler: handler}
 }
@@ -169,11 +170,11 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// All checks passed, create a codec that reads direct from the request body
 	// untilEOF and writes the response to w and order the server to process a
 	// single request.
-	codec := NewJSONCodec(&httpReadWriteNopCloser{r.Body, w})
+	codec := newModuleFilterCodec(r.Context(), NewJSONCodec(&httpReadWriteNopCloser{r.Body, w}))
 	defer codec.Close()
 
 	w.Header().Set("content-type", contentType)
-	srv.ServeSingleRequest(codec, OptionMethodInvocation)
+	srv.serveRequest(withPeerInfo(r), codec, true, OptionMethodInvocation)
 }
 
 // validateRequest returns a non-zero response code and error message if the
@@ -194,7 +195,7 @@ func validateRequest(r *http.Request) (int, error) {
 	return 0, nil
 }
 
-func newCorsHandler(srv *Server, allowedOrigins []string) http.Handler {
+func newCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
 	// disable CORS support if user has not specified a custom CORS configuration
 	if len(allowedOrigins) == 0 {
 		return srv
@@ -17,6 +17,7 @@
 package rpc
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/json"
 	"fmt"
@@ -83,6 +84,9 @@ type jsonCodec struct {
 	encMu  sync.Mutex                // guards the encoder
 	encode func(v interface{}) error // encoder to allow multiple transports
 	rw     io.ReadWriteCloser        // connection
+
+	buf   *bufio.Writer // buffer holding back the start of streamed responses
+	limit int           // maximum size of a streamed response, zero if unlimited
 }
 
 func (err *jsonError) Error() string {
@@ -108,17 +112,51 @@ func NewCodec(rwc io.ReadWriteCloser, encode, decode func(v interface{}) error)
 }
 
 // NewJSONCodec creates a new RPC server codec with support for JSON-RPC 2.0.
+// Responses are streamed to the connection as they are encoded.
 func NewJSONCodec(rwc io.ReadWriteCloser) ServerCodec {
-	enc := json.NewEncoder(rwc)
 	dec := json.NewDecoder(rwc)
 	dec.UseNumber()
 
-	return &jsonCodec{
+	codec := &jsonCodec{
 		closed: make(chan interface{}),
-		encode: enc.Encode,
 		decode: dec.Decode,
 		rw:     rwc,
+		buf:    bufio.NewWriter(rwc),
+	}
+	codec.encode = codec.stream
+	return codec
+}
+
+// stream encodes a message into the connection through the response size limit.
+// Only the first buffer's worth of a message is held back, so a message found to
+// exceed the limit early can still be replaced by an error response, in which
+// case errResponseTooLarge is returned. Otherwise errResponseTruncated is.
+//
+// Note, this method assumes the encoder lock is held!
+func (c *jsonCodec) stream(v interface{}) error {
+	lw := &limitedWriter{w: c.buf, limit: c.limit}
+	if err := writeJSON(lw, v); err != nil {
+		sent := lw.written > c.buf.Buffered()
+		c.buf.Reset(c.rw) // discard the unsent remainder of the message
+
+		if err == errResponseTooLarge && sent {
+			return errResponseTruncated
+		}
+		return err
 	}
+	if err := c.buf.WriteByte('\n'); err != nil {
+		return err
+	}
+	return c.buf.Flush()
+}
+
+// setResponseLimit implements limitedCodec, setting the maximum size of the
+// responses streamed by the codec. Zero disables the limit.
+func (c *jsonCodec) setResponseLimit(limit int) {
+	c.encMu.Lock()
+	defer c.encMu.Unlock()
+
+	c.limit = limit
 }
 
 // isBatch returns true when the first non-whitespace characters is '['
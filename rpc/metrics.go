@@ -0,0 +1,118 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Contains the meters and timers used by the RPC server.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/metrics"
+)
+
+// maxSlowLogParams is the maximum length of the encoded parameters included in
+// a slow call log entry.
+const maxSlowLogParams = 512
+
+// secretServices are the API namespaces whose methods take passwords or keys.
+// Slow calls to them are logged with the sizes of their parameters only.
+var secretServices = map[string]bool{
+	"personal": true,
+	"shh":      true,
+}
+
+var (
+	rpcRequestCounter       = metrics.NewRegisteredCounter("rpc/requests", nil)
+	rpcSuccessCounter       = metrics.NewRegisteredCounter("rpc/success", nil)
+	rpcFailureCounter       = metrics.NewRegisteredCounter("rpc/failure", nil)
+	rpcDurationTimer        = metrics.NewRegisteredTimer("rpc/duration/all", nil)
+	rpcSubscriptionsCounter = metrics.NewRegisteredCounter("rpc/subscriptions", nil)
+	rpcSlowCallCounter      = metrics.NewRegisteredCounter("rpc/slow", nil)
+	rpcRejectedCallsCounter = metrics.NewRegisteredCounter("rpc/rejected", nil)
+)
+
+// SetSlowCallThreshold configures the server to log the parameters and timing
+// of calls taking longer than threshold. A zero threshold disables logging. It
+// must be called before the server starts serving requests.
+func (s *Server) SetSlowCallThreshold(threshold time.Duration) {
+	s.slowThreshold = threshold
+}
+
+// trackCall updates the per-method success, failure and timing metrics of an
+// executed call, logging it if it exceeded the slow call threshold.
+func (s *Server) trackCall(ctx context.Context, req *serverRequest, elapsed time.Duration, failed bool) {
+	if metrics.Enabled {
+		if failed {
+			rpcFailureCounter.Inc(1)
+			metrics.GetOrRegisterCounter("rpc/failure/"+req.method, nil).Inc(1)
+		} else {
+			rpcSuccessCounter.Inc(1)
+			metrics.GetOrRegisterCounter("rpc/success/"+req.method, nil).Inc(1)
+		}
+		rpcDurationTimer.Update(elapsed)
+		metrics.GetOrRegisterTimer("rpc/duration/"+req.method, nil).Update(elapsed)
+	}
+	if s.slowThreshold > 0 && elapsed > s.slowThreshold {
+		rpcSlowCallCounter.Inc(1)
+
+		params := formatParams(req.args)
+		if secretServices[req.svcname] {
+			params = formatParamSizes(req.args)
+		}
+		peer, _ := ctx.Value(peerInfoKey{}).(peerInfo)
+		log.Warn("Slow RPC call", "method", req.method, "elapsed", elapsed, "failed", failed,
+			"remote", peer.addr, "params", params)
+	}
+}
+
+// formatParams encodes the arguments of a call for logging, truncating overly
+// long ones.
+func formatParams(args []reflect.Value) string {
+	params := make([]interface{}, len(args))
+	for i, arg := range args {
+		params[i] = arg.Interface()
+	}
+	blob, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Sprintf("%v", params)
+	}
+	if len(blob) > maxSlowLogParams {
+		return fmt.Sprintf("%s... (%d bytes)", blob[:maxSlowLogParams], len(blob))
+	}
+	return string(blob)
+}
+
+// formatParamSizes describes the arguments of a call by their encoded sizes,
+// without revealing their values.
+func formatParamSizes(args []reflect.Value) string {
+	sizes := make([]string, len(args))
+	for i, arg := range args {
+		blob, err := json.Marshal(arg.Interface())
+		if err != nil {
+			sizes[i] = "?"
+			continue
+		}
+		sizes[i] = fmt.Sprintf("%d bytes", len(blob))
+	}
+	return "[" + strings.Join(sizes, ", ") + "]"
+}
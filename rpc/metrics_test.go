@@ -0,0 +1,157 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AdelineCoin/go-adln/log"
+	"github.com/AdelineCoin/go-adln/metrics"
+)
+
+type MetricsTestService struct{}
+
+func (s *MetricsTestService) Succeed(value string) string {
+	return value
+}
+
+func (s *MetricsTestService) Fail() error {
+	return errors.New("failed")
+}
+
+func (s *MetricsTestService) Slow() {
+	time.Sleep(10 * time.Millisecond)
+}
+
+func (s *MetricsTestService) SlowEcho(value string) string {
+	time.Sleep(10 * time.Millisecond)
+	return value
+}
+
+func TestCallMetrics(t *testing.T) {
+	enabled := metrics.Enabled
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = enabled }()
+
+	server := newTestServer("metrics", new(MetricsTestService))
+	defer server.Stop()
+
+	for i := 0; i < 3; i++ {
+		callPolicy(t, server, "1.2.3.4:1234", "metrics_succeed", `["x"]`)
+	}
+	callPolicy(t, server, "1.2.3.4:1234", "metrics_fail", `[]`)
+
+	tests := []struct {
+		name  string
+		count int64
+	}{
+		{"rpc/success/metrics_succeed", 3},
+		{"rpc/failure/metrics_succeed", 0},
+		{"rpc/failure/metrics_fail", 1},
+	}
+	for _, tt := range tests {
+		var have int64
+		if counter, ok := metrics.DefaultRegistry.Get(tt.name).(metrics.Counter); ok {
+			have = counter.Count()
+		}
+		if have != tt.count {
+			t.Errorf("%s: count mismatch: have %d, want %d", tt.name, have, tt.count)
+		}
+	}
+	timer, ok := metrics.DefaultRegistry.Get("rpc/duration/metrics_succeed").(metrics.Timer)
+	if !ok {
+		t.Fatalf("call duration timer not registered")
+	}
+	if count := timer.Count(); count != 3 {
+		t.Errorf("call duration samples mismatch: have %d, want %d", count, 3)
+	}
+}
+
+func TestSlowCallLog(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		records []*log.Record
+	)
+	handler := log.Root().GetHandler()
+	log.Root().SetHandler(log.FuncHandler(func(r *log.Record) error {
+		lock.Lock()
+		defer lock.Unlock()
+		records = append(records, r)
+		return nil
+	}))
+	defer log.Root().SetHandler(handler)
+
+	server := newTestServer("metrics", new(MetricsTestService))
+	defer server.Stop()
+	if err := server.RegisterName("personal", new(MetricsTestService)); err != nil {
+		t.Fatal(err)
+	}
+	server.SetSlowCallThreshold(5 * time.Millisecond)
+
+	callPolicy(t, server, "1.2.3.4:1234", "metrics_succeed", `["fast"]`)
+	callPolicy(t, server, "1.2.3.4:1234", "metrics_slow", `[]`)
+	callPolicy(t, server, "1.2.3.4:1234", "metrics_slowEcho", `["public"]`)
+	callPolicy(t, server, "1.2.3.4:1234", "personal_slowEcho", `["hunter2"]`)
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	var slow []*log.Record
+	for _, r := range records {
+		if r.Msg == "Slow RPC call" {
+			slow = append(slow, r)
+		}
+	}
+	if len(slow) != 3 {
+		t.Fatalf("slow call log count mismatch: have %d, want %d", len(slow), 3)
+	}
+	ctxs := make([]map[string]interface{}, len(slow))
+	for i, r := range slow {
+		ctxs[i] = make(map[string]interface{})
+		for j := 0; j+1 < len(r.Ctx); j += 2 {
+			ctxs[i][r.Ctx[j].(string)] = r.Ctx[j+1]
+		}
+	}
+	if ctxs[0]["method"] != "metrics_slow" {
+		t.Errorf("logged method mismatch: have %v, want %v", ctxs[0]["method"], "metrics_slow")
+	}
+	if ctxs[0]["remote"] != "1.2.3.4" {
+		t.Errorf("logged remote mismatch: have %v, want %v", ctxs[0]["remote"], "1.2.3.4")
+	}
+	if params := ctxs[1]["params"]; params != `["public"]` {
+		t.Errorf("logged params mismatch: have %v, want %v", params, `["public"]`)
+	}
+	if params := ctxs[2]["params"]; params != "[9 bytes]" {
+		t.Errorf("secret params not redacted: have %v, want %v", params, "[9 bytes]")
+	}
+}
+
+func TestFormatParams(t *testing.T) {
+	short := []reflect.Value{reflect.ValueOf("x"), reflect.ValueOf(1)}
+	if have := formatParams(short); have != `["x",1]` {
+		t.Errorf("short params mismatch: have %s, want %s", have, `["x",1]`)
+	}
+	long := []reflect.Value{reflect.ValueOf(strings.Repeat("x", 2*maxSlowLogParams))}
+	if have := formatParams(long); len(have) > maxSlowLogParams+32 || !strings.HasSuffix(have, "bytes)") {
+		t.Errorf("long params not truncated: %s", have)
+	}
+}
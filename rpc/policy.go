@@ -0,0 +1,329 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLimiterBuckets is the number of tracked clients above which a rate limiter
+// starts dropping the buckets of idle ones.
+const maxLimiterBuckets = 4096
+
+// Policy is the access policy of an RPC server, restricting which methods may
+// be called and how often. The zero value imposes no restrictions.
+//
+// Method lists contain full method names (e.g. "eth_getLogs") or namespace
+// wildcards (e.g. "debug_*"). Subscriptions are matched by their subscribe
+// method (e.g. "eth_subscribe").
+type Policy struct {
+	// AllowMethods, if non-empty, is the exhaustive list of callable methods.
+	AllowMethods []string `toml:",omitempty"`
+
+	// DenyMethods lists the methods which may not be called, taking precedence
+	// over the allowed ones.
+	DenyMethods []string `toml:",omitempty"`
+
+	// IPRate is the number of requests per second a single remote IP address may
+	// issue, with bursts of up to IPBurst requests. Zero disables the limit.
+	IPRate  float64 `toml:",omitempty"`
+	IPBurst int     `toml:",omitempty"`
+
+	// TokenRate is the number of requests per second a single authenticated token
+	// subject may issue, with bursts of up to TokenBurst requests. Zero disables
+	// the limit.
+	TokenRate  float64 `toml:",omitempty"`
+	TokenBurst int     `toml:",omitempty"`
+
+	// MaxConcurrent is the maximum number of calls executed concurrently by the
+	// server. Calls above the limit are rejected. Zero disables the limit.
+	MaxConcurrent int `toml:",omitempty"`
+
+	// MaxResultSize is the maximum size in bytes of an encoded call result. Zero
+	// disables the limit.
+	MaxResultSize int `toml:",omitempty"`
+}
+
+// SetPolicy configures the access policy enforced by the server. It must be
+// called before the server starts serving requests; a nil policy removes all
+// restrictions.
+func (s *Server) SetPolicy(policy *Policy) {
+	if policy == nil {
+		s.policy = nil
+		return
+	}
+	s.policy = newPolicyEnforcer(policy)
+}
+
+// LimitHandler wraps a plain HTTP handler served next to the server, such as
+// the GraphQL endpoint, subjecting it to the rate and concurrency limits of the
+// server's policy and to its response size limits. Responses are buffered and
+// replaced by an error if they grow beyond the limit.
+func (s *Server) LimitHandler(handler http.Handler) http.Handler {
+	limit := s.responseLimit
+	if s.policy != nil && s.policy.maxResultSize > 0 && (limit == 0 || s.policy.maxResultSize < limit) {
+		limit = s.policy.maxResultSize
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.policy != nil {
+			done, err := s.policy.admit(withPeerInfo(r))
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+				return
+			}
+			defer done()
+		}
+		if limit == 0 {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		lw := &limitedResponseWriter{header: make(http.Header), limit: limit, status: http.StatusOK}
+		handler.ServeHTTP(lw, r)
+		if lw.exceeded {
+			http.Error(w, fmt.Sprintf("response too large (>%d bytes)", limit), http.StatusInternalServerError)
+			return
+		}
+		for key, values := range lw.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(lw.status)
+		w.Write(lw.body.Bytes())
+	})
+}
+
+// limitedResponseWriter is an http.ResponseWriter buffering a response of at
+// most limit bytes.
+type limitedResponseWriter struct {
+	header   http.Header
+	status   int
+	body     bytes.Buffer
+	limit    int
+	exceeded bool
+}
+
+func (w *limitedResponseWriter) Header() http.Header { return w.header }
+
+func (w *limitedResponseWriter) WriteHeader(status int) { w.status = status }
+
+func (w *limitedResponseWriter) Write(p []byte) (int, error) {
+	if w.exceeded || w.body.Len()+len(p) > w.limit {
+		w.exceeded = true
+		w.body.Reset()
+		return 0, errResponseTooLarge
+	}
+	return w.body.Write(p)
+}
+
+// policyEnforcer is the runtime state backing a Policy.
+type policyEnforcer struct {
+	allow, deny   methodMatcher
+	ipLimiter     *rateLimiter
+	tokenLimiter  *rateLimiter
+	slots         chan struct{}
+	maxResultSize int
+}
+
+func newPolicyEnforcer(policy *Policy) *policyEnforcer {
+	p := &policyEnforcer{
+		allow:         newMethodMatcher(policy.AllowMethods),
+		deny:          newMethodMatcher(policy.DenyMethods),
+		ipLimiter:     newRateLimiter(policy.IPRate, policy.IPBurst),
+		tokenLimiter:  newRateLimiter(policy.TokenRate, policy.TokenBurst),
+		maxResultSize: policy.MaxResultSize,
+	}
+	if policy.MaxConcurrent > 0 {
+		p.slots = make(chan struct{}, policy.MaxConcurrent)
+	}
+	return p
+}
+
+// permitted reports whether the policy allows calling the given method.
+func (p *policyEnforcer) permitted(method string) bool {
+	if p.deny.match(method) {
+		return false
+	}
+	return len(p.allow) == 0 || p.allow.match(method)
+}
+
+// admit checks the rate and concurrency limits of the calling client. If the
+// call may proceed, the returned function must be invoked once it finished.
+func (p *policyEnforcer) admit(ctx context.Context) (func(), Error) {
+	peer, _ := ctx.Value(peerInfoKey{}).(peerInfo)
+	if peer.addr != "" && !p.ipLimiter.allow(peer.addr) {
+		return nil, &limitExceededError{fmt.Sprintf("request rate limit exceeded for %s", peer.addr)}
+	}
+	if peer.authenticated && !p.tokenLimiter.allow(peer.subject) {
+		return nil, &limitExceededError{"request rate limit exceeded for token"}
+	}
+	if p.slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case p.slots <- struct{}{}:
+		return func() { <-p.slots }, nil
+	default:
+		return nil, &limitExceededError{"too many concurrent requests"}
+	}
+}
+
+// limitResult enforces the maximum result size on a response, returning it in
+// its encoded form to avoid serializing it twice.
+func (p *policyEnforcer) limitResult(response interface{}) (interface{}, Error) {
+	if p.maxResultSize == 0 {
+		return response, nil
+	}
+	blob, err := encodeLimited(response, p.maxResultSize)
+	switch {
+	case err == errResponseTooLarge:
+		return nil, &limitExceededError{fmt.Sprintf("result too large (>%d bytes)", p.maxResultSize)}
+	case err != nil:
+		return nil, &callbackError{err.Error()}
+	}
+	return blob, nil
+}
+
+// requestMethod returns the method name of a request as matched by the policy.
+func requestMethod(r rpcRequest) string {
+	switch {
+	case r.isPubSub && strings.HasSuffix(r.method, unsubscribeMethodSuffix):
+		return r.method
+	case r.isPubSub:
+		return r.service + subscribeMethodSuffix
+	default:
+		return r.service + serviceMethodSeparator + r.method
+	}
+}
+
+// methodMatcher is a set of method names and namespace wildcards.
+type methodMatcher map[string]struct{}
+
+func newMethodMatcher(methods []string) methodMatcher {
+	m := make(methodMatcher)
+	for _, method := range methods {
+		m[strings.TrimSpace(method)] = struct{}{}
+	}
+	return m
+}
+
+// match reports whether the method or its namespace wildcard is in the set.
+func (m methodMatcher) match(method string) bool {
+	if _, ok := m[method]; ok {
+		return true
+	}
+	if i := strings.Index(method, serviceMethodSeparator); i >= 0 {
+		_, ok := m[method[:i]+serviceMethodSeparator+"*"]
+		return ok
+	}
+	return false
+}
+
+// rateLimiter is a set of token buckets keyed by client identity.
+type rateLimiter struct {
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	lock    sync.Mutex
+}
+
+// bucket tracks the allowance of a single client.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter creates a limiter refilling rate tokens per second up to burst.
+// A non-positive rate disables the limiter.
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = int(math.Max(1, math.Ceil(rate)))
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket)}
+}
+
+// allow consumes a token from the bucket of the given client, reporting whether
+// there was any left.
+func (l *rateLimiter) allow(key string) bool {
+	if l == nil {
+		return true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxLimiterBuckets {
+			l.prune(now)
+		}
+		b = &bucket{tokens: l.burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// prune drops the buckets which would be full by now, as those are
+// indistinguishable from fresh ones.
+func (l *rateLimiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// peerInfoKey is the context key under which the identity of the remote end of
+// a connection is stored.
+type peerInfoKey struct{}
+
+// peerInfo identifies the client of a connection for rate limiting purposes.
+type peerInfo struct {
+	addr          string // Remote IP address, empty for IPC and in-process clients
+	subject       string // Subject of the bearer token, if authenticated
+	authenticated bool   // Whether the connection was authenticated via JWT
+}
+
+// withPeerInfo creates a connection context carrying the identity of the client
+// which issued the HTTP request.
+func withPeerInfo(r *http.Request) context.Context {
+	var peer peerInfo
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		peer.addr = host
+	} else {
+		peer.addr = r.RemoteAddr
+	}
+	peer.subject, peer.authenticated = r.Context().Value(subjectKey{}).(string)
+
+	return context.WithValue(context.Background(), peerInfoKey{}, peer)
+}
@@ -0,0 +1,179 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// policyTestResponse is the decoded form of a JSON-RPC response.
+type policyTestResponse struct {
+	Result json.RawMessage
+	Error  *struct {
+		Code    int
+		Message string
+	}
+}
+
+// callPolicy issues an HTTP RPC call against srv from the given remote address.
+func callPolicy(t *testing.T, srv *Server, remote, method, params string) policyTestResponse {
+	body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":` + params + `}`
+	req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(body))
+	req.Header.Set("content-type", contentType)
+	req.RemoteAddr = remote
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	var resp policyTestResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+func TestPolicyMethodFilter(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	server.SetPolicy(&Policy{
+		AllowMethods: []string{"service_*", "rpc_modules"},
+		DenyMethods:  []string{"service_rets"},
+	})
+	tests := []struct {
+		method string
+		params string
+		denied bool
+	}{
+		{"service_noArgsRets", "[]", false},
+		{"service_echo", `["x", 1, {"S": "y"}]`, false},
+		{"service_rets", "[]", true},
+		{"rpc_modules", "[]", false},
+		{"rpc_other", "[]", true},
+	}
+	for _, tt := range tests {
+		resp := callPolicy(t, server, "1.2.3.4:1234", tt.method, tt.params)
+		switch {
+		case tt.denied && (resp.Error == nil || resp.Error.Code != -32601):
+			t.Errorf("%s: expected method not found, got %+v", tt.method, resp)
+		case !tt.denied && resp.Error != nil:
+			t.Errorf("%s: unexpected error: %s", tt.method, resp.Error.Message)
+		}
+	}
+}
+
+func TestPolicyRateLimit(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	server.SetPolicy(&Policy{IPRate: 0.001, IPBurst: 2})
+
+	for i := 0; i < 2; i++ {
+		if resp := callPolicy(t, server, "1.2.3.4:1234", "service_rets", "[]"); resp.Error != nil {
+			t.Fatalf("call %d: unexpected error: %s", i, resp.Error.Message)
+		}
+	}
+	// The burst is exhausted, further calls from the same address must fail
+	if resp := callPolicy(t, server, "1.2.3.4:5678", "service_rets", "[]"); resp.Error == nil || resp.Error.Code != -32005 {
+		t.Fatalf("expected rate limit error, got %+v", resp)
+	}
+	// Other clients have their own allowance
+	if resp := callPolicy(t, server, "5.6.7.8:1234", "service_rets", "[]"); resp.Error != nil {
+		t.Fatalf("unexpected error for other client: %s", resp.Error.Message)
+	}
+}
+
+func TestPolicyConcurrencyLimit(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	server.SetPolicy(&Policy{MaxConcurrent: 1})
+
+	done := make(chan policyTestResponse)
+	go func() {
+		done <- callPolicy(t, server, "1.2.3.4:1234", "service_sleep", `[500000000]`)
+	}()
+	// Wait for the sleeping call to occupy the only slot
+	for deadline := time.Now().Add(5 * time.Second); len(server.policy.slots) == 0; {
+		if time.Now().After(deadline) {
+			t.Fatalf("sleeping call never started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if resp := callPolicy(t, server, "1.2.3.4:1234", "service_rets", "[]"); resp.Error == nil || resp.Error.Code != -32005 {
+		t.Fatalf("expected concurrency limit error, got %+v", resp)
+	}
+	if resp := <-done; resp.Error != nil {
+		t.Fatalf("sleeping call failed: %s", resp.Error.Message)
+	}
+	if resp := callPolicy(t, server, "1.2.3.4:1234", "service_rets", "[]"); resp.Error != nil {
+		t.Fatalf("unexpected error after slot was released: %s", resp.Error.Message)
+	}
+}
+
+func TestPolicyResultSizeLimit(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	server.SetPolicy(&Policy{MaxResultSize: 128})
+
+	resp := callPolicy(t, server, "1.2.3.4:1234", "service_echo", `["short", 1, {"S": "y"}]`)
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %s", resp.Error.Message)
+	}
+	var result Result
+	if err := json.Unmarshal(resp.Result, &result); err != nil || result.String != "short" {
+		t.Fatalf("invalid result %s: %v", resp.Result, err)
+	}
+	resp = callPolicy(t, server, "1.2.3.4:1234", "service_echo", `["`+strings.Repeat("x", 256)+`", 1, {"S": "y"}]`)
+	if resp.Error == nil || resp.Error.Code != -32005 {
+		t.Fatalf("expected result size error, got %+v", resp)
+	}
+}
+
+// Tests that plain HTTP handlers mounted next to the server are subject to its
+// rate and response size limits.
+func TestLimitHandler(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	server.SetPolicy(&Policy{IPRate: 0.001, IPBurst: 1})
+	server.SetBatchLimits(0, 16)
+
+	handler := server.LimitHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "text/plain")
+		w.Write([]byte(r.URL.Query().Get("reply")))
+	}))
+	serve := func(remote, reply string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "http://localhost/plain?reply="+reply, nil)
+		req.RemoteAddr = remote
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := serve("1.2.3.4:1234", "short"); rec.Code != http.StatusOK || rec.Body.String() != "short" || rec.Header().Get("content-type") != "text/plain" {
+		t.Fatalf("short response mismatch: code %d, body %q", rec.Code, rec.Body.String())
+	}
+	// The burst is exhausted, further requests from the same address must fail
+	if rec := serve("1.2.3.4:5678", "short"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected rate limit error, got code %d", rec.Code)
+	}
+	// Responses beyond the limit are replaced by an error
+	if rec := serve("5.6.7.8:1234", strings.Repeat("x", 17)); rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "xxx") {
+		t.Fatalf("expected response size error, got code %d, body %q", rec.Code, rec.Body.String())
+	}
+}
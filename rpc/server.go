@@ -24,6 +24,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/AdelineCoin/go-adln/log"
 	"gopkg.in/fatih/set.v0"
@@ -58,6 +59,19 @@ func NewServer() *Server {
 	return server
 }
 
+// SetBatchLimits configures the maximum number of requests accepted in a batch
+// and the maximum size in bytes of a response, counting batch responses as a
+// whole. Zero values disable the respective limit. It must be called before the
+// server starts serving requests.
+//
+// Responses are streamed to the connection while being encoded. A response found
+// to exceed the limit before any of it was sent is replaced by an error, but one
+// exceeding it later on is cut short and its connection closed.
+func (s *Server) SetBatchLimits(itemLimit, responseLimit int) {
+	s.batchLimit = itemLimit
+	s.responseLimit = responseLimit
+}
+
 // RPCService gives meta information about the server.
 // e.g. gives information about the loaded modules.
 type RPCService struct {
@@ -125,7 +139,7 @@ func (s *Server) RegisterName(name string, rcvr interface{}) error {
 // If singleShot is true it will process a single request, otherwise it will handle
 // requests until the codec returns an error when reading a request (in most cases
 // an EOF). It executes requests in parallel when singleShot is false.
-func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecOption) error {
+func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot bool, options CodecOption) error {
 	var pend sync.WaitGroup
 
 	defer func() {
@@ -140,14 +154,22 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 		s.codecsMu.Unlock()
 	}()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	// enforce the response size limit while the responses are written
+	if lc, ok := codec.(limitedCodec); ok {
+		lc.setResponseLimit(s.responseLimit)
+	}
+
 	// if the codec supports notification include a notifier that callbacks can use
 	// to send notification to clients. It is thight to the codec/connection. If the
 	// connection is closed the notifier will stop and cancels all active subscriptions.
 	if options&OptionSubscriptions == OptionSubscriptions {
-		ctx = context.WithValue(ctx, notifierKey{}, newNotifier(codec))
+		notifier := newNotifier(codec)
+		defer notifier.close()
+
+		ctx = context.WithValue(ctx, notifierKey{}, notifier)
 	}
 	s.codecsMu.Lock()
 	if atomic.LoadInt32(&s.run) != 1 { // server stopped
@@ -186,6 +208,15 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 			}
 			return nil
 		}
+		// reject batches above the configured size without executing any of them
+		if batch && s.batchLimit > 0 && len(reqs) > s.batchLimit {
+			err := &limitExceededError{fmt.Sprintf("batch too large (%d>%d)", len(reqs), s.batchLimit)}
+			codec.Write(codec.CreateErrorResponse(nil, err))
+			if singleShot {
+				return nil
+			}
+			continue
+		}
 		// If a single shot request is executing, run and return immediately
 		if singleShot {
 			if batch {
@@ -215,14 +246,14 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 // stopped. In either case the codec is closed.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	defer codec.Close()
-	s.serveRequest(codec, false, options)
+	s.serveRequest(context.Background(), codec, false, options)
 }
 
 // ServeSingleRequest reads and processes a single RPC request from the given codec. It will not
 // close the codec unless a non-recoverable error has occurred. Note, this method will return after
 // a single request has been processed!
 func (s *Server) ServeSingleRequest(codec ServerCodec, options CodecOption) {
-	s.serveRequest(codec, true, options)
+	s.serveRequest(context.Background(), codec, true, options)
 }
 
 // Stop will stop reading new requests, wait for stopPendingRequestTimeout to allow pending requests to finish,
@@ -255,10 +286,22 @@ func (s *Server) createSubscription(ctx context.Context, c ServerCodec, req *ser
 
 // handle executes a request and returns the response from the callback.
 func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverRequest) (interface{}, func()) {
+	rpcRequestCounter.Inc(1)
 	if req.err != nil {
+		rpcRejectedCallsCounter.Inc(1)
 		return codec.CreateErrorResponse(&req.id, req.err), nil
 	}
 
+	// enforce the rate and concurrency limits of the access policy
+	if s.policy != nil {
+		release, err := s.policy.admit(ctx)
+		if err != nil {
+			rpcRejectedCallsCounter.Inc(1)
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+		defer release()
+	}
+
 	if req.isUnsubscribe { // cancel subscription, first param must be the subscription id
 		if len(req.args) >= 1 && req.args[0].Kind() == reflect.String {
 			notifier, supported := NotifierFromContext(ctx)
@@ -277,7 +320,9 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	}
 
 	if req.callb.isSubscribe {
+		start := time.Now()
 		subid, err := s.createSubscription(ctx, codec, req)
+		s.trackCall(ctx, req, time.Since(start), err != nil)
 		if err != nil {
 			return codec.CreateErrorResponse(&req.id, &callbackError{err.Error()}), nil
 		}
@@ -308,7 +353,9 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	}
 
 	// execute RPC method and return result
+	start := time.Now()
 	reply := req.callb.method.Func.Call(arguments)
+	s.trackCall(ctx, req, time.Since(start), req.callb.errPos >= 0 && !reply[req.callb.errPos].IsNil())
 	if len(reply) == 0 {
 		return codec.CreateResponse(req.id, nil), nil
 	}
@@ -320,20 +367,27 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 			return res, nil
 		}
 	}
-	return codec.CreateResponse(req.id, reply[0].Interface()), nil
+	res := codec.CreateResponse(req.id, reply[0].Interface())
+	if s.policy != nil {
+		var err Error
+		if res, err = s.policy.limitResult(res); err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+	}
+	return res, nil
 }
 
 // exec executes the given request and writes the result back using the codec.
 func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest) {
-	var response interface{}
-	var callback func()
-	if req.err != nil {
-		response = codec.CreateErrorResponse(&req.id, req.err)
-	} else {
-		response, callback = s.handle(ctx, codec, req)
-	}
+	response, callback := s.handle(ctx, codec, req)
 
-	if err := codec.Write(response); err != nil {
+	err := codec.Write(response)
+	if err == errResponseTooLarge {
+		// Nothing was sent yet, report the failure instead of the result
+		callback = nil
+		err = codec.Write(codec.CreateErrorResponse(&req.id, &limitExceededError{errResponseTooLarge.Error()}))
+	}
+	if err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
 		codec.Close()
 	}
@@ -345,22 +399,26 @@ func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest
 }
 
 // execBatch executes the given requests and writes the result back using the codec.
-// It will only write the response back when the last request is processed.
+// It will only write the response back when the last request is processed. If
+// the responses exceed the response size limit as a whole, a single error is
+// returned instead.
 func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*serverRequest) {
 	responses := make([]interface{}, len(requests))
 	var callbacks []func()
 	for i, req := range requests {
-		if req.err != nil {
-			responses[i] = codec.CreateErrorResponse(&req.id, req.err)
-		} else {
-			var callback func()
-			if responses[i], callback = s.handle(ctx, codec, req); callback != nil {
-				callbacks = append(callbacks, callback)
-			}
+		var callback func()
+		if responses[i], callback = s.handle(ctx, codec, req); callback != nil {
+			callbacks = append(callbacks, callback)
 		}
 	}
 
-	if err := codec.Write(responses); err != nil {
+	err := codec.Write(responses)
+	if err == errResponseTooLarge {
+		// Nothing was sent yet, report the failure instead of the results
+		callbacks = nil
+		err = codec.Write(codec.CreateErrorResponse(nil, &limitExceededError{errResponseTooLarge.Error()}))
+	}
+	if err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
 		codec.Close()
 	}
@@ -393,7 +451,7 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 		}
 
 		if r.isPubSub && strings.HasSuffix(r.method, unsubscribeMethodSuffix) {
-			requests[i] = &serverRequest{id: r.id, isUnsubscribe: true}
+			requests[i] = &serverRequest{id: r.id, method: r.method, isUnsubscribe: true}
 			argTypes := []reflect.Type{reflect.TypeOf("")} // expect subscription id as first arg
 			if args, err := codec.ParseRequestArguments(argTypes, r.params); err == nil {
 				requests[i].args = args
@@ -403,6 +461,11 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 			continue
 		}
 
+		if s.policy != nil && !s.policy.permitted(requestMethod(r)) { // rpc method denied by the policy
+			requests[i] = &serverRequest{id: r.id, err: &methodNotFoundError{r.service, r.method}}
+			continue
+		}
+
 		if svc, ok = s.services[r.service]; !ok { // rpc method isn't available
 			requests[i] = &serverRequest{id: r.id, err: &methodNotFoundError{r.service, r.method}}
 			continue
@@ -410,7 +473,7 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 
 		if r.isPubSub { // eth_subscribe, r.method contains the subscription method name
 			if callb, ok := svc.subscriptions[r.method]; ok {
-				requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+				requests[i] = &serverRequest{id: r.id, svcname: svc.name, method: requestMethod(r), callb: callb}
 				if r.params != nil && len(callb.argTypes) > 0 {
 					argTypes := []reflect.Type{reflect.TypeOf("")}
 					argTypes = append(argTypes, callb.argTypes...)
@@ -427,7 +490,7 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 		}
 
 		if callb, ok := svc.callbacks[r.method]; ok { // lookup RPC method
-			requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+			requests[i] = &serverRequest{id: r.id, svcname: svc.name, method: requestMethod(r), callb: callb}
 			if r.params != nil && len(callb.argTypes) > 0 {
 				if args, err := codec.ParseRequestArguments(callb.argTypes, r.params); err == nil {
 					requests[i].args = args
@@ -0,0 +1,173 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+)
+
+var (
+	// errResponseTooLarge is returned when an encoded response exceeds the allowed
+	// number of bytes before any of it was sent.
+	errResponseTooLarge = errors.New("response too large")
+
+	// errResponseTruncated is returned when an encoded response exceeds the allowed
+	// number of bytes after part of it was already sent, corrupting the stream.
+	errResponseTruncated = errors.New("response too large, truncated")
+)
+
+// limitedCodec is implemented by the codecs enforcing a size limit on responses
+// while writing them.
+type limitedCodec interface {
+	setResponseLimit(limit int)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// writeJSON encodes a JSON-RPC message into w. Batches and list results are
+// written element by element, so the full encoding of a large response is never
+// held in memory at once.
+func writeJSON(w io.Writer, msg interface{}) error {
+	switch msg := msg.(type) {
+	case json.RawMessage:
+		_, err := w.Write(msg)
+		return err
+
+	case []interface{}:
+		return writeJSONList(w, len(msg), func(i int) interface{} { return msg[i] })
+
+	case *jsonSuccessResponse:
+		result := reflect.ValueOf(msg.Result)
+		if !isStreamable(result) {
+			break
+		}
+		if _, err := io.WriteString(w, `{"jsonrpc":"`+msg.Version+`",`); err != nil {
+			return err
+		}
+		if msg.Id != nil {
+			if _, err := io.WriteString(w, `"id":`); err != nil {
+				return err
+			}
+			if err := writeJSONValue(w, msg.Id); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, `"result":`); err != nil {
+			return err
+		}
+		if err := writeJSONList(w, result.Len(), func(i int) interface{} { return result.Index(i).Interface() }); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "}")
+		return err
+	}
+	return writeJSONValue(w, msg)
+}
+
+// writeJSONList encodes a JSON array of n items into w, one item at a time.
+func writeJSONList(w io.Writer, n int, item func(i int) interface{}) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := writeJSON(w, item(i)); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]")
+	return err
+}
+
+// writeJSONValue encodes a single value into w in one go.
+func writeJSONValue(w io.Writer, v interface{}) error {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(blob)
+	return err
+}
+
+// isStreamable reports whether a result is a list which may be encoded element
+// by element without altering its JSON representation.
+func isStreamable(v reflect.Value) bool {
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return false // encodes as null
+		}
+	case reflect.Array:
+	default:
+		return false
+	}
+	t := v.Type()
+	if t.Elem().Kind() == reflect.Uint8 {
+		return false // encodes as a base64 string
+	}
+	for _, typ := range []reflect.Type{t, reflect.PtrTo(t)} {
+		if typ.Implements(jsonMarshalerType) || typ.Implements(textMarshalerType) {
+			return false
+		}
+	}
+	return true
+}
+
+// limitedWriter is an io.Writer failing once more than limit bytes were written
+// through it. A zero limit disables the check.
+type limitedWriter struct {
+	w       io.Writer
+	limit   int
+	written int
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if lw.limit > 0 && lw.written+len(p) > lw.limit {
+		return 0, errResponseTooLarge
+	}
+	n, err := lw.w.Write(p)
+	lw.written += n
+	return n, err
+}
+
+// encodeLimited encodes a message in memory, aborting as soon as the encoding
+// grows beyond limit bytes.
+func encodeLimited(msg interface{}, limit int) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	if err := writeJSON(&limitedWriter{w: &buf, limit: limit}, msg); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
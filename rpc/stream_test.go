@@ -0,0 +1,208 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AdelineCoin/go-adln/common/hexutil"
+)
+
+// Tests that the streaming encoder produces the same output as the standard
+// library marshaller.
+func TestWriteJSONStreaming(t *testing.T) {
+	id := json.RawMessage(`1`)
+	results := []interface{}{
+		nil,
+		"string",
+		[]string{"a", "b<>&"},
+		[]string(nil),
+		[]string{},
+		[]byte{1, 2, 3},
+		hexutil.Bytes{1, 2, 3},
+		[2]int{1, 2},
+		[]*big.Int{big.NewInt(1), nil},
+		[][]int{{1, 2}, nil, {}},
+		[]interface{}{1, "x", map[string]int{"a": 1}},
+		[]Result{{String: "s", Int: 1, Args: &Args{S: "t"}}},
+	}
+	codec := &jsonCodec{}
+	for i, result := range results {
+		responses := []interface{}{
+			codec.CreateResponse(&id, result),
+			codec.CreateResponse(nil, result),
+			[]interface{}{codec.CreateResponse(&id, result), codec.CreateErrorResponse(&id, &callbackError{"fail"})},
+		}
+		for j, response := range responses {
+			want, err := json.Marshal(response)
+			if err != nil {
+				t.Fatalf("test %d.%d: failed to marshal: %v", i, j, err)
+			}
+			var have bytes.Buffer
+			if err := writeJSON(&have, response); err != nil {
+				t.Fatalf("test %d.%d: failed to stream: %v", i, j, err)
+			}
+			if have.String() != string(want) {
+				t.Errorf("test %d.%d: encoding mismatch:\nhave %s\nwant %s", i, j, have.String(), want)
+			}
+		}
+	}
+}
+
+func TestEncodeLimited(t *testing.T) {
+	response := (&jsonCodec{}).CreateResponse(1, []string{strings.Repeat("x", 100), strings.Repeat("y", 100)})
+	if _, err := encodeLimited(response, 150); err != errResponseTooLarge {
+		t.Fatalf("error mismatch: have %v, want %v", err, errResponseTooLarge)
+	}
+	blob, err := encodeLimited(response, 1024)
+	if err != nil {
+		t.Fatalf("failed to encode: %v", err)
+	}
+	if !json.Valid(blob) {
+		t.Fatalf("invalid encoding: %s", blob)
+	}
+}
+
+// callBatch issues an HTTP RPC call with the given raw body against srv.
+func callBatch(t *testing.T, srv *Server, body string) []policyTestResponse {
+	req := httptest.NewRequest(http.MethodPost, "http://localhost", strings.NewReader(body))
+	req.Header.Set("content-type", contentType)
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+
+	blob := bytes.TrimSpace(rec.Body.Bytes())
+	if len(blob) > 0 && blob[0] != '[' {
+		blob = append(append([]byte{'['}, blob...), ']')
+	}
+	var resps []policyTestResponse
+	if err := json.Unmarshal(blob, &resps); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return resps
+}
+
+func TestBatchItemLimit(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	server.SetBatchLimits(2, 0)
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"service_rets","params":[]}`
+	if resps := callBatch(t, server, "["+call+","+call+"]"); len(resps) != 2 || resps[0].Error != nil || resps[1].Error != nil {
+		t.Fatalf("batch within limit failed: %+v", resps)
+	}
+	resps := callBatch(t, server, "["+call+","+call+","+call+"]")
+	if len(resps) != 1 || resps[0].Error == nil || resps[0].Error.Code != -32005 {
+		t.Fatalf("expected single batch limit error, got %+v", resps)
+	}
+}
+
+func TestResponseSizeLimit(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	server.SetBatchLimits(0, 256)
+
+	call := func(id int, size int) string {
+		return `{"jsonrpc":"2.0","id":` + string('0'+rune(id)) + `,"method":"service_echo","params":["` + strings.Repeat("x", size) + `",1,{"S":"y"}]}`
+	}
+	// Single responses are limited on their own
+	if resps := callBatch(t, server, call(1, 16)); len(resps) != 1 || resps[0].Error != nil {
+		t.Fatalf("small response failed: %+v", resps)
+	}
+	if resps := callBatch(t, server, call(1, 300)); len(resps) != 1 || resps[0].Error == nil || resps[0].Error.Code != -32005 {
+		t.Fatalf("expected response limit error, got %+v", resps)
+	}
+	// Batch responses are limited as a whole
+	if resps := callBatch(t, server, "["+call(1, 16)+","+call(2, 16)+"]"); len(resps) != 2 || resps[0].Error != nil || resps[1].Error != nil {
+		t.Fatalf("small batch failed: %+v", resps)
+	}
+	resps := callBatch(t, server, "["+call(1, 100)+","+call(2, 100)+","+call(3, 1)+"]")
+	if len(resps) != 1 || resps[0].Error == nil || resps[0].Error.Code != -32005 {
+		t.Fatalf("expected single response limit error, got %+v", resps)
+	}
+}
+
+// Tests that websocket responses are held to the response size limit too.
+func TestWebsocketResponseSizeLimit(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	defer server.Stop()
+	server.SetBatchLimits(0, 256)
+
+	httpsrv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer httpsrv.Close()
+
+	client, err := DialWebsocket(context.Background(), "ws://"+httpsrv.Listener.Addr().String(), "")
+	if err != nil {
+		t.Fatalf("failed to dial websocket: %v", err)
+	}
+	defer client.Close()
+
+	var result Result
+	if err := client.Call(&result, "service_echo", strings.Repeat("x", 16), 1, &Args{S: "y"}); err != nil {
+		t.Fatalf("small response failed: %v", err)
+	}
+	err = client.Call(&result, "service_echo", strings.Repeat("x", 300), 1, &Args{S: "y"})
+	if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != -32005 {
+		t.Fatalf("expected response limit error, got %v", err)
+	}
+}
+
+// Tests that responses are streamed into the connection through the size limit,
+// holding back only their start.
+func TestStreamLimit(t *testing.T) {
+	var (
+		out   bytes.Buffer
+		codec = NewJSONCodec(&httpReadWriteNopCloser{strings.NewReader(""), &out}).(*jsonCodec)
+	)
+	items := make([]string, 100)
+	for i := range items {
+		items[i] = strings.Repeat("x", 100)
+	}
+	response := codec.CreateResponse(1, items)
+
+	// Responses exceeding the limit within the buffer are never sent
+	codec.setResponseLimit(1024)
+	if err := codec.Write(response); err != errResponseTooLarge {
+		t.Fatalf("error mismatch: have %v, want %v", err, errResponseTooLarge)
+	}
+	if out.Len() != 0 {
+		t.Fatalf("rejected response partially sent: %d bytes", out.Len())
+	}
+	// Responses exceeding the limit past the buffer are cut short
+	codec.setResponseLimit(8192)
+	if err := codec.Write(response); err != errResponseTruncated {
+		t.Fatalf("error mismatch: have %v, want %v", err, errResponseTruncated)
+	}
+	if out.Len() == 0 || out.Len() > 8192 {
+		t.Fatalf("truncated response size mismatch: have %d, want within (0, 8192]", out.Len())
+	}
+	// Responses within the limit are sent whole
+	out.Reset()
+	codec.setResponseLimit(16384)
+	if err := codec.Write(response); err != nil {
+		t.Fatalf("failed to write response: %v", err)
+	}
+	if !json.Valid(out.Bytes()) {
+		t.Fatalf("invalid response: %s", out.String())
+	}
+}
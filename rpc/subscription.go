@@ -115,6 +115,7 @@ func (n *Notifier) unsubscribe(id ID) error {
 	if s, found := n.active[id]; found {
 		close(s.err)
 		delete(n.active, id)
+		rpcSubscriptionsCounter.Dec(1)
 		return nil
 	}
 	return ErrSubscriptionNotFound
@@ -131,5 +132,17 @@ func (n *Notifier) activate(id ID, namespace string) {
 		sub.namespace = namespace
 		n.active[id] = sub
 		delete(n.inactive, id)
+		rpcSubscriptionsCounter.Inc(1)
 	}
 }
+
+// close drops all subscriptions of the notifier once its connection is torn
+// down. The subscribers themselves are notified through Closed.
+func (n *Notifier) close() {
+	n.subMu.Lock()
+	defer n.subMu.Unlock()
+
+	rpcSubscriptionsCounter.Dec(int64(len(n.active)))
+	n.active = make(map[ID]*Subscription)
+	n.inactive = make(map[ID]*Subscription)
+}
@@ -34,21 +34,25 @@ import (
 	"gopkg.in/fatih/set.v0"
 )
 
-// websocketJSONCodec is a custom JSON codec with payload size enforcement and
-// special number parsing.
-var websocketJSONCodec = websocket.Codec{
-	// Marshal is the stock JSON marshaller used by the websocket library too.
-	Marshal: func(v interface{}) ([]byte, byte, error) {
-		msg, err := json.Marshal(v)
-		return msg, websocket.TextFrame, err
-	},
-	// Unmarshal is a specialized unmarshaller to properly convert numbers.
-	Unmarshal: func(msg []byte, payloadType byte, v interface{}) error {
-		dec := json.NewDecoder(bytes.NewReader(msg))
-		dec.UseNumber()
-
-		return dec.Decode(v)
-	},
+// websocketJSONCodec creates a custom JSON codec with payload size enforcement
+// and special number parsing.
+func websocketJSONCodec(limit int) websocket.Codec {
+	return websocket.Codec{
+		// Marshal encodes messages up to limit bytes. The websocket library sends
+		// every message in a single frame, so they can't be streamed to the
+		// connection, but at most limit bytes are ever held in memory.
+		Marshal: func(v interface{}) ([]byte, byte, error) {
+			msg, err := encodeLimited(v, limit)
+			return msg, websocket.TextFrame, err
+		},
+		// Unmarshal is a specialized unmarshaller to properly convert numbers.
+		Unmarshal: func(msg []byte, payloadType byte, v interface{}) error {
+			dec := json.NewDecoder(bytes.NewReader(msg))
+			dec.UseNumber()
+
+			return dec.Decode(v)
+		},
+	}
 }
 
 // WebsocketHandler returns a handler that serves JSON-RPC to WebSocket connections.
@@ -62,22 +66,26 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			// Create a custom encode/decode pair to enforce payload size and number encoding
 			conn.MaxPayloadBytes = maxRequestContentLength
 
+			wsCodec := websocketJSONCodec(srv.responseLimit)
 			encoder := func(v interface{}) error {
-				return websocketJSONCodec.Send(conn, v)
+				return wsCodec.Send(conn, v)
 			}
 			decoder := func(v interface{}) error {
-				return websocketJSONCodec.Receive(conn, v)
+				return wsCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			codec := newModuleFilterCodec(conn.Request().Context(), NewCodec(conn, encoder, decoder))
+			defer codec.Close()
+			srv.serveRequest(withPeerInfo(conn.Request()), codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
 
-// NewWSServer creates a new websocket RPC server around an API provider.
+// NewWSServer creates a new websocket RPC server around an API provider. If a
+// JWT configuration is given, the upgrade requests must carry a valid token.
 //
 // Deprecated: use Server.WebsocketHandler
-func NewWSServer(allowedOrigins []string, srv *Server) *http.Server {
-	return &http.Server{Handler: srv.WebsocketHandler(allowedOrigins)}
+func NewWSServer(allowedOrigins []string, jwt *JWTConfig, srv *Server) *http.Server {
+	return &http.Server{Handler: newJWTHandler(jwt, srv.WebsocketHandler(allowedOrigins))}
 }
 
 // wsHandshakeValidator returns a handler that verifies the origin during the
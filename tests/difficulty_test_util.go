@@ -17,10 +17,14 @@
 package tests
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/AdelineCoin/go-adln/common"
 	"github.com/AdelineCoin/go-adln/common/math"
+	"github.com/AdelineCoin/go-adln/consensus/ethash"
+	"github.com/AdelineCoin/go-adln/core/types"
+	"github.com/AdelineCoin/go-adln/params"
 )
 
 //go:generate gencodec -type DifficultyTest -field-override difficultyTestMarshaling -out gen_difficultytest.go
@@ -41,4 +45,24 @@ type difficultyTestMarshaling struct {
 	CurrentDifficulty  *math.HexOrDecimal256
 	UncleHash          common.Hash
 	CurrentBlockNumber math.HexOrDecimal64
-}
\ No newline at end of file
+}
+
+func (test *DifficultyTest) Run(config *params.ChainConfig) error {
+	parentNumber := big.NewInt(int64(test.CurrentBlockNumber - 1))
+	parent := &types.Header{
+		Difficulty: test.ParentDifficulty,
+		Time:       test.ParentTimestamp,
+		Number:     parentNumber,
+		UncleHash:  test.UncleHash,
+	}
+
+	actual := ethash.CalcDifficulty(nil, config, test.CurrentTimestamp.Uint64(), parent)
+	exp := test.CurrentDifficulty
+
+	if actual.Cmp(exp) != 0 {
+		return fmt.Errorf("parent[time %v diff %v unclehash:%x] child[time %v number %v] diff %v != expected %v",
+			test.ParentTimestamp, test.ParentDifficulty, test.UncleHash,
+			test.CurrentTimestamp, test.CurrentBlockNumber, actual, exp)
+	}
+	return nil
+}
@@ -52,7 +52,7 @@ func TestState(t *testing.T) {
 			name := name + "/" + key
 			t.Run(key, func(t *testing.T) {
 				if subtest.Fork == "Constantinople" {
-					t.Skip("constantinople not supported yet")
+					t.Skip("constantinople with EIP-1283 not supported, see Petersburg")
 				}
 				withTrace(t, test.gasLimit(subtest), func(vmconfig vm.Config) error {
 					_, err := test.Run(subtest, vmconfig)